@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// protoc-gen-go-hadooprpc generates the clients, and optionally the server interfaces, of hadoop rpc for the
+// services in proto files. The generated code goes to the package of option package instead of the package of
+// messages, since clients depend on pkg/yarn/client/ipc, which depends on the messages. Server interfaces go to
+// their own package, so that clients do not link pkg/yarn/server/ipc.
+//
+// Options:
+//
+//	package=<import path>                 go package of the generated clients, required
+//	protocol=<service>=<protocol name>    hadoop protocol name of service, required for every service generated
+//	protocol_var=<service>=<var name>     name of the protocol name var, UPPER_SNAKE of the service by default
+//	server_package=<import path>          go package of server interfaces registered to pkg/yarn/server/ipc, which is
+//	                                      a sub package of package, no server interfaces are generated if not set
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/compiler/protogen"
+)
+
+func main() {
+	opts := newOptions()
+	protogen.Options{ParamFunc: opts.flags().Set}.Run(func(gen *protogen.Plugin) error {
+		return generate(gen, opts)
+	})
+}
+
+// keyValues is a flag of repeated <key>=<value>
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	return fmt.Sprint(map[string]string(kv))
+}
+
+func (kv keyValues) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" || v == "" {
+		return fmt.Errorf("invalid value %q, expecting <service>=<value>", value)
+	}
+	kv[k] = v
+	return nil
+}
+
+type options struct {
+	goPackage     string
+	protocols     keyValues
+	protocolVars  keyValues
+	serverPackage string
+}
+
+func newOptions() *options {
+	return &options{protocols: keyValues{}, protocolVars: keyValues{}}
+}
+
+// flags parses the plugin parameters into o
+func (o *options) flags() *flag.FlagSet {
+	flags := flag.NewFlagSet("protoc-gen-go-hadooprpc", flag.ContinueOnError)
+	flags.StringVar(&o.goPackage, "package", "", "go package of the generated clients")
+	flags.Var(o.protocols, "protocol", "hadoop protocol name of service, in the form of <service>=<protocol name>")
+	flags.Var(o.protocolVars, "protocol_var", "name of the protocol name var of service, in the form of <service>=<var name>")
+	flags.StringVar(&o.serverPackage, "server_package", "", "go package of the generated server interfaces")
+	return flags
+}
+
+const (
+	contextPackage   = protogen.GoImportPath("context")
+	authPackage      = protogen.GoImportPath("github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth")
+	ipcClientPackage = protogen.GoImportPath("github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc")
+	ipcServerPackage = protogen.GoImportPath("github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/ipc")
+)
+
+const licenseHeader = `/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+`
+
+func generate(gen *protogen.Plugin, opts *options) error {
+	if opts.goPackage == "" {
+		return fmt.Errorf("option package is required")
+	}
+	if opts.serverPackage != "" && !strings.HasPrefix(opts.serverPackage, opts.goPackage+"/") {
+		return fmt.Errorf("option server_package %v is not a sub package of %v", opts.serverPackage, opts.goPackage)
+	}
+	for _, file := range gen.Files {
+		if !file.Generate || len(file.Services) == 0 {
+			continue
+		}
+		if err := generateFile(gen, file, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateFile generates <proto name>_hadooprpc.pb.go of the services in file, the one of server interfaces is
+// generated into the directory of server_package relative to the one of package
+func generateFile(gen *protogen.Plugin, file *protogen.File, opts *options) error {
+	importPath := protogen.GoImportPath(opts.goPackage)
+	filename := strings.TrimSuffix(path.Base(file.Desc.Path()), ".proto") + "_hadooprpc.pb.go"
+	g := newGeneratedFile(gen, file, filename, opts.goPackage)
+	var server *protogen.GeneratedFile
+	if opts.serverPackage != "" {
+		dir := strings.TrimPrefix(opts.serverPackage, opts.goPackage+"/")
+		server = newGeneratedFile(gen, file, path.Join(dir, filename), opts.serverPackage)
+	}
+	for _, service := range file.Services {
+		protocol, ok := opts.protocols[service.GoName]
+		if !ok {
+			return fmt.Errorf("hadoop protocol name of service %v in %v is unknown, set it by option protocol=%v=<protocol name>",
+				service.GoName, file.Desc.Path(), service.GoName)
+		}
+		protocolVar, ok := opts.protocolVars[service.GoName]
+		if !ok {
+			protocolVar = protocolVarName(service.GoName)
+		}
+		generateClient(g, service, protocol, protocolVar)
+		if server != nil {
+			generateServer(server, service, importPath.Ident(protocolVar))
+		}
+	}
+	return nil
+}
+
+func newGeneratedFile(gen *protogen.Plugin, file *protogen.File, filename string, goPackage string) *protogen.GeneratedFile {
+	g := gen.NewGeneratedFile(filename, protogen.GoImportPath(goPackage))
+	g.P(licenseHeader)
+	g.P("// Code generated by protoc-gen-go-hadooprpc. DO NOT EDIT.")
+	g.P("// source: ", file.Desc.Path())
+	g.P()
+	g.P("package ", path.Base(goPackage))
+	g.P()
+	return g
+}
+
+func generateClient(g *protogen.GeneratedFile, service *protogen.Service, protocol string, protocolVar string) {
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	background := g.QualifiedGoIdent(contextPackage.Ident("Background"))
+	newHeader := g.QualifiedGoIdent(authPackage.Ident("NewRPCRequestHeaderProto"))
+	clientName := service.GoName + "Client"
+
+	g.P("// ", protocolVar, " is the hadoop protocol name of ", service.GoName)
+	g.P("var ", protocolVar, " = ", fmt.Sprintf("%q", protocol))
+	g.P()
+	g.P("// ", service.GoName, " is the client of ", protocolVar, ", see ", clientName)
+	g.P("type ", service.GoName, " interface {")
+	for _, method := range service.Methods {
+		in, out := g.QualifiedGoIdent(method.Input.GoIdent), g.QualifiedGoIdent(method.Output.GoIdent)
+		g.P(method.GoName, "(in *", in, ", out *", out, ") error")
+		g.P(method.GoName, "WithContext(ctx ", ctx, ", in *", in, ", out *", out, ") error")
+	}
+	g.P("}")
+	g.P()
+	g.P("var _ ", service.GoName, " = &", clientName, "{}")
+	g.P()
+	g.P("// ", clientName, " calls the methods of ", protocolVar, " by the ipc client embedded")
+	g.P("type ", clientName, " struct {")
+	g.P("*", g.QualifiedGoIdent(ipcClientPackage.Ident("Client")))
+	g.P("}")
+	g.P()
+	for _, method := range service.Methods {
+		in, out := g.QualifiedGoIdent(method.Input.GoIdent), g.QualifiedGoIdent(method.Output.GoIdent)
+		g.P("func (c *", clientName, ") ", method.GoName, "(in *", in, ", out *", out, ") error {")
+		g.P("return c.", method.GoName, "WithContext(", background, "(), in, out)")
+		g.P("}")
+		g.P()
+		g.P("func (c *", clientName, ") ", method.GoName, "WithContext(ctx ", ctx, ", in *", in, ", out *", out, ") error {")
+		g.P("return c.CallWithContext(ctx, ", newHeader, "(", fmt.Sprintf("%q", method.Desc.Name()), ", &", protocolVar, "), in, out)")
+		g.P("}")
+		g.P()
+	}
+}
+
+// generateServer generates the server interface of service, protocolVar is the protocol name var of the client
+func generateServer(g *protogen.GeneratedFile, service *protogen.Service, protocolVar protogen.GoIdent) {
+	ctx := g.QualifiedGoIdent(contextPackage.Ident("Context"))
+	serverName := service.GoName + "Server"
+
+	g.P("// ", serverName, " is the server of ", protocolVar.GoName, ", see Register", serverName)
+	g.P("type ", serverName, " interface {")
+	for _, method := range service.Methods {
+		g.P(method.GoName, "(ctx ", ctx, ", in *", method.Input.GoIdent, ") (*", method.Output.GoIdent, ", error)")
+	}
+	g.P("}")
+	g.P()
+	g.P("// Register", serverName, " serves the methods of ", protocolVar.GoName, " by impl")
+	g.P("func Register", serverName, "(s *", ipcServerPackage.Ident("Server"), ", impl ", serverName, ") {")
+	for _, method := range service.Methods {
+		g.P(ipcServerPackage.Ident("Register"), "(s, ", protocolVar, ", ", fmt.Sprintf("%q", method.Desc.Name()), ", impl.", method.GoName, ")")
+	}
+	g.P("}")
+	g.P()
+}
+
+// protocolVarName returns the UPPER_SNAKE name of service without the Service suffix, and ends with PROTOCOL,
+// e.g. HA_SERVICE_PROTOCOL of HAServiceProtocolService and TRACE_ADMIN_PROTOCOL of TraceAdminService
+func protocolVarName(service string) string {
+	name := []rune(strings.TrimSuffix(service, "Service"))
+	var b strings.Builder
+	for i, r := range name {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && i > 0 {
+			prevLower := name[i-1] >= 'a' && name[i-1] <= 'z' || name[i-1] >= '0' && name[i-1] <= '9'
+			nextLower := i+1 < len(name) && name[i+1] >= 'a' && name[i+1] <= 'z'
+			prevUpper := name[i-1] >= 'A' && name[i-1] <= 'Z'
+			if prevLower || prevUpper && nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(r)
+	}
+	varName := strings.ToUpper(b.String())
+	if !strings.HasSuffix(varName, "_PROTOCOL") {
+		varName += "_PROTOCOL"
+	}
+	return varName
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/pluginpb"
+
+	_ "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	_ "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	_ "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+)
+
+const servicePackage = "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+
+// newTestRequest builds the request protoc sends for fileToGenerate, the proto files are the ones registered by the
+// generated messages, and their go packages are the ones of the messages
+func newTestRequest(t *testing.T, fileToGenerate string, params ...string) *pluginpb.CodeGeneratorRequest {
+	request := &pluginpb.CodeGeneratorRequest{FileToGenerate: []string{fileToGenerate}}
+	seen := map[string]bool{}
+	var visit func(f protoreflect.FileDescriptor)
+	visit = func(f protoreflect.FileDescriptor) {
+		if seen[f.Path()] {
+			return
+		}
+		seen[f.Path()] = true
+		for i := 0; i < f.Imports().Len(); i++ {
+			visit(f.Imports().Get(i).FileDescriptor)
+		}
+		request.ProtoFile = append(request.ProtoFile, protodesc.ToFileDescriptorProto(f))
+		params = append(params, "M"+f.Path()+"="+goPackageOf(t, f))
+	}
+	file, err := protoregistry.GlobalFiles.FindFileByPath(fileToGenerate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visit(file)
+	request.Parameter = proto.String(strings.Join(params, ","))
+	return request
+}
+
+// goPackageOf returns the go package of the types in f, files of services only are in the package of their first
+// import, e.g. applicationclient_protocol.proto is in the package of yarn_service_protos.proto
+func goPackageOf(t *testing.T, f protoreflect.FileDescriptor) string {
+	var fullName protoreflect.FullName
+	if f.Messages().Len() > 0 {
+		fullName = f.Messages().Get(0).FullName()
+	} else if f.Enums().Len() > 0 {
+		fullName = f.Enums().Get(0).FullName()
+	} else if f.Imports().Len() > 0 {
+		return goPackageOf(t, f.Imports().Get(0).FileDescriptor)
+	}
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(fullName); err == nil {
+		return reflect.TypeOf(mt.Zero().Interface()).Elem().PkgPath()
+	}
+	if et, err := protoregistry.GlobalTypes.FindEnumByName(fullName); err == nil {
+		return reflect.TypeOf(et.New(0)).PkgPath()
+	}
+	t.Fatalf("go package of %v is unknown", f.Path())
+	return ""
+}
+
+func runPlugin(t *testing.T, request *pluginpb.CodeGeneratorRequest, opts *options) *pluginpb.CodeGeneratorResponse {
+	gen, err := protogen.Options{ParamFunc: opts.flags().Set}.New(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := generate(gen, opts); err != nil {
+		gen.Error(err)
+	}
+	return gen.Response()
+}
+
+func TestGenerateUpToDate(t *testing.T) {
+	request := newTestRequest(t, "HAServiceProtocol.proto", "package="+servicePackage,
+		"protocol=HAServiceProtocolService=org.apache.hadoop.ha.HAServiceProtocol", "server_package="+servicePackage+"/server")
+	response := runPlugin(t, request, newOptions())
+	assert.Nil(t, response.Error)
+	if assert.Len(t, response.File, 2) {
+		for i, name := range []string{"HAServiceProtocol_hadooprpc.pb.go", "server/HAServiceProtocol_hadooprpc.pb.go"} {
+			assert.Equal(t, name, response.File[i].GetName())
+			expected, err := os.ReadFile("../../pkg/yarn/apis/service/" + name)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), response.File[i].GetContent(), "run hack/generate-yarn.sh to update the generated code")
+		}
+		// clients do not link the rpc server
+		assert.NotContains(t, response.File[0].GetContent(), "pkg/yarn/server/ipc")
+	}
+}
+
+func TestGenerateWithoutProtocol(t *testing.T) {
+	request := newTestRequest(t, "applicationclient_protocol.proto", "package="+servicePackage)
+	response := runPlugin(t, request, newOptions())
+	if assert.NotNil(t, response.Error) {
+		assert.Contains(t, response.GetError(), "protocol=ApplicationClientProtocolService=<protocol name>")
+	}
+
+	response = runPlugin(t, newTestRequest(t, "applicationclient_protocol.proto"), newOptions())
+	assert.Contains(t, response.GetError(), "option package is required")
+
+	response = runPlugin(t, newTestRequest(t, "HAServiceProtocol.proto", "package="+servicePackage,
+		"protocol=HAServiceProtocolService=org.apache.hadoop.ha.HAServiceProtocol", "server_package=example.com/server"), newOptions())
+	assert.Contains(t, response.GetError(), "option server_package example.com/server is not a sub package of "+servicePackage)
+}
+
+func TestProtocolVarName(t *testing.T) {
+	tests := map[string]string{
+		"ApplicationClientProtocolService": "APPLICATION_CLIENT_PROTOCOL",
+		"HAServiceProtocolService":         "HA_SERVICE_PROTOCOL",
+		"ZKFCProtocolService":              "ZKFC_PROTOCOL",
+		"TraceAdminService":                "TRACE_ADMIN_PROTOCOL",
+		"ProtocolInfoService":              "PROTOCOL_INFO_PROTOCOL",
+	}
+	for service, expected := range tests {
+		assert.Equal(t, expected, protocolVarName(service), service)
+	}
+}
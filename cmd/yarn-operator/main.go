@@ -18,9 +18,11 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"math/rand"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
@@ -33,6 +35,8 @@ import (
 	utilclient "github.com/koordinator-sh/koordinator/pkg/util/client"
 	"github.com/koordinator-sh/koordinator/pkg/util/fieldindex"
 	"github.com/koordinator-sh/yarn-copilot/cmd/yarn-operator/options"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnclient "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client"
 )
 
 var (
@@ -49,7 +53,10 @@ func main() {
 	var leaderElectionNamespace string
 	var namespace string
 	var syncPeriodStr string
-	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	var kerberosPrincipal, kerberosKeytab string
+	var clusterKerberosKeytabs string
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8080",
+		"The address the metric endpoint binds to, which also serves the configs of yarn clusters at /debug/yarn/config/.")
 	flag.StringVar(&healthProbeAddr, "health-probe-addr", ":8000", "The address the healthz/readyz endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true, "Whether you need to enable leader election.")
 	flag.StringVar(&leaderElectionNamespace, "leader-election-namespace", "koordinator-system",
@@ -59,6 +66,13 @@ func main() {
 	flag.BoolVar(&enablePprof, "enable-pprof", true, "Enable pprof for controller manager.")
 	flag.StringVar(&pprofAddr, "pprof-addr", ":8090", "The address the pprof binds to.")
 	flag.StringVar(&syncPeriodStr, "sync-period", "", "Determines the minimum frequency at which watched resources are reconciled.")
+	flag.StringVar(&kerberosPrincipal, "kerberos-principal", "",
+		"The kerberos principal logged in with kerberos-keytab to talk to secure yarn clusters. It is ignored if "+
+			"kerberos-keytab is empty, then the principal in the ticket cache of kinit is used once a secure yarn cluster is connected.")
+	flag.StringVar(&kerberosKeytab, "kerberos-keytab", "", "The keytab file of kerberos-principal.")
+	flag.StringVar(&clusterKerberosKeytabs, "cluster-kerberos-keytabs", "",
+		"The kerberos identities of yarn clusters which do not use kerberos-principal, "+
+			"in the format of <cluster-id>=<principal>:<keytab>,... e.g. c1=yarn/op@EXAMPLE.COM:/etc/c1.keytab.")
 	opts := options.NewOptions()
 	opts.InitFlags(flag.CommandLine)
 	//sloconfig.InitFlags(flag.CommandLine)
@@ -78,6 +92,17 @@ func main() {
 		}()
 	}
 
+	if kerberosKeytab != "" {
+		if err := security.LoginUserFromKeytab(kerberosPrincipal, kerberosKeytab); err != nil {
+			setupLog.Error(err, "unable to login with kerberos keytab")
+			os.Exit(1)
+		}
+	}
+	if err := loginClusterUsers(clusterKerberosKeytabs); err != nil {
+		setupLog.Error(err, "unable to login with cluster kerberos keytabs")
+		os.Exit(1)
+	}
+
 	cfg := ctrl.GetConfigOrDie()
 	setRestConfig(cfg)
 	cfg.UserAgent = "koordinator-yarn-operator"
@@ -145,3 +170,26 @@ func setRestConfig(c *rest.Config) {
 		c.Burst = *restConfigBurst
 	}
 }
+
+// loginClusterUsers logs in the users of clusters in the format of <cluster-id>=<principal>:<keytab>,...
+func loginClusterUsers(clusterKeytabs string) error {
+	for _, clusterKeytab := range strings.Split(clusterKeytabs, ",") {
+		if strings.TrimSpace(clusterKeytab) == "" {
+			continue
+		}
+		clusterID, identity, found := strings.Cut(strings.TrimSpace(clusterKeytab), "=")
+		if !found {
+			return fmt.Errorf("invalid cluster kerberos keytab %v", clusterKeytab)
+		}
+		principal, keytab, found := strings.Cut(identity, ":")
+		if !found {
+			return fmt.Errorf("invalid cluster kerberos keytab %v", clusterKeytab)
+		}
+		ugi, err := security.LoginUserFromKeytabAndReturnUGI(principal, keytab)
+		if err != nil {
+			return fmt.Errorf("login cluster %v with keytab %v failed, error %v", clusterID, keytab, err)
+		}
+		yarnclient.DefaultYarnClientFactory.SetClusterUser(clusterID, ugi)
+	}
+	return nil
+}
@@ -25,6 +25,33 @@ YARN_API_FILES="$( find ${GOYARN_API_PATH} -name "*.proto" )"
 echo ">> generate go pkgs for yarn proto files in ${GOYARN_API_PATH}"
 echo ">> api file names: ${YARN_API_FILES}"
 
+# hadoop rpc clients of the services are generated by protoc-gen-go-hadooprpc into HADOOP_RPC_OUT_PATH, and servers
+# into the server sub package of it, so that clients do not link the rpc server,
+# every service must have its hadoop protocol name, which is the name in @ProtocolInfo of the java protocol
+HADOOP_RPC_OUT_PATH="${GOYARN_ROOT}/pkg/yarn/apis/service"
+HADOOP_RPC_PROTOCOLS=(
+  "ApplicationClientProtocolService=org.apache.hadoop.yarn.api.ApplicationClientProtocolPB"
+  "ApplicationMasterProtocolService=org.apache.hadoop.yarn.api.ApplicationMasterProtocolPB"
+  "ContainerManagementProtocolService=org.apache.hadoop.yarn.api.ContainerManagementProtocolPB"
+  "ResourceManagerAdministrationProtocolService=org.apache.hadoop.yarn.server.api.ResourceManagerAdministrationProtocolPB"
+  "HAServiceProtocolService=org.apache.hadoop.ha.HAServiceProtocol"
+  "ZKFCProtocolService=org.apache.hadoop.ha.ZKFCProtocol"
+  "GenericRefreshProtocolService=org.apache.hadoop.ipc.GenericRefreshProtocol"
+  "RefreshCallQueueProtocolService=org.apache.hadoop.ipc.RefreshCallQueueProtocol"
+  "ProtocolInfoService=org.apache.hadoop.ipc.ProtocolMetaInfoPB"
+  "GetUserMappingsProtocolService=org.apache.hadoop.tools.GetUserMappingsProtocol"
+  "RefreshUserMappingsProtocolService=org.apache.hadoop.security.RefreshUserMappingsProtocol"
+  "RefreshAuthorizationPolicyProtocolService=org.apache.hadoop.security.authorize.RefreshAuthorizationPolicyProtocol"
+  "TraceAdminService=org.apache.hadoop.tracing.TraceAdminPB"
+)
+HADOOP_RPC_ARGS="--go-hadooprpc_opt=package=${GOYARN_PKG_ROOT}/pkg/yarn/apis/service --go-hadooprpc_opt=server_package=${GOYARN_PKG_ROOT}/pkg/yarn/apis/service/server "
+HADOOP_RPC_ARGS+="--go-hadooprpc_opt=protocol_var=ResourceManagerAdministrationProtocolService=RESOURCE_MANAGER_ADMIN_PROTOCOL "
+for protocol in "${HADOOP_RPC_PROTOCOLS[@]}"
+do
+  HADOOP_RPC_ARGS+="--go-hadooprpc_opt=protocol=${protocol} "
+done
+GOBIN="$( cd ${GOYARN_ROOT}; pwd )/bin" go install ${GOYARN_ROOT}/cmd/protoc-gen-go-hadooprpc
+
 # input: "./hack/../pkg/yarn/apis/proto/hadoopyarn/server/resourcemanager_administration_protocol.proto"
 function generate_code() {
   yarn_api_proto=${1}
@@ -39,12 +66,14 @@ function generate_code() {
   # --go-grpc_opt=MSecurity.proto=github.com/koordinator-sh/koordiantor/pkg/yarn/apis/proto/hadoopcommon
   PKG_NAME_ARGS=""
   RPC_PKG_NAME_ARGS=""
+  HADOOP_RPC_PKG_NAME_ARGS=""
   for file_pkg_kv in "${file_pkg_map[@]}"
   do
     file_name="${file_pkg_kv%%:*}" #
     pkg_name="${file_pkg_kv##*:}"
     PKG_NAME_ARGS+="--go_opt=M${file_name}=${pkg_name} "
     RPC_PKG_NAME_ARGS+="--go-grpc_opt=M${file_name}=${pkg_name} "
+    HADOOP_RPC_PKG_NAME_ARGS+="--go-hadooprpc_opt=M${file_name}=${pkg_name} "
   done
 
   #proto_cmd="protoc ${PROTO_PATH_ARGS} ${PKG_NAME_ARGS} --go_opt=paths=source_relative --go_out=${yarn_api_out_path} --go-grpc_opt=paths=source_relative ${RPC_PKG_NAME_ARGS} --go-grpc_out=${yarn_api_out_path} ${yarn_api_proto}"
@@ -53,6 +82,13 @@ function generate_code() {
   echo ">> ready to generate for ${file_name}, protoc command:"
   echo "${proto_cmd}"
   ${proto_cmd}
+
+  if grep -Eq "^service " ${yarn_api_proto}; then
+    hadoop_rpc_cmd="protoc ${PROTO_PATH_ARGS} ${HADOOP_RPC_PKG_NAME_ARGS} ${HADOOP_RPC_ARGS} --plugin=protoc-gen-go-hadooprpc=${GOYARN_ROOT}/bin/protoc-gen-go-hadooprpc --go-hadooprpc_out=${HADOOP_RPC_OUT_PATH} ${yarn_api_proto}"
+    echo ">> ready to generate hadoop rpc for ${yarn_api_proto}, protoc command:"
+    echo "${hadoop_rpc_cmd}"
+    ${hadoop_rpc_cmd}
+  fi
 }
 
 
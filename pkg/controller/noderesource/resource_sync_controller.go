@@ -22,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"strconv"
 	"strings"
+	"sync"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -48,6 +49,8 @@ const (
 
 type YARNResourceSyncReconciler struct {
 	client.Client
+	// clientsMtx protects yarnClient and yarnClients, which are replaced once the yarn config dir changes
+	clientsMtx    sync.Mutex
 	yarnClient    yarnclient.YarnClient
 	yarnClients   map[string]yarnclient.YarnClient
 	yarnNodeCache *cache.NodesSyncer
@@ -89,7 +92,7 @@ func (r *YARNResourceSyncReconciler) Reconcile(ctx context.Context, req reconcil
 	vcores, memoryMB := calculate(batchCPU, batchMemory)
 
 	// TODO control update frequency by ignore unnecessary node update event
-	if err := r.updateYARNNodeResource(yarnNode, vcores, memoryMB); err != nil {
+	if err := r.updateYARNNodeResource(ctx, yarnNode, vcores, memoryMB); err != nil {
 		klog.Warningf("update batch resource to yarn node %+v failed, k8s node name: %s, error %v", yarnNode, node.Name, err)
 		return ctrl.Result{Requeue: true}, err
 	}
@@ -166,18 +169,20 @@ func (r *YARNResourceSyncReconciler) updateYARNAllocatedResource(node *corev1.No
 }
 
 func Add(mgr ctrl.Manager) error {
-	clients, err := yarnclient.DefaultYarnClientFactory.CreateAllYarnClients()
-	if err != nil {
+	clientFactory := yarnclient.NewDefaultReloadingYarnClientFactory()
+	if err := clientFactory.Reload(); err != nil {
 		return err
 	}
+	clients := clientFactory.Clients()
 	yarnNodesSyncer := cache.NewNodesSyncer(clients)
 
 	coll := yarnmetrics.NewYarnMetricCollector(yarnNodesSyncer)
-	if err = metrics.Registry.Register(coll); err != nil {
+	if err := metrics.Registry.Register(coll); err != nil {
 		return err
 	}
 	r := &YARNResourceSyncReconciler{
 		Client:        mgr.GetClient(),
+		yarnClient:    clients[yarnclient.DefaultClusterID],
 		yarnClients:   clients,
 		yarnNodeCache: yarnNodesSyncer,
 	}
@@ -185,9 +190,26 @@ func Add(mgr ctrl.Manager) error {
 	if err := mgr.Add(yarnNodesSyncer); err != nil {
 		return err
 	}
+	clientFactory.Subscribe(yarnNodesSyncer.SetYarnClients)
+	clientFactory.Subscribe(r.setYarnClients)
+	if err := mgr.Add(clientFactory); err != nil {
+		return err
+	}
+	// the effective configs of yarn clusters are served by the metrics server for debugging
+	if err := mgr.AddMetricsExtraHandler(yarnclient.ConfigHandlerPath, yarnclient.NewConfigHandler(clientFactory)); err != nil {
+		return err
+	}
 	return r.SetupWithManager(mgr)
 }
 
+// setYarnClients replaces the clients of clusters with the ones reloaded from the yarn config dir
+func (r *YARNResourceSyncReconciler) setYarnClients(clients map[string]yarnclient.YarnClient) {
+	r.clientsMtx.Lock()
+	defer r.clientsMtx.Unlock()
+	r.yarnClient = clients[yarnclient.DefaultClusterID]
+	r.yarnClients = clients
+}
+
 func (r *YARNResourceSyncReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// TODO use source.Channel to handle yarn node requested update event
 	return ctrl.NewControllerManagedBy(mgr).
@@ -255,7 +277,7 @@ func (r *YARNResourceSyncReconciler) getYARNNode(node *corev1.Node) (*cache.Yarn
 	return yarnNode, nil
 }
 
-func (r *YARNResourceSyncReconciler) updateYARNNodeResource(yarnNode *cache.YarnNode, vcores, memoryMB int64) error {
+func (r *YARNResourceSyncReconciler) updateYARNNodeResource(ctx context.Context, yarnNode *cache.YarnNode, vcores, memoryMB int64) error {
 	if yarnNode == nil {
 		return nil
 	}
@@ -279,9 +301,11 @@ func (r *YARNResourceSyncReconciler) updateYARNNodeResource(yarnNode *cache.Yarn
 	if err != nil || yarnClient == nil {
 		return err
 	}
-	if resp, err := yarnClient.UpdateNodeResource(request); err != nil {
+	if resp, err := yarnClient.UpdateNodeResourceWithContext(ctx, request); err != nil && yarnclient.NeedReinitialize(err) {
 		initErr := yarnClient.Reinitialize()
 		return fmt.Errorf("UpdateNodeResource resp %v, error %v, reinitialize error %v", resp, err, initErr)
+	} else if err != nil {
+		return fmt.Errorf("UpdateNodeResource resp %v, error %w", resp, err)
 	}
 	return nil
 }
@@ -289,30 +313,20 @@ func (r *YARNResourceSyncReconciler) updateYARNNodeResource(yarnNode *cache.Yarn
 func (r *YARNResourceSyncReconciler) getYARNClient(yarnNode *cache.YarnNode) (yarnclient.YarnClient, error) {
 	if yarnNode == nil {
 		return nil, nil
-	} else if yarnNode.ClusterID == "" && r.yarnClient != nil {
-		return r.yarnClient, nil
-	} else if yarnNode.ClusterID == "" && r.yarnClient == nil {
-		yarnClient, err := yarnclient.DefaultYarnClientFactory.CreateDefaultYarnClient()
-		if err != nil {
-			return nil, err
-		}
-		r.yarnClient = yarnClient
-		return yarnClient, nil
-	}
-
-	//yarnNode.ClusterID != ""
-	if clusterClient, exist := r.yarnClients[yarnNode.ClusterID]; exist {
-		return clusterClient, nil
 	}
-	// create new client by cluster id
-	clusterClient, err := yarnclient.DefaultYarnClientFactory.CreateYarnClientByClusterID(yarnNode.ClusterID)
-	if err != nil {
-		return nil, err
+	r.clientsMtx.Lock()
+	defer r.clientsMtx.Unlock()
+	// clients are owned by the reloading factory, clusters not in the yarn config dir have no client until it reloads
+	if yarnNode.ClusterID == "" {
+		if r.yarnClient == nil {
+			return nil, fmt.Errorf("yarn client of default cluster not found")
+		}
+		return r.yarnClient, nil
 	}
-	if r.yarnClients == nil {
-		r.yarnClients = map[string]yarnclient.YarnClient{}
+	clusterClient, exist := r.yarnClients[yarnNode.ClusterID]
+	if !exist {
+		return nil, fmt.Errorf("yarn client of cluster %v not found in yarn config dir", yarnNode.ClusterID)
 	}
-	r.yarnClients[yarnNode.ClusterID] = clusterClient
 	return clusterClient, nil
 }
 
@@ -617,13 +617,9 @@ func TestYARNResourceSyncReconciler_getYARNClient(t *testing.T) {
 	type fields struct {
 		yarnClientOfReconciler  yarnclient.YarnClient
 		yarnClientsOfReconciler map[string]yarnclient.YarnClient
-		yarnClientFromFactory   yarnclient.YarnClient
-		yarnClientsFromFactory  map[string]yarnclient.YarnClient
 	}
 	type args struct {
-		yarnNode                  *cache.YarnNode
-		factoryCreateDefaultError error
-		factoryCreateClusterError map[string]error
+		yarnNode *cache.YarnNode
 	}
 	tests := []struct {
 		name    string
@@ -657,32 +653,14 @@ func TestYARNResourceSyncReconciler_getYARNClient(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "get default client by empty cluster id from new",
-			fields: fields{
-				yarnClientFromFactory: yarnClient,
-			},
-			args: args{
-				yarnNode: &cache.YarnNode{
-					Name:      "test-node",
-					Port:      8042,
-					ClusterID: "",
-				},
-			},
-			want:    yarnClient,
-			wantErr: false,
-		},
-		{
-			name: "get default client by empty cluster id from new with error",
-			fields: fields{
-				yarnClientFromFactory: yarnClient,
-			},
+			name:   "get default client not found",
+			fields: fields{},
 			args: args{
 				yarnNode: &cache.YarnNode{
 					Name:      "test-node",
 					Port:      8042,
 					ClusterID: "",
 				},
-				factoryCreateDefaultError: fmt.Errorf("create default error"),
 			},
 			want:    nil,
 			wantErr: true,
@@ -703,33 +681,16 @@ func TestYARNResourceSyncReconciler_getYARNClient(t *testing.T) {
 			wantErr: false,
 		},
 		{
-			name: "get cluster client from new",
+			name: "get cluster client not found",
 			fields: fields{
-				yarnClientsFromFactory: yarnClients,
-			},
-			args: args{
-				yarnNode: &cache.YarnNode{
-					Name:      "test-node",
-					Port:      8042,
-					ClusterID: "test-cluster1",
-				},
-			},
-			want:    yarnCluster1Client,
-			wantErr: false,
-		},
-		{
-			name: "get cluster client from new with error",
-			fields: fields{
-				yarnClientsFromFactory: yarnClients,
+				yarnClientOfReconciler:  yarnClient,
+				yarnClientsOfReconciler: yarnClients,
 			},
 			args: args{
 				yarnNode: &cache.YarnNode{
 					Name:      "test-node",
 					Port:      8042,
-					ClusterID: "test-cluster1",
-				},
-				factoryCreateClusterError: map[string]error{
-					"test-cluster1": fmt.Errorf("create default error"),
+					ClusterID: "test-cluster2",
 				},
 			},
 			want:    nil,
@@ -738,16 +699,6 @@ func TestYARNResourceSyncReconciler_getYARNClient(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockYarnClientFactory := mock_client.NewMockYarnClientFactory(ctrl)
-			yarnclient.DefaultYarnClientFactory = mockYarnClientFactory
-
-			if tt.fields.yarnClientFromFactory != nil {
-				mockYarnClientFactory.EXPECT().CreateDefaultYarnClient().Return(tt.fields.yarnClientFromFactory, tt.args.factoryCreateDefaultError)
-			}
-			for clusterID, client := range tt.fields.yarnClientsFromFactory {
-				createError := tt.args.factoryCreateClusterError[clusterID]
-				mockYarnClientFactory.EXPECT().CreateYarnClientByClusterID(clusterID).Return(client, createError).AnyTimes()
-			}
 			r := &YARNResourceSyncReconciler{
 				yarnClient:  tt.fields.yarnClientOfReconciler,
 				yarnClients: tt.fields.yarnClientsOfReconciler,
@@ -769,11 +720,11 @@ func TestYARNResourceSyncReconciler_updateYARNNodeResource(t *testing.T) {
 	defer ctrl.Finish()
 
 	type fields struct {
-		yarnClientErrorFromFactory error
-		doUpdate                   bool
-		updateNodeResourceError    error
-		doReinit                   bool
-		reinitError                error
+		yarnClientNotFound      bool
+		doUpdate                bool
+		updateNodeResourceError error
+		doReinit                bool
+		reinitError             error
 	}
 	type args struct {
 		yarnNode *cache.YarnNode
@@ -797,7 +748,7 @@ func TestYARNResourceSyncReconciler_updateYARNNodeResource(t *testing.T) {
 		{
 			name: "get client failed",
 			fields: fields{
-				yarnClientErrorFromFactory: fmt.Errorf("create client error"),
+				yarnClientNotFound: true,
 			},
 			args: args{
 				yarnNode: &cache.YarnNode{},
@@ -829,21 +780,19 @@ func TestYARNResourceSyncReconciler_updateYARNNodeResource(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockYarnClientFactory := mock_client.NewMockYarnClientFactory(ctrl)
-			yarnclient.DefaultYarnClientFactory = mockYarnClientFactory
 			yarnClient := mock_client.NewMockYarnClient(ctrl)
-			if tt.args.yarnNode != nil {
-				mockYarnClientFactory.EXPECT().CreateDefaultYarnClient().Return(yarnClient, tt.fields.yarnClientErrorFromFactory)
-			}
 			if tt.fields.doUpdate {
-				yarnClient.EXPECT().UpdateNodeResource(gomock.Any()).Return(nil, tt.fields.updateNodeResourceError)
+				yarnClient.EXPECT().UpdateNodeResourceWithContext(gomock.Any(), gomock.Any()).Return(nil, tt.fields.updateNodeResourceError)
 			}
 			if tt.fields.doReinit {
 				yarnClient.EXPECT().Reinitialize().Return(tt.fields.reinitError)
 			}
 
 			r := &YARNResourceSyncReconciler{}
-			if err := r.updateYARNNodeResource(tt.args.yarnNode, tt.args.vcores, tt.args.memoryMB); (err != nil) != tt.wantErr {
+			if !tt.fields.yarnClientNotFound {
+				r.yarnClient = yarnClient
+			}
+			if err := r.updateYARNNodeResource(context.TODO(), tt.args.yarnNode, tt.args.vcores, tt.args.memoryMB); (err != nil) != tt.wantErr {
 				t.Errorf("updateYARNNodeResource() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -932,7 +881,7 @@ func TestYARNResourceSyncReconciler_getYARNNodeAllocatedResource(t *testing.T) {
 			mockYarnClientFactory := mock_client.NewMockYarnClientFactory(ctrl)
 			yarnclient.DefaultYarnClientFactory = mockYarnClientFactory
 			yarnClient := mock_client.NewMockYarnClient(ctrl)
-			yarnClient.EXPECT().GetClusterNodes(gomock.Any()).Return(tt.fields.yarnNodesProto, nil).AnyTimes()
+			yarnClient.EXPECT().GetClusterNodesWithContext(gomock.Any(), gomock.Any()).Return(tt.fields.yarnNodesProto, nil).AnyTimes()
 			yarnNodeCache := cache.NewNodesSyncer(map[string]yarnclient.YarnClient{yarnclient.DefaultClusterID: yarnClient})
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -1163,14 +1112,12 @@ func TestYARNResourceSyncReconciler_Reconcile(t *testing.T) {
 			client := fake.NewClientBuilder().WithScheme(scheme).Build()
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			mockYarnClientFactory := mock_client.NewMockYarnClientFactory(ctrl)
-			yarnclient.DefaultYarnClientFactory = mockYarnClientFactory
 			yarnClient := mock_client.NewMockYarnClient(ctrl)
-			mockYarnClientFactory.EXPECT().CreateYarnClientByClusterID(yarnclient.DefaultClusterID).Return(yarnClient, nil).AnyTimes()
-			yarnClient.EXPECT().GetClusterNodes(gomock.Any()).Return(tt.fields.yarnNodesProto, nil).AnyTimes()
+			yarnClient.EXPECT().GetClusterNodesWithContext(gomock.Any(), gomock.Any()).Return(tt.fields.yarnNodesProto, nil).AnyTimes()
 			yarnClient.EXPECT().Reinitialize().Return(nil).AnyTimes()
-			yarnClient.EXPECT().UpdateNodeResource(gomock.Any()).Return(nil, tt.fields.yarnUpdateErr).AnyTimes()
-			yarnNodeCache := cache.NewNodesSyncer(map[string]yarnclient.YarnClient{yarnclient.DefaultClusterID: yarnClient})
+			yarnClient.EXPECT().UpdateNodeResourceWithContext(gomock.Any(), gomock.Any()).Return(nil, tt.fields.yarnUpdateErr).AnyTimes()
+			yarnClients := map[string]yarnclient.YarnClient{yarnclient.DefaultClusterID: yarnClient}
+			yarnNodeCache := cache.NewNodesSyncer(yarnClients)
 
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
@@ -1181,6 +1128,8 @@ func TestYARNResourceSyncReconciler_Reconcile(t *testing.T) {
 
 			r := &YARNResourceSyncReconciler{
 				Client:        client,
+				yarnClient:    yarnClient,
+				yarnClients:   yarnClients,
 				yarnNodeCache: yarnNodeCache,
 			}
 			if tt.fields.node != nil {
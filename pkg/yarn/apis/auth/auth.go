@@ -20,13 +20,10 @@ package auth
 import (
 	"bytes"
 	"encoding/binary"
-	"os/user"
 	"runtime"
 	"strings"
 	"unicode"
 
-	"k8s.io/klog/v2"
-
 	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 )
 
@@ -99,19 +96,11 @@ func GetCalleeRPCRequestHeaderProto(protocolName *string) *hadoop_common.Request
 	names := strings.Split(fullName, ".")
 	unicodeName := []rune(names[len(names)-1])
 	unicodeName[0] = unicode.ToLower(unicodeName[0])
-	methodName := string(unicodeName)
-	return &hadoop_common.RequestHeaderProto{MethodName: &methodName, DeclaringClassProtocolName: protocolName, ClientProtocolVersion: &CLIENT_PROTOCOL_VERSION}
+	return NewRPCRequestHeaderProto(string(unicodeName), protocolName)
 }
 
-func CreateSimpleUGIProto() (*hadoop_common.UserInformationProto, error) {
-	// Figure the current user-name
-	var username string
-	if user, err := user.Current(); err != nil {
-		klog.Warningf("user.Current", err)
-		return nil, err
-	} else {
-		username = user.Username
-	}
-
-	return &hadoop_common.UserInformationProto{EffectiveUser: nil, RealUser: &username}, nil
+// NewRPCRequestHeaderProto creates the request header with an explicit method name, for callers whose
+// method name differs from the rpc name, e.g. the context variants of service clients
+func NewRPCRequestHeaderProto(methodName string, protocolName *string) *hadoop_common.RequestHeaderProto {
+	return &hadoop_common.RequestHeaderProto{MethodName: &methodName, DeclaringClassProtocolName: protocolName, ClientProtocolVersion: &CLIENT_PROTOCOL_VERSION}
 }
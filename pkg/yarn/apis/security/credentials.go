@@ -0,0 +1,400 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+// EnvHadoopTokenFileLocation is the token file of containers and jobs, which is loaded by the current user
+const EnvHadoopTokenFileLocation = "HADOOP_TOKEN_FILE_LOCATION"
+
+// kinds of tokens in yarn
+const (
+	TokenKindRMDelegation = "RM_DELEGATION_TOKEN"
+	TokenKindAMRM         = "YARN_AM_RM_TOKEN"
+	TokenKindNM           = "NMToken"
+)
+
+// protocolTokenKinds is the kind of tokens used by protocols, which is the TokenInfo of SecurityInfo in hadoop,
+// protocols without token kinds only support kerberos
+var protocolTokenKinds = struct {
+	sync.RWMutex
+	kinds map[string]string
+}{kinds: map[string]string{}}
+
+// RegisterProtocolTokenKind makes connections of protocol authenticate with tokens of kind, which is called by the
+// services of protocols
+func RegisterProtocolTokenKind(protocol string, kind string) {
+	protocolTokenKinds.Lock()
+	defer protocolTokenKinds.Unlock()
+	protocolTokenKinds.kinds[protocol] = kind
+}
+
+// TokenKindForProtocol returns the kind of tokens used by protocol, or empty if protocol does not support tokens
+func TokenKindForProtocol(protocol string) string {
+	protocolTokenKinds.RLock()
+	defer protocolTokenKinds.RUnlock()
+	return protocolTokenKinds.kinds[protocol]
+}
+
+// SelectToken returns the token of kind for service like the token selectors of hadoop. The service of a token is
+// the ip:port of server, a comma separated list of them for rm ha, or a logical name.
+func SelectToken(tokens []*hadoop_common.TokenProto, kind string, service string) (*hadoop_common.TokenProto, bool) {
+	if kind == "" || service == "" {
+		return nil, false
+	}
+	for _, token := range tokens {
+		if token.GetKind() != kind {
+			continue
+		}
+		for _, tokenService := range strings.Split(token.GetService(), ",") {
+			if strings.TrimSpace(tokenService) == service {
+				return token, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// SerializedFormat is the format of token storage files
+type SerializedFormat byte
+
+const (
+	// SerializedFormatWritable is the format of hadoop 2, which is readable by all versions
+	SerializedFormatWritable SerializedFormat = 0
+	// SerializedFormatProtobuf is the default format since hadoop 3.3
+	SerializedFormatProtobuf SerializedFormat = 1
+)
+
+var tokenStorageMagic = []byte("HDTS")
+
+// Credentials is the tokens and secret keys of a user by alias, which is stored in token storage files like
+// Credentials in hadoop
+type Credentials struct {
+	tokens     map[string]*hadoop_common.TokenProto
+	secretKeys map[string][]byte
+}
+
+func NewCredentials() *Credentials {
+	return &Credentials{tokens: map[string]*hadoop_common.TokenProto{}, secretKeys: map[string][]byte{}}
+}
+
+func (c *Credentials) AddToken(alias string, token *hadoop_common.TokenProto) {
+	c.tokens[alias] = token
+}
+
+func (c *Credentials) GetToken(alias string) *hadoop_common.TokenProto {
+	return c.tokens[alias]
+}
+
+// GetAllTokens returns the tokens sorted by alias
+func (c *Credentials) GetAllTokens() []*hadoop_common.TokenProto {
+	tokens := make([]*hadoop_common.TokenProto, 0, len(c.tokens))
+	for _, alias := range sortedKeys(c.tokens) {
+		tokens = append(tokens, c.tokens[alias])
+	}
+	return tokens
+}
+
+func (c *Credentials) AddSecretKey(alias string, key []byte) {
+	c.secretKeys[alias] = key
+}
+
+func (c *Credentials) GetSecretKey(alias string) []byte {
+	return c.secretKeys[alias]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ReadTokenStorageFile loads the credentials from a token storage file, e.g. $HADOOP_TOKEN_FILE_LOCATION
+func ReadTokenStorageFile(path string) (*Credentials, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	credentials, err := ReadTokenStorageStream(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("read token storage file %v failed, error %v", path, err)
+	}
+	return credentials, nil
+}
+
+// ReadTokenStorageStream reads the credentials in either writable or protobuf format
+func ReadTokenStorageStream(r io.Reader) (*Credentials, error) {
+	header := make([]byte, len(tokenStorageMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(tokenStorageMagic)], tokenStorageMagic) {
+		return nil, errors.New("bad header found in token storage")
+	}
+	switch format := SerializedFormat(header[len(tokenStorageMagic)]); format {
+	case SerializedFormatWritable:
+		return readWritableCredentials(bufio.NewReader(r))
+	case SerializedFormatProtobuf:
+		return readProtoCredentials(r)
+	default:
+		return nil, fmt.Errorf("unknown token storage format %v", format)
+	}
+}
+
+// WriteTokenStorageFile saves the credentials to path in format
+func (c *Credentials) WriteTokenStorageFile(path string, format SerializedFormat) error {
+	buf := bytes.NewBuffer(nil)
+	if err := c.WriteTokenStorageToStream(buf, format); err != nil {
+		return err
+	}
+	// tokens are secrets, so the file is only readable by the owner
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+func (c *Credentials) WriteTokenStorageToStream(w io.Writer, format SerializedFormat) error {
+	var data []byte
+	switch format {
+	case SerializedFormatWritable:
+		data = c.appendWritable(nil)
+	case SerializedFormatProtobuf:
+		var err error
+		if data, err = c.marshalProto(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown token storage format %v", format)
+	}
+	header := append(append([]byte{}, tokenStorageMagic...), byte(format))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+func readProtoCredentials(r io.Reader) (*Credentials, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// the message is delimited by its length
+	length, n := protowire.ConsumeVarint(data)
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	if uint64(len(data)-n) < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	var storage hadoop_common.CredentialsProto
+	if err := proto.Unmarshal(data[n:n+int(length)], &storage); err != nil {
+		return nil, err
+	}
+
+	credentials := NewCredentials()
+	for _, kv := range storage.GetTokens() {
+		if kv.GetToken() != nil {
+			credentials.AddToken(kv.GetAlias(), kv.GetToken())
+		}
+	}
+	for _, kv := range storage.GetSecrets() {
+		credentials.AddSecretKey(kv.GetAlias(), kv.GetSecret())
+	}
+	return credentials, nil
+}
+
+func (c *Credentials) marshalProto() ([]byte, error) {
+	storage := &hadoop_common.CredentialsProto{}
+	for _, alias := range sortedKeys(c.tokens) {
+		storage.Tokens = append(storage.Tokens, &hadoop_common.CredentialsKVProto{Alias: proto.String(alias), Token: c.tokens[alias]})
+	}
+	for _, alias := range sortedKeys(c.secretKeys) {
+		storage.Secrets = append(storage.Secrets, &hadoop_common.CredentialsKVProto{Alias: proto.String(alias), Secret: c.secretKeys[alias]})
+	}
+	data, err := proto.Marshal(storage)
+	if err != nil {
+		return nil, err
+	}
+	return append(protowire.AppendVarint(nil, uint64(len(data))), data...), nil
+}
+
+// readWritableCredentials reads the credentials written by Credentials.write in hadoop
+func readWritableCredentials(r io.ByteReader) (*Credentials, error) {
+	credentials := NewCredentials()
+	size, err := readVInt(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < size; i++ {
+		alias, err := readWritableBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		token := &hadoop_common.TokenProto{}
+		if token.Identifier, err = readWritableBytes(r); err != nil {
+			return nil, err
+		}
+		if token.Password, err = readWritableBytes(r); err != nil {
+			return nil, err
+		}
+		kind, err := readWritableBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		service, err := readWritableBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		token.Kind, token.Service = proto.String(string(kind)), proto.String(string(service))
+		credentials.AddToken(string(alias), token)
+	}
+
+	if size, err = readVInt(r); err != nil {
+		return nil, err
+	}
+	for i := int64(0); i < size; i++ {
+		alias, err := readWritableBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		key, err := readWritableBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		credentials.AddSecretKey(string(alias), key)
+	}
+	return credentials, nil
+}
+
+func (c *Credentials) appendWritable(b []byte) []byte {
+	b = appendVInt(b, int64(len(c.tokens)))
+	for _, alias := range sortedKeys(c.tokens) {
+		token := c.tokens[alias]
+		b = appendWritableBytes(b, []byte(alias))
+		b = appendWritableBytes(b, token.GetIdentifier())
+		b = appendWritableBytes(b, token.GetPassword())
+		b = appendWritableBytes(b, []byte(token.GetKind()))
+		b = appendWritableBytes(b, []byte(token.GetService()))
+	}
+	b = appendVInt(b, int64(len(c.secretKeys)))
+	for _, alias := range sortedKeys(c.secretKeys) {
+		b = appendWritableBytes(b, []byte(alias))
+		b = appendWritableBytes(b, c.secretKeys[alias])
+	}
+	return b
+}
+
+// readWritableBytes reads a Text or byte array prefixed with its length
+func readWritableBytes(r io.ByteReader) ([]byte, error) {
+	length, err := readVInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("invalid length %v", length)
+	}
+	b := make([]byte, length)
+	for i := range b {
+		if b[i], err = r.ReadByte(); err != nil {
+			return nil, noEOF(err)
+		}
+	}
+	return b, nil
+}
+
+func appendWritableBytes(b []byte, data []byte) []byte {
+	return append(appendVInt(b, int64(len(data))), data...)
+}
+
+// readVInt reads the variable-length integer of WritableUtils.readVLong in hadoop
+func readVInt(r io.ByteReader) (int64, error) {
+	first, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	value := int8(first)
+	if value >= -112 {
+		return int64(value), nil
+	}
+	negative := value < -120
+	size := -111 - int(value)
+	if negative {
+		size = -119 - int(value)
+	}
+	var i int64
+	for idx := 0; idx < size-1; idx++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, noEOF(err)
+		}
+		i = i<<8 | int64(b)
+	}
+	if negative {
+		return i ^ -1, nil
+	}
+	return i, nil
+}
+
+// appendVInt appends the variable-length integer of WritableUtils.writeVLong in hadoop
+func appendVInt(b []byte, i int64) []byte {
+	if i >= -112 && i <= 127 {
+		return append(b, byte(i))
+	}
+	length := -112
+	if i < 0 {
+		i ^= -1
+		length = -120
+	}
+	for tmp := i; tmp != 0; tmp >>= 8 {
+		length--
+	}
+	b = append(b, byte(int8(length)))
+	if length < -120 {
+		length = -(length + 120)
+	} else {
+		length = -(length + 112)
+	}
+	for idx := length; idx != 0; idx-- {
+		b = append(b, byte(i>>((idx-1)*8)))
+	}
+	return b
+}
+
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+func TestVInt(t *testing.T) {
+	tests := []struct {
+		value int64
+		want  []byte
+	}{
+		{value: 0, want: []byte{0x00}},
+		{value: 127, want: []byte{0x7f}},
+		{value: -112, want: []byte{0x90}},
+		{value: 128, want: []byte{0x8f, 0x80}},
+		{value: 1000, want: []byte{0x8e, 0x03, 0xe8}},
+		{value: -113, want: []byte{0x87, 0x70}},
+		{value: -1000, want: []byte{0x86, 0x03, 0xe7}},
+	}
+	for _, tt := range tests {
+		got := appendVInt(nil, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+		value, err := readVInt(bytes.NewReader(got))
+		assert.NoError(t, err)
+		assert.Equal(t, tt.value, value)
+	}
+}
+
+func TestReadTokenStorageStream(t *testing.T) {
+	// written by Credentials.writeTokenStorageToStream of hadoop in writable format
+	data := []byte("HDTS\x00\x01\x05alias\x02id\x02pw\x13RM_DELEGATION_TOKEN\x0d10.0.0.1:8032\x01\x06secret\x03key")
+	credentials, err := ReadTokenStorageStream(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(&hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("pw"),
+		Kind: proto.String(TokenKindRMDelegation), Service: proto.String("10.0.0.1:8032")}, credentials.GetToken("alias")))
+	assert.Equal(t, []byte("key"), credentials.GetSecretKey("secret"))
+
+	buf := bytes.NewBuffer(nil)
+	assert.NoError(t, credentials.WriteTokenStorageToStream(buf, SerializedFormatWritable))
+	assert.Equal(t, data, buf.Bytes())
+
+	_, err = ReadTokenStorageStream(bytes.NewReader([]byte("HDTX\x00\x00\x00")))
+	assert.Error(t, err)
+	_, err = ReadTokenStorageStream(bytes.NewReader(data[:len(data)-1]))
+	assert.Error(t, err)
+}
+
+func TestTokenStorageFile(t *testing.T) {
+	ugi := NewRemoteUser("yarn")
+	ugi.AddUserToken(&hadoop_common.TokenProto{Identifier: []byte("rm"), Password: []byte("pw"),
+		Kind: proto.String(TokenKindRMDelegation), Service: proto.String("10.0.0.1:8032,10.0.0.2:8032")})
+	ugi.AddUserToken(&hadoop_common.TokenProto{Identifier: []byte("amrm"), Password: []byte("pw"),
+		Kind: proto.String(TokenKindAMRM), Service: proto.String("10.0.0.1:8030")})
+	ugi.AddUserToken(&hadoop_common.TokenProto{Identifier: []byte("nm"), Password: []byte("pw"),
+		Kind: proto.String(TokenKindNM), Service: proto.String("10.0.0.1:8030")})
+
+	for _, format := range []SerializedFormat{SerializedFormatWritable, SerializedFormatProtobuf} {
+		path := filepath.Join(t.TempDir(), "container_tokens")
+		assert.NoError(t, ugi.GetCredentials().WriteTokenStorageFile(path, format))
+		credentials, err := ReadTokenStorageFile(path)
+		assert.NoError(t, err)
+		assert.Len(t, credentials.GetAllTokens(), 3)
+
+		loaded := NewRemoteUser("yarn")
+		loaded.AddCredentials(credentials)
+		token, found := loaded.SelectToken(TokenKindRMDelegation, "10.0.0.2:8032")
+		assert.True(t, found)
+		assert.Equal(t, []byte("rm"), token.GetIdentifier())
+		token, found = loaded.SelectToken(TokenKindAMRM, "10.0.0.1:8030")
+		assert.True(t, found)
+		assert.Equal(t, []byte("amrm"), token.GetIdentifier())
+		_, found = loaded.SelectToken(TokenKindRMDelegation, "10.0.0.1:8030")
+		assert.False(t, found)
+		_, found = loaded.SelectToken(TokenKindRMDelegation, "10.0.0.1:803")
+		assert.False(t, found)
+	}
+}
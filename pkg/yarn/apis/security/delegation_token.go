@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"k8s.io/klog/v2"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+const (
+	// tokens are renewed after renewRatio of their lifetime, like the delegation token renewers of hadoop apps
+	defaultTokenRenewRatio = 0.75
+	// tokens are renewed at least once per interval, since the expiry time is unknown until the first renewal
+	defaultTokenRenewInterval = time.Hour
+	// delay of retrying a failed fetch
+	defaultTokenRetryInterval = time.Minute
+)
+
+// DelegationTokenClient talks to the server which issues delegation tokens, e.g. the ApplicationClientProtocol of rm
+type DelegationTokenClient interface {
+	GetDelegationToken(ctx context.Context, renewer string) (*hadoop_common.TokenProto, error)
+	// RenewDelegationToken returns the new expiry time of token
+	RenewDelegationToken(ctx context.Context, token *hadoop_common.TokenProto) (time.Time, error)
+	CancelDelegationToken(ctx context.Context, token *hadoop_common.TokenProto) error
+}
+
+// DelegationTokenManager keeps a valid delegation token for ugi. It fetches a token with the credential of ugi,
+// renews it before expiry, and fetches a new one when it reaches the max lifetime or fails to renew. The token is
+// published to ugi with service, so that connections to the server authenticate with the token.
+type DelegationTokenManager struct {
+	client  DelegationTokenClient
+	ugi     *UserGroupInformation
+	renewer string
+	service string
+
+	renewRatio    float64
+	renewInterval time.Duration
+	retryInterval time.Duration
+
+	mtx     sync.Mutex
+	token   *hadoop_common.TokenProto
+	expiry  time.Time
+	maxDate time.Time
+	// stopped is set by Cancel, so that the token is neither renewed nor fetched again
+	stopped bool
+	cancel  context.CancelFunc
+}
+
+// NewDelegationTokenManager creates a manager of tokens fetched by client for ugi, renewer is the user renewing the
+// token which is usually the short name of ugi, service is the token service of server like "ip1:port,ip2:port"
+func NewDelegationTokenManager(client DelegationTokenClient, ugi *UserGroupInformation, renewer string, service string) *DelegationTokenManager {
+	return &DelegationTokenManager{
+		client:        client,
+		ugi:           ugi,
+		renewer:       renewer,
+		service:       service,
+		renewRatio:    defaultTokenRenewRatio,
+		renewInterval: defaultTokenRenewInterval,
+		retryInterval: defaultTokenRetryInterval,
+	}
+}
+
+// Start fetches the first token and keeps it valid in background until ctx is done or Cancel is called
+func (m *DelegationTokenManager) Start(ctx context.Context) error {
+	if err := m.fetch(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m.mtx.Lock()
+	if m.stopped {
+		m.mtx.Unlock()
+		cancel()
+		return errors.New("delegation token manager is cancelled")
+	}
+	m.cancel = cancel
+	m.mtx.Unlock()
+	go m.run(ctx)
+	return nil
+}
+
+// Token returns the current token
+func (m *DelegationTokenManager) Token() *hadoop_common.TokenProto {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.token
+}
+
+// Cancel stops the manager, then cancels the current token and removes it from ugi. The manager cannot be
+// started again after Cancel.
+func (m *DelegationTokenManager) Cancel(ctx context.Context) error {
+	m.mtx.Lock()
+	token, cancel := m.token, m.cancel
+	m.token, m.stopped = nil, true
+	m.mtx.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	if token == nil {
+		return nil
+	}
+	m.ugi.RemoveUserToken(token.GetKind(), token.GetService())
+	return m.client.CancelDelegationToken(ctx, token)
+}
+
+func (m *DelegationTokenManager) run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(m.nextRenewal(time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if m.needsNewToken(time.Now()) {
+			m.fetchUntilSucceeded(ctx)
+			continue
+		}
+		if err := m.renew(ctx); err != nil {
+			klog.Warningf("renew delegation token for %v failed, fetch a new one, error %v", m.service, err)
+			m.fetchUntilSucceeded(ctx)
+		}
+	}
+}
+
+func (m *DelegationTokenManager) fetchUntilSucceeded(ctx context.Context) {
+	for {
+		err := m.fetch(ctx)
+		if err == nil {
+			return
+		}
+		klog.Warningf("fetch delegation token for %v failed, retry after %v, error %v", m.service, m.retryInterval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.retryInterval):
+		}
+	}
+}
+
+// fetch gets a new token and renews it once to learn its expiry time
+func (m *DelegationTokenManager) fetch(ctx context.Context) error {
+	token, err := m.client.GetDelegationToken(ctx, m.renewer)
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return errors.New("server returned no delegation token")
+	}
+	// the service of token is set by client like ClientRMProxy in hadoop
+	token.Service = &m.service
+	expiry, err := m.client.RenewDelegationToken(ctx, token)
+	if err != nil {
+		return fmt.Errorf("renew new delegation token failed, error %v", err)
+	}
+	maxDate, err := delegationTokenMaxDate(token)
+	if err != nil {
+		klog.V(4).Infof("unknown max date of delegation token for %v, error %v", m.service, err)
+	}
+
+	m.mtx.Lock()
+	if m.stopped {
+		m.mtx.Unlock()
+		// the manager is cancelled during fetching, the new token is not needed any more
+		if err := m.client.CancelDelegationToken(ctx, token); err != nil {
+			klog.V(4).Infof("cancel unused delegation token for %v failed, error %v", m.service, err)
+		}
+		return errors.New("delegation token manager is cancelled")
+	}
+	m.token, m.expiry, m.maxDate = token, expiry, maxDate
+	// published with mtx held, so that Cancel removes it after
+	m.ugi.AddUserToken(token)
+	m.mtx.Unlock()
+	klog.V(4).Infof("fetched delegation token for %v, expiry %v, max date %v", m.service, expiry, maxDate)
+	return nil
+}
+
+func (m *DelegationTokenManager) renew(ctx context.Context) error {
+	token := m.Token()
+	if token == nil {
+		return errors.New("no delegation token to renew")
+	}
+	expiry, err := m.client.RenewDelegationToken(ctx, token)
+	if err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	if m.token == token {
+		m.expiry = expiry
+	}
+	m.mtx.Unlock()
+	klog.V(4).Infof("renewed delegation token for %v, expiry %v", m.service, expiry)
+	return nil
+}
+
+// nextRenewal returns the delay before renewing the token
+func (m *DelegationTokenManager) nextRenewal(now time.Time) time.Duration {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delay := time.Duration(float64(m.expiry.Sub(now)) * m.renewRatio)
+	if delay > m.renewInterval {
+		delay = m.renewInterval
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay
+}
+
+// needsNewToken returns true if the token has reached its max date, or the last renewal could not extend it any
+// further, so that a new token is fetched while the current one is still valid
+func (m *DelegationTokenManager) needsNewToken(now time.Time) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.token == nil {
+		return true
+	}
+	if m.maxDate.IsZero() {
+		return false
+	}
+	return !now.Before(m.maxDate) || !m.expiry.Before(m.maxDate)
+}
+
+// delegationTokenMaxDate decodes the maxDate of YARNDelegationTokenIdentifierProto in the identifier of token
+func delegationTokenMaxDate(token *hadoop_common.TokenProto) (time.Time, error) {
+	const maxDateField = 5
+	b := token.GetIdentifier()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return time.Time{}, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if num == maxDateField && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return time.Time{}, protowire.ParseError(n)
+			}
+			return time.UnixMilli(int64(v)), nil
+		}
+		if n = protowire.ConsumeFieldValue(num, typ, b); n < 0 {
+			return time.Time{}, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return time.Time{}, errors.New("no max date in token identifier")
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+type fakeDelegationTokenClient struct {
+	maxDate   time.Time
+	expiry    time.Time
+	fetched   int
+	renewErr  error
+	cancelled []*hadoop_common.TokenProto
+}
+
+func (c *fakeDelegationTokenClient) GetDelegationToken(ctx context.Context, renewer string) (*hadoop_common.TokenProto, error) {
+	c.fetched++
+	// owner, renewer, realUser, issueDate, maxDate of YARNDelegationTokenIdentifierProto
+	identifier := protowire.AppendTag(nil, 2, protowire.BytesType)
+	identifier = protowire.AppendString(identifier, renewer)
+	identifier = protowire.AppendTag(identifier, 5, protowire.VarintType)
+	identifier = protowire.AppendVarint(identifier, uint64(c.maxDate.UnixMilli()))
+	return &hadoop_common.TokenProto{Identifier: identifier, Password: []byte("pw"), Kind: proto.String(TokenKindRMDelegation)}, nil
+}
+
+func (c *fakeDelegationTokenClient) RenewDelegationToken(ctx context.Context, token *hadoop_common.TokenProto) (time.Time, error) {
+	return c.expiry, c.renewErr
+}
+
+func (c *fakeDelegationTokenClient) CancelDelegationToken(ctx context.Context, token *hadoop_common.TokenProto) error {
+	c.cancelled = append(c.cancelled, token)
+	return nil
+}
+
+func TestDelegationTokenManager(t *testing.T) {
+	now := time.Now()
+	client := &fakeDelegationTokenClient{maxDate: now.Add(7 * 24 * time.Hour), expiry: now.Add(24 * time.Hour)}
+	ugi := NewRemoteUser("test")
+	m := NewDelegationTokenManager(client, ugi, "test", "10.0.0.1:8032,10.0.0.2:8032")
+
+	assert.NoError(t, m.fetch(context.Background()))
+	assert.Equal(t, 1, client.fetched)
+	token, found := ugi.SelectToken(TokenKindRMDelegation, "10.0.0.2:8032")
+	assert.True(t, found)
+	assert.Equal(t, m.Token(), token)
+	assert.Equal(t, client.maxDate.UnixMilli(), m.maxDate.UnixMilli())
+
+	// renewed at most once per interval, and before expiry
+	assert.Equal(t, defaultTokenRenewInterval, m.nextRenewal(now))
+	assert.Equal(t, 45*time.Minute, m.nextRenewal(now.Add(23*time.Hour)))
+	assert.Equal(t, time.Duration(0), m.nextRenewal(now.Add(25*time.Hour)))
+
+	assert.False(t, m.needsNewToken(now))
+	client.expiry = client.maxDate
+	assert.NoError(t, m.renew(context.Background()))
+	assert.True(t, m.needsNewToken(now))
+
+	client.renewErr = errors.New("token expired")
+	assert.Error(t, m.renew(context.Background()))
+
+	assert.NoError(t, m.Cancel(context.Background()))
+	assert.Equal(t, []*hadoop_common.TokenProto{token}, client.cancelled)
+	assert.Nil(t, m.Token())
+	_, found = ugi.SelectToken(TokenKindRMDelegation, "10.0.0.2:8032")
+	assert.False(t, found)
+
+	// no token is published after cancel, and the token fetched meanwhile is cancelled
+	client.renewErr = nil
+	assert.Error(t, m.fetch(context.Background()))
+	assert.Nil(t, m.Token())
+	assert.Len(t, ugi.GetUserTokens(), 0)
+	assert.Len(t, client.cancelled, 2)
+	assert.Error(t, m.Start(context.Background()))
+}
@@ -18,11 +18,20 @@ limitations under the License.
 package security
 
 import (
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
 	"crypto/md5"
 	"crypto/rand"
+	"crypto/rc4"
+	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
 	"strings"
 
 	"k8s.io/klog/v2"
@@ -30,29 +39,57 @@ import (
 	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 )
 
+const (
+	digestMaxBuf     = 65536
+	digestNonceCount = "00000001"
+
+	// mac(10) + message type(2) + sequence number(4), and the padding of block ciphers
+	digestIntegrityOverhead = 16
+	digestPrivacyOverhead   = 26
+
+	digestMACLength = 10
+
+	clientSigningMagic = "Digest session key to client-to-server signing key magic constant"
+	serverSigningMagic = "Digest session key to server-to-client signing key magic constant"
+	clientSealingMagic = "Digest H(A1) to client-to-server sealing key magic constant"
+	serverSealingMagic = "Digest H(A1) to server-to-client sealing key magic constant"
+)
+
+// ciphers of auth-conf, the strongest first
+var digestCiphers = []string{"3des", "rc4", "des", "rc4-56", "rc4-40"}
+
+var digestMessageType = []byte{0x00, 0x01}
+
 func getChallengeParams(challenge string) (map[string]string, error) {
 	challengeParams := make(map[string]string)
-	splits := strings.Split(string(challenge), ",")
 
-	for _, split := range splits {
+	for len(challenge) > 0 {
 		//split on first '='
-		keyVal := strings.SplitN(split, "=", 2)
-
+		keyVal := strings.SplitN(challenge, "=", 2)
 		if len(keyVal) != 2 {
-			klog.Warningf("found invalid param: ", split)
-			return nil, errors.New("found invalid param: " + split)
+			klog.Warningf("found invalid param: %v", challenge)
+			return nil, errors.New("found invalid param: " + challenge)
 		}
-
-		key := keyVal[0]
+		key := strings.TrimSpace(keyVal[0])
 		value := keyVal[1]
 
-		//some challenge params are quoted (realm, nonce, qop).
+		//some challenge params are quoted (realm, nonce, qop, cipher), which may contain commas.
 		//strip these out.
 		quote := "\""
-		if strings.HasPrefix(value, quote) && strings.HasSuffix(value, quote) {
-			value = value[len(quote):]
-			value = value[:len(value)-len(quote)]
+		if strings.HasPrefix(value, quote) {
+			end := strings.Index(value[len(quote):], quote)
+			if end < 0 {
+				return nil, errors.New("found unterminated quoted param: " + key)
+			}
+			challenge = value[len(quote)+end+len(quote):]
+			value = value[len(quote) : len(quote)+end]
+		} else if end := strings.Index(value, ","); end >= 0 {
+			challenge = value[end:]
+			value = value[:end]
+		} else {
+			challenge = ""
 		}
+		challenge = strings.TrimPrefix(challenge, ",")
 
 		challengeParams[key] = value
 	}
@@ -61,7 +98,7 @@ func getChallengeParams(challenge string) (map[string]string, error) {
 }
 
 // we only support a very specific digest-md5 mechanism for the moment
-// multiple realm, qop not supported
+// multiple realm not supported
 func validateChallengeParameters(challengeParams map[string]string) error {
 	var errString string
 
@@ -75,11 +112,6 @@ func validateChallengeParameters(challengeParams map[string]string) error {
 		errString += "missing or invalid nonce. "
 	}
 
-	qop, exists := challengeParams["qop"]
-	if !exists || qop != "auth" {
-		errString += "missing, invalid or unsupported qop. "
-	}
-
 	charset, exists := challengeParams["charset"]
 	if !exists || charset != "utf-8" {
 		errString += "missing, invalid or unsupported charset. "
@@ -97,101 +129,379 @@ func validateChallengeParameters(challengeParams map[string]string) error {
 	return nil
 }
 
-func generateChallengeReponse(username string, password string, protocol string, serverId string, challengeParams map[string]string) (string, error) {
+// DigestMD5Client is the client side of SASL DIGEST-MD5 mechanism in RFC 2831, which is used by TOKEN auth of
+// hadoop rpc. It protects messages with auth-int and auth-conf once the negotiation is completed.
+type DigestMD5Client struct {
+	digestSecurityLayer
+	token   *hadoop_common.TokenProto
+	allowed []QOP
+
+	cnonce       string
+	responseAuth string
+	complete     bool
+}
+
+// digestSecurityLayer protects messages after the negotiation, which is shared by both sides of DIGEST-MD5
+type digestSecurityLayer struct {
+	qop         QOP
+	cipher      string
+	ha1         [md5.Size]byte
+	maxSendSize int
+
+	sendKi, recvKi   []byte
+	sendSeq, recvSeq uint32
+	encrypter        digestCipher
+	decrypter        digestCipher
+}
+
+type digestCipher struct {
+	stream cipher.Stream
+	block  cipher.BlockMode
+}
+
+func (c *digestCipher) blockSize() int {
+	if c.block != nil {
+		return c.block.BlockSize()
+	}
+	return 0
+}
+
+func (c *digestCipher) crypt(dst, src []byte) {
+	if c.block != nil {
+		c.block.CryptBlocks(dst, src)
+	} else {
+		c.stream.XORKeyStream(dst, src)
+	}
+}
+
+// NewDigestMD5Client creates a client authenticating with token, the strongest qop in allowed which server
+// supports is negotiated, only auth is allowed if allowed is empty
+func NewDigestMD5Client(token *hadoop_common.TokenProto, allowed []QOP) *DigestMD5Client {
+	return &DigestMD5Client{token: token, allowed: allowed}
+}
+
+// EvaluateChallenge returns the response to the digest challenge of server
+func (d *DigestMD5Client) EvaluateChallenge(protocol string, serverId string, challenge []byte) ([]byte, error) {
+	if len(challenge) <= 0 {
+		klog.Warningf("challenge cannot be empty!")
+		return nil, errors.New("challenge cannot be empty!")
+	}
+
+	challengeParams, err := getChallengeParams(string(challenge))
+	if err != nil {
+		klog.Warningf("challenge params extraction failure! %v", err)
+		return nil, err
+	}
+	if err = validateChallengeParameters(challengeParams); err != nil {
+		klog.Warningf("challenge params validation failure! %v", err)
+		return nil, err
+	}
+
+	// qop is auth if absent in challenge
+	offered := []QOP{QOPAuth}
+	if qops, exists := challengeParams["qop"]; exists {
+		offered = nil
+		for _, qop := range strings.Split(qops, ",") {
+			offered = append(offered, QOP(strings.TrimSpace(qop)))
+		}
+	}
+	if d.qop, err = selectQOP(offered, d.allowed); err != nil {
+		return nil, err
+	}
+	if d.qop == QOPAuthConf {
+		if d.cipher, err = selectDigestCipher(challengeParams["cipher"]); err != nil {
+			return nil, err
+		}
+	}
+	maxbuf := digestMaxBuf
+	if value, exists := challengeParams["maxbuf"]; exists {
+		if maxbuf, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid maxbuf %v", value)
+		}
+	}
+	d.maxSendSize = maxbuf - digestIntegrityOverhead
+	if d.qop == QOPAuthConf {
+		d.maxSendSize = maxbuf - digestPrivacyOverhead
+	}
+
+	if d.cnonce == "" {
+		//generate a response nonce
+		nonceBuffer := make([]byte, 30)
+		if _, err := rand.Read(nonceBuffer); err != nil {
+			return nil, err
+		}
+		d.cnonce = base64.StdEncoding.EncodeToString(nonceBuffer)
+	}
+
+	username := base64.StdEncoding.EncodeToString(d.token.GetIdentifier())
+	password := base64.StdEncoding.EncodeToString(d.token.GetPassword())
+	response := d.generateChallengeReponse(username, password, protocol, serverId, challengeParams)
+	klog.V(5).Infof("generated challenge response: %s", response)
+	return []byte(response), nil
+}
+
+func selectDigestCipher(ciphers string) (string, error) {
+	offered := strings.Split(ciphers, ",")
+	for _, c := range digestCiphers {
+		for _, o := range offered {
+			if strings.TrimSpace(o) == c {
+				return c, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no supported cipher in %v", ciphers)
+}
+
+func (d *DigestMD5Client) generateChallengeReponse(username string, password string, protocol string, serverId string, challengeParams map[string]string) string {
 	buffer := make([]string, 0, 128)
 
 	charset := "charset=utf-8"
 	quote := "\""
 	comma := ","
-	maxbuf := "maxbuf=65536"
-	nonceCount := "nc=00000001"
-	nonceCountHex := "00000001"
 
 	realm := challengeParams["realm"]
 	nonce := challengeParams["nonce"]
-	qop := challengeParams["qop"]
 	digestUri := protocol + "/" + serverId
-	method := "AUTHENTICATE"
 
 	buffer = append(buffer, charset, comma)
 	buffer = append(buffer, "username=", quote, username, quote, comma)
 	buffer = append(buffer, "realm=", quote, realm, quote, comma)
 	buffer = append(buffer, "nonce=", quote, nonce, quote, comma)
-	buffer = append(buffer, nonceCount, comma) //nonce count is one
-
-	//generate a response nonce
-	count := 30
-	nonceBuffer := make([]byte, count)
-	_, err := rand.Read(nonceBuffer)
-	if err != nil {
-		return "", err
-	}
-	encodedNonce := base64.StdEncoding.EncodeToString(nonceBuffer)
-	buffer = append(buffer, "cnonce=", quote, encodedNonce, quote, comma)
-
+	buffer = append(buffer, "nc=", digestNonceCount, comma) //nonce count is one
+	buffer = append(buffer, "cnonce=", quote, d.cnonce, quote, comma)
 	buffer = append(buffer, "digest-uri=", quote, digestUri, quote, comma)
-	buffer = append(buffer, maxbuf, comma)
+	buffer = append(buffer, "maxbuf=", strconv.Itoa(digestMaxBuf), comma)
+	if d.cipher != "" {
+		buffer = append(buffer, "cipher=", quote, d.cipher, quote, comma)
+	}
 
-	//for the md5-sess/qop=auth case, the computation is :
+	//for the md5-sess case, the computation is :
 	//HA1=MD5(MD5(username:realm:password):nonce:cnonce)
-	//HA2=MD5(method:digestURI)//
+	//HA2=MD5(AUTHENTICATE:digestURI) for auth, or MD5(AUTHENTICATE:digestURI:00000000000000000000000000000000)
 	//response=MD5(HA1:nonce:nonceCount:clientNonce:qop:HA2)
+	//and the response auth of server uses HA2 without AUTHENTICATE
+	ha1Part1md5 := md5.Sum([]byte(username + ":" + realm + ":" + password))
+	d.ha1 = md5.Sum([]byte(string(ha1Part1md5[:]) + ":" + nonce + ":" + d.cnonce))
 
-	hashSize := 16 //16 bytes
-	ha1Part1 := username + ":" + realm + ":" + password
-	ha1Part2 := ":" + nonce + ":" + encodedNonce
-	ha1Part1md5 := md5.Sum([]byte(ha1Part1))
-	ha1Input := string(ha1Part1md5[:hashSize]) + ha1Part2
-
-	HA1 := md5.Sum([]byte(ha1Input))
-	HA2 := md5.Sum([]byte(method + ":" + digestUri))
-
-	ha1Hex := hex.EncodeToString(HA1[:hashSize])
-	ha2Hex := hex.EncodeToString(HA2[:hashSize])
-
-	responseHashInput := string(ha1Hex + ":" + nonce + ":" + nonceCountHex + ":" + encodedNonce + ":" + qop + ":" + ha2Hex)
-	responseHash := md5.Sum([]byte(responseHashInput))
-	responseHashHex := hex.EncodeToString(responseHash[:hashSize])
+	a2 := ":" + digestUri
+	if d.qop != QOPAuth {
+		a2 += ":00000000000000000000000000000000"
+	}
+	response := d.digest(nonce, "AUTHENTICATE"+a2)
+	d.responseAuth = d.digest(nonce, a2)
 	//end digest-md5 computation
 
-	buffer = append(buffer, "response=", responseHashHex, comma)
-	buffer = append(buffer, "qop=", qop)
-	response := strings.Join(buffer, "")
+	buffer = append(buffer, "response=", response, comma)
+	buffer = append(buffer, "qop=", string(d.qop))
+	return strings.Join(buffer, "")
+}
 
-	klog.V(5).Infof("generated challenge response: %s", response)
+func (d *DigestMD5Client) digest(nonce string, a2 string) string {
+	return digestResponse(d.ha1, nonce, d.cnonce, d.qop, a2)
+}
 
-	return response, nil
+func digestResponse(ha1 [md5.Size]byte, nonce string, cnonce string, qop QOP, a2 string) string {
+	ha1Hex := hex.EncodeToString(ha1[:])
+	ha2 := md5.Sum([]byte(a2))
+	responseHash := md5.Sum([]byte(ha1Hex + ":" + nonce + ":" + digestNonceCount + ":" + cnonce + ":" + string(qop) + ":" + hex.EncodeToString(ha2[:])))
+	return hex.EncodeToString(responseHash[:])
 }
 
-func GetDigestMD5ChallengeResponse(protocol string, serverId string, challenge []byte, userToken *hadoop_common.TokenProto) (string, error) {
-	if len(challenge) <= 0 {
-		klog.Warningf("challenge cannot be empty!")
+// VerifyResponseAuth checks the rspauth sent by server on success, and sets up the security layer
+func (d *DigestMD5Client) VerifyResponseAuth(token []byte) error {
+	params, err := getChallengeParams(string(token))
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(params["rspauth"]), []byte(d.responseAuth)) != 1 {
+		return errors.New("server response auth does not match, the server may not know the token password")
+	}
+	if err := d.setup(true); err != nil {
+		return err
+	}
+	d.complete = true
+	return nil
+}
+
+func (d *DigestMD5Client) IsComplete() bool {
+	return d.complete
+}
 
-		return "", errors.New("challenge cannot be empty!")
+// setup derives the keys of the negotiated qop and cipher from ha1, the keys of client are swapped on server
+func (d *digestSecurityLayer) setup(client bool) error {
+	if d.qop == QOPAuth {
+		return nil
+	}
+	signingMagic := [2]string{clientSigningMagic, serverSigningMagic}
+	sealingMagic := [2]string{clientSealingMagic, serverSealingMagic}
+	if !client {
+		signingMagic[0], signingMagic[1] = signingMagic[1], signingMagic[0]
+		sealingMagic[0], sealingMagic[1] = sealingMagic[1], sealingMagic[0]
+	}
+	sendKi := md5.Sum(append(d.ha1[:], signingMagic[0]...))
+	recvKi := md5.Sum(append(d.ha1[:], signingMagic[1]...))
+	d.sendKi, d.recvKi = sendKi[:], recvKi[:]
+	if d.qop != QOPAuthConf {
+		return nil
 	}
 
+	n := md5.Size
+	switch d.cipher {
+	case "rc4-40":
+		n = 5
+	case "rc4-56":
+		n = 7
+	}
+	sendKc := md5.Sum(append(append([]byte{}, d.ha1[:n]...), sealingMagic[0]...))
+	recvKc := md5.Sum(append(append([]byte{}, d.ha1[:n]...), sealingMagic[1]...))
 	var err error
+	if d.encrypter, err = newDigestCipher(d.cipher, sendKc[:], true); err != nil {
+		return err
+	}
+	d.decrypter, err = newDigestCipher(d.cipher, recvKc[:], false)
+	return err
+}
 
-	challengeParams, err := getChallengeParams(string(challenge))
+func newDigestCipher(name string, kc []byte, encrypt bool) (digestCipher, error) {
+	var block cipher.Block
+	var err error
+	switch name {
+	case "rc4", "rc4-56", "rc4-40":
+		stream, err := rc4.NewCipher(kc)
+		return digestCipher{stream: stream}, err
+	case "des":
+		block, err = des.NewCipher(addDESParity(kc[0:7]))
+	case "3des":
+		k1, k2 := addDESParity(kc[0:7]), addDESParity(kc[7:14])
+		block, err = des.NewTripleDESCipher(append(append(append([]byte{}, k1...), k2...), k1...))
+	default:
+		return digestCipher{}, fmt.Errorf("unsupported cipher %v", name)
+	}
 	if err != nil {
-		klog.Warningf("challenge params extraction failure! ", err)
-		return "", err
+		return digestCipher{}, err
+	}
+	iv := kc[8:16]
+	if encrypt {
+		return digestCipher{block: cipher.NewCBCEncrypter(block, iv)}, nil
 	}
+	return digestCipher{block: cipher.NewCBCDecrypter(block, iv)}, nil
+}
 
-	err = validateChallengeParameters(challengeParams)
-	if err != nil {
-		klog.Warningf("challenge params validation failure! ", err)
-		return "", err
+// addDESParity expands 7 bytes to a des key of 8 bytes, 7 bits each with an odd parity bit
+func addDESParity(in []byte) []byte {
+	var v uint64
+	for _, b := range in {
+		v = v<<8 | uint64(b)
+	}
+	key := make([]byte, 8)
+	for i := range key {
+		b := byte(v>>(49-7*uint(i))) << 1
+		if bits.OnesCount8(b)%2 == 0 {
+			b |= 1
+		}
+		key[i] = b
 	}
+	return key
+}
+
+func (d *digestSecurityLayer) QOP() QOP {
+	return d.qop
+}
 
-	username := base64.StdEncoding.EncodeToString(userToken.GetIdentifier())
-	password := base64.StdEncoding.EncodeToString(userToken.GetPassword())
-	response, err := generateChallengeReponse(username, password, protocol, serverId, challengeParams)
+func (d *digestSecurityLayer) MaxWrapSize() int {
+	return d.maxSendSize
+}
 
+// Wrap returns message | mac for auth-int, or encrypted (message | padding | mac) for auth-conf, followed by
+// the message type and sequence number
+func (d *digestSecurityLayer) Wrap(message []byte) ([]byte, error) {
+	if d.qop == QOPAuth {
+		return nil, errors.New("no security layer negotiated")
+	}
+	seq := make([]byte, 4)
+	binary.BigEndian.PutUint32(seq, d.sendSeq)
+	d.sendSeq++
+	mac := digestMAC(d.sendKi, seq, message)
+
+	var wrapped []byte
+	if d.qop == QOPAuthInt {
+		wrapped = append(append([]byte{}, message...), mac...)
+	} else {
+		padLength := 0
+		if blockSize := d.encrypter.blockSize(); blockSize > 0 {
+			padLength = blockSize - (len(message)+digestMACLength)%blockSize
+		}
+		plain := make([]byte, 0, len(message)+padLength+digestMACLength)
+		plain = append(plain, message...)
+		for i := 0; i < padLength; i++ {
+			plain = append(plain, byte(padLength))
+		}
+		plain = append(plain, mac...)
+		wrapped = make([]byte, len(plain))
+		d.encrypter.crypt(wrapped, plain)
+	}
+	wrapped = append(wrapped, digestMessageType...)
+	return append(wrapped, seq...), nil
+}
+
+func (d *digestSecurityLayer) Unwrap(token []byte) ([]byte, error) {
+	if d.qop == QOPAuth {
+		return nil, errors.New("no security layer negotiated")
+	}
+	if len(token) < digestMACLength+6 {
+		return nil, errors.New("wrapped message too short")
+	}
+	body, messageType, seq := token[:len(token)-6], token[len(token)-6:len(token)-4], token[len(token)-4:]
+	if string(messageType) != string(digestMessageType) {
+		return nil, fmt.Errorf("invalid message type %v", messageType)
+	}
+	if binary.BigEndian.Uint32(seq) != d.recvSeq {
+		return nil, fmt.Errorf("invalid sequence number %v, expecting %v", binary.BigEndian.Uint32(seq), d.recvSeq)
+	}
+
+	var message, mac []byte
+	if d.qop == QOPAuthInt {
+		message, mac = body[:len(body)-digestMACLength], body[len(body)-digestMACLength:]
+	} else {
+		blockSize := d.decrypter.blockSize()
+		if blockSize > 0 && len(body)%blockSize != 0 {
+			return nil, errors.New("encrypted message is not a multiple of block size")
+		}
+		plain := make([]byte, len(body))
+		d.decrypter.crypt(plain, body)
+		message, mac = plain[:len(plain)-digestMACLength], plain[len(plain)-digestMACLength:]
+		if blockSize > 0 {
+			padLength := 0
+			if len(message) > 0 {
+				padLength = int(message[len(message)-1])
+			}
+			if padLength == 0 || padLength > blockSize || padLength > len(message) {
+				return nil, errors.New("invalid padding of encrypted message")
+			}
+			message = message[:len(message)-padLength]
+		}
+	}
+	if !hmac.Equal(mac, digestMAC(d.recvKi, seq, message)) {
+		return nil, errors.New("invalid mac of wrapped message")
+	}
+	d.recvSeq++
+	return message, nil
+}
+
+// digestMAC is the first 10 bytes of HMAC-MD5(ki, seq | message)
+func digestMAC(ki []byte, seq []byte, message []byte) []byte {
+	h := hmac.New(md5.New, ki)
+	h.Write(seq)
+	h.Write(message)
+	return h.Sum(nil)[:digestMACLength]
+}
+
+// GetDigestMD5ChallengeResponse returns the response of qop auth to challenge
+func GetDigestMD5ChallengeResponse(protocol string, serverId string, challenge []byte, userToken *hadoop_common.TokenProto) (string, error) {
+	response, err := NewDigestMD5Client(userToken, nil).EvaluateChallenge(protocol, serverId, challenge)
 	if err != nil {
-		klog.Warningf("Failed to generate challenge response! ", err)
+		klog.Warningf("Failed to generate challenge response! %v", err)
 		return "", err
 	}
-
-	return response, nil
+	return string(response), nil
 }
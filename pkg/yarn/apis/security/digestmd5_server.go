@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DigestMD5Server is the server side of SASL DIGEST-MD5 mechanism in RFC 2831, which verifies the tokens of
+// DigestMD5Client like SaslRpcServer of hadoop does. The username of client is the base64 of token identifier,
+// and the password of the identifier is returned by password.
+type DigestMD5Server struct {
+	digestSecurityLayer
+	protocol string
+	serverID string
+	offered  []QOP
+	password func(identifier []byte) ([]byte, error)
+
+	nonce      string
+	identifier []byte
+	complete   bool
+}
+
+// NewDigestMD5Server creates a server of protocol and serverID, which are the digest-uri of clients and
+// serverID is also the realm. The qops in offered are accepted, only auth is offered if it is empty.
+func NewDigestMD5Server(protocol string, serverID string, offered []QOP, password func(identifier []byte) ([]byte, error)) *DigestMD5Server {
+	if len(offered) == 0 {
+		offered = []QOP{QOPAuth}
+	}
+	return &DigestMD5Server{protocol: protocol, serverID: serverID, offered: offered, password: password}
+}
+
+// Challenge returns the digest challenge, which is sent to clients before their responses
+func (d *DigestMD5Server) Challenge() ([]byte, error) {
+	if d.nonce == "" {
+		nonceBuffer := make([]byte, 30)
+		if _, err := rand.Read(nonceBuffer); err != nil {
+			return nil, err
+		}
+		d.nonce = base64.StdEncoding.EncodeToString(nonceBuffer)
+	}
+	qops := make([]string, 0, len(d.offered))
+	for _, qop := range d.offered {
+		qops = append(qops, string(qop))
+	}
+	challenge := fmt.Sprintf(`realm="%v",nonce="%v",qop="%v",charset=utf-8,maxbuf=%v,algorithm=md5-sess`,
+		d.serverID, d.nonce, strings.Join(qops, ","), digestMaxBuf)
+	if containsQOP(d.offered, QOPAuthConf) {
+		challenge += fmt.Sprintf(`,cipher="%v"`, strings.Join(digestCiphers, ","))
+	}
+	return []byte(challenge), nil
+}
+
+// EvaluateResponse verifies the digest response of client, and returns the response auth proving the server knows
+// the password as well. The security layer is set up once the response is verified.
+func (d *DigestMD5Server) EvaluateResponse(response []byte) ([]byte, error) {
+	if d.nonce == "" {
+		return nil, errors.New("digest response before challenge")
+	}
+	if d.complete {
+		return nil, errors.New("digest negotiation is already completed")
+	}
+	params, err := getChallengeParams(string(response))
+	if err != nil {
+		return nil, err
+	}
+
+	if params["nonce"] != d.nonce {
+		return nil, errors.New("nonce of digest response does not match")
+	}
+	if params["nc"] != digestNonceCount {
+		return nil, fmt.Errorf("invalid nonce count %v", params["nc"])
+	}
+	if realm, exists := params["realm"]; exists && realm != d.serverID {
+		return nil, fmt.Errorf("invalid realm %v", realm)
+	}
+	if digestURI := d.protocol + "/" + d.serverID; params["digest-uri"] != digestURI {
+		return nil, fmt.Errorf("invalid digest-uri %v, expecting %v", params["digest-uri"], digestURI)
+	}
+	d.qop = QOPAuth
+	if qop, exists := params["qop"]; exists {
+		d.qop = QOP(qop)
+	}
+	if !containsQOP(d.offered, d.qop) {
+		return nil, fmt.Errorf("qop %v is not offered", d.qop)
+	}
+	if d.qop == QOPAuthConf {
+		if d.cipher, err = selectDigestCipher(params["cipher"]); err != nil {
+			return nil, err
+		}
+	}
+	maxbuf := digestMaxBuf
+	if value, exists := params["maxbuf"]; exists {
+		if maxbuf, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("invalid maxbuf %v", value)
+		}
+	}
+	d.maxSendSize = maxbuf - digestIntegrityOverhead
+	if d.qop == QOPAuthConf {
+		d.maxSendSize = maxbuf - digestPrivacyOverhead
+	}
+
+	identifier, err := base64.StdEncoding.DecodeString(params["username"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid username %v", params["username"])
+	}
+	password, err := d.password(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	cnonce := params["cnonce"]
+	ha1Part1md5 := md5.Sum([]byte(params["username"] + ":" + d.serverID + ":" + base64.StdEncoding.EncodeToString(password)))
+	d.ha1 = md5.Sum([]byte(string(ha1Part1md5[:]) + ":" + d.nonce + ":" + cnonce))
+	a2 := ":" + params["digest-uri"]
+	if d.qop != QOPAuth {
+		a2 += ":00000000000000000000000000000000"
+	}
+	expected := digestResponse(d.ha1, d.nonce, cnonce, d.qop, "AUTHENTICATE"+a2)
+	if subtle.ConstantTimeCompare([]byte(params["response"]), []byte(expected)) != 1 {
+		return nil, errors.New("digest response does not match, the client may not know the token password")
+	}
+
+	if err := d.setup(false); err != nil {
+		return nil, err
+	}
+	d.identifier = identifier
+	d.complete = true
+	return []byte("rspauth=" + digestResponse(d.ha1, d.nonce, cnonce, d.qop, a2)), nil
+}
+
+func (d *DigestMD5Server) IsComplete() bool {
+	return d.complete
+}
+
+// Identifier returns the token identifier of the client authenticated
+func (d *DigestMD5Server) Identifier() []byte {
+	return d.identifier
+}
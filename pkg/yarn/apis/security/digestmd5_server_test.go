@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+func TestDigestMD5Server(t *testing.T) {
+	passwords := map[string][]byte{"id": []byte("password")}
+	password := func(identifier []byte) ([]byte, error) {
+		if p, ok := passwords[string(identifier)]; ok {
+			return p, nil
+		}
+		return nil, errors.New("token not found")
+	}
+	tests := []struct {
+		name    string
+		token   *hadoop_common.TokenProto
+		offered []QOP
+		allowed []QOP
+		wantQOP QOP
+		wantErr string
+	}{
+		{
+			name:    "authentication",
+			token:   &hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("password")},
+			wantQOP: QOPAuth,
+		},
+		{
+			name:    "integrity",
+			token:   &hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("password")},
+			offered: []QOP{QOPAuthInt, QOPAuth},
+			allowed: []QOP{QOPAuthInt},
+			wantQOP: QOPAuthInt,
+		},
+		{
+			name:    "privacy",
+			token:   &hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("password")},
+			offered: []QOP{QOPAuthConf},
+			allowed: []QOP{QOPAuthInt, QOPAuthConf},
+			wantQOP: QOPAuthConf,
+		},
+		{
+			name:    "wrong password",
+			token:   &hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("guess")},
+			wantErr: "digest response does not match",
+		},
+		{
+			name:    "unknown token",
+			token:   &hadoop_common.TokenProto{Identifier: []byte("unknown"), Password: []byte("password")},
+			wantErr: "token not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewDigestMD5Server("", "default", tt.offered, password)
+			client := NewDigestMD5Client(tt.token, tt.allowed)
+			challenge, err := server.Challenge()
+			assert.NoError(t, err)
+			response, err := client.EvaluateChallenge("", "default", challenge)
+			assert.NoError(t, err)
+			responseAuth, err := server.EvaluateResponse(response)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.False(t, server.IsComplete())
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, server.IsComplete())
+			assert.Equal(t, tt.token.GetIdentifier(), server.Identifier())
+			assert.NoError(t, client.VerifyResponseAuth(responseAuth))
+			assert.Equal(t, tt.wantQOP, server.QOP())
+			if tt.wantQOP == QOPAuth {
+				return
+			}
+
+			wrapped, err := client.Wrap([]byte("rpc request"))
+			assert.NoError(t, err)
+			unwrapped, err := server.Unwrap(wrapped)
+			assert.NoError(t, err)
+			assert.Equal(t, "rpc request", string(unwrapped))
+			wrapped, err = server.Wrap([]byte("rpc response"))
+			assert.NoError(t, err)
+			unwrapped, err = client.Unwrap(wrapped)
+			assert.NoError(t, err)
+			assert.Equal(t, "rpc response", string(unwrapped))
+		})
+	}
+}
+
+func TestDigestMD5ServerDigestURI(t *testing.T) {
+	server := NewDigestMD5Server("", "default", nil, func(identifier []byte) ([]byte, error) {
+		return []byte("password"), nil
+	})
+	client := NewDigestMD5Client(&hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("password")}, nil)
+	challenge, err := server.Challenge()
+	assert.NoError(t, err)
+	response, err := client.EvaluateChallenge("yarn", "default", challenge)
+	assert.NoError(t, err)
+	_, err = server.EvaluateResponse(response)
+	assert.ErrorContains(t, err, "invalid digest-uri")
+
+	// a replayed response of another nonce is rejected
+	_, err = NewDigestMD5Server("yarn", "default", nil, nil).EvaluateResponse(response)
+	assert.Error(t, err)
+	other := NewDigestMD5Server("yarn", "default", nil, nil)
+	_, err = other.Challenge()
+	assert.NoError(t, err)
+	_, err = other.EvaluateResponse(response)
+	assert.ErrorContains(t, err, "nonce of digest response does not match")
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+func TestGetChallengeParams(t *testing.T) {
+	params, err := getChallengeParams(`realm="default",nonce="abc,def",qop="auth,auth-int,auth-conf",charset=utf-8,cipher="3des,rc4",algorithm=md5-sess`)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"realm":     "default",
+		"nonce":     "abc,def",
+		"qop":       "auth,auth-int,auth-conf",
+		"charset":   "utf-8",
+		"cipher":    "3des,rc4",
+		"algorithm": "md5-sess",
+	}, params)
+
+	_, err = getChallengeParams(`realm="default`)
+	assert.Error(t, err)
+}
+
+// the example of RFC 2831 section 4
+func TestDigestMD5Response(t *testing.T) {
+	d := &DigestMD5Client{cnonce: "OA6MHXh6VqTrRk"}
+	d.qop = QOPAuth
+	params := map[string]string{"realm": "elwood.innosoft.com", "nonce": "OA6MG9tEQGm2hh"}
+	response := d.generateChallengeReponse("chris", "secret", "imap", "elwood.innosoft.com", params)
+	assert.Contains(t, response, "response=d388dad90d4bbd760a152321f2143af7,")
+	assert.Equal(t, "ea40f60335c427b5527b84dbabcdfffd", d.responseAuth)
+
+	assert.Error(t, d.VerifyResponseAuth([]byte("rspauth=00000000000000000000000000000000")))
+	assert.False(t, d.IsComplete())
+	assert.NoError(t, d.VerifyResponseAuth([]byte("rspauth=ea40f60335c427b5527b84dbabcdfffd")))
+	assert.True(t, d.IsComplete())
+}
+
+// digestMD5Server mirrors the keys of client, as the server side of the security layer
+func digestMD5Server(t *testing.T, d *DigestMD5Client) *digestSecurityLayer {
+	server := &digestSecurityLayer{qop: d.qop, cipher: d.cipher, ha1: d.ha1, maxSendSize: d.maxSendSize}
+	assert.NoError(t, server.setup(false))
+	return server
+}
+
+func TestDigestMD5Client(t *testing.T) {
+	challenge := `realm="default",nonce="nonce",qop="auth,auth-int,auth-conf",charset=utf-8,cipher="rc4-40,rc4-56,rc4,des,3des",maxbuf=65536,algorithm=md5-sess`
+	tests := []struct {
+		name       string
+		challenge  string
+		allowed    []QOP
+		wantQOP    QOP
+		wantCipher string
+		wantErr    bool
+	}{
+		{
+			name:      "authentication",
+			challenge: challenge,
+			wantQOP:   QOPAuth,
+		},
+		{
+			name:      "integrity",
+			challenge: challenge,
+			allowed:   []QOP{QOPAuth, QOPAuthInt},
+			wantQOP:   QOPAuthInt,
+		},
+		{
+			name:       "privacy with 3des",
+			challenge:  challenge,
+			allowed:    []QOP{QOPAuthInt, QOPAuthConf},
+			wantQOP:    QOPAuthConf,
+			wantCipher: "3des",
+		},
+		{
+			name:       "privacy with des",
+			challenge:  strings.Replace(challenge, "rc4-40,rc4-56,rc4,des,3des", "des,rc4-40", 1),
+			allowed:    []QOP{QOPAuthConf},
+			wantQOP:    QOPAuthConf,
+			wantCipher: "des",
+		},
+		{
+			name:       "privacy with rc4",
+			challenge:  strings.Replace(challenge, "rc4-40,rc4-56,rc4,des,3des", "rc4-40,rc4", 1),
+			allowed:    []QOP{QOPAuthConf},
+			wantQOP:    QOPAuthConf,
+			wantCipher: "rc4",
+		},
+		{
+			name:       "privacy with rc4-40",
+			challenge:  strings.Replace(challenge, "rc4-40,rc4-56,rc4,des,3des", "rc4-40", 1),
+			allowed:    []QOP{QOPAuthConf},
+			wantQOP:    QOPAuthConf,
+			wantCipher: "rc4-40",
+		},
+		{
+			name:      "server only supports authentication",
+			challenge: strings.Replace(challenge, "auth,auth-int,auth-conf", "auth", 1),
+			allowed:   []QOP{QOPAuthConf},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDigestMD5Client(&hadoop_common.TokenProto{Identifier: []byte("id"), Password: []byte("password")}, tt.allowed)
+			response, err := d.EvaluateChallenge("yarn", "default", []byte(tt.challenge))
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			if tt.wantErr {
+				return
+			}
+			assert.Contains(t, string(response), "qop="+string(tt.wantQOP))
+			assert.Equal(t, tt.wantQOP, d.QOP())
+			assert.Equal(t, tt.wantCipher, d.cipher)
+			assert.NoError(t, d.VerifyResponseAuth([]byte("rspauth="+d.responseAuth)))
+			if tt.wantQOP == QOPAuth {
+				_, err = d.Wrap([]byte("rpc request"))
+				assert.Error(t, err)
+				return
+			}
+
+			server := digestMD5Server(t, d)
+			// the sequence numbers and cipher states are kept between messages
+			for _, message := range []string{"rpc request", "", "another rpc request of 32 bytes"} {
+				wrapped, err := d.Wrap([]byte(message))
+				assert.NoError(t, err)
+				unwrapped, err := server.Unwrap(wrapped)
+				assert.NoError(t, err)
+				assert.Equal(t, message, string(unwrapped))
+
+				wrapped, err = server.Wrap([]byte(message))
+				assert.NoError(t, err)
+				unwrapped, err = d.Unwrap(wrapped)
+				assert.NoError(t, err)
+				assert.Equal(t, message, string(unwrapped))
+			}
+
+			wrapped, err := d.Wrap([]byte("rpc request"))
+			assert.NoError(t, err)
+			wrapped[0] ^= 0xff
+			_, err = server.Unwrap(wrapped)
+			assert.Error(t, err)
+		})
+	}
+}
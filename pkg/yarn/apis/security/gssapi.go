@@ -0,0 +1,331 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/chksumtype"
+	"github.com/jcmturner/gokrb5/v8/iana/flags"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+// security layers of SASL GSSAPI in RFC 4752, which are selected by hadoop.rpc.protection
+const (
+	SaslQopAuth     byte = 0x01
+	SaslQopAuthInt  byte = 0x02
+	SaslQopAuthConf byte = 0x04
+)
+
+// flags of wrap token in RFC 4121 section 4.2.2
+const (
+	wrapFlagSentByAcceptor byte = 0x01
+	wrapFlagSealed         byte = 0x02
+	wrapFlagAcceptorSubkey byte = 0x04
+)
+
+var krb5TokenIdAPReq = []byte{0x01, 0x00}
+
+var gssapiQOPs = map[byte]QOP{SaslQopAuth: QOPAuth, SaslQopAuthInt: QOPAuthInt, SaslQopAuthConf: QOPAuthConf}
+
+const (
+	gssapiMaxBuf = 65536
+	// header and checksum of wrap token, plus the confounder and encrypted header for sealed ones
+	gssapiWrapOverhead = 64
+)
+
+// GSSAPIClient is the client side of SASL GSSAPI mechanism with kerberos v5, which is used by KERBEROS auth of
+// hadoop rpc. The wrap tokens are in the format of RFC 4121, so the tickets must be encrypted with aes rather
+// than des or rc4.
+type GSSAPIClient struct {
+	credential KerberosCredential
+	spn        string
+	allowed    []QOP
+
+	authenticator  types.Authenticator
+	sessionKey     types.EncryptionKey
+	acceptorSubkey types.EncryptionKey
+	established    bool
+	complete       bool
+
+	qop         QOP
+	maxSendSize int
+	sendSeq     uint64
+}
+
+// NewGSSAPIClient creates a client authenticating credential to the service principal spn, e.g. rm/host, the
+// strongest qop in allowed which server supports is negotiated, only auth is allowed if allowed is empty
+func NewGSSAPIClient(credential KerberosCredential, spn string, allowed []QOP) *GSSAPIClient {
+	return &GSSAPIClient{credential: credential, spn: spn, allowed: allowed}
+}
+
+// InitialResponse returns the initial context token with AP_REQ for the service ticket of spn
+func (g *GSSAPIClient) InitialResponse() ([]byte, error) {
+	ticket, sessionKey, err := g.credential.GetServiceTicket(g.spn)
+	if err != nil {
+		return nil, fmt.Errorf("get service ticket for %v failed, error %v", g.spn, err)
+	}
+	authenticator, err := types.NewAuthenticator(g.credential.Realm(), g.credential.PrincipalName())
+	if err != nil {
+		return nil, err
+	}
+	authenticator.Cksum = types.Checksum{
+		CksumType: chksumtype.GSSAPI,
+		Checksum:  authenticatorChecksum(gssapi.ContextFlagMutual | gssapi.ContextFlagInteg | gssapi.ContextFlagConf),
+	}
+	apReq, err := messages.NewAPReq(ticket, sessionKey, authenticator)
+	if err != nil {
+		return nil, err
+	}
+	types.SetFlag(&apReq.APOptions, flags.APOptionMutualRequired)
+	apReqBytes, err := apReq.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	g.authenticator, g.sessionKey = authenticator, sessionKey
+	g.sendSeq = uint64(authenticator.SeqNumber)
+	token, _ := asn1.Marshal(gssapi.OIDKRB5.OID())
+	token = append(token, krb5TokenIdAPReq...)
+	token = append(token, apReqBytes...)
+	return asn1tools.AddASNAppTag(token, 0), nil
+}
+
+// EvaluateChallenge verifies AP_REP of the server first, and then responds to the security layers offered by server
+func (g *GSSAPIClient) EvaluateChallenge(challenge []byte) ([]byte, error) {
+	if g.complete {
+		return nil, errors.New("GSSAPI authentication already completed")
+	}
+	if !g.established {
+		if err := g.verifyAPRep(challenge); err != nil {
+			return nil, err
+		}
+		g.established = true
+		return []byte{}, nil
+	}
+
+	offer, err := g.unwrap(challenge)
+	if err != nil {
+		return nil, err
+	}
+	if len(offer) != 4 {
+		return nil, fmt.Errorf("invalid GSSAPI security layer offer of %v bytes", len(offer))
+	}
+	var offered []QOP
+	for mask, qop := range gssapiQOPs {
+		if offer[0]&mask != 0 {
+			offered = append(offered, qop)
+		}
+	}
+	if g.qop, err = selectQOP(offered, g.allowed); err != nil {
+		return nil, err
+	}
+
+	layer := []byte{SaslQopAuth, 0, 0, 0}
+	if g.qop != QOPAuth {
+		binary.BigEndian.PutUint32(layer, gssapiMaxBuf)
+		for mask, qop := range gssapiQOPs {
+			if qop == g.qop {
+				layer[0] = mask
+			}
+		}
+		// the server does not limit the size of messages if max buffer size is 0
+		serverMaxBuf := int(offer[1])<<16 | int(offer[2])<<8 | int(offer[3])
+		if serverMaxBuf == 0 {
+			serverMaxBuf = gssapiMaxBuf
+		}
+		g.maxSendSize = serverMaxBuf - gssapiWrapOverhead
+	}
+	response, err := g.wrap(layer)
+	if err != nil {
+		return nil, err
+	}
+	g.complete = true
+	return response, nil
+}
+
+func (g *GSSAPIClient) QOP() QOP {
+	return g.qop
+}
+
+func (g *GSSAPIClient) MaxWrapSize() int {
+	return g.maxSendSize
+}
+
+// Wrap returns the wrap token of message, which is sealed for auth-conf
+func (g *GSSAPIClient) Wrap(message []byte) ([]byte, error) {
+	if !g.complete || g.qop == QOPAuth {
+		return nil, errors.New("no security layer negotiated")
+	}
+	if g.qop == QOPAuthConf {
+		return g.seal(message)
+	}
+	return g.wrap(message)
+}
+
+func (g *GSSAPIClient) Unwrap(token []byte) ([]byte, error) {
+	if !g.complete || g.qop == QOPAuth {
+		return nil, errors.New("no security layer negotiated")
+	}
+	return g.unwrap(token)
+}
+
+func (g *GSSAPIClient) IsComplete() bool {
+	return g.complete
+}
+
+func (g *GSSAPIClient) verifyAPRep(token []byte) error {
+	if len(token) == 0 {
+		return errors.New("no AP_REP from server for mutual authentication")
+	}
+	var krb5Token spnego.KRB5Token
+	if err := krb5Token.Unmarshal(token); err != nil {
+		return err
+	}
+	if krb5Token.IsKRBError() {
+		return krb5Token.KRBError
+	} else if !krb5Token.IsAPRep() {
+		return errors.New("expected AP_REP from server")
+	}
+	plain, err := crypto.DecryptEncPart(krb5Token.APRep.EncPart, g.sessionKey, keyusage.AP_REP_ENCPART)
+	if err != nil {
+		return fmt.Errorf("decrypt AP_REP failed, error %v", err)
+	}
+	var repPart messages.EncAPRepPart
+	if err := repPart.Unmarshal(plain); err != nil {
+		return err
+	}
+	// the time of authenticator is encoded in seconds
+	if repPart.CTime.Unix() != g.authenticator.CTime.Unix() || repPart.Cusec != g.authenticator.Cusec {
+		return errors.New("mutual authentication failed, AP_REP does not match the authenticator")
+	}
+	if repPart.Subkey.KeyType != 0 {
+		g.acceptorSubkey = repPart.Subkey
+	}
+	return nil
+}
+
+func (g *GSSAPIClient) unwrap(token []byte) ([]byte, error) {
+	if len(token) < gssapi.HdrLen {
+		return nil, errors.New("wrap token shorter than header length")
+	}
+	// undo the right rotation count of RFC 4121 section 4.2.5
+	token = append([]byte{}, token...)
+	if body := token[gssapi.HdrLen:]; len(body) > 0 {
+		rrc := int(binary.BigEndian.Uint16(token[6:8])) % len(body)
+		rotated := append(append([]byte{}, body[rrc:]...), body[:rrc]...)
+		copy(body, rotated)
+	}
+	binary.BigEndian.PutUint16(token[6:8], 0)
+
+	var wrapToken gssapi.WrapToken
+	if err := wrapToken.Unmarshal(token, true); err != nil {
+		return nil, err
+	}
+	key := g.sessionKey
+	if wrapToken.Flags&wrapFlagAcceptorSubkey != 0 {
+		key = g.acceptorSubkey
+	}
+	if wrapToken.Flags&wrapFlagSealed == 0 {
+		if _, err := wrapToken.Verify(key, keyusage.GSSAPI_ACCEPTOR_SEAL); err != nil {
+			return nil, err
+		}
+		return wrapToken.Payload, nil
+	}
+
+	// the sealed token is header | encrypted(payload | filler | header)
+	encType, err := crypto.GetEtype(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := encType.DecryptMessage(key.KeyValue, token[gssapi.HdrLen:], keyusage.GSSAPI_ACCEPTOR_SEAL)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt wrap token failed, error %v", err)
+	}
+	trailer := int(wrapToken.EC) + gssapi.HdrLen
+	if len(plain) < trailer {
+		return nil, errors.New("sealed wrap token shorter than header length")
+	}
+	if !bytes.Equal(plain[len(plain)-gssapi.HdrLen:], token[:gssapi.HdrLen]) {
+		return nil, errors.New("header of sealed wrap token does not match")
+	}
+	return plain[:len(plain)-trailer], nil
+}
+
+func (g *GSSAPIClient) wrapKey() (types.EncryptionKey, byte) {
+	if g.acceptorSubkey.KeyType != 0 {
+		return g.acceptorSubkey, wrapFlagAcceptorSubkey
+	}
+	return g.sessionKey, 0
+}
+
+func (g *GSSAPIClient) wrap(payload []byte) ([]byte, error) {
+	key, wrapFlags := g.wrapKey()
+	encType, err := crypto.GetEtype(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	wrapToken := gssapi.WrapToken{
+		Flags:     wrapFlags,
+		EC:        uint16(encType.GetHMACBitLength() / 8),
+		SndSeqNum: g.sendSeq,
+		Payload:   payload,
+	}
+	if err := wrapToken.SetCheckSum(key, keyusage.GSSAPI_INITIATOR_SEAL); err != nil {
+		return nil, err
+	}
+	g.sendSeq++
+	return wrapToken.Marshal()
+}
+
+// seal encrypts payload without filler and rotation, so the header is the same in the token and encrypted data
+func (g *GSSAPIClient) seal(payload []byte) ([]byte, error) {
+	key, wrapFlags := g.wrapKey()
+	encType, err := crypto.GetEtype(key.KeyType)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, gssapi.HdrLen)
+	copy(header, []byte{0x05, 0x04, wrapFlags | wrapFlagSealed, gssapi.FillerByte})
+	binary.BigEndian.PutUint64(header[8:], g.sendSeq)
+
+	plain := append(append([]byte{}, payload...), header...)
+	_, encrypted, err := encType.EncryptMessage(key.KeyValue, plain, keyusage.GSSAPI_INITIATOR_SEAL)
+	if err != nil {
+		return nil, err
+	}
+	g.sendSeq++
+	return append(header, encrypted...), nil
+}
+
+// authenticatorChecksum is the checksum of authenticator in RFC 4121 section 4.1.1 without channel bindings
+func authenticatorChecksum(contextFlags uint32) []byte {
+	checksum := make([]byte, 24)
+	binary.LittleEndian.PutUint32(checksum[:4], 16)
+	binary.LittleEndian.PutUint32(checksum[20:24], contextFlags)
+	return checksum
+}
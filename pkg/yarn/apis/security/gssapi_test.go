@@ -0,0 +1,236 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gofork/encoding/asn1"
+	"github.com/jcmturner/gokrb5/v8/asn1tools"
+	"github.com/jcmturner/gokrb5/v8/crypto"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/asnAppTag"
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/iana/msgtype"
+	"github.com/jcmturner/gokrb5/v8/iana/nametype"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testRealm = "EXAMPLE.COM"
+	testSPN   = "rm/rm1.example.com"
+)
+
+// fakeKDC issues service tickets encrypted with the key of service keytab, instead of talking to a real kdc
+type fakeKDC struct {
+	serviceKeytab *keytab.Keytab
+}
+
+func (k *fakeKDC) Realm() string {
+	return testRealm
+}
+
+func (k *fakeKDC) PrincipalName() types.PrincipalName {
+	return types.NewPrincipalName(nametype.KRB_NT_PRINCIPAL, "yarn")
+}
+
+func (k *fakeKDC) GetServiceTicket(spn string) (messages.Ticket, types.EncryptionKey, error) {
+	now := time.Now().UTC()
+	return messages.NewTicket(k.PrincipalName(), testRealm, types.NewPrincipalName(nametype.KRB_NT_SRV_HST, spn), testRealm,
+		types.NewKrbFlags(), k.serviceKeytab, etypeID.AES256_CTS_HMAC_SHA1_96, 1, now, now, now.Add(time.Hour), now.Add(time.Hour))
+}
+
+// fakeAcceptor is the server side of GSSAPI as hadoop rpc server does
+type fakeAcceptor struct {
+	serviceKeytab *keytab.Keytab
+	sessionKey    types.EncryptionKey
+}
+
+func (a *fakeAcceptor) acceptAPReq(t *testing.T, token []byte, tamper bool) []byte {
+	var oid asn1.ObjectIdentifier
+	rest, err := asn1.UnmarshalWithParams(token, &oid, "application,explicit,tag:0")
+	assert.NoError(t, err)
+	assert.True(t, oid.Equal(gssapi.OIDKRB5.OID()))
+	assert.Equal(t, krb5TokenIdAPReq, rest[:2])
+
+	var apReq messages.APReq
+	assert.NoError(t, apReq.Unmarshal(rest[2:]))
+	ok, err := apReq.Verify(a.serviceKeytab, time.Minute, types.HostAddress{}, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	a.sessionKey = apReq.Ticket.DecryptedEncPart.Key
+
+	repPart := messages.EncAPRepPart{CTime: apReq.Authenticator.CTime, Cusec: apReq.Authenticator.Cusec}
+	if tamper {
+		repPart.Cusec++
+	}
+	repPartBytes, err := asn1.Marshal(repPart)
+	assert.NoError(t, err)
+	encPart, err := crypto.GetEncryptedData(asn1tools.AddASNAppTag(repPartBytes, asnAppTag.EncAPRepPart), a.sessionKey, keyusage.AP_REP_ENCPART, 0)
+	assert.NoError(t, err)
+	apRepBytes, err := asn1.Marshal(messages.APRep{PVNO: 5, MsgType: msgtype.KRB_AP_REP, EncPart: encPart})
+	assert.NoError(t, err)
+
+	apRep, _ := asn1.Marshal(gssapi.OIDKRB5.OID())
+	apRep = append(apRep, 0x02, 0x00)
+	apRep = append(apRep, asn1tools.AddASNAppTag(apRepBytes, asnAppTag.APREP)...)
+	return asn1tools.AddASNAppTag(apRep, 0)
+}
+
+func (a *fakeAcceptor) wrap(t *testing.T, payload []byte) []byte {
+	wrapToken := gssapi.WrapToken{Flags: wrapFlagSentByAcceptor, EC: 12, Payload: payload}
+	assert.NoError(t, wrapToken.SetCheckSum(a.sessionKey, keyusage.GSSAPI_ACCEPTOR_SEAL))
+	b, err := wrapToken.Marshal()
+	assert.NoError(t, err)
+	return b
+}
+
+func (a *fakeAcceptor) unwrap(t *testing.T, token []byte) []byte {
+	var wrapToken gssapi.WrapToken
+	assert.NoError(t, wrapToken.Unmarshal(token, false))
+	ok, err := wrapToken.Verify(a.sessionKey, keyusage.GSSAPI_INITIATOR_SEAL)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	return wrapToken.Payload
+}
+
+// seal encrypts payload with the right rotation count of rrc, as MIT kerberos does
+func (a *fakeAcceptor) seal(t *testing.T, payload []byte, rrc int) []byte {
+	header := []byte{0x05, 0x04, wrapFlagSentByAcceptor | wrapFlagSealed, gssapi.FillerByte, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	encType, err := crypto.GetEtype(a.sessionKey.KeyType)
+	assert.NoError(t, err)
+	_, encrypted, err := encType.EncryptMessage(a.sessionKey.KeyValue, append(append([]byte{}, payload...), header...), keyusage.GSSAPI_ACCEPTOR_SEAL)
+	assert.NoError(t, err)
+	rrc %= len(encrypted)
+	rotated := append(append([]byte{}, encrypted[len(encrypted)-rrc:]...), encrypted[:len(encrypted)-rrc]...)
+	header[7] = byte(rrc)
+	return append(header, rotated...)
+}
+
+func (a *fakeAcceptor) unseal(t *testing.T, token []byte) []byte {
+	assert.Equal(t, wrapFlagSealed, token[2]&wrapFlagSealed)
+	encType, err := crypto.GetEtype(a.sessionKey.KeyType)
+	assert.NoError(t, err)
+	plain, err := encType.DecryptMessage(a.sessionKey.KeyValue, token[gssapi.HdrLen:], keyusage.GSSAPI_INITIATOR_SEAL)
+	assert.NoError(t, err)
+	assert.Equal(t, token[:gssapi.HdrLen], plain[len(plain)-gssapi.HdrLen:])
+	return plain[:len(plain)-gssapi.HdrLen]
+}
+
+func TestGSSAPIClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		tamperAPRep bool
+		offeredQop  byte
+		allowed     []QOP
+		wantLayer   []byte
+		wantErr     bool
+	}{
+		{
+			name:       "authentication",
+			offeredQop: SaslQopAuth | SaslQopAuthInt | SaslQopAuthConf,
+			wantLayer:  []byte{SaslQopAuth, 0, 0, 0},
+		},
+		{
+			name:       "integrity",
+			offeredQop: SaslQopAuth | SaslQopAuthInt | SaslQopAuthConf,
+			allowed:    []QOP{QOPAuth, QOPAuthInt},
+			wantLayer:  []byte{SaslQopAuthInt, 0x01, 0, 0},
+		},
+		{
+			name:       "privacy",
+			offeredQop: SaslQopAuth | SaslQopAuthInt | SaslQopAuthConf,
+			allowed:    []QOP{QOPAuthConf},
+			wantLayer:  []byte{SaslQopAuthConf, 0x01, 0, 0},
+		},
+		{
+			name:        "mutual authentication failed",
+			tamperAPRep: true,
+			offeredQop:  SaslQopAuth,
+			wantErr:     true,
+		},
+		{
+			name:       "server requires privacy",
+			offeredQop: SaslQopAuthConf,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceKeytab := keytab.New()
+			assert.NoError(t, serviceKeytab.AddEntry(testSPN, testRealm, "rm-password", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96))
+			acceptor := &fakeAcceptor{serviceKeytab: serviceKeytab}
+			client := NewGSSAPIClient(&fakeKDC{serviceKeytab: serviceKeytab}, testSPN, tt.allowed)
+
+			initial, err := client.InitialResponse()
+			assert.NoError(t, err)
+			response, err := client.EvaluateChallenge(acceptor.acceptAPReq(t, initial, tt.tamperAPRep))
+			if tt.tamperAPRep {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Empty(t, response)
+
+			response, err = client.EvaluateChallenge(acceptor.wrap(t, []byte{tt.offeredQop, 0, 0x10, 0}))
+			assert.Equal(t, tt.wantErr, err != nil, err)
+			assert.Equal(t, !tt.wantErr, client.IsComplete())
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.wantLayer, acceptor.unwrap(t, response))
+
+			message := []byte("rpc request")
+			switch client.QOP() {
+			case QOPAuth:
+				_, err = client.Wrap(message)
+				assert.Error(t, err)
+			case QOPAuthInt:
+				wrapped, err := client.Wrap(message)
+				assert.NoError(t, err)
+				assert.Equal(t, message, acceptor.unwrap(t, wrapped))
+				unwrapped, err := client.Unwrap(acceptor.wrap(t, message))
+				assert.NoError(t, err)
+				assert.Equal(t, message, unwrapped)
+			case QOPAuthConf:
+				wrapped, err := client.Wrap(message)
+				assert.NoError(t, err)
+				assert.Equal(t, message, acceptor.unseal(t, wrapped))
+				for _, rrc := range []int{0, 28} {
+					unwrapped, err := client.Unwrap(acceptor.seal(t, message, rrc))
+					assert.NoError(t, err)
+					assert.Equal(t, message, unwrapped)
+				}
+			}
+		})
+	}
+}
+
+func TestGetServerPrincipal(t *testing.T) {
+	got, err := GetServerPrincipal("rm/_HOST@EXAMPLE.COM", "RM1.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm/rm1.example.com@EXAMPLE.COM", got)
+
+	got, err = GetServerPrincipal("rm/rm2.example.com@EXAMPLE.COM", "rm1.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm/rm2.example.com@EXAMPLE.COM", got)
+}
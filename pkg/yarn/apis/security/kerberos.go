@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/messages"
+	"github.com/jcmturner/gokrb5/v8/types"
+)
+
+const (
+	defaultKrb5Config = "/etc/krb5.conf"
+	hostPattern       = "_HOST"
+)
+
+// KerberosCredential is a kerberos login of user, which gets service tickets from kdc
+type KerberosCredential interface {
+	Realm() string
+	PrincipalName() types.PrincipalName
+	GetServiceTicket(spn string) (messages.Ticket, types.EncryptionKey, error)
+}
+
+// KerberosUserName returns the full principal of credential, e.g. yarn/host@REALM
+func KerberosUserName(credential KerberosCredential) string {
+	return credential.PrincipalName().PrincipalNameString() + "@" + credential.Realm()
+}
+
+type krb5Login struct {
+	client *krbclient.Client
+}
+
+func (l *krb5Login) Realm() string {
+	return l.client.Credentials.Domain()
+}
+
+func (l *krb5Login) PrincipalName() types.PrincipalName {
+	return l.client.Credentials.CName()
+}
+
+func (l *krb5Login) GetServiceTicket(spn string) (messages.Ticket, types.EncryptionKey, error) {
+	return l.client.GetServiceTicket(spn)
+}
+
+// loadKrb5Config loads krb5.conf from $KRB5_CONFIG, or /etc/krb5.conf if not set
+func loadKrb5Config() (*krbconfig.Config, error) {
+	path := os.Getenv("KRB5_CONFIG")
+	if path == "" {
+		path = defaultKrb5Config
+	}
+	cfg, err := krbconfig.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("load krb5 config %v failed, error %v", path, err)
+	}
+	return cfg, nil
+}
+
+// LoginFromKeytab logs in principal like yarn/host@REALM with keytabFile, the default realm of krb5.conf is used
+// if principal has no realm
+func LoginFromKeytab(principal string, keytabFile string) (KerberosCredential, error) {
+	cfg, err := loadKrb5Config()
+	if err != nil {
+		return nil, err
+	}
+	kt, err := keytab.Load(keytabFile)
+	if err != nil {
+		return nil, fmt.Errorf("load keytab %v failed, error %v", keytabFile, err)
+	}
+	name, realm := principal, cfg.LibDefaults.DefaultRealm
+	if i := strings.LastIndex(principal, "@"); i >= 0 {
+		name, realm = principal[:i], principal[i+1:]
+	}
+	client := krbclient.NewWithKeytab(name, realm, kt, cfg, krbclient.DisablePAFXFAST(true))
+	if err := client.Login(); err != nil {
+		return nil, fmt.Errorf("login %v with keytab %v failed, error %v", principal, keytabFile, err)
+	}
+	return &krb5Login{client: client}, nil
+}
+
+// LoginFromTicketCache logs in with the tickets in ccacheFile obtained by kinit, $KRB5CCNAME or /tmp/krb5cc_<uid>
+// is used if ccacheFile is empty
+func LoginFromTicketCache(ccacheFile string) (KerberosCredential, error) {
+	cfg, err := loadKrb5Config()
+	if err != nil {
+		return nil, err
+	}
+	if ccacheFile == "" {
+		ccacheFile = defaultTicketCache()
+	}
+	ccache, err := credentials.LoadCCache(ccacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("load ticket cache %v failed, error %v", ccacheFile, err)
+	}
+	client, err := krbclient.NewFromCCache(ccache, cfg, krbclient.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("login with ticket cache %v failed, error %v", ccacheFile, err)
+	}
+	return &krb5Login{client: client}, nil
+}
+
+func defaultTicketCache() string {
+	if name := os.Getenv("KRB5CCNAME"); name != "" {
+		return strings.TrimPrefix(name, "FILE:")
+	}
+	return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+}
+
+// LoginUserFromKeytab logs in the current user with keytab, like UserGroupInformation.loginUserFromKeytab in hadoop
+func LoginUserFromKeytab(principal string, keytabFile string) error {
+	credential, err := LoginFromKeytab(principal, keytabFile)
+	if err != nil {
+		return err
+	}
+	GetCurrentUser().SetKerberosCredential(credential)
+	return nil
+}
+
+// LoginUserFromKeytabAndReturnUGI logs in a new user with keytab, which does not change the current user
+func LoginUserFromKeytabAndReturnUGI(principal string, keytabFile string) (*UserGroupInformation, error) {
+	credential, err := LoginFromKeytab(principal, keytabFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewKerberosUser(credential), nil
+}
+
+// LoginUserFromTicketCache logs in the current user with the tickets obtained by kinit
+func LoginUserFromTicketCache(ccacheFile string) error {
+	credential, err := LoginFromTicketCache(ccacheFile)
+	if err != nil {
+		return err
+	}
+	GetCurrentUser().SetKerberosCredential(credential)
+	return nil
+}
+
+// GetServerPrincipal replaces _HOST in principalConfig like rm/_HOST@REALM with the lower-cased hostname,
+// which is the same as SecurityUtil.getServerPrincipal in hadoop
+func GetServerPrincipal(principalConfig string, hostname string) (string, error) {
+	components := strings.FieldsFunc(principalConfig, func(r rune) bool { return r == '/' || r == '@' })
+	if len(components) != 3 || components[1] != hostPattern {
+		return principalConfig, nil
+	}
+	if hostname == "" || hostname == "0.0.0.0" {
+		var err error
+		if hostname, err = os.Hostname(); err != nil {
+			return "", err
+		}
+	}
+	return components[0] + "/" + strings.ToLower(hostname) + "@" + components[2], nil
+}
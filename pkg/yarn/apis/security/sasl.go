@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QOP is the quality of protection negotiated by SASL
+type QOP string
+
+const (
+	QOPAuth     QOP = "auth"
+	QOPAuthInt  QOP = "auth-int"
+	QOPAuthConf QOP = "auth-conf"
+)
+
+// the strongest first
+var qopsByStrength = []QOP{QOPAuthConf, QOPAuthInt, QOPAuth}
+
+// ParseRPCProtection converts hadoop.rpc.protection like "integrity,privacy" to qops, which is the same as
+// SaslPropertiesResolver in hadoop
+func ParseRPCProtection(protection string) ([]QOP, error) {
+	var qops []QOP
+	for _, p := range strings.Split(protection, ",") {
+		switch strings.ToLower(strings.TrimSpace(p)) {
+		case "authentication":
+			qops = append(qops, QOPAuth)
+		case "integrity":
+			qops = append(qops, QOPAuthInt)
+		case "privacy":
+			qops = append(qops, QOPAuthConf)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown rpc protection %v", p)
+		}
+	}
+	return qops, nil
+}
+
+// selectQOP returns the strongest qop offered by server and allowed by client, only auth is allowed if allowed is empty
+func selectQOP(offered []QOP, allowed []QOP) (QOP, error) {
+	if len(allowed) == 0 {
+		allowed = []QOP{QOPAuth}
+	}
+	for _, qop := range qopsByStrength {
+		if containsQOP(offered, qop) && containsQOP(allowed, qop) {
+			return qop, nil
+		}
+	}
+	return "", fmt.Errorf("no common protection layer between client %v and server %v", allowed, offered)
+}
+
+func containsQOP(qops []QOP, qop QOP) bool {
+	for _, q := range qops {
+		if q == qop {
+			return true
+		}
+	}
+	return false
+}
+
+// SaslWrapper protects messages with integrity or privacy after the SASL negotiation, the messages must be
+// wrapped and unwrapped in order since sequence numbers and cipher states are kept between messages
+type SaslWrapper interface {
+	QOP() QOP
+	Wrap(message []byte) ([]byte, error)
+	Unwrap(token []byte) ([]byte, error)
+	// MaxWrapSize is the max size of a message to wrap, which is limited by the receive buffer of peer
+	MaxWrapSize() int
+}
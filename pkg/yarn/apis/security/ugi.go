@@ -18,7 +18,9 @@ limitations under the License.
 package security
 
 import (
+	"os"
 	"os/user"
+	"sort"
 	"sync"
 
 	"k8s.io/klog/v2"
@@ -26,90 +28,238 @@ import (
 	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 )
 
-/** a (very) basic UserGroupInformation implementation for storing user data/tokens,
-  This implementation is currently *not* thread-safe
-*/
-
+// UserGroupInformation is the identity of a client, which is one of
+//   - a remote user trusted by simple auth, see NewRemoteUser
+//   - a kerberos user, see NewKerberosUser
+//   - a proxy user impersonated by a real user, see NewProxyUser
+//
+// and the tokens of the user. It is safe for concurrent use, so that clients talking to different clusters
+// can use different identities at the same time.
 type UserGroupInformation struct {
-	// rwMutex    sync.RWMutex
-	userInfo   *hadoop_common.UserInformationProto
-	userTokens map[string]*hadoop_common.TokenProto
+	userName string
+	// realUser is the user authenticating for a proxy user
+	realUser *UserGroupInformation
+
+	// rwMutex protects the following fields
+	rwMutex    sync.RWMutex
+	tokens     map[tokenKey]*hadoop_common.TokenProto
+	secretKeys map[string][]byte
+	kerberos   KerberosCredential
+}
+
+// tokenKey is the same as the alias of tokens in hadoop, which allows one token of each kind for a service
+type tokenKey struct {
+	kind    string
+	service string
 }
 
 var once sync.Once
 var currentUserGroupInformation *UserGroupInformation
-var maxTokens = 16
-
-func CreateCurrentUserInfoProto() (*hadoop_common.UserInformationProto, error) {
-	// Figure the current user-name
-	var username string
-	if currentUser, err := user.Current(); err != nil {
-		klog.Warningf("user.Current", err)
-		return nil, err
-	} else {
-		username = currentUser.Username
-	}
 
-	return &hadoop_common.UserInformationProto{EffectiveUser: nil, RealUser: &username}, nil
+// NewRemoteUser creates a user with the name only, like UserGroupInformation.createRemoteUser in hadoop
+func NewRemoteUser(userName string) *UserGroupInformation {
+	return &UserGroupInformation{userName: userName, tokens: map[tokenKey]*hadoop_common.TokenProto{}, secretKeys: map[string][]byte{}}
 }
 
-func Allocate(userInfo *hadoop_common.UserInformationProto, userTokens map[string]*hadoop_common.TokenProto) *UserGroupInformation {
-	ugi := new(UserGroupInformation)
+// NewKerberosUser creates a user authenticated by credential, whose name is the kerberos principal
+func NewKerberosUser(credential KerberosCredential) *UserGroupInformation {
+	ugi := NewRemoteUser("")
+	ugi.kerberos = credential
+	return ugi
+}
 
-	if userInfo != nil {
-		ugi.userInfo = userInfo
-	} else {
-		currentUserInfo, _ := CreateCurrentUserInfoProto()
-		ugi.userInfo = currentUserInfo
-	}
+// NewProxyUser creates a user impersonated by realUser, like UserGroupInformation.createProxyUser in hadoop,
+// the server must allow realUser to impersonate userName by hadoop.proxyuser.* settings
+func NewProxyUser(userName string, realUser *UserGroupInformation) *UserGroupInformation {
+	ugi := NewRemoteUser(userName)
+	ugi.realUser = realUser
+	return ugi
+}
 
-	if userTokens != nil {
-		ugi.userTokens = userTokens
-	} else {
-		ugi.userTokens = make(map[string]*hadoop_common.TokenProto) //empty, with room for maxTokens tokens.
+func currentUserName() string {
+	currentUser, err := user.Current()
+	if err != nil {
+		klog.Warningf("user.Current failed, error %v", err)
+		return ""
 	}
-
-	return ugi
+	return currentUser.Username
 }
 
 func initializeCurrentUser() {
 	once.Do(func() {
-		currentUserGroupInformation = Allocate(nil, nil)
+		currentUserGroupInformation = NewRemoteUser(currentUserName())
+		// containers and jobs launched by yarn get their tokens from the token file
+		if tokenFile := os.Getenv(EnvHadoopTokenFileLocation); tokenFile != "" {
+			credentials, err := ReadTokenStorageFile(tokenFile)
+			if err != nil {
+				klog.Warningf("failed to load tokens of current user from %v=%v, error %v", EnvHadoopTokenFileLocation, tokenFile, err)
+				return
+			}
+			currentUserGroupInformation.AddCredentials(credentials)
+		}
 	})
 }
 
-func (ugi *UserGroupInformation) GetUserInformation() *hadoop_common.UserInformationProto {
-	return ugi.userInfo
+// GetCurrentUser returns the user of the process, which is used by clients without an explicit user, the tokens in
+// $HADOOP_TOKEN_FILE_LOCATION are loaded on the first call
+func GetCurrentUser() *UserGroupInformation {
+	initializeCurrentUser()
+
+	return currentUserGroupInformation
 }
 
-func (ugi *UserGroupInformation) GetUserTokens() map[string]*hadoop_common.TokenProto {
-	return ugi.userTokens
+// UserName returns the kerberos principal if the user has logged in with kerberos, otherwise the name of user
+func (ugi *UserGroupInformation) UserName() string {
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	if ugi.kerberos != nil {
+		return KerberosUserName(ugi.kerberos)
+	}
+	return ugi.userName
 }
 
-func (ugi *UserGroupInformation) AddUserTokenWithAlias(alias string, token *hadoop_common.TokenProto) {
-	if token == nil {
-		klog.Warningf("supplied token is nil!")
-		return
+// RealUser returns the user authenticating for a proxy user, or nil if the user is not a proxy user
+func (ugi *UserGroupInformation) RealUser() *UserGroupInformation {
+	return ugi.realUser
+}
+
+// AuthenticatingUser returns the real user of a proxy user, or the user itself
+func (ugi *UserGroupInformation) AuthenticatingUser() *UserGroupInformation {
+	if ugi.realUser != nil {
+		return ugi.realUser
 	}
+	return ugi
+}
 
-	if length := len(ugi.userTokens); length < maxTokens {
-		ugi.userTokens[alias] = token
-	} else {
-		klog.Warningf("user already has maxTokens:", maxTokens)
+// WithoutTokens returns a user of the same identity without tokens, which authenticates with kerberos or simple
+// auth even if the server accepts the tokens of ugi
+func (ugi *UserGroupInformation) WithoutTokens() *UserGroupInformation {
+	if ugi.realUser != nil {
+		return NewProxyUser(ugi.userName, ugi.realUser.WithoutTokens())
 	}
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	user := NewRemoteUser(ugi.userName)
+	user.kerberos = ugi.kerberos
+	return user
 }
 
+// GetUserInformation returns the user information sent in connection context, which is the same as ProtoUtil in hadoop
+func (ugi *UserGroupInformation) GetUserInformation() *hadoop_common.UserInformationProto {
+	userName := ugi.UserName()
+	userInfo := &hadoop_common.UserInformationProto{EffectiveUser: &userName}
+	if ugi.realUser != nil {
+		realUserName := ugi.realUser.UserName()
+		userInfo.RealUser = &realUserName
+	}
+	return userInfo
+}
+
+// GetUserTokens returns a copy of the tokens of user
+func (ugi *UserGroupInformation) GetUserTokens() []*hadoop_common.TokenProto {
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	tokens := make([]*hadoop_common.TokenProto, 0, len(ugi.tokens))
+	for _, token := range ugi.tokens {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// GetUserToken returns the token of kind for service
+func (ugi *UserGroupInformation) GetUserToken(kind string, service string) (*hadoop_common.TokenProto, bool) {
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	token, found := ugi.tokens[tokenKey{kind: kind, service: service}]
+	return token, found
+}
+
+// AddUserToken adds token to user, replacing the token of the same kind and service
 func (ugi *UserGroupInformation) AddUserToken(token *hadoop_common.TokenProto) {
 	if token == nil {
 		klog.Warningf("supplied token is nil!")
 		return
 	}
 
-	ugi.AddUserTokenWithAlias(token.GetService(), token)
+	ugi.rwMutex.Lock()
+	defer ugi.rwMutex.Unlock()
+	ugi.tokens[tokenKey{kind: token.GetKind(), service: token.GetService()}] = token
 }
 
-func GetCurrentUser() *UserGroupInformation {
-	initializeCurrentUser()
+// SelectToken returns the token of kind for service, see SelectToken for the rules of services
+func (ugi *UserGroupInformation) SelectToken(kind string, service string) (*hadoop_common.TokenProto, bool) {
+	return SelectToken(ugi.GetUserTokens(), kind, service)
+}
 
-	return currentUserGroupInformation
+// AddCredentials adds the tokens and secret keys of credentials to user, aliases of tokens are not kept since
+// tokens are keyed by kind and service
+func (ugi *UserGroupInformation) AddCredentials(credentials *Credentials) {
+	for _, token := range credentials.GetAllTokens() {
+		ugi.AddUserToken(token)
+	}
+	ugi.rwMutex.Lock()
+	defer ugi.rwMutex.Unlock()
+	for alias, key := range credentials.secretKeys {
+		ugi.secretKeys[alias] = key
+	}
+}
+
+// GetCredentials returns the tokens and secret keys of user, which can be saved by WriteTokenStorageFile.
+// Tokens are aliased by service like hadoop, or by kind/service if several kinds of tokens share a service.
+func (ugi *UserGroupInformation) GetCredentials() *Credentials {
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	keys := make([]tokenKey, 0, len(ugi.tokens))
+	for key := range ugi.tokens {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].service != keys[j].service {
+			return keys[i].service < keys[j].service
+		}
+		return keys[i].kind < keys[j].kind
+	})
+
+	credentials := NewCredentials()
+	for _, key := range keys {
+		alias := key.service
+		if credentials.GetToken(alias) != nil {
+			alias = key.kind + "/" + key.service
+		}
+		credentials.AddToken(alias, ugi.tokens[key])
+	}
+	for alias, key := range ugi.secretKeys {
+		credentials.AddSecretKey(alias, key)
+	}
+	return credentials
+}
+
+// GetSecretKey returns the secret key of alias
+func (ugi *UserGroupInformation) GetSecretKey(alias string) []byte {
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	return ugi.secretKeys[alias]
+}
+
+// RemoveUserToken removes the token of kind for service
+func (ugi *UserGroupInformation) RemoveUserToken(kind string, service string) {
+	ugi.rwMutex.Lock()
+	defer ugi.rwMutex.Unlock()
+	delete(ugi.tokens, tokenKey{kind: kind, service: service})
+}
+
+// GetKerberosCredential returns the credential of the authenticating user, which is the real user of a proxy user
+func (ugi *UserGroupInformation) GetKerberosCredential() KerberosCredential {
+	if ugi.realUser != nil {
+		return ugi.realUser.GetKerberosCredential()
+	}
+	ugi.rwMutex.RLock()
+	defer ugi.rwMutex.RUnlock()
+	return ugi.kerberos
+}
+
+func (ugi *UserGroupInformation) SetKerberosCredential(credential KerberosCredential) {
+	ugi.rwMutex.Lock()
+	defer ugi.rwMutex.Unlock()
+	ugi.kerberos = credential
 }
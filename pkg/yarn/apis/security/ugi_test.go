@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+func TestUserGroupInformation(t *testing.T) {
+	tests := []struct {
+		name         string
+		ugi          *UserGroupInformation
+		wantUserName string
+		wantUserInfo *hadoop_common.UserInformationProto
+	}{
+		{
+			name:         "remote user",
+			ugi:          NewRemoteUser("yarn"),
+			wantUserName: "yarn",
+			wantUserInfo: &hadoop_common.UserInformationProto{EffectiveUser: proto.String("yarn")},
+		},
+		{
+			name:         "kerberos user",
+			ugi:          NewKerberosUser(&fakeKDC{}),
+			wantUserName: "yarn@" + testRealm,
+			wantUserInfo: &hadoop_common.UserInformationProto{EffectiveUser: proto.String("yarn@" + testRealm)},
+		},
+		{
+			name:         "proxy user",
+			ugi:          NewProxyUser("alice", NewKerberosUser(&fakeKDC{})),
+			wantUserName: "alice",
+			wantUserInfo: &hadoop_common.UserInformationProto{EffectiveUser: proto.String("alice"), RealUser: proto.String("yarn@" + testRealm)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantUserName, tt.ugi.UserName())
+			assert.True(t, proto.Equal(tt.wantUserInfo, tt.ugi.GetUserInformation()), tt.ugi.GetUserInformation())
+			// a proxy user authenticates with the credential of real user
+			assert.Equal(t, tt.ugi.AuthenticatingUser().GetKerberosCredential(), tt.ugi.GetKerberosCredential())
+
+			tt.ugi.AddUserToken(&hadoop_common.TokenProto{Kind: proto.String(TokenKindRMDelegation), Service: proto.String("rm:8032")})
+			withoutTokens := tt.ugi.WithoutTokens()
+			assert.Equal(t, tt.wantUserName, withoutTokens.UserName())
+			assert.True(t, proto.Equal(tt.wantUserInfo, withoutTokens.GetUserInformation()))
+			assert.Equal(t, tt.ugi.GetKerberosCredential(), withoutTokens.GetKerberosCredential())
+			assert.Len(t, withoutTokens.GetUserTokens(), 0)
+		})
+	}
+}
+
+func TestUserTokens(t *testing.T) {
+	ugi := NewRemoteUser("yarn")
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			service := fmt.Sprintf("rm%d:8032", i)
+			ugi.AddUserToken(&hadoop_common.TokenProto{Kind: proto.String("RM_DELEGATION_TOKEN"), Service: proto.String(service)})
+			ugi.AddUserToken(&hadoop_common.TokenProto{Kind: proto.String("YARN_AM_RM_TOKEN"), Service: proto.String(service)})
+		}(i)
+	}
+	wg.Wait()
+	assert.Len(t, ugi.GetUserTokens(), 64)
+
+	token, found := ugi.GetUserToken("YARN_AM_RM_TOKEN", "rm3:8032")
+	assert.True(t, found)
+	assert.Equal(t, "YARN_AM_RM_TOKEN", token.GetKind())
+
+	ugi.AddUserToken(&hadoop_common.TokenProto{Kind: proto.String("YARN_AM_RM_TOKEN"), Service: proto.String("rm3:8032"), Identifier: []byte("new")})
+	token, _ = ugi.GetUserToken("YARN_AM_RM_TOKEN", "rm3:8032")
+	assert.Equal(t, []byte("new"), token.GetIdentifier())
+	assert.Len(t, ugi.GetUserTokens(), 64)
+
+	ugi.RemoveUserToken("YARN_AM_RM_TOKEN", "rm3:8032")
+	_, found = ugi.GetUserToken("YARN_AM_RM_TOKEN", "rm3:8032")
+	assert.False(t, found)
+}
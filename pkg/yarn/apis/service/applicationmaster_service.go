@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarn_conf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+func init() {
+	security.RegisterProtocolTokenKind(APPLICATION_MASTER_PROTOCOL, security.TokenKindAMRM)
+}
+
+func DialApplicationMasterProtocolService(conf yarn_conf.YarnConfiguration, rmSchedulerAddress *string, ugi *security.UserGroupInformation) (ApplicationMasterProtocolService, error) {
+	var serverAddress string
+	var err error
+	if rmSchedulerAddress != nil {
+		serverAddress = *rmSchedulerAddress
+	} else if serverAddress, err = conf.GetRMSchedulerAddress(); err != nil {
+		return nil, err
+	}
+
+	c, err := newIPCClient(conf, serverAddress, ugi)
+	if err != nil {
+		return nil, err
+	}
+	return &ApplicationMasterProtocolServiceClient{c}, nil
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarn_conf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+func init() {
+	security.RegisterProtocolTokenKind(CONTAINER_MANAGEMENT_PROTOCOL, security.TokenKindNM)
+}
+
+func DialContainerManagementProtocolService(conf yarn_conf.YarnConfiguration, nmAddress string, ugi *security.UserGroupInformation) (ContainerManagementProtocolService, error) {
+	c, err := newIPCClient(conf, nmAddress, ugi)
+	if err != nil {
+		return nil, err
+	}
+	return &ContainerManagementProtocolServiceClient{c}, nil
+}
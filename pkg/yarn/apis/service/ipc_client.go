@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	uuid "github.com/nu7hatch/gouuid"
+
+	gohadoop "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarn_conf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// newIPCClient creates an ipc client of ugi for serverAddress, timeouts and security settings are read from conf
+// if it is not nil, otherwise the defaults of ipc client are used. The current user is used if ugi is nil.
+func newIPCClient(conf yarn_conf.YarnConfiguration, serverAddress string, ugi *security.UserGroupInformation) (*hadoop_ipc_client.Client, error) {
+	clientId, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+	c := &hadoop_ipc_client.Client{ClientId: clientId, Ugi: ugi, ServerAddress: serverAddress}
+	if conf == nil {
+		return c, nil
+	}
+
+	if c.ConnectTimeout, err = conf.GetTimeDuration(yarn_conf.IPC_CLIENT_CONNECT_TIMEOUT, 0, time.Millisecond); err != nil {
+		return nil, err
+	}
+	if c.RPCTimeout, err = conf.GetTimeDuration(yarn_conf.IPC_CLIENT_RPC_TIMEOUT_MS, 0, time.Millisecond); err != nil {
+		return nil, err
+	}
+
+	// tokens of ha rms may use the cluster id as a logical service
+	haEnabled, err := conf.GetRMEnabledHA()
+	if err != nil {
+		return nil, err
+	}
+	if haEnabled {
+		if c.TokenService, err = conf.Get(yarn_conf.RM_CLUSTER_ID, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	authentication, err := conf.GetEnum(yarn_conf.HADOOP_SECURITY_AUTHENTICATION, yarn_conf.AUTHENTICATION_SIMPLE,
+		yarn_conf.AUTHENTICATION_SIMPLE, yarn_conf.AUTHENTICATION_KERBEROS)
+	if err != nil {
+		return nil, err
+	}
+	switch authentication {
+	case yarn_conf.AUTHENTICATION_SIMPLE:
+	case yarn_conf.AUTHENTICATION_KERBEROS:
+		// all protocols served by rm use the principal of rm
+		if c.ServerPrincipal, err = conf.Get(yarn_conf.RM_PRINCIPAL, ""); err != nil {
+			return nil, err
+		}
+		c.AuthMethod = gohadoop.AUTH_KERBEROS
+	default:
+		return nil, fmt.Errorf("unsupported %v %v", yarn_conf.HADOOP_SECURITY_AUTHENTICATION, authentication)
+	}
+
+	protection, err := conf.Get(yarn_conf.HADOOP_RPC_PROTECTION, "authentication")
+	if err != nil {
+		return nil, err
+	}
+	if c.Protection, err = security.ParseRPCProtection(protection); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -17,57 +17,22 @@ limitations under the License.
 package service
 
 import (
-	"encoding/json"
-	"math"
-
-	uuid "github.com/nu7hatch/gouuid"
-	"google.golang.org/protobuf/proto"
-
-	gohadoop "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
-	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
-	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	yarn_conf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
 
-// Reference proto, json, and math imports to suppress error if they are not otherwise used.
-var _ = proto.Marshal
-var _ = &json.SyntaxError{}
-var _ = math.Inf
-
-var RESOURCE_MANAGER_ADMIN_PROTOCOL = "org.apache.hadoop.yarn.server.api.ResourceManagerAdministrationProtocolPB"
-
-func init() {
-}
-
-type ResourceManagerAdministrationProtocolService interface {
-	UpdateNodeResource(in *yarnserver.UpdateNodeResourceRequestProto, out *yarnserver.UpdateNodeResourceResponseProto) error
-}
-
-type ResourceManagerAdministrationProtocolServiceClient struct {
-	*hadoop_ipc_client.Client
-}
-
-func (c *ResourceManagerAdministrationProtocolServiceClient) UpdateNodeResource(in *yarnserver.UpdateNodeResourceRequestProto, out *yarnserver.UpdateNodeResourceResponseProto) error {
-	return c.Call(gohadoop.GetCalleeRPCRequestHeaderProto(&RESOURCE_MANAGER_ADMIN_PROTOCOL), in, out)
-}
-
-func DialResourceManagerAdministrationProtocolService(conf yarn_conf.YarnConfiguration, rmAddress *string) (ResourceManagerAdministrationProtocolService, error) {
-	clientId, err := uuid.NewV4()
-	if err != nil {
-		return nil, err
-	}
-	ugi, err := gohadoop.CreateSimpleUGIProto()
-	if err != nil {
-		return nil, err
-	}
-
+func DialResourceManagerAdministrationProtocolService(conf yarn_conf.YarnConfiguration, rmAddress *string, ugi *security.UserGroupInformation) (ResourceManagerAdministrationProtocolService, error) {
 	var serverAddress string
+	var err error
 	if rmAddress != nil {
 		serverAddress = *rmAddress
 	} else if serverAddress, err = conf.GetRMAdminAddress(); err != nil {
 		return nil, err
 	}
 
-	c := &hadoop_ipc_client.Client{ClientId: clientId, Ugi: ugi, ServerAddress: serverAddress}
+	c, err := newIPCClient(conf, serverAddress, ugi)
+	if err != nil {
+		return nil, err
+	}
 	return &ResourceManagerAdministrationProtocolServiceClient{c}, nil
 }
@@ -35,6 +35,7 @@ const (
 
 // YARN RM only supports get all nodes from cluster, sync to cache for efficiency
 type NodesSyncer struct {
+	clientsMtx  sync.RWMutex
 	yarnClients map[string]yarnclient.YarnClient
 	started     atomic.Bool
 
@@ -51,6 +52,13 @@ func NewNodesSyncer(yarnClients map[string]yarnclient.YarnClient) *NodesSyncer {
 	}
 }
 
+// SetYarnClients replaces the clients of clusters to sync, which is a subscriber of ReloadingYarnClientFactory
+func (r *NodesSyncer) SetYarnClients(yarnClients map[string]yarnclient.YarnClient) {
+	r.clientsMtx.Lock()
+	defer r.clientsMtx.Unlock()
+	r.yarnClients = yarnClients
+}
+
 func (r *NodesSyncer) GetNodeResource(yarnNode *YarnNode) (*hadoopyarn.NodeReportProto, bool) {
 	if yarnNode == nil {
 		return nil, false
@@ -77,7 +85,7 @@ func (r *NodesSyncer) Start(ctx context.Context) error {
 		for {
 			select {
 			case <-t.C:
-				if err := r.syncYARNNodeAllocatedResource(); err != nil {
+				if err := r.syncYARNNodeAllocatedResource(ctx); err != nil {
 					klog.Errorf("sync yarn node allocated resource failed, error: %v", err)
 				} else {
 					r.started.Store(true)
@@ -124,14 +132,19 @@ func (r *NodesSyncer) GetYarnNodeInfo() map[string][]*hadoopyarn.NodeReportProto
 	return res
 }
 
-func (r *NodesSyncer) syncYARNNodeAllocatedResource() error {
+func (r *NodesSyncer) syncYARNNodeAllocatedResource(ctx context.Context) error {
 	req := hadoopyarn.GetClusterNodesRequestProto{NodeStates: []hadoopyarn.NodeStateProto{hadoopyarn.NodeStateProto_NS_RUNNING}}
 	res := map[string]map[string]*hadoopyarn.NodeReportProto{}
-	for id, yarnClient := range r.yarnClients {
-		nodes, err := yarnClient.GetClusterNodes(&req)
-		if err != nil {
+	r.clientsMtx.RLock()
+	yarnClients := r.yarnClients
+	r.clientsMtx.RUnlock()
+	for id, yarnClient := range yarnClients {
+		nodes, err := yarnClient.GetClusterNodesWithContext(ctx, &req)
+		if err != nil && yarnclient.NeedReinitialize(err) {
 			initErr := yarnClient.Reinitialize()
 			return fmt.Errorf("GetClusterNodes error %v, reinitialize error %v", err, initErr)
+		} else if err != nil {
+			return fmt.Errorf("GetClusterNodes of cluster %v error %w", id, err)
 		}
 		if nodes == nil {
 			continue
@@ -0,0 +1,277 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/zk"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+const (
+	activeStandbyElectorLock = "ActiveStandbyElectorLock"
+
+	zkResolverMinBackoff = 100 * time.Millisecond
+	zkResolverMaxBackoff = 10 * time.Second
+)
+
+// activeRMResolver finds the active rm of an ha cluster
+type activeRMResolver interface {
+	ActiveRMID(ctx context.Context) (string, error)
+	Close()
+}
+
+// activeRMChangeFunc is called with the new active rm once a resolver learns about a failover by itself
+type activeRMChangeFunc func(resolver activeRMResolver, rmID string)
+
+// newActiveRMResolver creates the resolver selected by yarn-copilot.client.active-rm-resolver
+func newActiveRMResolver(conf yarnconf.YarnConfiguration, ugi *security.UserGroupInformation, onChange activeRMChangeFunc) (activeRMResolver, error) {
+	resolver, err := conf.GetClientActiveRMResolver()
+	if err != nil {
+		return nil, err
+	}
+	switch resolver {
+	case yarnconf.ACTIVE_RM_RESOLVER_HA_STATUS:
+		rmIDs, err := conf.GetRMs()
+		if err != nil {
+			return nil, err
+		}
+		return &haStatusActiveRMResolver{conf: conf, rmIDs: rmIDs, ugi: ugi}, nil
+	case yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER:
+		return newZKActiveRMResolver(conf, onChange)
+	}
+	return nil, fmt.Errorf("unknown %v %q, expecting %v or %v", yarnconf.CLIENT_ACTIVE_RM_RESOLVER, resolver,
+		yarnconf.ACTIVE_RM_RESOLVER_HA_STATUS, yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER)
+}
+
+// haStatusActiveRMResolver asks the ha service status of rms in turn, an rm down costs a connect timeout
+type haStatusActiveRMResolver struct {
+	conf  yarnconf.YarnConfiguration
+	rmIDs []string
+	ugi   *security.UserGroupInformation
+}
+
+func (r *haStatusActiveRMResolver) ActiveRMID(ctx context.Context) (string, error) {
+	return getActiveRMID(ctx, r.conf, r.rmIDs, r.ugi)
+}
+
+func (r *haStatusActiveRMResolver) Close() {}
+
+func getActiveRMID(ctx context.Context, conf yarnconf.YarnConfiguration, rmIDs []string, ugi *security.UserGroupInformation) (string, error) {
+	for _, rmID := range rmIDs {
+		rmAdminAddr, err := conf.GetRMAdminAddressByID(rmID)
+		if err != nil {
+			return "", err
+		}
+		haClient, err := CreateYarnHAClient(conf, rmAdminAddr, ugi)
+		if err != nil {
+			return "", fmt.Errorf("create yarn %v ha client for %v failed %v", rmID, rmAdminAddr, err)
+		}
+		resp, err := haClient.GetServiceStatusWithContext(ctx, &hadoopcommon.GetServiceStatusRequestProto{})
+		if err != nil && ctx.Err() != nil {
+			return "", err
+		} else if err != nil {
+			klog.V(4).Infof("get %v service status for %v failed %v, try next rm", rmID, rmAdminAddr, err)
+			continue
+		}
+		if resp.State != nil && *resp.State == hadoopcommon.HAServiceStateProto_ACTIVE {
+			return rmID, nil
+		}
+	}
+	return "", fmt.Errorf("active rm not found in %v", rmIDs)
+}
+
+// zkActiveRMResolver follows the lock znode of ActiveStandbyElector, which is held by the active rm with
+// ActiveRMInfoProto as data. The znode is watched, so that failovers are known once rm wins the election.
+type zkActiveRMResolver struct {
+	connectString  string
+	lockPath       string
+	sessionTimeout time.Duration
+	onChange       activeRMChangeFunc
+	cancel         context.CancelFunc
+
+	mtx sync.Mutex
+	// activeRMID is empty if the lock is not held, e.g. during a failover
+	activeRMID string
+	// err is the error of the last read, which is returned if the lock has never been read
+	err error
+	// synced is closed once the lock is read or failed to be read for the first time
+	synced     chan struct{}
+	syncedOnce sync.Once
+}
+
+func newZKActiveRMResolver(conf yarnconf.YarnConfiguration, onChange activeRMChangeFunc) (*zkActiveRMResolver, error) {
+	connectString, err := conf.GetZKAddress()
+	if err != nil {
+		return nil, err
+	}
+	if connectString == "" {
+		return nil, fmt.Errorf("%v is required by active rm resolver %v", yarnconf.ZK_ADDRESS, yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER)
+	}
+	clusterID, err := conf.GetRMClusterID()
+	if err != nil {
+		return nil, err
+	}
+	if clusterID == "" {
+		return nil, fmt.Errorf("%v is required by active rm resolver %v", yarnconf.RM_CLUSTER_ID, yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER)
+	}
+	basePath, err := conf.GetRMAutoFailoverZKBasePath()
+	if err != nil {
+		return nil, err
+	}
+	timeoutMS, err := conf.GetZKTimeoutMS()
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &zkActiveRMResolver{
+		connectString:  connectString,
+		lockPath:       path.Join(basePath, clusterID, activeStandbyElectorLock),
+		sessionTimeout: time.Duration(timeoutMS) * time.Millisecond,
+		onChange:       onChange,
+		cancel:         cancel,
+		synced:         make(chan struct{}),
+	}
+	go r.run(ctx)
+	return r, nil
+}
+
+// ActiveRMID waits for the lock to be read for the first time, and returns the rm holding it
+func (r *zkActiveRMResolver) ActiveRMID(ctx context.Context) (string, error) {
+	select {
+	case <-r.synced:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.activeRMID != "" {
+		return r.activeRMID, nil
+	}
+	if r.err != nil {
+		return "", fmt.Errorf("read active rm from zookeeper %v failed, error %w", r.connectString, r.err)
+	}
+	return "", fmt.Errorf("active rm not found, %v is not held in zookeeper %v", r.lockPath, r.connectString)
+}
+
+func (r *zkActiveRMResolver) Close() {
+	r.cancel()
+}
+
+// run watches the lock until the resolver is closed, sessions lost are created again with backoff
+func (r *zkActiveRMResolver) run(ctx context.Context) {
+	backoff := zkResolverMinBackoff
+	for {
+		watched, err := r.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		klog.V(4).Infof("watch active rm in zookeeper %v stopped, error %v", r.connectString, err)
+		r.update(nil, err)
+		if watched {
+			backoff = zkResolverMinBackoff
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > zkResolverMaxBackoff {
+			backoff = zkResolverMaxBackoff
+		}
+	}
+}
+
+// watch reads the lock again on every change of it, and returns once the session is lost. watched is whether the
+// lock has been read in the session.
+func (r *zkActiveRMResolver) watch(ctx context.Context) (watched bool, err error) {
+	conn, err := zk.Dial(ctx, r.connectString, r.sessionTimeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	for {
+		exists, _, watch, err := conn.ExistsW(ctx, r.lockPath)
+		if err != nil {
+			return watched, err
+		}
+		rmID := ""
+		if exists {
+			data, _, err := conn.Get(ctx, r.lockPath)
+			if err == zk.ErrNoNode {
+				// deleted after exists, the watch has fired
+			} else if err != nil {
+				return watched, err
+			} else if rmID, err = parseActiveRMInfo(data); err != nil {
+				klog.Warningf("invalid data of %v in zookeeper %v, error %v", r.lockPath, r.connectString, err)
+			}
+		}
+		watched = true
+		r.update(&rmID, nil)
+
+		select {
+		case event := <-watch:
+			if event.Type == zk.EventNotWatching {
+				return watched, event.Err
+			}
+			klog.V(5).Infof("%v of %v in zookeeper %v", event.Type, r.lockPath, r.connectString)
+		case <-ctx.Done():
+			return watched, ctx.Err()
+		}
+	}
+}
+
+// update records the result of a read, rmID is nil if the read failed, in which case the last active rm is kept
+func (r *zkActiveRMResolver) update(rmID *string, err error) {
+	r.mtx.Lock()
+	changed := rmID != nil && *rmID != r.activeRMID
+	if rmID != nil {
+		r.activeRMID = *rmID
+	}
+	r.err = err
+	r.mtx.Unlock()
+	r.syncedOnce.Do(func() { close(r.synced) })
+
+	if changed && *rmID != "" {
+		klog.V(3).Infof("active rm is %v in zookeeper %v", *rmID, r.connectString)
+		if r.onChange != nil {
+			r.onChange(r, *rmID)
+		}
+	}
+}
+
+func parseActiveRMInfo(data []byte) (string, error) {
+	info := &yarnserver.ActiveRMInfoProto{}
+	if err := proto.Unmarshal(data, info); err != nil {
+		return "", err
+	}
+	if info.GetRmId() == "" {
+		return "", fmt.Errorf("rm id is empty")
+	}
+	return info.GetRmId(), nil
+}
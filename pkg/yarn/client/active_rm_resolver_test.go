@@ -0,0 +1,172 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakerm"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakezk"
+)
+
+const testLockPath = "/yarn-leader-election/yarn-cluster/ActiveStandbyElectorLock"
+
+func setTestActiveRM(t *testing.T, server *fakezk.Server, rmID string) {
+	data, err := proto.Marshal(&yarnserver.ActiveRMInfoProto{ClusterId: proto.String("yarn-cluster"), RmId: proto.String(rmID)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Set(testLockPath, data)
+}
+
+func activeRMIDOf(c *yarnClient) string {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.rmIDs[c.activeRMIndex]
+}
+
+func TestZKActiveRMResolverFailover(t *testing.T) {
+	zkServer, err := fakezk.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zkServer.Close()
+	rms := map[string]*fakerm.ResourceManager{}
+	for _, rmID := range []string{"rm1", "rm2"} {
+		rm, err := fakerm.NewResourceManager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rm.Close()
+		rms[rmID] = rm
+	}
+	rms["rm1"].SetHAState(hadoopcommon.HAServiceStateProto_STANDBY)
+	setTestActiveRM(t, zkServer, "rm2")
+
+	// rm0 never responds, which costs a connect timeout of every probe of ha status
+	properties := fakerm.HAYarnSite(rms)
+	blackhole := newBlackholeServer(t)
+	properties[yarnconf.RM_HA_RM_IDS] = "rm0,rm1,rm2"
+	properties[yarnconf.RM_ADDRESS+".rm0"] = blackhole
+	properties[yarnconf.RM_ADMIN_ADDRESS+".rm0"] = blackhole
+	properties[yarnconf.RM_CLUSTER_ID] = "yarn-cluster"
+	properties[yarnconf.ZK_ADDRESS] = zkServer.Address()
+	properties[yarnconf.CLIENT_ACTIVE_RM_RESOLVER] = yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER
+	c := NewYarnClient(writeYarnSite(t, properties), "", WithRetryPolicy(NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm2"].CallCount("getClusterNodes"))
+	assert.Equal(t, 0, rms["rm1"].CallCount("getServiceStatus")+rms["rm2"].CallCount("getServiceStatus"))
+
+	// the client switches once the lock is taken by the new active rm, without calling the old one
+	rms["rm2"].SetHAState(hadoopcommon.HAServiceStateProto_STANDBY)
+	rms["rm1"].SetHAState(hadoopcommon.HAServiceStateProto_ACTIVE)
+	setTestActiveRM(t, zkServer, "rm1")
+	assert.Eventually(t, func() bool { return activeRMIDOf(c.(*yarnClient)) == "rm1" }, 5*time.Second, 10*time.Millisecond)
+	_, err = c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm1"].CallCount("getClusterNodes"))
+	assert.Equal(t, 1, rms["rm2"].CallCount("getClusterNodes"))
+	activeRMID, err := c.(*yarnClient).GetActiveRMID()
+	assert.NoError(t, err)
+	assert.Equal(t, "rm1", activeRMID)
+
+	// the lock is watched again once the session is lost
+	zkServer.ExpireSessions()
+	assert.Eventually(t, func() bool { return zkServer.SessionCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	setTestActiveRM(t, zkServer, "rm2")
+	assert.Eventually(t, func() bool { return activeRMIDOf(c.(*yarnClient)) == "rm2" }, 5*time.Second, 10*time.Millisecond)
+
+	// the resolver stops with the client
+	c.Close()
+	assert.Eventually(t, func() bool { return zkServer.SessionCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestZKActiveRMResolverLockNotHeld(t *testing.T) {
+	zkServer, err := fakezk.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zkServer.Close()
+	conf, err := yarnconf.NewYarnConfiguration(writeYarnSite(t, map[string]string{
+		yarnconf.RM_CLUSTER_ID:             "yarn-cluster",
+		yarnconf.RM_ZK_ADDRESS:             zkServer.Address(),
+		yarnconf.CLIENT_ACTIVE_RM_RESOLVER: yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER,
+	}), "")
+	assert.NoError(t, err)
+	changes := make(chan string, 1)
+	resolver, err := newActiveRMResolver(conf, nil, func(_ activeRMResolver, rmID string) { changes <- rmID })
+	assert.NoError(t, err)
+	defer resolver.Close()
+
+	_, err = resolver.ActiveRMID(context.Background())
+	assert.ErrorContains(t, err, testLockPath+" is not held")
+	setTestActiveRM(t, zkServer, "rm1")
+	select {
+	case rmID := <-changes:
+		assert.Equal(t, "rm1", rmID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change of active rm is not notified")
+	}
+}
+
+func TestNewActiveRMResolver(t *testing.T) {
+	tests := map[string]struct {
+		properties map[string]string
+		err        string
+	}{
+		"ha status by default": {properties: map[string]string{}},
+		"unknown resolver": {
+			properties: map[string]string{yarnconf.CLIENT_ACTIVE_RM_RESOLVER: "dns"},
+			err:        `invalid value "dns" of yarn-copilot.client.active-rm-resolver from yarn-site.xml`,
+		},
+		"zookeeper without address": {
+			properties: map[string]string{yarnconf.CLIENT_ACTIVE_RM_RESOLVER: yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER, yarnconf.RM_CLUSTER_ID: "c"},
+			err:        "hadoop.zk.address is required",
+		},
+		"zookeeper without cluster id": {
+			properties: map[string]string{yarnconf.CLIENT_ACTIVE_RM_RESOLVER: yarnconf.ACTIVE_RM_RESOLVER_ZOOKEEPER, yarnconf.ZK_ADDRESS: "zk:2181"},
+			err:        "yarn.resourcemanager.cluster-id is required",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf, err := yarnconf.NewYarnConfiguration(writeYarnSite(t, tt.properties), "")
+			assert.NoError(t, err)
+			resolver, err := newActiveRMResolver(conf, nil, nil)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.IsType(t, &haStatusActiveRMResolver{}, resolver)
+		})
+	}
+}
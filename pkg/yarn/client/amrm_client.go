@@ -0,0 +1,610 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+const (
+	defaultHeartbeatInterval = time.Second
+	// unregisterRetryInterval is the interval of finish calls until rm confirms the am is unregistered
+	unregisterRetryInterval = 100 * time.Millisecond
+)
+
+var errAMShutdown = errors.New("rm asks the application master to shut down")
+
+// AMRMCallbackHandler handles the events of allocate heartbeats. It is called in the heartbeat goroutine in order,
+// so it must not wait for Stop or Unregister of the client.
+type AMRMCallbackHandler interface {
+	OnNodesUpdated(reports []*hadoopyarn.NodeReportProto)
+	OnContainersCompleted(statuses []*hadoopyarn.ContainerStatusProto)
+	OnContainersAllocated(containers []*hadoopyarn.ContainerProto)
+	OnContainersUpdated(containers []*hadoopyarn.UpdatedContainerProto)
+	// OnPreemption is called when rm is going to preempt containers of the application, which is the chance of
+	// the am to release containers of its own choice
+	OnPreemption(message *hadoopyarn.PreemptionMessageProto)
+	// OnShutdownRequest is called when rm asks the am to shut down, heartbeats are stopped
+	OnShutdownRequest()
+	// OnError is called when a heartbeat fails after retries, heartbeats are stopped
+	OnError(err error)
+}
+
+type AMRMClientOption func(c *AMRMClient)
+
+// WithHeartbeatInterval sets the interval of allocate heartbeats, which is 1s by default
+func WithHeartbeatInterval(interval time.Duration) AMRMClientOption {
+	return func(c *AMRMClient) {
+		c.heartbeatInterval = interval
+	}
+}
+
+// WithNMTokenCache makes the client save nm tokens into cache, e.g. the cache shared with node manager clients
+func WithNMTokenCache(cache *NMTokenCache) AMRMClientOption {
+	return func(c *AMRMClient) {
+		c.nmTokens = cache
+	}
+}
+
+// resourceRequestKey identifies a resource request in the ask table, requests of the same key are merged
+type resourceRequestKey struct {
+	priority            int32
+	resourceName        string
+	allocationRequestID int64
+	executionType       hadoopyarn.ExecutionTypeProto
+	capability          string
+}
+
+func newResourceRequestKey(request *hadoopyarn.ResourceRequestProto) resourceRequestKey {
+	return resourceRequestKey{
+		priority:            request.GetPriority().GetPriority(),
+		resourceName:        request.GetResourceName(),
+		allocationRequestID: request.GetAllocationRequestId(),
+		executionType:       request.GetExecutionTypeRequest().GetExecutionType(),
+		capability:          protoKey(request.GetCapability()),
+	}
+}
+
+func protoKey(m proto.Message) string {
+	b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	return string(b)
+}
+
+// AMRMClient talks to the scheduler of rm for an application master like AMRMClientAsync in hadoop. It registers
+// the am, keeps the table of outstanding resource requests, sends allocate heartbeats in the background, and reports
+// the responses to a callback handler. The am authenticates with the YARN_AM_RM_TOKEN in the credentials of user,
+// which are loaded from $HADOOP_TOKEN_FILE_LOCATION for the current user.
+type AMRMClient struct {
+	conf               yarnconf.YarnConfiguration
+	clusterID          string
+	ugi                *security.UserGroupInformation
+	handler            AMRMCallbackHandler
+	heartbeatInterval  time.Duration
+	nmTokens           *NMTokenCache
+	retryPolicy        RetryPolicy
+	schedulerAddresses []string
+	amrmTokenService   string
+
+	// mtx protects the following fields
+	mtx                sync.Mutex
+	activeIndex        int
+	activeClient       *YarnApplicationMasterClient
+	registerRequest    *hadoopyarn.RegisterApplicationMasterRequestProto
+	responseID         int32
+	progress           float32
+	availableResources *hadoopyarn.ResourceProto
+	clusterNodeCount   int32
+	asks               map[resourceRequestKey]*hadoopyarn.ResourceRequestProto
+	changedAsks        map[resourceRequestKey]struct{}
+	// pendingReleases are released containers not completed yet, which are sent again after resync
+	pendingReleases    map[string]*hadoopyarn.ContainerIdProto
+	releases           map[string]*hadoopyarn.ContainerIdProto
+	blacklistedNodes   map[string]struct{}
+	blacklistAdditions map[string]struct{}
+	blacklistRemovals  map[string]struct{}
+	updates            []*hadoopyarn.UpdateContainerRequestProto
+	cancel             context.CancelFunc
+	done               chan struct{}
+}
+
+// NewAMRMClient creates an am client of cluster talking to the schedulers of rms as ugi, or the current user if ugi
+// is nil. Rms are failed over in the order of yarn.resourcemanager.ha.rm-ids if ha is enabled.
+func NewAMRMClient(confDir string, clusterID string, ugi *security.UserGroupInformation, handler AMRMCallbackHandler,
+	opts ...AMRMClientOption) (*AMRMClient, error) {
+	conf, err := yarnconf.NewYarnConfiguration(confDir, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if ugi == nil {
+		ugi = security.GetCurrentUser()
+	}
+	c := &AMRMClient{
+		conf:               conf,
+		clusterID:          clusterID,
+		ugi:                ugi,
+		handler:            handler,
+		heartbeatInterval:  defaultHeartbeatInterval,
+		asks:               map[resourceRequestKey]*hadoopyarn.ResourceRequestProto{},
+		changedAsks:        map[resourceRequestKey]struct{}{},
+		pendingReleases:    map[string]*hadoopyarn.ContainerIdProto{},
+		releases:           map[string]*hadoopyarn.ContainerIdProto{},
+		blacklistedNodes:   map[string]struct{}{},
+		blacklistAdditions: map[string]struct{}{},
+		blacklistRemovals:  map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.nmTokens == nil {
+		c.nmTokens = NewNMTokenCache()
+	}
+	if c.retryPolicy, err = NewRetryPolicyFromConf(conf); err != nil {
+		return nil, err
+	}
+
+	haEnabled, err := conf.GetRMEnabledHA()
+	if err != nil {
+		return nil, err
+	}
+	if !haEnabled {
+		address, err := conf.GetRMSchedulerAddress()
+		if err != nil {
+			return nil, err
+		}
+		c.schedulerAddresses = []string{address}
+	} else {
+		rmIDs, err := conf.GetRMs()
+		if err != nil {
+			return nil, err
+		}
+		for _, rmID := range rmIDs {
+			address, err := conf.GetRMSchedulerAddressByID(rmID)
+			if err != nil {
+				return nil, err
+			}
+			c.schedulerAddresses = append(c.schedulerAddresses, address)
+		}
+	}
+
+	// the service of am rm token is set by clients, which is all schedulers of the cluster as ClientRMProxy does
+	services := make([]string, 0, len(c.schedulerAddresses))
+	for _, address := range c.schedulerAddresses {
+		services = append(services, ipc.BuildTokenService(address))
+	}
+	c.amrmTokenService = strings.Join(services, ",")
+	for _, token := range ugi.GetUserTokens() {
+		if token.GetKind() == security.TokenKindAMRM && token.GetService() != c.amrmTokenService {
+			ugi.RemoveUserToken(token.GetKind(), token.GetService())
+			c.updateAMRMToken(token)
+		}
+	}
+	return c, nil
+}
+
+// NMTokens returns the nm tokens received from rm
+func (c *AMRMClient) NMTokens() *NMTokenCache {
+	return c.nmTokens
+}
+
+// AvailableResources returns the resources available for the application in the last heartbeat
+func (c *AMRMClient) AvailableResources() *hadoopyarn.ResourceProto {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.availableResources
+}
+
+// ClusterNodeCount returns the number of nodes in the cluster in the last heartbeat
+func (c *AMRMClient) ClusterNodeCount() int32 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.clusterNodeCount
+}
+
+// RegisterApplicationMaster registers the am to rm, which must be done before heartbeats
+func (c *AMRMClient) RegisterApplicationMaster(ctx context.Context, request *hadoopyarn.RegisterApplicationMasterRequestProto) (*hadoopyarn.RegisterApplicationMasterResponseProto, error) {
+	c.mtx.Lock()
+	c.registerRequest = request
+	c.mtx.Unlock()
+	return c.register(ctx)
+}
+
+func (c *AMRMClient) register(ctx context.Context) (*hadoopyarn.RegisterApplicationMasterResponseProto, error) {
+	c.mtx.Lock()
+	request := c.registerRequest
+	c.mtx.Unlock()
+	if request == nil {
+		return nil, errors.New("application master is not registered")
+	}
+
+	var response *hadoopyarn.RegisterApplicationMasterResponseProto
+	err := c.invoke(ctx, "RegisterApplicationMaster", func(ctx context.Context, client *YarnApplicationMasterClient) error {
+		var err error
+		response, err = client.RegisterApplicationMasterWithContext(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.nmTokens.addTokens(response.GetNmTokensFromPreviousAttempts())
+	c.mtx.Lock()
+	c.responseID = 0
+	c.mtx.Unlock()
+	return response, nil
+}
+
+// AddContainerRequest adds request to the outstanding requests, the containers of requests of the same priority,
+// resource name, capability, execution type and allocation request id are summed up. The caller makes the requests
+// of nodes, racks and "*" for a container request, the same as AMRMClient in hadoop.
+func (c *AMRMClient) AddContainerRequest(request *hadoopyarn.ResourceRequestProto) {
+	key := newResourceRequestKey(request)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if ask, ok := c.asks[key]; ok {
+		ask.NumContainers = proto.Int32(ask.GetNumContainers() + request.GetNumContainers())
+	} else {
+		c.asks[key] = proto.Clone(request).(*hadoopyarn.ResourceRequestProto)
+	}
+	c.changedAsks[key] = struct{}{}
+}
+
+// RemoveContainerRequest removes the containers of request from the outstanding requests, which should be called
+// for the requests satisfied by allocated containers, or the containers no longer needed
+func (c *AMRMClient) RemoveContainerRequest(request *hadoopyarn.ResourceRequestProto) {
+	key := newResourceRequestKey(request)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	ask, ok := c.asks[key]
+	if !ok {
+		return
+	}
+	numContainers := ask.GetNumContainers() - request.GetNumContainers()
+	if numContainers < 0 {
+		numContainers = 0
+	}
+	ask.NumContainers = proto.Int32(numContainers)
+	c.changedAsks[key] = struct{}{}
+}
+
+// OutstandingRequests returns a copy of the outstanding requests
+func (c *AMRMClient) OutstandingRequests() []*hadoopyarn.ResourceRequestProto {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	requests := make([]*hadoopyarn.ResourceRequestProto, 0, len(c.asks))
+	for _, ask := range c.asks {
+		if ask.GetNumContainers() > 0 {
+			requests = append(requests, proto.Clone(ask).(*hadoopyarn.ResourceRequestProto))
+		}
+	}
+	return requests
+}
+
+// ReleaseAssignedContainer releases a container allocated to the application
+func (c *AMRMClient) ReleaseAssignedContainer(containerID *hadoopyarn.ContainerIdProto) {
+	key := protoKey(containerID)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.pendingReleases[key] = containerID
+	c.releases[key] = containerID
+}
+
+// UpdateBlacklist adds or removes nodes or racks that containers should not be allocated on
+func (c *AMRMClient) UpdateBlacklist(additions, removals []string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for _, node := range additions {
+		c.blacklistedNodes[node] = struct{}{}
+		c.blacklistAdditions[node] = struct{}{}
+		delete(c.blacklistRemovals, node)
+	}
+	for _, node := range removals {
+		delete(c.blacklistedNodes, node)
+		delete(c.blacklistAdditions, node)
+		c.blacklistRemovals[node] = struct{}{}
+	}
+}
+
+// RequestContainerUpdate asks rm to change the resource or execution type of a container, the updated container is
+// reported by OnContainersUpdated
+func (c *AMRMClient) RequestContainerUpdate(request *hadoopyarn.UpdateContainerRequestProto) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.updates = append(c.updates, request)
+}
+
+// SetProgress sets the progress of the application in [0, 1] reported in heartbeats
+func (c *AMRMClient) SetProgress(progress float32) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.progress = progress
+}
+
+// Start starts allocate heartbeats, which run until ctx is done, Stop or Unregister is called, or they fail
+func (c *AMRMClient) Start(ctx context.Context) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.registerRequest == nil {
+		return errors.New("application master is not registered")
+	}
+	if c.done != nil {
+		return errors.New("heartbeats of application master are started already")
+	}
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+	return nil
+}
+
+// Stop stops heartbeats and waits for the running one
+func (c *AMRMClient) Stop() {
+	c.mtx.Lock()
+	cancel, done := c.cancel, c.done
+	c.mtx.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+func (c *AMRMClient) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		err := c.heartbeat(ctx)
+		if ctx.Err() != nil {
+			return
+		} else if errors.Is(err, errAMShutdown) {
+			c.handler.OnShutdownRequest()
+			return
+		} else if err != nil {
+			c.handler.OnError(err)
+			return
+		}
+		timer.Reset(c.heartbeatInterval)
+	}
+}
+
+// heartbeat sends the changes of requests since the last heartbeat to rm, and handles the response
+func (c *AMRMClient) heartbeat(ctx context.Context) error {
+	c.mtx.Lock()
+	request := &hadoopyarn.AllocateRequestProto{ResponseId: proto.Int32(c.responseID), Progress: proto.Float32(c.progress)}
+	sentAsks, sentReleases := c.changedAsks, c.releases
+	sentAdditions, sentRemovals, sentUpdates := c.blacklistAdditions, c.blacklistRemovals, c.updates
+	c.changedAsks, c.releases = map[resourceRequestKey]struct{}{}, map[string]*hadoopyarn.ContainerIdProto{}
+	c.blacklistAdditions, c.blacklistRemovals, c.updates = map[string]struct{}{}, map[string]struct{}{}, nil
+	for key := range sentAsks {
+		request.Ask = append(request.Ask, proto.Clone(c.asks[key]).(*hadoopyarn.ResourceRequestProto))
+	}
+	for _, containerID := range sentReleases {
+		request.Release = append(request.Release, containerID)
+	}
+	if len(sentAdditions) > 0 || len(sentRemovals) > 0 {
+		request.BlacklistRequest = &hadoopyarn.ResourceBlacklistRequestProto{
+			BlacklistAdditions: sortedNodes(sentAdditions), BlacklistRemovals: sortedNodes(sentRemovals)}
+	}
+	request.UpdateRequests = sentUpdates
+	c.mtx.Unlock()
+
+	var response *hadoopyarn.AllocateResponseProto
+	err := c.invoke(ctx, "Allocate", func(ctx context.Context, client *YarnApplicationMasterClient) error {
+		var err error
+		response, err = client.AllocateWithContext(ctx, request)
+		return err
+	})
+	if err != nil {
+		// send the changes again in the next heartbeat, unless they are changed again
+		c.mtx.Lock()
+		for key := range sentAsks {
+			c.changedAsks[key] = struct{}{}
+		}
+		for key, containerID := range sentReleases {
+			c.releases[key] = containerID
+		}
+		for node := range sentAdditions {
+			if _, removed := c.blacklistRemovals[node]; !removed {
+				c.blacklistAdditions[node] = struct{}{}
+			}
+		}
+		for node := range sentRemovals {
+			if _, added := c.blacklistAdditions[node]; !added {
+				c.blacklistRemovals[node] = struct{}{}
+			}
+		}
+		c.updates = append(sentUpdates, c.updates...)
+		c.mtx.Unlock()
+		if ipc.IsException(err, ipc.ApplicationMasterNotRegisteredException) {
+			// rm has restarted or failed over without the state of am
+			return c.resync(ctx)
+		}
+		return err
+	}
+
+	if response.AMCommand != nil {
+		switch response.GetAMCommand() {
+		case hadoopyarn.AMCommandProto_AM_SHUTDOWN:
+			return errAMShutdown
+		case hadoopyarn.AMCommandProto_AM_RESYNC:
+			return c.resync(ctx)
+		}
+	}
+
+	c.mtx.Lock()
+	c.responseID = response.GetResponseId()
+	c.availableResources, c.clusterNodeCount = response.GetLimit(), response.GetNumClusterNodes()
+	for key := range sentAsks {
+		if _, changed := c.changedAsks[key]; !changed && c.asks[key].GetNumContainers() == 0 {
+			delete(c.asks, key)
+		}
+	}
+	for _, status := range response.GetCompletedContainerStatuses() {
+		delete(c.pendingReleases, protoKey(status.GetContainerId()))
+	}
+	c.mtx.Unlock()
+
+	c.nmTokens.addTokens(response.GetNmTokens())
+	if token := response.GetAmRmToken(); token != nil {
+		c.updateAMRMToken(token)
+	}
+	for _, updateErr := range response.GetUpdateErrors() {
+		klog.Warningf("update container %v of yarn cluster %v failed, reason %v",
+			updateErr.GetUpdateRequest().GetContainerId(), c.clusterID, updateErr.GetReason())
+	}
+
+	if len(response.GetUpdatedNodes()) > 0 {
+		c.handler.OnNodesUpdated(response.GetUpdatedNodes())
+	}
+	if len(response.GetCompletedContainerStatuses()) > 0 {
+		c.handler.OnContainersCompleted(response.GetCompletedContainerStatuses())
+	}
+	if len(response.GetAllocatedContainers()) > 0 {
+		c.handler.OnContainersAllocated(response.GetAllocatedContainers())
+	}
+	if len(response.GetUpdatedContainers()) > 0 {
+		c.handler.OnContainersUpdated(response.GetUpdatedContainers())
+	}
+	if response.GetPreempt() != nil {
+		c.handler.OnPreemption(response.GetPreempt())
+	}
+	return nil
+}
+
+func sortedNodes(nodes map[string]struct{}) []string {
+	sorted := make([]string, 0, len(nodes))
+	for node := range nodes {
+		sorted = append(sorted, node)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// resync registers the am again and sends all outstanding requests in the next heartbeat, which is required after
+// rm lost the state of am
+func (c *AMRMClient) resync(ctx context.Context) error {
+	klog.Infof("application master of yarn cluster %v is not registered in rm, register again", c.clusterID)
+	if _, err := c.register(ctx); err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for key := range c.asks {
+		c.changedAsks[key] = struct{}{}
+	}
+	for key, containerID := range c.pendingReleases {
+		c.releases[key] = containerID
+	}
+	for node := range c.blacklistedNodes {
+		c.blacklistAdditions[node] = struct{}{}
+	}
+	return nil
+}
+
+// updateAMRMToken replaces the am rm token of user with the token rolled over by rm
+func (c *AMRMClient) updateAMRMToken(token *hadoopcommon.TokenProto) {
+	token = proto.Clone(token).(*hadoopcommon.TokenProto)
+	token.Service = proto.String(c.amrmTokenService)
+	c.ugi.AddUserToken(token)
+}
+
+// UnregisterApplicationMaster stops heartbeats and unregisters the am with the final status of application
+func (c *AMRMClient) UnregisterApplicationMaster(ctx context.Context, status hadoopyarn.FinalApplicationStatusProto,
+	diagnostics string, trackingURL string) error {
+	c.Stop()
+	request := &hadoopyarn.FinishApplicationMasterRequestProto{FinalApplicationStatus: status.Enum(),
+		Diagnostics: proto.String(diagnostics), TrackingUrl: proto.String(trackingURL)}
+	for {
+		var response *hadoopyarn.FinishApplicationMasterResponseProto
+		err := c.invoke(ctx, "FinishApplicationMaster", func(ctx context.Context, client *YarnApplicationMasterClient) error {
+			var err error
+			response, err = client.FinishApplicationMasterWithContext(ctx, request)
+			return err
+		})
+		if ipc.IsException(err, ipc.ApplicationMasterNotRegisteredException) {
+			if _, err := c.register(ctx); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		// rm unregisters the am asynchronously
+		if response.GetIsUnregistered() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for application master to be unregistered: %w", ctx.Err())
+		case <-time.After(unregisterRetryInterval):
+		}
+	}
+}
+
+// invoke calls fn with the client of active scheduler, all calls of the protocol are idempotent or deduplicated by
+// rm with the response id
+func (c *AMRMClient) invoke(ctx context.Context, method string, fn func(ctx context.Context, client *YarnApplicationMasterClient) error) error {
+	var failover func(fromIndex int)
+	if len(c.schedulerAddresses) > 1 {
+		failover = c.failover
+	}
+	return retryInvoke(ctx, fmt.Sprintf("%v of yarn cluster %v", method, c.clusterID), c.retryPolicy, true,
+		c.activeRM, fn, failover)
+}
+
+func (c *AMRMClient) activeRM() (int, *YarnApplicationMasterClient, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.activeClient == nil {
+		client, err := CreateYarnApplicationMasterClient(c.conf, &c.schedulerAddresses[c.activeIndex], c.ugi)
+		if err != nil {
+			return 0, nil, err
+		}
+		c.activeClient = client
+	}
+	return c.activeIndex, c.activeClient, nil
+}
+
+func (c *AMRMClient) failover(fromIndex int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.activeIndex != fromIndex {
+		return
+	}
+	c.activeIndex = (c.activeIndex + 1) % len(c.schedulerAddresses)
+	c.activeClient = nil
+	klog.V(3).Infof("application master of yarn cluster %v fails over to scheduler %v", c.clusterID,
+		c.schedulerAddresses[c.activeIndex])
+}
@@ -0,0 +1,309 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+// fakeScheduler records the calls of am, and answers allocate calls with the responses queued
+type fakeScheduler struct {
+	addr string
+
+	mtx          sync.Mutex
+	registers    int
+	finishes     int
+	allocates    []*hadoopyarn.AllocateRequestProto
+	responses    []*hadoopyarn.AllocateResponseProto
+	notRegistred bool
+}
+
+func newFakeScheduler(t *testing.T) *fakeScheduler {
+	s := &fakeScheduler{}
+	listener := newFakeRPCServer(t, func(header *hadoop_common.RpcRequestHeaderProto, method string, param []byte) (proto.Message, string) {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		switch method {
+		case "registerApplicationMaster":
+			s.registers++
+			s.notRegistred = false
+			return &hadoopyarn.RegisterApplicationMasterResponseProto{NmTokensFromPreviousAttempts: []*hadoopyarn.NMTokenProto{
+				{NodeId: &hadoopyarn.NodeIdProto{Host: proto.String("node0"), Port: proto.Int32(45454)}, Token: newTestToken(security.TokenKindNM, "node0")},
+			}}, ""
+		case "allocate":
+			if s.notRegistred {
+				return nil, ipc.ApplicationMasterNotRegisteredException
+			}
+			request := &hadoopyarn.AllocateRequestProto{}
+			if err := proto.Unmarshal(param, request); err != nil {
+				return nil, ipc.IllegalArgumentException
+			}
+			s.allocates = append(s.allocates, request)
+			response := &hadoopyarn.AllocateResponseProto{}
+			if len(s.responses) > 0 {
+				response, s.responses = s.responses[0], s.responses[1:]
+			}
+			response.ResponseId = proto.Int32(request.GetResponseId() + 1)
+			return response, ""
+		case "finishApplicationMaster":
+			s.finishes++
+			return &hadoopyarn.FinishApplicationMasterResponseProto{IsUnregistered: proto.Bool(s.finishes > 1)}, ""
+		}
+		return nil, ipc.RpcNoSuchMethodException
+	})
+	s.addr = listener.Addr().String()
+	return s
+}
+
+func (s *fakeScheduler) queue(response *hadoopyarn.AllocateResponseProto) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.responses = append(s.responses, response)
+}
+
+func (s *fakeScheduler) allocateRequests() []*hadoopyarn.AllocateRequestProto {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]*hadoopyarn.AllocateRequestProto{}, s.allocates...)
+}
+
+// waitAllocates waits for n more allocate calls and returns them
+func (s *fakeScheduler) waitAllocates(t *testing.T, n int) []*hadoopyarn.AllocateRequestProto {
+	from := len(s.allocateRequests())
+	assert.Eventually(t, func() bool { return len(s.allocateRequests()) >= from+n }, 5*time.Second, time.Millisecond)
+	return s.allocateRequests()[from : from+n]
+}
+
+type recordingHandler struct {
+	mtx       sync.Mutex
+	allocated []*hadoopyarn.ContainerProto
+	completed []*hadoopyarn.ContainerStatusProto
+	preempted int
+	shutdown  bool
+	err       error
+}
+
+func (h *recordingHandler) OnNodesUpdated(reports []*hadoopyarn.NodeReportProto) {}
+
+func (h *recordingHandler) OnContainersCompleted(statuses []*hadoopyarn.ContainerStatusProto) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.completed = append(h.completed, statuses...)
+}
+
+func (h *recordingHandler) OnContainersAllocated(containers []*hadoopyarn.ContainerProto) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.allocated = append(h.allocated, containers...)
+}
+
+func (h *recordingHandler) OnContainersUpdated(containers []*hadoopyarn.UpdatedContainerProto) {}
+
+func (h *recordingHandler) OnPreemption(message *hadoopyarn.PreemptionMessageProto) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.preempted++
+}
+
+func (h *recordingHandler) OnShutdownRequest() {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.shutdown = true
+}
+
+func (h *recordingHandler) OnError(err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.err = err
+}
+
+func newTestToken(kind, identifier string) *hadoop_common.TokenProto {
+	return &hadoop_common.TokenProto{Kind: proto.String(kind), Service: proto.String(""),
+		Identifier: []byte(identifier), Password: []byte("password")}
+}
+
+func newResourceRequest(priority int32, resourceName string, memory int64, numContainers int32) *hadoopyarn.ResourceRequestProto {
+	return &hadoopyarn.ResourceRequestProto{
+		Priority:      &hadoopyarn.PriorityProto{Priority: proto.Int32(priority)},
+		ResourceName:  proto.String(resourceName),
+		Capability:    &hadoopyarn.ResourceProto{Memory: proto.Int64(memory), VirtualCores: proto.Int32(1)},
+		NumContainers: proto.Int32(numContainers),
+	}
+}
+
+func newTestAMRMClient(t *testing.T, scheduler *fakeScheduler, ugi *security.UserGroupInformation, handler AMRMCallbackHandler) *AMRMClient {
+	confDir := writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.scheduler.address": scheduler.addr,
+	})
+	c, err := NewAMRMClient(confDir, "", ugi, handler, WithHeartbeatInterval(10*time.Millisecond))
+	assert.NoError(t, err)
+	_, err = c.RegisterApplicationMaster(context.Background(), &hadoopyarn.RegisterApplicationMasterRequestProto{
+		Host: proto.String("localhost")})
+	assert.NoError(t, err)
+	return c
+}
+
+func TestAMRMClientHeartbeats(t *testing.T) {
+	scheduler := newFakeScheduler(t)
+	ugi := security.NewRemoteUser("am")
+	handler := &recordingHandler{}
+	c := newTestAMRMClient(t, scheduler, ugi, handler)
+	defer c.Stop()
+
+	_, found := c.NMTokens().GetToken("node0:45454")
+	assert.True(t, found, "nm tokens of previous attempts are kept")
+
+	c.AddContainerRequest(newResourceRequest(1, "*", 1024, 1))
+	c.AddContainerRequest(newResourceRequest(1, "*", 1024, 2))
+	c.AddContainerRequest(newResourceRequest(2, "*", 2048, 1))
+	c.SetProgress(0.5)
+	assert.NoError(t, c.Start(context.Background()))
+	assert.Error(t, c.Start(context.Background()))
+
+	requests := scheduler.waitAllocates(t, 2)
+	assert.Len(t, requests[0].GetAsk(), 2)
+	for _, ask := range requests[0].GetAsk() {
+		if ask.GetPriority().GetPriority() == 1 {
+			assert.Equal(t, int32(3), ask.GetNumContainers())
+		}
+	}
+	assert.Equal(t, float32(0.5), requests[0].GetProgress())
+	assert.Equal(t, int32(0), requests[0].GetResponseId())
+	// unchanged requests are not sent again
+	assert.Empty(t, requests[1].GetAsk())
+	assert.Equal(t, int32(1), requests[1].GetResponseId())
+
+	containerID := &hadoopyarn.ContainerIdProto{Id: proto.Int64(1)}
+	scheduler.queue(&hadoopyarn.AllocateResponseProto{
+		AllocatedContainers: []*hadoopyarn.ContainerProto{{Id: containerID,
+			NodeId: &hadoopyarn.NodeIdProto{Host: proto.String("node1"), Port: proto.Int32(45454)}}},
+		NmTokens: []*hadoopyarn.NMTokenProto{{NodeId: &hadoopyarn.NodeIdProto{Host: proto.String("node1"), Port: proto.Int32(45454)},
+			Token: newTestToken(security.TokenKindNM, "node1")}},
+		AmRmToken: newTestToken(security.TokenKindAMRM, "new"),
+		Preempt:   &hadoopyarn.PreemptionMessageProto{},
+		Limit:     &hadoopyarn.ResourceProto{Memory: proto.Int64(4096)},
+	})
+	assert.Eventually(t, func() bool {
+		handler.mtx.Lock()
+		defer handler.mtx.Unlock()
+		return len(handler.allocated) == 1 && handler.preempted == 1
+	}, 5*time.Second, time.Millisecond)
+	_, found = c.NMTokens().GetToken("node1:45454")
+	assert.True(t, found)
+	token, _ := ugi.GetUserToken(security.TokenKindAMRM, scheduler.addr)
+	assert.Equal(t, []byte("new"), token.GetIdentifier())
+	assert.Equal(t, int64(4096), c.AvailableResources().GetMemory())
+
+	// the satisfied request and the released container are sent once
+	c.RemoveContainerRequest(newResourceRequest(1, "*", 1024, 1))
+	c.ReleaseAssignedContainer(containerID)
+	c.UpdateBlacklist([]string{"node2"}, nil)
+	var sent *hadoopyarn.AllocateRequestProto
+	assert.Eventually(t, func() bool {
+		for _, request := range scheduler.allocateRequests() {
+			if len(request.GetRelease()) > 0 {
+				sent = request
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, time.Millisecond)
+	assert.Len(t, sent.GetAsk(), 1)
+	assert.Equal(t, int32(2), sent.GetAsk()[0].GetNumContainers())
+	assert.Equal(t, []string{"node2"}, sent.GetBlacklistRequest().GetBlacklistAdditions())
+	for _, request := range scheduler.waitAllocates(t, 2) {
+		assert.Empty(t, request.GetAsk())
+		assert.Empty(t, request.GetRelease())
+		assert.Nil(t, request.GetBlacklistRequest())
+	}
+	assert.Len(t, c.OutstandingRequests(), 2)
+
+	c.RemoveContainerRequest(newResourceRequest(2, "*", 2048, 1))
+	scheduler.waitAllocates(t, 2)
+	assert.Len(t, c.OutstandingRequests(), 1)
+
+	assert.NoError(t, c.UnregisterApplicationMaster(context.Background(), hadoopyarn.FinalApplicationStatusProto_APP_SUCCEEDED, "", ""))
+	assert.Equal(t, 2, scheduler.finishes, "finish is called until the am is unregistered")
+	// heartbeats are stopped
+	count := len(scheduler.allocateRequests())
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, count, len(scheduler.allocateRequests()))
+	assert.NoError(t, handler.err)
+}
+
+func TestAMRMClientTokenService(t *testing.T) {
+	ugi := security.NewRemoteUser("am")
+	ugi.AddUserToken(newTestToken(security.TokenKindAMRM, "id"))
+	confDir := writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.ha.enabled":            "true",
+		"yarn.resourcemanager.ha.rm-ids":             "rm1,rm2",
+		"yarn.resourcemanager.scheduler.address.rm1": "127.0.0.1:8030",
+		"yarn.resourcemanager.scheduler.address.rm2": "127.0.0.2:8030",
+	})
+	_, err := NewAMRMClient(confDir, "", ugi, &recordingHandler{})
+	assert.NoError(t, err)
+	// the am rm token is for the schedulers of all rms
+	token, found := ugi.GetUserToken(security.TokenKindAMRM, "127.0.0.1:8030,127.0.0.2:8030")
+	assert.True(t, found)
+	assert.Equal(t, []byte("id"), token.GetIdentifier())
+	_, found = ugi.GetUserToken(security.TokenKindAMRM, "")
+	assert.False(t, found)
+}
+
+func TestAMRMClientResync(t *testing.T) {
+	scheduler := newFakeScheduler(t)
+	handler := &recordingHandler{}
+	c := newTestAMRMClient(t, scheduler, security.NewRemoteUser("am"), handler)
+	defer c.Stop()
+
+	c.AddContainerRequest(newResourceRequest(1, "*", 1024, 2))
+	c.UpdateBlacklist([]string{"node2"}, nil)
+	c.ReleaseAssignedContainer(&hadoopyarn.ContainerIdProto{Id: proto.Int64(1)})
+	assert.NoError(t, c.Start(context.Background()))
+	scheduler.waitAllocates(t, 2)
+
+	// rm restarts without the state of am
+	scheduler.mtx.Lock()
+	scheduler.notRegistred = true
+	scheduler.mtx.Unlock()
+	requests := scheduler.waitAllocates(t, 1)
+	assert.Equal(t, 2, scheduler.registers)
+	assert.Equal(t, int32(0), requests[0].GetResponseId())
+	assert.Len(t, requests[0].GetAsk(), 1)
+	assert.Len(t, requests[0].GetRelease(), 1)
+	assert.Equal(t, []string{"node2"}, requests[0].GetBlacklistRequest().GetBlacklistAdditions())
+
+	// rm asks the am to shut down
+	scheduler.queue(&hadoopyarn.AllocateResponseProto{AMCommand: hadoopyarn.AMCommandProto_AM_SHUTDOWN.Enum()})
+	assert.Eventually(t, func() bool {
+		handler.mtx.Lock()
+		defer handler.mtx.Unlock()
+		return handler.shutdown
+	}, 5*time.Second, time.Millisecond)
+	assert.NoError(t, handler.err)
+}
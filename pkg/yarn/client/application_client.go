@@ -17,7 +17,11 @@ limitations under the License.
 package client
 
 import (
+	"context"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
 	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
@@ -26,16 +30,462 @@ type YarnApplicationClient struct {
 	client yarnservice.ApplicationClientProtocolService
 }
 
-func CreateYarnApplicationClient(conf yarnconf.YarnConfiguration, rmAddress *string) (*YarnApplicationClient, error) {
-	c, err := yarnservice.DialApplicationClientProtocolService(conf, rmAddress)
+func CreateYarnApplicationClient(conf yarnconf.YarnConfiguration, rmAddress *string, ugi *security.UserGroupInformation) (*YarnApplicationClient, error) {
+	c, err := yarnservice.DialApplicationClientProtocolService(conf, rmAddress, ugi)
 	return &YarnApplicationClient{client: c}, err
 }
 
 func (c *YarnApplicationClient) GetClusterNode(request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+	return c.GetClusterNodeWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetClusterNodeWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
 	response := &hadoopyarn.GetClusterNodesResponseProto{}
-	err := c.client.GetClusterNodes(request, response)
+	err := c.client.GetClusterNodesWithContext(ctx, request, response)
 	if err != nil {
 		return response, err
 	}
 	return response, nil
 }
+
+func (c *YarnApplicationClient) GetNewApplication(request *hadoopyarn.GetNewApplicationRequestProto) (*hadoopyarn.GetNewApplicationResponseProto, error) {
+	return c.GetNewApplicationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetNewApplicationWithContext(ctx context.Context, request *hadoopyarn.GetNewApplicationRequestProto) (*hadoopyarn.GetNewApplicationResponseProto, error) {
+	response := &hadoopyarn.GetNewApplicationResponseProto{}
+	err := c.client.GetNewApplicationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetApplicationReport(request *hadoopyarn.GetApplicationReportRequestProto) (*hadoopyarn.GetApplicationReportResponseProto, error) {
+	return c.GetApplicationReportWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetApplicationReportWithContext(ctx context.Context, request *hadoopyarn.GetApplicationReportRequestProto) (*hadoopyarn.GetApplicationReportResponseProto, error) {
+	response := &hadoopyarn.GetApplicationReportResponseProto{}
+	err := c.client.GetApplicationReportWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) SubmitApplication(request *hadoopyarn.SubmitApplicationRequestProto) (*hadoopyarn.SubmitApplicationResponseProto, error) {
+	return c.SubmitApplicationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) SubmitApplicationWithContext(ctx context.Context, request *hadoopyarn.SubmitApplicationRequestProto) (*hadoopyarn.SubmitApplicationResponseProto, error) {
+	response := &hadoopyarn.SubmitApplicationResponseProto{}
+	err := c.client.SubmitApplicationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) FailApplicationAttempt(request *hadoopyarn.FailApplicationAttemptRequestProto) (*hadoopyarn.FailApplicationAttemptResponseProto, error) {
+	return c.FailApplicationAttemptWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) FailApplicationAttemptWithContext(ctx context.Context, request *hadoopyarn.FailApplicationAttemptRequestProto) (*hadoopyarn.FailApplicationAttemptResponseProto, error) {
+	response := &hadoopyarn.FailApplicationAttemptResponseProto{}
+	err := c.client.FailApplicationAttemptWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) ForceKillApplication(request *hadoopyarn.KillApplicationRequestProto) (*hadoopyarn.KillApplicationResponseProto, error) {
+	return c.ForceKillApplicationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) ForceKillApplicationWithContext(ctx context.Context, request *hadoopyarn.KillApplicationRequestProto) (*hadoopyarn.KillApplicationResponseProto, error) {
+	response := &hadoopyarn.KillApplicationResponseProto{}
+	err := c.client.ForceKillApplicationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetClusterMetrics(request *hadoopyarn.GetClusterMetricsRequestProto) (*hadoopyarn.GetClusterMetricsResponseProto, error) {
+	return c.GetClusterMetricsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetClusterMetricsWithContext(ctx context.Context, request *hadoopyarn.GetClusterMetricsRequestProto) (*hadoopyarn.GetClusterMetricsResponseProto, error) {
+	response := &hadoopyarn.GetClusterMetricsResponseProto{}
+	err := c.client.GetClusterMetricsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetApplications(request *hadoopyarn.GetApplicationsRequestProto) (*hadoopyarn.GetApplicationsResponseProto, error) {
+	return c.GetApplicationsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetApplicationsWithContext(ctx context.Context, request *hadoopyarn.GetApplicationsRequestProto) (*hadoopyarn.GetApplicationsResponseProto, error) {
+	response := &hadoopyarn.GetApplicationsResponseProto{}
+	err := c.client.GetApplicationsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetQueueInfo(request *hadoopyarn.GetQueueInfoRequestProto) (*hadoopyarn.GetQueueInfoResponseProto, error) {
+	return c.GetQueueInfoWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetQueueInfoWithContext(ctx context.Context, request *hadoopyarn.GetQueueInfoRequestProto) (*hadoopyarn.GetQueueInfoResponseProto, error) {
+	response := &hadoopyarn.GetQueueInfoResponseProto{}
+	err := c.client.GetQueueInfoWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetQueueUserAcls(request *hadoopyarn.GetQueueUserAclsInfoRequestProto) (*hadoopyarn.GetQueueUserAclsInfoResponseProto, error) {
+	return c.GetQueueUserAclsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetQueueUserAclsWithContext(ctx context.Context, request *hadoopyarn.GetQueueUserAclsInfoRequestProto) (*hadoopyarn.GetQueueUserAclsInfoResponseProto, error) {
+	response := &hadoopyarn.GetQueueUserAclsInfoResponseProto{}
+	err := c.client.GetQueueUserAclsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetDelegationToken(request *hadoopcommon.GetDelegationTokenRequestProto) (*hadoopcommon.GetDelegationTokenResponseProto, error) {
+	return c.GetDelegationTokenWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.GetDelegationTokenRequestProto) (*hadoopcommon.GetDelegationTokenResponseProto, error) {
+	response := &hadoopcommon.GetDelegationTokenResponseProto{}
+	err := c.client.GetDelegationTokenWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) RenewDelegationToken(request *hadoopcommon.RenewDelegationTokenRequestProto) (*hadoopcommon.RenewDelegationTokenResponseProto, error) {
+	return c.RenewDelegationTokenWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) RenewDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.RenewDelegationTokenRequestProto) (*hadoopcommon.RenewDelegationTokenResponseProto, error) {
+	response := &hadoopcommon.RenewDelegationTokenResponseProto{}
+	err := c.client.RenewDelegationTokenWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) CancelDelegationToken(request *hadoopcommon.CancelDelegationTokenRequestProto) (*hadoopcommon.CancelDelegationTokenResponseProto, error) {
+	return c.CancelDelegationTokenWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) CancelDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.CancelDelegationTokenRequestProto) (*hadoopcommon.CancelDelegationTokenResponseProto, error) {
+	response := &hadoopcommon.CancelDelegationTokenResponseProto{}
+	err := c.client.CancelDelegationTokenWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) MoveApplicationAcrossQueues(request *hadoopyarn.MoveApplicationAcrossQueuesRequestProto) (*hadoopyarn.MoveApplicationAcrossQueuesResponseProto, error) {
+	return c.MoveApplicationAcrossQueuesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) MoveApplicationAcrossQueuesWithContext(ctx context.Context, request *hadoopyarn.MoveApplicationAcrossQueuesRequestProto) (*hadoopyarn.MoveApplicationAcrossQueuesResponseProto, error) {
+	response := &hadoopyarn.MoveApplicationAcrossQueuesResponseProto{}
+	err := c.client.MoveApplicationAcrossQueuesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetApplicationAttemptReport(request *hadoopyarn.GetApplicationAttemptReportRequestProto) (*hadoopyarn.GetApplicationAttemptReportResponseProto, error) {
+	return c.GetApplicationAttemptReportWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetApplicationAttemptReportWithContext(ctx context.Context, request *hadoopyarn.GetApplicationAttemptReportRequestProto) (*hadoopyarn.GetApplicationAttemptReportResponseProto, error) {
+	response := &hadoopyarn.GetApplicationAttemptReportResponseProto{}
+	err := c.client.GetApplicationAttemptReportWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetApplicationAttempts(request *hadoopyarn.GetApplicationAttemptsRequestProto) (*hadoopyarn.GetApplicationAttemptsResponseProto, error) {
+	return c.GetApplicationAttemptsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetApplicationAttemptsWithContext(ctx context.Context, request *hadoopyarn.GetApplicationAttemptsRequestProto) (*hadoopyarn.GetApplicationAttemptsResponseProto, error) {
+	response := &hadoopyarn.GetApplicationAttemptsResponseProto{}
+	err := c.client.GetApplicationAttemptsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetContainerReport(request *hadoopyarn.GetContainerReportRequestProto) (*hadoopyarn.GetContainerReportResponseProto, error) {
+	return c.GetContainerReportWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetContainerReportWithContext(ctx context.Context, request *hadoopyarn.GetContainerReportRequestProto) (*hadoopyarn.GetContainerReportResponseProto, error) {
+	response := &hadoopyarn.GetContainerReportResponseProto{}
+	err := c.client.GetContainerReportWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetContainers(request *hadoopyarn.GetContainersRequestProto) (*hadoopyarn.GetContainersResponseProto, error) {
+	return c.GetContainersWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetContainersWithContext(ctx context.Context, request *hadoopyarn.GetContainersRequestProto) (*hadoopyarn.GetContainersResponseProto, error) {
+	response := &hadoopyarn.GetContainersResponseProto{}
+	err := c.client.GetContainersWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetNewReservation(request *hadoopyarn.GetNewReservationRequestProto) (*hadoopyarn.GetNewReservationResponseProto, error) {
+	return c.GetNewReservationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetNewReservationWithContext(ctx context.Context, request *hadoopyarn.GetNewReservationRequestProto) (*hadoopyarn.GetNewReservationResponseProto, error) {
+	response := &hadoopyarn.GetNewReservationResponseProto{}
+	err := c.client.GetNewReservationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) SubmitReservation(request *hadoopyarn.ReservationSubmissionRequestProto) (*hadoopyarn.ReservationSubmissionResponseProto, error) {
+	return c.SubmitReservationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) SubmitReservationWithContext(ctx context.Context, request *hadoopyarn.ReservationSubmissionRequestProto) (*hadoopyarn.ReservationSubmissionResponseProto, error) {
+	response := &hadoopyarn.ReservationSubmissionResponseProto{}
+	err := c.client.SubmitReservationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) UpdateReservation(request *hadoopyarn.ReservationUpdateRequestProto) (*hadoopyarn.ReservationUpdateResponseProto, error) {
+	return c.UpdateReservationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) UpdateReservationWithContext(ctx context.Context, request *hadoopyarn.ReservationUpdateRequestProto) (*hadoopyarn.ReservationUpdateResponseProto, error) {
+	response := &hadoopyarn.ReservationUpdateResponseProto{}
+	err := c.client.UpdateReservationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) DeleteReservation(request *hadoopyarn.ReservationDeleteRequestProto) (*hadoopyarn.ReservationDeleteResponseProto, error) {
+	return c.DeleteReservationWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) DeleteReservationWithContext(ctx context.Context, request *hadoopyarn.ReservationDeleteRequestProto) (*hadoopyarn.ReservationDeleteResponseProto, error) {
+	response := &hadoopyarn.ReservationDeleteResponseProto{}
+	err := c.client.DeleteReservationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) ListReservations(request *hadoopyarn.ReservationListRequestProto) (*hadoopyarn.ReservationListResponseProto, error) {
+	return c.ListReservationsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) ListReservationsWithContext(ctx context.Context, request *hadoopyarn.ReservationListRequestProto) (*hadoopyarn.ReservationListResponseProto, error) {
+	response := &hadoopyarn.ReservationListResponseProto{}
+	err := c.client.ListReservationsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetNodeToLabels(request *hadoopyarn.GetNodesToLabelsRequestProto) (*hadoopyarn.GetNodesToLabelsResponseProto, error) {
+	return c.GetNodeToLabelsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetNodeToLabelsWithContext(ctx context.Context, request *hadoopyarn.GetNodesToLabelsRequestProto) (*hadoopyarn.GetNodesToLabelsResponseProto, error) {
+	response := &hadoopyarn.GetNodesToLabelsResponseProto{}
+	err := c.client.GetNodeToLabelsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetLabelsToNodes(request *hadoopyarn.GetLabelsToNodesRequestProto) (*hadoopyarn.GetLabelsToNodesResponseProto, error) {
+	return c.GetLabelsToNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetLabelsToNodesWithContext(ctx context.Context, request *hadoopyarn.GetLabelsToNodesRequestProto) (*hadoopyarn.GetLabelsToNodesResponseProto, error) {
+	response := &hadoopyarn.GetLabelsToNodesResponseProto{}
+	err := c.client.GetLabelsToNodesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetClusterNodeLabels(request *hadoopyarn.GetClusterNodeLabelsRequestProto) (*hadoopyarn.GetClusterNodeLabelsResponseProto, error) {
+	return c.GetClusterNodeLabelsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetClusterNodeLabelsWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodeLabelsRequestProto) (*hadoopyarn.GetClusterNodeLabelsResponseProto, error) {
+	response := &hadoopyarn.GetClusterNodeLabelsResponseProto{}
+	err := c.client.GetClusterNodeLabelsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) UpdateApplicationPriority(request *hadoopyarn.UpdateApplicationPriorityRequestProto) (*hadoopyarn.UpdateApplicationPriorityResponseProto, error) {
+	return c.UpdateApplicationPriorityWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) UpdateApplicationPriorityWithContext(ctx context.Context, request *hadoopyarn.UpdateApplicationPriorityRequestProto) (*hadoopyarn.UpdateApplicationPriorityResponseProto, error) {
+	response := &hadoopyarn.UpdateApplicationPriorityResponseProto{}
+	err := c.client.UpdateApplicationPriorityWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) SignalToContainer(request *hadoopyarn.SignalContainerRequestProto) (*hadoopyarn.SignalContainerResponseProto, error) {
+	return c.SignalToContainerWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) SignalToContainerWithContext(ctx context.Context, request *hadoopyarn.SignalContainerRequestProto) (*hadoopyarn.SignalContainerResponseProto, error) {
+	response := &hadoopyarn.SignalContainerResponseProto{}
+	err := c.client.SignalToContainerWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) UpdateApplicationTimeouts(request *hadoopyarn.UpdateApplicationTimeoutsRequestProto) (*hadoopyarn.UpdateApplicationTimeoutsResponseProto, error) {
+	return c.UpdateApplicationTimeoutsWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) UpdateApplicationTimeoutsWithContext(ctx context.Context, request *hadoopyarn.UpdateApplicationTimeoutsRequestProto) (*hadoopyarn.UpdateApplicationTimeoutsResponseProto, error) {
+	response := &hadoopyarn.UpdateApplicationTimeoutsResponseProto{}
+	err := c.client.UpdateApplicationTimeoutsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetResourceProfiles(request *hadoopyarn.GetAllResourceProfilesRequestProto) (*hadoopyarn.GetAllResourceProfilesResponseProto, error) {
+	return c.GetResourceProfilesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetResourceProfilesWithContext(ctx context.Context, request *hadoopyarn.GetAllResourceProfilesRequestProto) (*hadoopyarn.GetAllResourceProfilesResponseProto, error) {
+	response := &hadoopyarn.GetAllResourceProfilesResponseProto{}
+	err := c.client.GetResourceProfilesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetResourceProfile(request *hadoopyarn.GetResourceProfileRequestProto) (*hadoopyarn.GetResourceProfileResponseProto, error) {
+	return c.GetResourceProfileWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetResourceProfileWithContext(ctx context.Context, request *hadoopyarn.GetResourceProfileRequestProto) (*hadoopyarn.GetResourceProfileResponseProto, error) {
+	response := &hadoopyarn.GetResourceProfileResponseProto{}
+	err := c.client.GetResourceProfileWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetResourceTypeInfo(request *hadoopyarn.GetAllResourceTypeInfoRequestProto) (*hadoopyarn.GetAllResourceTypeInfoResponseProto, error) {
+	return c.GetResourceTypeInfoWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetResourceTypeInfoWithContext(ctx context.Context, request *hadoopyarn.GetAllResourceTypeInfoRequestProto) (*hadoopyarn.GetAllResourceTypeInfoResponseProto, error) {
+	response := &hadoopyarn.GetAllResourceTypeInfoResponseProto{}
+	err := c.client.GetResourceTypeInfoWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetClusterNodeAttributes(request *hadoopyarn.GetClusterNodeAttributesRequestProto) (*hadoopyarn.GetClusterNodeAttributesResponseProto, error) {
+	return c.GetClusterNodeAttributesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetClusterNodeAttributesWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodeAttributesRequestProto) (*hadoopyarn.GetClusterNodeAttributesResponseProto, error) {
+	response := &hadoopyarn.GetClusterNodeAttributesResponseProto{}
+	err := c.client.GetClusterNodeAttributesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetAttributesToNodes(request *hadoopyarn.GetAttributesToNodesRequestProto) (*hadoopyarn.GetAttributesToNodesResponseProto, error) {
+	return c.GetAttributesToNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetAttributesToNodesWithContext(ctx context.Context, request *hadoopyarn.GetAttributesToNodesRequestProto) (*hadoopyarn.GetAttributesToNodesResponseProto, error) {
+	response := &hadoopyarn.GetAttributesToNodesResponseProto{}
+	err := c.client.GetAttributesToNodesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationClient) GetNodesToAttributes(request *hadoopyarn.GetNodesToAttributesRequestProto) (*hadoopyarn.GetNodesToAttributesResponseProto, error) {
+	return c.GetNodesToAttributesWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationClient) GetNodesToAttributesWithContext(ctx context.Context, request *hadoopyarn.GetNodesToAttributesRequestProto) (*hadoopyarn.GetNodesToAttributesResponseProto, error) {
+	response := &hadoopyarn.GetNodesToAttributesResponseProto{}
+	err := c.client.GetNodesToAttributesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+type YarnApplicationMasterClient struct {
+	client yarnservice.ApplicationMasterProtocolService
+}
+
+func CreateYarnApplicationMasterClient(conf yarnconf.YarnConfiguration, rmSchedulerAddress *string, ugi *security.UserGroupInformation) (*YarnApplicationMasterClient, error) {
+	c, err := yarnservice.DialApplicationMasterProtocolService(conf, rmSchedulerAddress, ugi)
+	return &YarnApplicationMasterClient{client: c}, err
+}
+
+func (c *YarnApplicationMasterClient) RegisterApplicationMaster(request *hadoopyarn.RegisterApplicationMasterRequestProto) (*hadoopyarn.RegisterApplicationMasterResponseProto, error) {
+	return c.RegisterApplicationMasterWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationMasterClient) RegisterApplicationMasterWithContext(ctx context.Context, request *hadoopyarn.RegisterApplicationMasterRequestProto) (*hadoopyarn.RegisterApplicationMasterResponseProto, error) {
+	response := &hadoopyarn.RegisterApplicationMasterResponseProto{}
+	err := c.client.RegisterApplicationMasterWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationMasterClient) FinishApplicationMaster(request *hadoopyarn.FinishApplicationMasterRequestProto) (*hadoopyarn.FinishApplicationMasterResponseProto, error) {
+	return c.FinishApplicationMasterWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationMasterClient) FinishApplicationMasterWithContext(ctx context.Context, request *hadoopyarn.FinishApplicationMasterRequestProto) (*hadoopyarn.FinishApplicationMasterResponseProto, error) {
+	response := &hadoopyarn.FinishApplicationMasterResponseProto{}
+	err := c.client.FinishApplicationMasterWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnApplicationMasterClient) Allocate(request *hadoopyarn.AllocateRequestProto) (*hadoopyarn.AllocateResponseProto, error) {
+	return c.AllocateWithContext(context.Background(), request)
+}
+
+func (c *YarnApplicationMasterClient) AllocateWithContext(ctx context.Context, request *hadoopyarn.AllocateRequestProto) (*hadoopyarn.AllocateResponseProto, error) {
+	response := &hadoopyarn.AllocateResponseProto{}
+	err := c.client.AllocateWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
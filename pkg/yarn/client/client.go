@@ -17,12 +17,16 @@ limitations under the License.
 package client
 
 import (
+	"context"
 	"fmt"
+	"sync"
+
 	"k8s.io/klog/v2"
 
 	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
 	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
 
@@ -32,142 +36,606 @@ type YarnClient interface {
 	Close()
 	UpdateNodeResource(request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error)
 	GetClusterNodes(request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error)
+	// UpdateNodeResourceWithContext and GetClusterNodesWithContext stop once ctx is cancelled or its deadline passes,
+	// including the discovery of active rm if the client is not initialized.
+	UpdateNodeResourceWithContext(ctx context.Context, request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error)
+	GetClusterNodesWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error)
+	// GetDelegationTokenWithContext, RenewDelegationTokenWithContext and CancelDelegationTokenWithContext manage rm
+	// delegation tokens, which are only issued and renewed to users authenticated by kerberos.
+	GetDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.GetDelegationTokenRequestProto) (*hadoopcommon.GetDelegationTokenResponseProto, error)
+	RenewDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.RenewDelegationTokenRequestProto) (*hadoopcommon.RenewDelegationTokenResponseProto, error)
+	CancelDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.CancelDelegationTokenRequestProto) (*hadoopcommon.CancelDelegationTokenResponseProto, error)
+	// the rm admin operations below are idempotent and sent to the active rm, as `yarn rmadmin` does
+	RefreshQueuesWithContext(ctx context.Context, request *yarnserver.RefreshQueuesRequestProto) (*yarnserver.RefreshQueuesResponseProto, error)
+	RefreshNodesWithContext(ctx context.Context, request *yarnserver.RefreshNodesRequestProto) (*yarnserver.RefreshNodesResponseProto, error)
+	RefreshSuperUserGroupsConfigurationWithContext(ctx context.Context, request *yarnserver.RefreshSuperUserGroupsConfigurationRequestProto) (*yarnserver.RefreshSuperUserGroupsConfigurationResponseProto, error)
+	RefreshUserToGroupsMappingsWithContext(ctx context.Context, request *yarnserver.RefreshUserToGroupsMappingsRequestProto) (*yarnserver.RefreshUserToGroupsMappingsResponseProto, error)
+	RefreshAdminAclsWithContext(ctx context.Context, request *yarnserver.RefreshAdminAclsRequestProto) (*yarnserver.RefreshAdminAclsResponseProto, error)
+	RefreshServiceAclsWithContext(ctx context.Context, request *yarnserver.RefreshServiceAclsRequestProto) (*yarnserver.RefreshServiceAclsResponseProto, error)
+	GetGroupsForUserWithContext(ctx context.Context, request *yarnserver.GetGroupsForUserRequestProto) (*yarnserver.GetGroupsForUserResponseProto, error)
+	RefreshNodesResourcesWithContext(ctx context.Context, request *yarnserver.RefreshNodesResourcesRequestProto) (*yarnserver.RefreshNodesResourcesResponseProto, error)
+	AddToClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.AddToClusterNodeLabelsRequestProto) (*yarnserver.AddToClusterNodeLabelsResponseProto, error)
+	RemoveFromClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.RemoveFromClusterNodeLabelsRequestProto) (*yarnserver.RemoveFromClusterNodeLabelsResponseProto, error)
+	ReplaceLabelsOnNodesWithContext(ctx context.Context, request *yarnserver.ReplaceLabelsOnNodeRequestProto) (*yarnserver.ReplaceLabelsOnNodeResponseProto, error)
+	CheckForDecommissioningNodesWithContext(ctx context.Context, request *yarnserver.CheckForDecommissioningNodesRequestProto) (*yarnserver.CheckForDecommissioningNodesResponseProto, error)
+	RefreshClusterMaxPriorityWithContext(ctx context.Context, request *yarnserver.RefreshClusterMaxPriorityRequestProto) (*yarnserver.RefreshClusterMaxPriorityResponseProto, error)
+	MapAttributesToNodesWithContext(ctx context.Context, request *yarnserver.NodesToAttributesMappingRequestProto) (*yarnserver.NodesToAttributesMappingResponseProto, error)
 }
 
 var _ YarnClient = &yarnClient{}
 
 type yarnClient struct {
-	confDir              string
+	confDir     string
+	clusterID   string
+	retryPolicy RetryPolicy
+	ugi         *security.UserGroupInformation
+
+	// mtx protects the following fields, which are changed by initialize and failover
+	mtx                  sync.RWMutex
 	conf                 yarnconf.YarnConfiguration
 	haEnabled            bool
+	rmIDs                []string
+	activeRMIndex        int
 	activeRMAdminAddress *string
 	activeRMAddress      *string
-	clusterID            string
+	activeRetryPolicy    RetryPolicy
+	// activeRMClients are kept for all attempts of calls to the active rm, so that a retried call has the same
+	// client id and the retry cache of rm recognizes it
+	activeRMClients *rmClients
+	// activeRMResolver finds the active rm of ha cluster, it may switch the active rm once it learns about a failover
+	activeRMResolver activeRMResolver
+	// federation is set if the cluster is federated, whose calls go through the router except the admin operations
+	// of nodes
+	federation *federation
+	// closed is set by Close, after which the client is never initialized again, so that a client retired by
+	// ReloadingYarnClientFactory does not leak the connections and resolvers of a new initialization
+	closed bool
+}
+
+// rmClients are the protocol clients of an rm
+type rmClients struct {
+	admin       *YarnAdminClient
+	application *YarnApplicationClient
+}
+
+type YarnClientOption func(c *yarnClient)
+
+// WithRetryPolicy makes the client retry failed calls with policy, instead of the failover policy configured by
+// yarn.client.failover-* settings
+func WithRetryPolicy(policy RetryPolicy) YarnClientOption {
+	return func(c *yarnClient) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithUser makes the client talk to rm as ugi instead of the current user, e.g. a kerberos user logged in with
+// the keytab of cluster, or a proxy user
+func WithUser(ugi *security.UserGroupInformation) YarnClientOption {
+	return func(c *yarnClient) {
+		c.ugi = ugi
+	}
 }
 
-func NewYarnClient(confDir string, clusterID string) YarnClient {
-	return &yarnClient{confDir: confDir, clusterID: clusterID}
+func NewYarnClient(confDir string, clusterID string, opts ...YarnClientOption) YarnClient {
+	c := &yarnClient{confDir: confDir, clusterID: clusterID}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *yarnClient) Initialize() error {
-	if conf, err := yarnconf.NewYarnConfiguration(c.confDir, c.clusterID); err == nil {
+	return c.initialize(context.Background())
+}
+
+func (c *yarnClient) initialize(ctx context.Context) error {
+	conf, err := yarnconf.NewYarnConfiguration(c.confDir, c.clusterID)
+	if err != nil {
 		// TODO use flags for conf dir config
-		c.conf = conf
-	} else {
 		return err
 	}
 
-	if ha, err := c.conf.GetRMEnabledHA(); err == nil {
-		c.haEnabled = ha
-	} else {
+	haEnabled, err := conf.GetRMEnabledHA()
+	if err != nil {
+		return err
+	}
+
+	retryPolicy := c.retryPolicy
+	if retryPolicy == nil {
+		if retryPolicy, err = NewRetryPolicyFromConf(conf); err != nil {
+			return err
+		}
+	}
+
+	federationEnabled, err := conf.GetFederationEnabled()
+	if err != nil {
 		return err
 	}
+	if federationEnabled {
+		return c.initializeFederation(conf, retryPolicy)
+	}
 
 	// ha not enabled, use default conf
-	if !c.haEnabled {
-		if rmAdminAddr, err := c.conf.GetRMAdminAddress(); err == nil {
-			c.activeRMAdminAddress = &rmAdminAddr
-		} else {
+	if !haEnabled {
+		rmAdminAddr, err := conf.GetRMAdminAddress()
+		if err != nil {
 			return err
 		}
-		if rmAddr, err := c.conf.GetRMAddress(); err == nil {
-			c.activeRMAddress = &rmAddr
-		} else {
+		rmAddr, err := conf.GetRMAddress()
+		if err != nil {
 			return err
 		}
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		if c.closed {
+			return c.errClosed()
+		}
+		c.conf, c.haEnabled, c.activeRetryPolicy = conf, haEnabled, retryPolicy
+		c.rmIDs, c.activeRMIndex = nil, 0
+		c.activeRMAdminAddress, c.activeRMAddress, c.activeRMClients = &rmAdminAddr, &rmAddr, nil
+		c.setActiveRMResolver(nil)
+		c.setFederation(nil)
 		return nil
 	}
 
 	// ha enabled, get active rm address by id
-	var activeRMID string
-	var err error
-	if activeRMID, err = c.GetActiveRMID(); err != nil {
+	rmIDs, err := conf.GetRMs()
+	if err != nil {
 		return err
 	}
-	if rmAdminAddr, err := c.conf.GetRMAdminAddressByID(activeRMID); err == nil {
-		c.activeRMAdminAddress = &rmAdminAddr
-	} else {
+	resolver, err := newActiveRMResolver(conf, c.ugi, c.switchActiveRM)
+	if err != nil {
 		return err
 	}
-	if rmAddress, err := c.conf.GetRMAddressByID(activeRMID); err == nil {
-		c.activeRMAddress = &rmAddress
-	} else {
+	activeRMID, err := resolver.ActiveRMID(ctx)
+	if err != nil {
+		resolver.Close()
 		return err
 	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		resolver.Close()
+		return c.errClosed()
+	}
+	c.conf, c.haEnabled, c.activeRetryPolicy = conf, haEnabled, retryPolicy
+	c.rmIDs = rmIDs
+	c.setActiveRMResolver(resolver)
+	c.setFederation(nil)
+	for i, rmID := range rmIDs {
+		if rmID == activeRMID {
+			c.activeRMIndex = i
+		}
+	}
+	return c.setActiveRMByIndex(c.activeRMIndex)
+}
 
+// initializeFederation makes calls go through the router, which is a single address like rm without ha
+func (c *yarnClient) initializeFederation(conf yarnconf.YarnConfiguration, retryPolicy RetryPolicy) error {
+	routerAdminAddr, err := conf.GetRouterAdminAddress()
+	if err != nil {
+		return err
+	}
+	routerAddr, err := conf.GetRouterAddress()
+	if err != nil {
+		return err
+	}
+	subClusterOpts := []YarnClientOption{WithUser(c.ugi)}
+	if c.retryPolicy != nil {
+		subClusterOpts = append(subClusterOpts, WithRetryPolicy(c.retryPolicy))
+	}
+	f, err := newFederation(conf, c.confDir, c.clusterID, subClusterOpts...)
+	if err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.closed {
+		f.close()
+		return c.errClosed()
+	}
+	c.conf, c.haEnabled, c.activeRetryPolicy = conf, false, retryPolicy
+	c.rmIDs, c.activeRMIndex = nil, 0
+	c.activeRMAdminAddress, c.activeRMAddress, c.activeRMClients = &routerAdminAddr, &routerAddr, nil
+	c.setActiveRMResolver(nil)
+	c.setFederation(f)
 	return nil
 }
 
+// setFederation must be called with mtx held, the clients of sub-clusters replaced are closed
+func (c *yarnClient) setFederation(f *federation) {
+	if c.federation != nil {
+		c.federation.close()
+	}
+	c.federation = f
+}
+
+// setActiveRMResolver must be called with mtx held, the resolver replaced is closed
+func (c *yarnClient) setActiveRMResolver(resolver activeRMResolver) {
+	if c.activeRMResolver != nil {
+		c.activeRMResolver.Close()
+	}
+	c.activeRMResolver = resolver
+}
+
+// switchActiveRM switches to the active rm learned by resolver, which is ignored if the resolver has been replaced
+func (c *yarnClient) switchActiveRM(resolver activeRMResolver, rmID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.activeRMResolver != resolver || !c.haEnabled {
+		return
+	}
+	for i, id := range c.rmIDs {
+		if id != rmID {
+			continue
+		}
+		if i == c.activeRMIndex {
+			return
+		}
+		if err := c.setActiveRMByIndex(i); err != nil {
+			klog.Warningf("yarn cluster %v failed to switch to active rm %v, error %v", c.clusterID, rmID, err)
+			return
+		}
+		klog.V(3).Infof("yarn cluster %v switches to active rm %v", c.clusterID, rmID)
+		return
+	}
+	klog.Warningf("active rm %v of yarn cluster %v is not in %v", rmID, c.clusterID, c.rmIDs)
+}
+
+// setActiveRMByIndex must be called with mtx held
+func (c *yarnClient) setActiveRMByIndex(index int) error {
+	rmID := c.rmIDs[index]
+	rmAdminAddr, err := c.conf.GetRMAdminAddressByID(rmID)
+	if err != nil {
+		return err
+	}
+	rmAddr, err := c.conf.GetRMAddressByID(rmID)
+	if err != nil {
+		return err
+	}
+	c.activeRMIndex = index
+	c.activeRMAdminAddress, c.activeRMAddress, c.activeRMClients = &rmAdminAddr, &rmAddr, nil
+	return nil
+}
+
+// activeRM returns the index of active rm and its clients, which are created on the first call to it
+func (c *yarnClient) activeRM() (int, *rmClients, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.activeRMClients != nil {
+		return c.activeRMIndex, c.activeRMClients, nil
+	}
+	if c.closed {
+		return 0, nil, c.errClosed()
+	} else if c.activeRMAdminAddress == nil || c.activeRMAddress == nil {
+		return 0, nil, fmt.Errorf("yarn client of cluster %v is reinitializing", c.clusterID)
+	}
+	adminClient, err := CreateYarnAdminClient(c.conf, c.activeRMAdminAddress, c.ugi)
+	if err != nil {
+		return 0, nil, err
+	}
+	applicationClient, err := CreateYarnApplicationClient(c.conf, c.activeRMAddress, c.ugi)
+	if err != nil {
+		return 0, nil, err
+	}
+	c.activeRMClients = &rmClients{admin: adminClient, application: applicationClient}
+	return c.activeRMIndex, c.activeRMClients, nil
+}
+
+// Close releases the resolver and sub-clusters of client, calls and Reinitialize fail with ErrClientClosed after it
 func (c *yarnClient) Close() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.closed = true
+	c.reset()
+}
+
+// reset must be called with mtx held
+func (c *yarnClient) reset() {
 	c.activeRMAdminAddress = nil
 	c.activeRMAddress = nil
+	c.activeRMClients = nil
+	c.setActiveRMResolver(nil)
+	c.setFederation(nil)
 }
 
 func (c *yarnClient) Reinitialize() error {
-	c.Close()
+	c.mtx.Lock()
+	if c.closed {
+		c.mtx.Unlock()
+		return c.errClosed()
+	}
+	c.reset()
+	c.mtx.Unlock()
 	return c.Initialize()
 }
 
+func (c *yarnClient) errClosed() error {
+	return fmt.Errorf("%w: cluster %v", ErrClientClosed, c.clusterID)
+}
+
 func (c *yarnClient) UpdateNodeResource(request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
-	if c.activeRMAdminAddress == nil && c.haEnabled {
-		if err := c.Initialize(); err != nil {
-			return nil, err
-		}
+	return c.UpdateNodeResourceWithContext(context.Background(), request)
+}
+
+func (c *yarnClient) UpdateNodeResourceWithContext(ctx context.Context, request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
+	if f, err := c.getFederation(ctx); err != nil {
+		return nil, err
+	} else if f != nil {
+		return f.UpdateNodeResourceWithContext(ctx, request)
 	}
-	// TODO check response error code and retry auto
-	return c.updateNodeResource(request)
+	var response *yarnserver.UpdateNodeResourceResponseProto
+	err := c.invoke(ctx, "UpdateNodeResource", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.UpdateNodeResourceWithContext(ctx, request)
+		return err
+	})
+	return response, err
 }
 
 func (c *yarnClient) GetClusterNodes(request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
-	if c.activeRMAdminAddress == nil && c.haEnabled {
-		if err := c.Initialize(); err != nil {
-			return nil, err
-		}
+	return c.GetClusterNodesWithContext(context.Background(), request)
+}
+
+func (c *yarnClient) GetClusterNodesWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+	var response *hadoopyarn.GetClusterNodesResponseProto
+	err := c.invoke(ctx, "GetClusterNodes", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.application.GetClusterNodeWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) GetDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.GetDelegationTokenRequestProto) (*hadoopcommon.GetDelegationTokenResponseProto, error) {
+	var response *hadoopcommon.GetDelegationTokenResponseProto
+	err := c.invoke(ctx, "GetDelegationToken", false, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.application.GetDelegationTokenWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RenewDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.RenewDelegationTokenRequestProto) (*hadoopcommon.RenewDelegationTokenResponseProto, error) {
+	var response *hadoopcommon.RenewDelegationTokenResponseProto
+	err := c.invoke(ctx, "RenewDelegationToken", false, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.application.RenewDelegationTokenWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) CancelDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.CancelDelegationTokenRequestProto) (*hadoopcommon.CancelDelegationTokenResponseProto, error) {
+	var response *hadoopcommon.CancelDelegationTokenResponseProto
+	err := c.invoke(ctx, "CancelDelegationToken", false, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.application.CancelDelegationTokenWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshQueuesWithContext(ctx context.Context, request *yarnserver.RefreshQueuesRequestProto) (*yarnserver.RefreshQueuesResponseProto, error) {
+	var response *yarnserver.RefreshQueuesResponseProto
+	err := c.invoke(ctx, "RefreshQueues", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshQueuesWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshNodesWithContext(ctx context.Context, request *yarnserver.RefreshNodesRequestProto) (*yarnserver.RefreshNodesResponseProto, error) {
+	var response *yarnserver.RefreshNodesResponseProto
+	err := c.invoke(ctx, "RefreshNodes", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshNodesWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshSuperUserGroupsConfigurationWithContext(ctx context.Context, request *yarnserver.RefreshSuperUserGroupsConfigurationRequestProto) (*yarnserver.RefreshSuperUserGroupsConfigurationResponseProto, error) {
+	var response *yarnserver.RefreshSuperUserGroupsConfigurationResponseProto
+	err := c.invoke(ctx, "RefreshSuperUserGroupsConfiguration", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshSuperUserGroupsConfigurationWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshUserToGroupsMappingsWithContext(ctx context.Context, request *yarnserver.RefreshUserToGroupsMappingsRequestProto) (*yarnserver.RefreshUserToGroupsMappingsResponseProto, error) {
+	var response *yarnserver.RefreshUserToGroupsMappingsResponseProto
+	err := c.invoke(ctx, "RefreshUserToGroupsMappings", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshUserToGroupsMappingsWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshAdminAclsWithContext(ctx context.Context, request *yarnserver.RefreshAdminAclsRequestProto) (*yarnserver.RefreshAdminAclsResponseProto, error) {
+	var response *yarnserver.RefreshAdminAclsResponseProto
+	err := c.invoke(ctx, "RefreshAdminAcls", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshAdminAclsWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshServiceAclsWithContext(ctx context.Context, request *yarnserver.RefreshServiceAclsRequestProto) (*yarnserver.RefreshServiceAclsResponseProto, error) {
+	var response *yarnserver.RefreshServiceAclsResponseProto
+	err := c.invoke(ctx, "RefreshServiceAcls", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshServiceAclsWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) GetGroupsForUserWithContext(ctx context.Context, request *yarnserver.GetGroupsForUserRequestProto) (*yarnserver.GetGroupsForUserResponseProto, error) {
+	var response *yarnserver.GetGroupsForUserResponseProto
+	err := c.invoke(ctx, "GetGroupsForUser", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.GetGroupsForUserWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshNodesResourcesWithContext(ctx context.Context, request *yarnserver.RefreshNodesResourcesRequestProto) (*yarnserver.RefreshNodesResourcesResponseProto, error) {
+	var response *yarnserver.RefreshNodesResourcesResponseProto
+	err := c.invoke(ctx, "RefreshNodesResources", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshNodesResourcesWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) AddToClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.AddToClusterNodeLabelsRequestProto) (*yarnserver.AddToClusterNodeLabelsResponseProto, error) {
+	var response *yarnserver.AddToClusterNodeLabelsResponseProto
+	err := c.invoke(ctx, "AddToClusterNodeLabels", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.AddToClusterNodeLabelsWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RemoveFromClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.RemoveFromClusterNodeLabelsRequestProto) (*yarnserver.RemoveFromClusterNodeLabelsResponseProto, error) {
+	var response *yarnserver.RemoveFromClusterNodeLabelsResponseProto
+	err := c.invoke(ctx, "RemoveFromClusterNodeLabels", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RemoveFromClusterNodeLabelsWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) ReplaceLabelsOnNodesWithContext(ctx context.Context, request *yarnserver.ReplaceLabelsOnNodeRequestProto) (*yarnserver.ReplaceLabelsOnNodeResponseProto, error) {
+	if f, err := c.getFederation(ctx); err != nil {
+		return nil, err
+	} else if f != nil {
+		return f.ReplaceLabelsOnNodesWithContext(ctx, request)
 	}
-	// TODO check response error code and retry auto
-	return c.getClusterNodes(request)
+	var response *yarnserver.ReplaceLabelsOnNodeResponseProto
+	err := c.invoke(ctx, "ReplaceLabelsOnNodes", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.ReplaceLabelsOnNodesWithContext(ctx, request)
+		return err
+	})
+	return response, err
 }
 
-func (c *yarnClient) GetActiveRMID() (string, error) {
-	rmIDs, err := c.conf.GetRMs()
-	if err != nil {
-		return "", err
+func (c *yarnClient) CheckForDecommissioningNodesWithContext(ctx context.Context, request *yarnserver.CheckForDecommissioningNodesRequestProto) (*yarnserver.CheckForDecommissioningNodesResponseProto, error) {
+	var response *yarnserver.CheckForDecommissioningNodesResponseProto
+	err := c.invoke(ctx, "CheckForDecommissioningNodes", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.CheckForDecommissioningNodesWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) RefreshClusterMaxPriorityWithContext(ctx context.Context, request *yarnserver.RefreshClusterMaxPriorityRequestProto) (*yarnserver.RefreshClusterMaxPriorityResponseProto, error) {
+	var response *yarnserver.RefreshClusterMaxPriorityResponseProto
+	err := c.invoke(ctx, "RefreshClusterMaxPriority", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.RefreshClusterMaxPriorityWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+func (c *yarnClient) MapAttributesToNodesWithContext(ctx context.Context, request *yarnserver.NodesToAttributesMappingRequestProto) (*yarnserver.NodesToAttributesMappingResponseProto, error) {
+	if f, err := c.getFederation(ctx); err != nil {
+		return nil, err
+	} else if f != nil {
+		return f.MapAttributesToNodesWithContext(ctx, request)
 	}
-	for _, rmID := range rmIDs {
-		rmAdminAddr, err := c.conf.GetRMAdminAddressByID(rmID)
-		if err != nil {
-			return "", err
-		}
-		haClient, err := CreateYarnHAClient(rmAdminAddr)
-		if err != nil {
-			return "", fmt.Errorf("create yarn %v ha client for %v failed %v", rmID, rmAdminAddr, err)
-		}
-		resp, err := haClient.GetServiceStatus(&hadoopcommon.GetServiceStatusRequestProto{})
-		if err != nil {
-			klog.V(4).Infof("get %v service status for %v failed %v, try next rm", rmID, rmAdminAddr, err)
-			continue
-		}
-		if resp.State != nil && *resp.State == hadoopcommon.HAServiceStateProto_ACTIVE {
-			return rmID, nil
-		}
+	var response *yarnserver.NodesToAttributesMappingResponseProto
+	err := c.invoke(ctx, "MapAttributesToNodes", true, func(ctx context.Context, clients *rmClients) error {
+		var err error
+		response, err = clients.admin.MapAttributesToNodesWithContext(ctx, request)
+		return err
+	})
+	return response, err
+}
+
+// ensureInitialized initializes the client if it is not initialized or is being reinitialized
+func (c *yarnClient) ensureInitialized(ctx context.Context) error {
+	c.mtx.RLock()
+	closed, needInit := c.closed, c.conf == nil || c.activeRMAdminAddress == nil
+	c.mtx.RUnlock()
+	if closed {
+		return c.errClosed()
 	}
-	return "", fmt.Errorf("active rm not found in %v", rmIDs)
+	if needInit {
+		return c.initialize(ctx)
+	}
+	return nil
 }
 
-func (c *yarnClient) updateNodeResource(request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
-	// TODO keep client alive instead of create every time
-	adminClient, err := CreateYarnAdminClient(c.conf, c.activeRMAdminAddress)
-	if err != nil {
+// getFederation returns the federation of cluster, which is nil if the cluster is not federated
+func (c *yarnClient) getFederation(ctx context.Context) (*federation, error) {
+	if err := c.ensureInitialized(ctx); err != nil {
 		return nil, err
 	}
-	return adminClient.UpdateNodeResource(request)
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.federation, nil
 }
 
-func (c *yarnClient) getClusterNodes(request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
-	// TODO keep client alive instead of create every time
-	applicationClient, err := CreateYarnApplicationClient(c.conf, c.activeRMAddress)
+// invoke calls fn with the clients of active rm until it succeeds or the retry policy gives up
+func (c *yarnClient) invoke(ctx context.Context, method string, idempotent bool, fn func(ctx context.Context, clients *rmClients) error) error {
+	if err := c.ensureInitialized(ctx); err != nil {
+		return err
+	}
+
+	c.mtx.RLock()
+	policy, haEnabled := c.activeRetryPolicy, c.haEnabled
+	c.mtx.RUnlock()
+
+	var failover func(fromIndex int)
+	if haEnabled {
+		failover = c.failover
+	}
+	return retryInvoke(ctx, fmt.Sprintf("%v of yarn cluster %v", method, c.clusterID), policy, idempotent,
+		c.activeRM, fn, failover)
+}
+
+// failover switches from rm fromIndex to the next rm in yarn.resourcemanager.ha.rm-ids, which is the same as
+// ConfiguredRMFailoverProxyProvider in hadoop. It does nothing if another call failed on the same rm has already
+// switched, so that concurrent failures do not switch back to the rm failed.
+func (c *yarnClient) failover(fromIndex int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.haEnabled || len(c.rmIDs) == 0 || c.activeRMIndex != fromIndex {
+		return
+	}
+	next := (c.activeRMIndex + 1) % len(c.rmIDs)
+	if err := c.setActiveRMByIndex(next); err != nil {
+		klog.Warningf("yarn cluster %v failed to fail over to rm %v, error %v", c.clusterID, c.rmIDs[next], err)
+		return
+	}
+	klog.V(3).Infof("yarn cluster %v fails over to rm %v", c.clusterID, c.rmIDs[next])
+}
+
+func (c *yarnClient) GetActiveRMID() (string, error) {
+	c.mtx.RLock()
+	conf, resolver := c.conf, c.activeRMResolver
+	c.mtx.RUnlock()
+	if resolver != nil {
+		return resolver.ActiveRMID(context.Background())
+	}
+	rmIDs, err := conf.GetRMs()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	return applicationClient.GetClusterNode(request)
+	return getActiveRMID(context.Background(), conf, rmIDs, c.ugi)
 }
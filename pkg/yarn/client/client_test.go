@@ -0,0 +1,380 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// writeYarnSite writes a yarn-site.xml of properties into a temp dir, and returns the dir
+func writeYarnSite(t *testing.T, properties map[string]string) string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	content := "<configuration>\n"
+	for _, name := range names {
+		content += fmt.Sprintf("  <property><name>%v</name><value>%v</value></property>\n", name, properties[name])
+	}
+	content += "</configuration>\n"
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "yarn-site.xml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// newBlackholeServer accepts connections and reads requests without ever responding
+func newBlackholeServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(io.Discard, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestCallsStopWithContext(t *testing.T) {
+	address := newBlackholeServer(t)
+	tests := []struct {
+		name       string
+		properties map[string]string
+	}{
+		{
+			name: "single rm",
+			properties: map[string]string{
+				"yarn.resourcemanager.address":       address,
+				"yarn.resourcemanager.admin.address": address,
+			},
+		},
+		{
+			name: "discovering active rm",
+			properties: map[string]string{
+				"yarn.resourcemanager.ha.enabled":        "true",
+				"yarn.resourcemanager.ha.rm-ids":         "rm1,rm2",
+				"yarn.resourcemanager.address.rm1":       address,
+				"yarn.resourcemanager.admin.address.rm1": address,
+				"yarn.resourcemanager.address.rm2":       address,
+				"yarn.resourcemanager.admin.address.rm2": address,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewYarnClient(writeYarnSite(t, tt.properties), "")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			_, err := c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+			assert.Error(t, err)
+			assert.Less(t, time.Since(start), 2*time.Second)
+
+			ctx, cancel = context.WithCancel(context.Background())
+			time.AfterFunc(200*time.Millisecond, cancel)
+			start = time.Now()
+			_, err = c.UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{})
+			assert.Error(t, err)
+			assert.Less(t, time.Since(start), 2*time.Second)
+
+			// a cancelled ctx fails immediately
+			start = time.Now()
+			_, err = c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+			assert.Error(t, err)
+			assert.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
+
+// rpcHandler answers a call of method with a response, or an exception if exceptionClassName is not empty
+type rpcHandler func(header *hadoop_common.RpcRequestHeaderProto, method string, param []byte) (response proto.Message, exceptionClassName string)
+
+// newFakeRPCServer serves calls with handler, without sasl
+func newFakeRPCServer(t *testing.T, handler rpcHandler) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeRPCConn(conn, handler)
+		}
+	}()
+	return listener
+}
+
+func serveFakeRPCConn(conn net.Conn, handler rpcHandler) {
+	defer conn.Close()
+	// connection header of hrpc, version, service class and auth protocol
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil || !bytes.Equal(header[:4], yarnauth.RPC_HEADER) {
+		return
+	}
+	for {
+		var length int32
+		lengthBytes := make([]byte, 4)
+		if _, err := io.ReadFull(conn, lengthBytes); err != nil {
+			return
+		}
+		if err := yarnauth.ConvertBytesToFixed(lengthBytes, &length); err != nil {
+			return
+		}
+		packet := make([]byte, length)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return
+		}
+		headerBytes, n := protowire.ConsumeBytes(packet)
+		if n < 0 {
+			return
+		}
+		rpcHeader := &hadoop_common.RpcRequestHeaderProto{}
+		if err := proto.Unmarshal(headerBytes, rpcHeader); err != nil {
+			return
+		}
+		if rpcHeader.GetCallId() == ipc.SASL_RPC_CALL_ID {
+			// the server is not secure, which accepts tokens of clients without authentication
+			success := hadoop_common.RpcSaslProto_SUCCESS
+			responseHeader := &hadoop_common.RpcResponseHeaderProto{CallId: proto.Uint32(uint32(rpcHeader.GetCallId())),
+				Status: hadoop_common.RpcResponseHeaderProto_SUCCESS.Enum()}
+			if _, err := conn.Write(newResponsePacket(responseHeader, &hadoop_common.RpcSaslProto{State: &success})); err != nil {
+				return
+			}
+			continue
+		} else if rpcHeader.GetCallId() < 0 {
+			// connection context
+			continue
+		}
+		packet = packet[n:]
+		requestHeaderBytes, n := protowire.ConsumeBytes(packet)
+		if n < 0 {
+			return
+		}
+		requestHeader := &hadoop_common.RequestHeaderProto{}
+		if err := proto.Unmarshal(requestHeaderBytes, requestHeader); err != nil {
+			return
+		}
+		param, _ := protowire.ConsumeBytes(packet[n:])
+
+		response, exceptionClassName := handler(rpcHeader, requestHeader.GetMethodName(), param)
+		status := hadoop_common.RpcResponseHeaderProto_SUCCESS
+		responseHeader := &hadoop_common.RpcResponseHeaderProto{CallId: proto.Uint32(uint32(rpcHeader.GetCallId())),
+			Status: &status, ClientId: rpcHeader.GetClientId()}
+		if exceptionClassName != "" {
+			status, errorCode := hadoop_common.RpcResponseHeaderProto_ERROR, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION
+			responseHeader.Status, responseHeader.ErrorDetail = &status, &errorCode
+			responseHeader.ExceptionClassName = proto.String(exceptionClassName)
+			responseHeader.ErrorMsg = proto.String(requestHeader.GetMethodName() + " failed")
+			response = nil
+		}
+		if _, err := conn.Write(newResponsePacket(responseHeader, response)); err != nil {
+			return
+		}
+	}
+}
+
+// fakeRM answers all calls with an empty response after a RetriableException for the first failures calls, and
+// records the rpc headers of all calls
+type fakeRM struct {
+	listener net.Listener
+	failures int
+
+	mtx     sync.Mutex
+	headers []*hadoop_common.RpcRequestHeaderProto
+}
+
+func newFakeRM(t *testing.T, failures int) *fakeRM {
+	rm := &fakeRM{failures: failures}
+	rm.listener = newFakeRPCServer(t, func(header *hadoop_common.RpcRequestHeaderProto, method string, param []byte) (proto.Message, string) {
+		rm.mtx.Lock()
+		defer rm.mtx.Unlock()
+		rm.headers = append(rm.headers, header)
+		if len(rm.headers) <= rm.failures {
+			return nil, ipc.RetriableException
+		}
+		return &hadoopyarn.GetClusterNodesResponseProto{}, ""
+	})
+	return rm
+}
+
+func (rm *fakeRM) requestHeaders() []*hadoop_common.RpcRequestHeaderProto {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	return append([]*hadoop_common.RpcRequestHeaderProto{}, rm.headers...)
+}
+
+func newResponsePacket(messages ...proto.Message) []byte {
+	var body []byte
+	for _, message := range messages {
+		if message == nil {
+			continue
+		}
+		b, _ := proto.Marshal(message)
+		body = protowire.AppendBytes(body, b)
+	}
+	length, _ := yarnauth.ConvertFixedToBytes(int32(len(body)))
+	return append(length, body...)
+}
+
+func TestInvokeRetryOnSameClient(t *testing.T) {
+	rm := newFakeRM(t, 2)
+	confDir := writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.address":       rm.listener.Addr().String(),
+		"yarn.resourcemanager.admin.address": rm.listener.Addr().String(),
+	})
+	c := NewYarnClient(confDir, "", WithRetryPolicy(NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+
+	_, err := c.GetClusterNodesWithContext(context.Background(), &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	_, err = c.GetClusterNodesWithContext(context.Background(), &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+
+	// the first call is retried with the same client id and call id, which the retry cache of rm relies on
+	headers := rm.requestHeaders()
+	assert.Len(t, headers, 4)
+	for i, header := range headers {
+		assert.Equal(t, headers[0].GetClientId(), header.GetClientId())
+		if i < 3 {
+			assert.Equal(t, headers[0].GetCallId(), header.GetCallId())
+			assert.Equal(t, int32(i), header.GetRetryCount())
+		}
+	}
+	assert.NotEqual(t, headers[0].GetCallId(), headers[3].GetCallId())
+	assert.Equal(t, int32(0), headers[3].GetRetryCount())
+}
+
+func TestInvokeWithoutHAFailsFast(t *testing.T) {
+	c := NewYarnClient(writeYarnSite(t, map[string]string{yarnconf.RM_HOSTNAME: "127.0.0.1"}), "").(*yarnClient)
+	connectErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	attempts := 0
+	err := c.invoke(context.Background(), "Test", true, func(ctx context.Context, clients *rmClients) error {
+		attempts++
+		return connectErr
+	})
+	assert.ErrorIs(t, err, connectErr)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestInvokeFailover(t *testing.T) {
+	conf, err := yarnconf.NewYarnConfiguration(writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.ha.enabled":        "true",
+		"yarn.resourcemanager.ha.rm-ids":         "rm1,rm2",
+		"yarn.resourcemanager.address.rm1":       "127.0.0.1:18032",
+		"yarn.resourcemanager.admin.address.rm1": "127.0.0.1:18033",
+		"yarn.resourcemanager.address.rm2":       "127.0.0.1:28032",
+		"yarn.resourcemanager.admin.address.rm2": "127.0.0.1:28033",
+	}), "")
+	assert.NoError(t, err)
+	c := &yarnClient{conf: conf, haEnabled: true, rmIDs: []string{"rm1", "rm2"},
+		activeRetryPolicy: NewFailoverOnNetworkExceptionPolicy(3, 0, 0, time.Millisecond, 10*time.Millisecond)}
+	assert.NoError(t, c.setActiveRMByIndex(0))
+
+	// calls failed on rm1 together switch to rm2 only once
+	var wg sync.WaitGroup
+	var mtx sync.Mutex
+	var addresses []string
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.invoke(context.Background(), "Test", true, func(ctx context.Context, clients *rmClients) error {
+				mtx.Lock()
+				defer mtx.Unlock()
+				address := clients.application.client.(*yarnservice.ApplicationClientProtocolServiceClient).ServerAddress
+				addresses = append(addresses, address)
+				if address == "127.0.0.1:18032" {
+					return &ipc.RpcError{Status: hadoop_common.RpcResponseHeaderProto_ERROR,
+						ExceptionClassName: ipc.StandbyException, ErrorCode: hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION}
+				}
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, c.activeRMIndex)
+	for _, address := range addresses {
+		assert.Contains(t, []string{"127.0.0.1:18032", "127.0.0.1:28032"}, address)
+	}
+
+	c.failover(0)
+	assert.Equal(t, 1, c.activeRMIndex)
+	c.failover(1)
+	assert.Equal(t, 0, c.activeRMIndex)
+}
+
+func TestAdminCallsGoToAdminAddress(t *testing.T) {
+	rm := newFakeRM(t, 1)
+	confDir := writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.address":       newBlackholeServer(t),
+		"yarn.resourcemanager.admin.address": rm.listener.Addr().String(),
+	})
+	c := NewYarnClient(confDir, "", WithRetryPolicy(NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.RefreshQueuesWithContext(ctx, &yarnserver.RefreshQueuesRequestProto{})
+	assert.NoError(t, err)
+	_, err = c.CheckForDecommissioningNodesWithContext(ctx, &yarnserver.CheckForDecommissioningNodesRequestProto{})
+	assert.NoError(t, err)
+
+	// admin operations are idempotent, so the failed refreshQueues is retried
+	headers := rm.requestHeaders()
+	assert.Len(t, headers, 3)
+	assert.Equal(t, int32(1), headers[1].GetRetryCount())
+}
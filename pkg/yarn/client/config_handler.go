@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// ConfigHandlerPath is the path served by the handler of NewConfigHandler
+const ConfigHandlerPath = "/debug/yarn/config/"
+
+type configHandler struct {
+	factory *ReloadingYarnClientFactory
+}
+
+// NewConfigHandler serves the effective configuration of the clusters of factory with sensitive values redacted:
+//   - GET /debug/yarn/config/ lists the cluster ids
+//   - GET /debug/yarn/config/<cluster id>?format=xml|json dumps the configuration of a cluster, which is json by
+//     default, the default cluster is DefaultClusterID
+//   - GET /debug/yarn/config/<cluster id>?key=<key> describes the value of key and where it is set
+func NewConfigHandler(factory *ReloadingYarnClientFactory) http.Handler {
+	return &configHandler{factory: factory}
+}
+
+func (h *configHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	clusterID := strings.Trim(strings.TrimPrefix(r.URL.Path, ConfigHandlerPath), "/")
+	if clusterID == "" {
+		h.writeJSON(w, sortedKeys(h.factory.Clients()))
+		return
+	}
+	conf, ok := h.factory.Configuration(clusterID)
+	if !ok {
+		http.Error(w, "yarn cluster "+clusterID+" not found", http.StatusNotFound)
+		return
+	}
+
+	if key := r.URL.Query().Get("key"); key != "" {
+		description, err := conf.Describe(key)
+		if errors.Is(err, yarnconf.ErrPropertyNotSet) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		} else if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.writeJSON(w, description)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = yarnconf.DUMP_FORMAT_JSON
+	}
+	contentTypes := map[string]string{
+		yarnconf.DUMP_FORMAT_XML:  "application/xml",
+		yarnconf.DUMP_FORMAT_JSON: "application/json",
+	}
+	if _, ok := contentTypes[format]; !ok {
+		http.Error(w, "unknown format "+format, http.StatusBadRequest)
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err := conf.Dump(buf, format); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[format])
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		klog.V(4).Infof("write config of yarn cluster %v failed, error %v", clusterID, err)
+	}
+}
+
+func (h *configHandler) writeJSON(w http.ResponseWriter, v interface{}) {
+	buf := &bytes.Buffer{}
+	e := json.NewEncoder(buf)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	if err := e.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		klog.V(4).Infof("write yarn config response failed, error %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakerm"
+)
+
+func TestConfigHandler(t *testing.T) {
+	confDir, f, rms := newTestReloadingFactory(t)
+	properties := rms["rm1"].YarnSite()
+	properties["yarn.resourcemanager.keystore.password"] = "secret"
+	assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "a", properties))
+	assert.NoError(t, f.Reload())
+	handler := NewConfigHandler(f)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := get(ConfigHandlerPath)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var clusterIDs []string
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &clusterIDs))
+	assert.Equal(t, []string{DefaultClusterID, "a"}, clusterIDs)
+
+	w = get(ConfigHandlerPath + "a?key=" + yarnconf.RM_ADDRESS)
+	assert.Equal(t, http.StatusOK, w.Code)
+	description := &yarnconf.PropertyDescription{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), description))
+	assert.Equal(t, rms["rm1"].Address(), description.Value)
+	assert.Equal(t, filepath.Join(confDir, "a.yarn-site.xml"), description.Resource)
+	assert.NotZero(t, description.Line)
+
+	w = get(ConfigHandlerPath + "a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"value": "<redacted>"`)
+	assert.NotContains(t, w.Body.String(), "secret")
+
+	w = get(ConfigHandlerPath + DefaultClusterID + "?format=xml")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "<value>"+rms["rm0"].Address()+"</value>")
+
+	assert.Equal(t, http.StatusNotFound, get(ConfigHandlerPath+"b").Code)
+	assert.Equal(t, http.StatusNotFound, get(ConfigHandlerPath+"a?key=not.set").Code)
+	assert.Equal(t, http.StatusBadRequest, get(ConfigHandlerPath+"a?format=yaml").Code)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+type YarnContainerManagementClient struct {
+	client yarnservice.ContainerManagementProtocolService
+}
+
+func CreateYarnContainerManagementClient(conf yarnconf.YarnConfiguration, nmAddress string, ugi *security.UserGroupInformation) (*YarnContainerManagementClient, error) {
+	c, err := yarnservice.DialContainerManagementProtocolService(conf, nmAddress, ugi)
+	return &YarnContainerManagementClient{client: c}, err
+}
+
+func (c *YarnContainerManagementClient) StartContainers(request *hadoopyarn.StartContainersRequestProto) (*hadoopyarn.StartContainersResponseProto, error) {
+	return c.StartContainersWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) StartContainersWithContext(ctx context.Context, request *hadoopyarn.StartContainersRequestProto) (*hadoopyarn.StartContainersResponseProto, error) {
+	response := &hadoopyarn.StartContainersResponseProto{}
+	err := c.client.StartContainersWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) StopContainers(request *hadoopyarn.StopContainersRequestProto) (*hadoopyarn.StopContainersResponseProto, error) {
+	return c.StopContainersWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) StopContainersWithContext(ctx context.Context, request *hadoopyarn.StopContainersRequestProto) (*hadoopyarn.StopContainersResponseProto, error) {
+	response := &hadoopyarn.StopContainersResponseProto{}
+	err := c.client.StopContainersWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) GetContainerStatuses(request *hadoopyarn.GetContainerStatusesRequestProto) (*hadoopyarn.GetContainerStatusesResponseProto, error) {
+	return c.GetContainerStatusesWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) GetContainerStatusesWithContext(ctx context.Context, request *hadoopyarn.GetContainerStatusesRequestProto) (*hadoopyarn.GetContainerStatusesResponseProto, error) {
+	response := &hadoopyarn.GetContainerStatusesResponseProto{}
+	err := c.client.GetContainerStatusesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) IncreaseContainersResource(request *hadoopyarn.IncreaseContainersResourceRequestProto) (*hadoopyarn.IncreaseContainersResourceResponseProto, error) {
+	return c.IncreaseContainersResourceWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) IncreaseContainersResourceWithContext(ctx context.Context, request *hadoopyarn.IncreaseContainersResourceRequestProto) (*hadoopyarn.IncreaseContainersResourceResponseProto, error) {
+	response := &hadoopyarn.IncreaseContainersResourceResponseProto{}
+	err := c.client.IncreaseContainersResourceWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) UpdateContainer(request *hadoopyarn.ContainerUpdateRequestProto) (*hadoopyarn.ContainerUpdateResponseProto, error) {
+	return c.UpdateContainerWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) UpdateContainerWithContext(ctx context.Context, request *hadoopyarn.ContainerUpdateRequestProto) (*hadoopyarn.ContainerUpdateResponseProto, error) {
+	response := &hadoopyarn.ContainerUpdateResponseProto{}
+	err := c.client.UpdateContainerWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) SignalToContainer(request *hadoopyarn.SignalContainerRequestProto) (*hadoopyarn.SignalContainerResponseProto, error) {
+	return c.SignalToContainerWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) SignalToContainerWithContext(ctx context.Context, request *hadoopyarn.SignalContainerRequestProto) (*hadoopyarn.SignalContainerResponseProto, error) {
+	response := &hadoopyarn.SignalContainerResponseProto{}
+	err := c.client.SignalToContainerWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) Localize(request *hadoopyarn.ResourceLocalizationRequestProto) (*hadoopyarn.ResourceLocalizationResponseProto, error) {
+	return c.LocalizeWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) LocalizeWithContext(ctx context.Context, request *hadoopyarn.ResourceLocalizationRequestProto) (*hadoopyarn.ResourceLocalizationResponseProto, error) {
+	response := &hadoopyarn.ResourceLocalizationResponseProto{}
+	err := c.client.LocalizeWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) ReInitializeContainer(request *hadoopyarn.ReInitializeContainerRequestProto) (*hadoopyarn.ReInitializeContainerResponseProto, error) {
+	return c.ReInitializeContainerWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) ReInitializeContainerWithContext(ctx context.Context, request *hadoopyarn.ReInitializeContainerRequestProto) (*hadoopyarn.ReInitializeContainerResponseProto, error) {
+	response := &hadoopyarn.ReInitializeContainerResponseProto{}
+	err := c.client.ReInitializeContainerWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) RestartContainer(request *hadoopyarn.ContainerIdProto) (*hadoopyarn.RestartContainerResponseProto, error) {
+	return c.RestartContainerWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) RestartContainerWithContext(ctx context.Context, request *hadoopyarn.ContainerIdProto) (*hadoopyarn.RestartContainerResponseProto, error) {
+	response := &hadoopyarn.RestartContainerResponseProto{}
+	err := c.client.RestartContainerWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) RollbackLastReInitialization(request *hadoopyarn.ContainerIdProto) (*hadoopyarn.RollbackResponseProto, error) {
+	return c.RollbackLastReInitializationWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) RollbackLastReInitializationWithContext(ctx context.Context, request *hadoopyarn.ContainerIdProto) (*hadoopyarn.RollbackResponseProto, error) {
+	response := &hadoopyarn.RollbackResponseProto{}
+	err := c.client.RollbackLastReInitializationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) CommitLastReInitialization(request *hadoopyarn.ContainerIdProto) (*hadoopyarn.CommitResponseProto, error) {
+	return c.CommitLastReInitializationWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) CommitLastReInitializationWithContext(ctx context.Context, request *hadoopyarn.ContainerIdProto) (*hadoopyarn.CommitResponseProto, error) {
+	response := &hadoopyarn.CommitResponseProto{}
+	err := c.client.CommitLastReInitializationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnContainerManagementClient) GetLocalizationStatuses(request *hadoopyarn.GetLocalizationStatusesRequestProto) (*hadoopyarn.GetLocalizationStatusesResponseProto, error) {
+	return c.GetLocalizationStatusesWithContext(context.Background(), request)
+}
+
+func (c *YarnContainerManagementClient) GetLocalizationStatusesWithContext(ctx context.Context, request *hadoopyarn.GetLocalizationStatusesRequestProto) (*hadoopyarn.GetLocalizationStatusesResponseProto, error) {
+	response := &hadoopyarn.GetLocalizationStatusesResponseProto{}
+	err := c.client.GetLocalizationStatusesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
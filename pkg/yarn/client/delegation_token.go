@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+var _ security.DelegationTokenClient = &rmDelegationTokenClient{}
+
+// rmDelegationTokenClient manages rm delegation tokens through the ApplicationClientProtocol of yarn client
+type rmDelegationTokenClient struct {
+	client YarnClient
+}
+
+func (c *rmDelegationTokenClient) GetDelegationToken(ctx context.Context, renewer string) (*hadoopcommon.TokenProto, error) {
+	response, err := c.client.GetDelegationTokenWithContext(ctx, &hadoopcommon.GetDelegationTokenRequestProto{Renewer: &renewer})
+	if err != nil {
+		return nil, err
+	}
+	return response.GetToken(), nil
+}
+
+func (c *rmDelegationTokenClient) RenewDelegationToken(ctx context.Context, token *hadoopcommon.TokenProto) (time.Time, error) {
+	response, err := c.client.RenewDelegationTokenWithContext(ctx, &hadoopcommon.RenewDelegationTokenRequestProto{Token: token})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(response.GetNewExpiryTime())), nil
+}
+
+func (c *rmDelegationTokenClient) CancelDelegationToken(ctx context.Context, token *hadoopcommon.TokenProto) error {
+	_, err := c.client.CancelDelegationTokenWithContext(ctx, &hadoopcommon.CancelDelegationTokenRequestProto{Token: token})
+	return err
+}
+
+// NewRMDelegationTokenManager creates a manager which keeps an rm delegation token of yarn cluster clusterID for
+// ugi, so that clients of ugi talk to rm with the token. rm only issues and renews tokens for users authenticated
+// by kerberos, so the token is managed by a client of the same identity as ugi without tokens. The current user is
+// used if ugi is nil.
+func NewRMDelegationTokenManager(confDir string, clusterID string, ugi *security.UserGroupInformation, renewer string) (*security.DelegationTokenManager, error) {
+	conf, err := yarnconf.NewYarnConfiguration(confDir, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	service, err := GetRMDelegationTokenService(conf)
+	if err != nil {
+		return nil, err
+	}
+	if ugi == nil {
+		ugi = security.GetCurrentUser()
+	}
+	client := NewYarnClient(confDir, clusterID, WithUser(ugi.WithoutTokens()))
+	return security.NewDelegationTokenManager(&rmDelegationTokenClient{client: client}, ugi, renewer, service), nil
+}
+
+// GetRMDelegationTokenService returns the service of rm delegation tokens like ClientRMProxy in hadoop, which is
+// the ip:port of rm, or the comma separated ip:port of all rms if ha is enabled, so that the token works after
+// failover.
+func GetRMDelegationTokenService(conf yarnconf.YarnConfiguration) (string, error) {
+	haEnabled, err := conf.GetRMEnabledHA()
+	if err != nil {
+		return "", err
+	}
+	if !haEnabled {
+		rmAddr, err := conf.GetRMAddress()
+		if err != nil {
+			return "", err
+		}
+		return ipc.BuildTokenService(rmAddr), nil
+	}
+
+	rmIDs, err := conf.GetRMs()
+	if err != nil {
+		return "", err
+	}
+	services := make([]string, 0, len(rmIDs))
+	for _, rmID := range rmIDs {
+		rmAddr, err := conf.GetRMAddressByID(rmID)
+		if err != nil {
+			return "", err
+		}
+		services = append(services, ipc.BuildTokenService(rmAddr))
+	}
+	return strings.Join(services, ","), nil
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+// ErrClientClosed is returned by the calls of a closed yarn client, e.g. the one replaced by
+// ReloadingYarnClientFactory, which should be taken from the factory again instead of being reinitialized
+var ErrClientClosed = errors.New("yarn client is closed")
+
+// NeedReinitialize returns whether the yarn client should look for the active rm again after err, which means the rm
+// is unreachable, in standby state or has closed the connection for a fatal error. Permission and bad request errors
+// are returned by any rm, so reinitializing does not help.
+func NeedReinitialize(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrClientClosed) {
+		return false
+	}
+	if ipc.IsAccessDenied(err) || ipc.IsBadRequest(err) {
+		return false
+	}
+	if !ipc.IsRpcError(err) {
+		// connection level errors
+		return true
+	}
+	return ipc.IsStandby(err) || ipc.IsFatal(err)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+func TestNeedReinitialize(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no error",
+			err:  nil,
+			want: false,
+		},
+		{
+			name: "connection error",
+			err:  errors.New("dial tcp 127.0.0.1:8033: connect: connection refused"),
+			want: true,
+		},
+		{
+			name: "client closed",
+			err:  fmt.Errorf("get cluster nodes failed: %w", ErrClientClosed),
+			want: false,
+		},
+		{
+			name: "context canceled",
+			err:  fmt.Errorf("call failed: %w", context.Canceled),
+			want: false,
+		},
+		{
+			name: "standby rm",
+			err: fmt.Errorf("wrapped: %w", &ipc.RpcError{
+				Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+				ExceptionClassName: ipc.StandbyException,
+				ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+			}),
+			want: true,
+		},
+		{
+			name: "access denied",
+			err: &ipc.RpcError{
+				Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+				ExceptionClassName: ipc.AccessControlException,
+				ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+			},
+			want: false,
+		},
+		{
+			name: "fatal unauthorized",
+			err: &ipc.RpcError{
+				Status:    hadoop_common.RpcResponseHeaderProto_FATAL,
+				ErrorCode: hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED,
+			},
+			want: false,
+		},
+		{
+			name: "no such method",
+			err: &ipc.RpcError{
+				Status:    hadoop_common.RpcResponseHeaderProto_ERROR,
+				ErrorCode: hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_METHOD,
+			},
+			want: false,
+		},
+		{
+			name: "fatal unknown",
+			err: &ipc.RpcError{
+				Status:    hadoop_common.RpcResponseHeaderProto_FATAL,
+				ErrorCode: hadoop_common.RpcResponseHeaderProto_FATAL_UNKNOWN,
+			},
+			want: true,
+		},
+		{
+			name: "application error",
+			err: &ipc.RpcError{
+				Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+				ExceptionClassName: "org.apache.hadoop.yarn.exceptions.YarnException",
+				ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NeedReinitialize(tt.err))
+		})
+	}
+}
@@ -31,7 +31,7 @@ func main() {
 	conf, _ := yarnconf.NewYarnConfiguration(os.Getenv("HADOOP_CONF_DIR"), "")
 
 	// Create YarnAdminClient
-	yarnAdminClient, _ := yarnclient.CreateYarnAdminClient(conf, nil)
+	yarnAdminClient, _ := yarnclient.CreateYarnAdminClient(conf, nil, nil)
 
 	host := "core-1-3.c-f55b4f620febfd69.cn-zhangjiakou.emr.aliyuncs.com"
 	port := int32(8041)
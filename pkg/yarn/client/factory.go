@@ -21,8 +21,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
 
 const (
@@ -35,16 +39,38 @@ type YarnClientFactory interface {
 	CreateDefaultYarnClient() (YarnClient, error)
 	CreateYarnClientByClusterID(clusterID string) (YarnClient, error)
 	CreateAllYarnClients() (map[string]YarnClient, error)
+	// SetClusterUser makes clients of clusterID talk to rm as ugi, DefaultClusterID is for the default cluster,
+	// clients of clusters without a user use the current user
+	SetClusterUser(clusterID string, ugi *security.UserGroupInformation)
 }
 
-var DefaultYarnClientFactory YarnClientFactory = &yarnClientFactory{configDir: os.Getenv(envHadoopConfDir)}
+var DefaultYarnClientFactory YarnClientFactory = NewYarnClientFactory(os.Getenv(envHadoopConfDir))
 
 type yarnClientFactory struct {
 	configDir string
+
+	mtx   sync.RWMutex
+	users map[string]*security.UserGroupInformation
+}
+
+func NewYarnClientFactory(configDir string) YarnClientFactory {
+	return &yarnClientFactory{configDir: configDir, users: map[string]*security.UserGroupInformation{}}
+}
+
+func (f *yarnClientFactory) SetClusterUser(clusterID string, ugi *security.UserGroupInformation) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.users[clusterID] = ugi
+}
+
+func (f *yarnClientFactory) clusterUser(clusterID string) *security.UserGroupInformation {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.users[clusterID]
 }
 
 func (f *yarnClientFactory) CreateDefaultYarnClient() (YarnClient, error) {
-	c := NewYarnClient(f.configDir, "")
+	c := NewYarnClient(f.configDir, "", WithUser(f.clusterUser(DefaultClusterID)))
 	if err := c.Initialize(); err != nil {
 		return nil, err
 	}
@@ -52,7 +78,7 @@ func (f *yarnClientFactory) CreateDefaultYarnClient() (YarnClient, error) {
 }
 
 func (f *yarnClientFactory) CreateYarnClientByClusterID(clusterID string) (YarnClient, error) {
-	c := NewYarnClient(f.configDir, clusterID)
+	c := NewYarnClient(f.configDir, clusterID, WithUser(f.clusterUser(clusterID)))
 	if err := c.Initialize(); err != nil {
 		return nil, err
 	}
@@ -85,8 +111,14 @@ func (f *yarnClientFactory) CreateAllYarnClients() (map[string]YarnClient, error
 }
 
 func (f *yarnClientFactory) getAllKnownClusterID() ([]string, error) {
+	return listClusterIDs(f.configDir)
+}
+
+// listClusterIDs returns the ids of clusters with <cluster id>.yarn-site.xml in configDir, except the sub-clusters of
+// federated clusters
+func listClusterIDs(configDir string) ([]string, error) {
 	res := []string{}
-	err := filepath.WalkDir(f.configDir, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(configDir, func(path string, d fs.DirEntry, err error) error {
 		if d.IsDir() {
 			return nil
 		}
@@ -98,5 +130,29 @@ func (f *yarnClientFactory) getAllKnownClusterID() ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	return res, nil
+	// sub-clusters of federated clusters are reached through their federated clusters, instead of being clusters
+	subClusters := map[string]bool{}
+	for _, id := range append([]string{""}, res...) {
+		conf, err := yarnconf.NewYarnConfiguration(configDir, id)
+		if err != nil {
+			continue
+		}
+		if enabled, err := conf.GetFederationEnabled(); err != nil || !enabled {
+			continue
+		}
+		subClusterIDs, err := conf.GetFederationSubClusterIDs()
+		if err != nil {
+			return nil, err
+		}
+		for _, subClusterID := range subClusterIDs {
+			subClusters[subClusterID] = true
+		}
+	}
+	clusterIDs := make([]string, 0, len(res))
+	for _, id := range res {
+		if !subClusters[id] {
+			clusterIDs = append(clusterIDs, id)
+		}
+	}
+	return clusterIDs, nil
 }
@@ -0,0 +1,210 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// federation sends the admin operations of nodes in a federated cluster to the rm of the sub-cluster owning the
+// nodes, since the router only forwards the admin operations of the whole cluster. Sub-clusters are yarn clients of
+// the same conf dir with the sub-cluster id as cluster id, so that the rm of a sub-cluster can be ha as well.
+type federation struct {
+	clusterID     string
+	subClusterIDs []string
+	// machineList is the sub-cluster of nodes by lower case host, which is read from yarn.federation.machine-list
+	machineList map[string]string
+
+	subClusters map[string]YarnClient
+
+	mtx sync.Mutex
+	// nodeSubClusters is the sub-cluster of nodes by lower case host, which is learned from the nodes of sub-clusters
+	// if the machine list is not configured
+	nodeSubClusters map[string]string
+}
+
+func newFederation(conf yarnconf.YarnConfiguration, confDir string, clusterID string, opts ...YarnClientOption) (*federation, error) {
+	subClusterIDs, err := conf.GetFederationSubClusterIDs()
+	if err != nil {
+		return nil, err
+	}
+	if len(subClusterIDs) == 0 {
+		return nil, fmt.Errorf("%v of federated cluster %v is empty", yarnconf.FEDERATION_SUBCLUSTER_IDS, clusterID)
+	}
+	f := &federation{clusterID: clusterID, subClusterIDs: subClusterIDs, subClusters: map[string]YarnClient{}}
+	machineListPath, err := conf.GetFederationMachineList()
+	if err != nil {
+		return nil, err
+	}
+	if machineListPath != "" {
+		if f.machineList, err = readMachineList(machineListPath); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range subClusterIDs {
+		f.subClusters[id] = NewYarnClient(confDir, id, opts...)
+	}
+	return f, nil
+}
+
+// readMachineList reads the lines of "<node>,<sub-cluster id>,<rack>", as DefaultSubClusterResolverImpl of hadoop
+func readMachineList(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	machineList := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid line %v of machine list %v, expecting <node>,<sub-cluster id>,<rack>", lineNum, path)
+		}
+		machineList[strings.ToLower(strings.TrimSpace(fields[0]))] = strings.TrimSpace(fields[1])
+	}
+	return machineList, scanner.Err()
+}
+
+// resolve returns the sub-cluster owning the node of host, the nodes of sub-clusters are listed again if host is
+// not known, e.g. a node newly added
+func (f *federation) resolve(ctx context.Context, host string) (string, error) {
+	host = strings.ToLower(host)
+	if f.machineList != nil {
+		if id, ok := f.machineList[host]; ok {
+			return id, nil
+		}
+		return "", fmt.Errorf("node %v is not in the machine list of federated cluster %v", host, f.clusterID)
+	}
+
+	f.mtx.Lock()
+	id, ok := f.nodeSubClusters[host]
+	f.mtx.Unlock()
+	if ok {
+		return id, nil
+	}
+	nodeSubClusters := map[string]string{}
+	for _, id := range f.subClusterIDs {
+		response, err := f.subCluster(id).GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+		if err != nil {
+			return "", fmt.Errorf("list nodes of sub-cluster %v failed, error %w", id, err)
+		}
+		for _, node := range response.GetNodeReports() {
+			nodeSubClusters[strings.ToLower(node.GetNodeId().GetHost())] = id
+		}
+	}
+	f.mtx.Lock()
+	f.nodeSubClusters = nodeSubClusters
+	f.mtx.Unlock()
+	if id, ok := nodeSubClusters[host]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("node %v is not found in sub-clusters %v of federated cluster %v", host, f.subClusterIDs, f.clusterID)
+}
+
+func (f *federation) subCluster(id string) YarnClient {
+	return f.subClusters[id]
+}
+
+func (f *federation) close() {
+	for _, c := range f.subClusters {
+		c.Close()
+	}
+}
+
+// groupBySubCluster groups items by the sub-cluster owning their nodes, and returns the sub-cluster ids in order
+func groupBySubCluster[T any](ctx context.Context, f *federation, items []T, host func(T) string) ([]string, map[string][]T, error) {
+	groups := map[string][]T{}
+	for _, item := range items {
+		id, err := f.resolve(ctx, host(item))
+		if err != nil {
+			return nil, nil, err
+		}
+		groups[id] = append(groups[id], item)
+	}
+	ids := make([]string, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, groups, nil
+}
+
+func (f *federation) UpdateNodeResourceWithContext(ctx context.Context, request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
+	ids, groups, err := groupBySubCluster(ctx, f, request.GetNodeResourceMap(), func(m *hadoopyarn.NodeResourceMapProto) string {
+		return m.GetNodeId().GetHost()
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		klog.V(5).Infof("update resource of %v nodes in sub-cluster %v of federated cluster %v", len(groups[id]), id, f.clusterID)
+		if _, err := f.subCluster(id).UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: groups[id]}); err != nil {
+			return nil, fmt.Errorf("update node resource in sub-cluster %v failed, error %w", id, err)
+		}
+	}
+	return &yarnserver.UpdateNodeResourceResponseProto{}, nil
+}
+
+func (f *federation) ReplaceLabelsOnNodesWithContext(ctx context.Context, request *yarnserver.ReplaceLabelsOnNodeRequestProto) (*yarnserver.ReplaceLabelsOnNodeResponseProto, error) {
+	ids, groups, err := groupBySubCluster(ctx, f, request.GetNodeToLabels(), func(m *hadoopyarn.NodeIdToLabelsProto) string {
+		return m.GetNodeId().GetHost()
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		subRequest := &yarnserver.ReplaceLabelsOnNodeRequestProto{NodeToLabels: groups[id], FailOnUnknownNodes: request.FailOnUnknownNodes}
+		if _, err := f.subCluster(id).ReplaceLabelsOnNodesWithContext(ctx, subRequest); err != nil {
+			return nil, fmt.Errorf("replace labels on nodes in sub-cluster %v failed, error %w", id, err)
+		}
+	}
+	return &yarnserver.ReplaceLabelsOnNodeResponseProto{}, nil
+}
+
+func (f *federation) MapAttributesToNodesWithContext(ctx context.Context, request *yarnserver.NodesToAttributesMappingRequestProto) (*yarnserver.NodesToAttributesMappingResponseProto, error) {
+	ids, groups, err := groupBySubCluster(ctx, f, request.GetNodeToAttributes(), func(m *hadoopyarn.NodeToAttributesProto) string {
+		return m.GetNode()
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		subRequest := &yarnserver.NodesToAttributesMappingRequestProto{Operation: request.Operation,
+			NodeToAttributes: groups[id], FailOnUnknownNodes: request.FailOnUnknownNodes}
+		if _, err := f.subCluster(id).MapAttributesToNodesWithContext(ctx, subRequest); err != nil {
+			return nil, fmt.Errorf("map attributes to nodes in sub-cluster %v failed, error %w", id, err)
+		}
+	}
+	return &yarnserver.NodesToAttributesMappingResponseProto{}, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakerm"
+)
+
+// newTestFederation starts the rm of router and sub-clusters sc1 with node-1 and sc2 with node-2, and returns the
+// conf dir of the federated cluster, the router is an rm with all nodes
+func newTestFederation(t *testing.T, properties map[string]string) (string, *fakerm.ResourceManager, map[string]*fakerm.ResourceManager) {
+	newRM := func(hosts ...string) *fakerm.ResourceManager {
+		rm, err := fakerm.NewResourceManager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(rm.Close)
+		for _, host := range hosts {
+			rm.AddNode(&hadoopyarn.NodeReportProto{
+				NodeId:     &hadoopyarn.NodeIdProto{Host: proto.String(host), Port: proto.Int32(8041)},
+				Capability: &hadoopyarn.ResourceProto{Memory: proto.Int64(8192), VirtualCores: proto.Int32(8)},
+			})
+		}
+		return rm
+	}
+	router := newRM("node-1", "node-2")
+	subClusters := map[string]*fakerm.ResourceManager{"sc1": newRM("node-1"), "sc2": newRM("node-2")}
+
+	confDir := t.TempDir()
+	yarnSite := map[string]string{
+		yarnconf.FEDERATION_ENABLED:        "true",
+		yarnconf.FEDERATION_SUBCLUSTER_IDS: "sc1, sc2",
+		yarnconf.ROUTER_CLIENTRM_ADDRESS:   router.Address(),
+		yarnconf.ROUTER_RMADMIN_ADDRESS:    router.AdminAddress(),
+	}
+	for k, v := range properties {
+		yarnSite[k] = v
+	}
+	assert.NoError(t, fakerm.WriteYarnSite(confDir, yarnSite))
+	for id, rm := range subClusters {
+		assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, id, rm.YarnSite()))
+	}
+	return confDir, router, subClusters
+}
+
+func newTestNodeResource(host string, vcores int32) *hadoopyarn.NodeResourceMapProto {
+	return &hadoopyarn.NodeResourceMapProto{
+		NodeId:         &hadoopyarn.NodeIdProto{Host: proto.String(host), Port: proto.Int32(8041)},
+		ResourceOption: &hadoopyarn.ResourceOptionProto{Resource: &hadoopyarn.ResourceProto{Memory: proto.Int64(4096), VirtualCores: proto.Int32(vcores)}},
+	}
+}
+
+func nodeVCores(t *testing.T, rm *fakerm.ResourceManager, host string) int32 {
+	node, ok := rm.Node(&hadoopyarn.NodeIdProto{Host: proto.String(host), Port: proto.Int32(8041)})
+	assert.True(t, ok)
+	return node.GetCapability().GetVirtualCores()
+}
+
+func TestFederationRouting(t *testing.T) {
+	confDir, router, subClusters := newTestFederation(t, nil)
+	c := NewYarnClient(confDir, "", WithRetryPolicy(NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// calls of the cluster go through the router
+	nodes, err := c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Len(t, nodes.GetNodeReports(), 2)
+	assert.Equal(t, 1, router.CallCount("getClusterNodes"))
+	_, err = c.RefreshQueuesWithContext(ctx, &yarnserver.RefreshQueuesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, router.CallCount("refreshQueues"))
+
+	// node resources are updated by the rm of sub-clusters owning the nodes
+	_, err = c.UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{
+		newTestNodeResource("node-1", 4), newTestNodeResource("node-2", 2)}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, router.CallCount("updateNodeResource"))
+	assert.Equal(t, int32(4), nodeVCores(t, subClusters["sc1"], "node-1"))
+	assert.Equal(t, int32(2), nodeVCores(t, subClusters["sc2"], "node-2"))
+
+	// nodes of sub-clusters are listed again only for unknown nodes
+	_, err = c.UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{
+		newTestNodeResource("node-1", 6)}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, subClusters["sc1"].CallCount("getClusterNodes"))
+	_, err = c.UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{
+		newTestNodeResource("node-3", 6)}})
+	assert.ErrorContains(t, err, "node node-3 is not found in sub-clusters [sc1 sc2]")
+	assert.Equal(t, 2, subClusters["sc1"].CallCount("getClusterNodes"))
+}
+
+func TestFederationMachineList(t *testing.T) {
+	machineList := filepath.Join(t.TempDir(), "machine-list")
+	assert.NoError(t, os.WriteFile(machineList, []byte("node-1, sc1, rack1\n\nnode-2, sc2, rack2\n"), 0644))
+	confDir, _, subClusters := newTestFederation(t, map[string]string{yarnconf.FEDERATION_MACHINE_LIST: machineList})
+	c := NewYarnClient(confDir, "", WithRetryPolicy(NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+	defer c.Close()
+
+	_, err := c.UpdateNodeResourceWithContext(context.Background(), &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{
+		newTestNodeResource("node-2", 2)}})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), nodeVCores(t, subClusters["sc2"], "node-2"))
+	assert.Equal(t, 0, subClusters["sc2"].CallCount("getClusterNodes"))
+
+	_, err = c.UpdateNodeResourceWithContext(context.Background(), &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{
+		newTestNodeResource("node-3", 2)}})
+	assert.ErrorContains(t, err, "node node-3 is not in the machine list")
+}
+
+func TestFactorySkipsSubClusters(t *testing.T) {
+	confDir, _, _ := newTestFederation(t, nil)
+	assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "other", map[string]string{}))
+	ids, err := NewYarnClientFactory(confDir).(*yarnClientFactory).getAllKnownClusterID()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"other"}, ids)
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// FenceFunc fences an rm which failed to become standby gracefully during failover, e.g. by killing its process,
+// it must return nil only if the rm is sure to be not active anymore
+type FenceFunc func(ctx context.Context, rmID string) error
+
+type YarnHAAdminOption func(a *YarnHAAdmin)
+
+// WithForceManual allows manual transitions when automatic failover is enabled, the same as --forcemanual of
+// `yarn rmadmin`. It may lead to two active rms if the elector of rm makes another decision at the same time.
+func WithForceManual() YarnHAAdminOption {
+	return func(a *YarnHAAdmin) {
+		a.forceManual = true
+	}
+}
+
+// WithFencer makes Failover fence the rm failed over from with fence if it can not be transitioned to standby
+func WithFencer(fence FenceFunc) YarnHAAdminOption {
+	return func(a *YarnHAAdmin) {
+		a.fence = fence
+	}
+}
+
+// YarnHAAdmin manages the ha state of rms in yarn.resourcemanager.ha.rm-ids, as the ha commands of `yarn rmadmin`
+type YarnHAAdmin struct {
+	conf        yarnconf.YarnConfiguration
+	clusterID   string
+	rmIDs       []string
+	ugi         *security.UserGroupInformation
+	forceManual bool
+	fence       FenceFunc
+}
+
+// NewYarnHAAdmin creates an ha admin of cluster talking to rms as ugi, or the current user if ugi is nil
+func NewYarnHAAdmin(confDir string, clusterID string, ugi *security.UserGroupInformation, opts ...YarnHAAdminOption) (*YarnHAAdmin, error) {
+	conf, err := yarnconf.NewYarnConfiguration(confDir, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	if haEnabled, err := conf.GetRMEnabledHA(); err != nil {
+		return nil, err
+	} else if !haEnabled {
+		return nil, fmt.Errorf("ha of yarn cluster %v is not enabled", clusterID)
+	}
+	rmIDs, err := conf.GetRMs()
+	if err != nil {
+		return nil, err
+	}
+	a := &YarnHAAdmin{conf: conf, clusterID: clusterID, rmIDs: rmIDs, ugi: ugi}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+func (a *YarnHAAdmin) haClient(rmID string) (*YarnHAClient, error) {
+	found := false
+	for _, id := range a.rmIDs {
+		found = found || id == rmID
+	}
+	if !found {
+		return nil, fmt.Errorf("rm %v not found in %v of yarn cluster %v", rmID, a.rmIDs, a.clusterID)
+	}
+	rmAdminAddr, err := a.conf.GetRMAdminAddressByID(rmID)
+	if err != nil {
+		return nil, err
+	}
+	return CreateYarnHAClient(a.conf, rmAdminAddr, a.ugi)
+}
+
+// checkManualAllowed refuses manual transitions if the rms fail over automatically, unless forced
+func (a *YarnHAAdmin) checkManualAllowed() error {
+	autoFailover, err := a.conf.GetRMAutoFailoverEnabled()
+	if err != nil {
+		return err
+	}
+	if autoFailover && !a.forceManual {
+		return fmt.Errorf("manual ha transition of yarn cluster %v is disallowed since %v is enabled",
+			a.clusterID, yarnconf.AUTO_FAILOVER_ENABLED)
+	}
+	return nil
+}
+
+func (a *YarnHAAdmin) requestInfo() *hadoopcommon.HAStateChangeRequestInfoProto {
+	source := hadoopcommon.HARequestSource_REQUEST_BY_USER
+	if a.forceManual {
+		source = hadoopcommon.HARequestSource_REQUEST_BY_USER_FORCED
+	}
+	return &hadoopcommon.HAStateChangeRequestInfoProto{ReqSource: &source}
+}
+
+func (a *YarnHAAdmin) GetServiceStatus(ctx context.Context, rmID string) (*hadoopcommon.GetServiceStatusResponseProto, error) {
+	c, err := a.haClient(rmID)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetServiceStatusWithContext(ctx, &hadoopcommon.GetServiceStatusRequestProto{})
+}
+
+// MonitorHealth returns nil if rm is healthy, the same as `yarn rmadmin -checkHealth`
+func (a *YarnHAAdmin) MonitorHealth(ctx context.Context, rmID string) error {
+	c, err := a.haClient(rmID)
+	if err != nil {
+		return err
+	}
+	_, err = c.MonitorHealthWithContext(ctx, &hadoopcommon.MonitorHealthRequestProto{})
+	return err
+}
+
+// TransitionToActive makes rm active, it refuses if any other rm is active already
+func (a *YarnHAAdmin) TransitionToActive(ctx context.Context, rmID string) error {
+	if err := a.checkManualAllowed(); err != nil {
+		return err
+	}
+	for _, other := range a.rmIDs {
+		if other == rmID {
+			continue
+		}
+		status, err := a.GetServiceStatus(ctx, other)
+		if err != nil {
+			// unreachable rms are not active for clients either
+			klog.V(4).Infof("get service status of rm %v of yarn cluster %v failed %v", other, a.clusterID, err)
+			continue
+		}
+		if status.GetState() == hadoopcommon.HAServiceStateProto_ACTIVE {
+			return fmt.Errorf("rm %v of yarn cluster %v is already active, fail over from it instead", other, a.clusterID)
+		}
+	}
+	return a.transitionToActive(ctx, rmID)
+}
+
+func (a *YarnHAAdmin) transitionToActive(ctx context.Context, rmID string) error {
+	c, err := a.haClient(rmID)
+	if err != nil {
+		return err
+	}
+	_, err = c.TransitionToActiveWithContext(ctx, &hadoopcommon.TransitionToActiveRequestProto{ReqInfo: a.requestInfo()})
+	return err
+}
+
+func (a *YarnHAAdmin) TransitionToStandby(ctx context.Context, rmID string) error {
+	if err := a.checkManualAllowed(); err != nil {
+		return err
+	}
+	return a.transitionToStandby(ctx, rmID)
+}
+
+func (a *YarnHAAdmin) transitionToStandby(ctx context.Context, rmID string) error {
+	c, err := a.haClient(rmID)
+	if err != nil {
+		return err
+	}
+	_, err = c.TransitionToStandbyWithContext(ctx, &hadoopcommon.TransitionToStandbyRequestProto{ReqInfo: a.requestInfo()})
+	return err
+}
+
+// TransitionToObserver makes rm an observer, which is rejected by rms not supporting observers
+func (a *YarnHAAdmin) TransitionToObserver(ctx context.Context, rmID string) error {
+	if err := a.checkManualAllowed(); err != nil {
+		return err
+	}
+	c, err := a.haClient(rmID)
+	if err != nil {
+		return err
+	}
+	_, err = c.TransitionToObserverWithContext(ctx, &hadoopcommon.TransitionToObserverRequestProto{ReqInfo: a.requestInfo()})
+	return err
+}
+
+// Failover makes rm to active instead of rm from, the same as FailoverController of hadoop:
+// 1. rm to must be a healthy standby ready to become active,
+// 2. rm from is transitioned to standby, or fenced if it fails and a fencer is configured,
+// 3. rm to is transitioned to active, and rm from is transitioned back to active if it fails without fencing,
+// 4. rm to must be active at last.
+func (a *YarnHAAdmin) Failover(ctx context.Context, from, to string) error {
+	if err := a.checkManualAllowed(); err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("can not fail over from rm %v to itself", from)
+	}
+
+	status, err := a.GetServiceStatus(ctx, to)
+	if err != nil {
+		return fmt.Errorf("get service status of rm %v failed %w", to, err)
+	}
+	if status.GetState() != hadoopcommon.HAServiceStateProto_STANDBY {
+		return fmt.Errorf("rm %v can not become active since it is %v", to, status.GetState())
+	}
+	if !status.GetReadyToBecomeActive() {
+		return fmt.Errorf("rm %v is not ready to become active: %v", to, status.GetNotReadyReason())
+	}
+	if err := a.MonitorHealth(ctx, to); err != nil {
+		return fmt.Errorf("rm %v is not healthy %w", to, err)
+	}
+
+	fenced := false
+	if err := a.transitionToStandby(ctx, from); err != nil {
+		if a.fence == nil {
+			return fmt.Errorf("transition rm %v to standby failed %w, and no fencer is configured", from, err)
+		}
+		klog.Warningf("transition rm %v of yarn cluster %v to standby failed %v, fence it", from, a.clusterID, err)
+		if err := a.fence(ctx, from); err != nil {
+			return fmt.Errorf("fence rm %v failed %w", from, err)
+		}
+		fenced = true
+	}
+
+	if err := a.transitionToActive(ctx, to); err != nil {
+		if fenced {
+			return fmt.Errorf("transition rm %v to active failed %w, rm %v has been fenced", to, err, from)
+		}
+		if rollbackErr := a.transitionToActive(ctx, from); rollbackErr != nil {
+			return fmt.Errorf("transition rm %v to active failed %w, and transition rm %v back to active failed %v",
+				to, err, from, rollbackErr)
+		}
+		return fmt.Errorf("transition rm %v to active failed %w, rm %v is active again", to, err, from)
+	}
+
+	status, err = a.GetServiceStatus(ctx, to)
+	if err != nil {
+		return fmt.Errorf("verify rm %v is active failed %w", to, err)
+	}
+	if status.GetState() != hadoopcommon.HAServiceStateProto_ACTIVE {
+		return fmt.Errorf("rm %v is %v after transition to active", to, status.GetState())
+	}
+	klog.V(3).Infof("yarn cluster %v fails over from rm %v to rm %v", a.clusterID, from, to)
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+// fakeHARM keeps the ha state of an rm
+type fakeHARM struct {
+	addr string
+
+	mtx             sync.Mutex
+	state           hadoop_common.HAServiceStateProto
+	unhealthy       bool
+	failTransitions bool
+	sources         []hadoop_common.HARequestSource
+}
+
+func newFakeHARM(t *testing.T, state hadoop_common.HAServiceStateProto) *fakeHARM {
+	rm := &fakeHARM{state: state}
+	listener := newFakeRPCServer(t, func(header *hadoop_common.RpcRequestHeaderProto, method string, param []byte) (proto.Message, string) {
+		rm.mtx.Lock()
+		defer rm.mtx.Unlock()
+		switch method {
+		case "getServiceStatus":
+			return &hadoop_common.GetServiceStatusResponseProto{State: rm.state.Enum(), ReadyToBecomeActive: proto.Bool(!rm.unhealthy)}, ""
+		case "monitorHealth":
+			if rm.unhealthy {
+				return nil, "org.apache.hadoop.ha.HealthCheckFailedException"
+			}
+			return &hadoop_common.MonitorHealthResponseProto{}, ""
+		case "transitionToActive", "transitionToStandby":
+			request := &hadoop_common.TransitionToActiveRequestProto{}
+			if err := proto.Unmarshal(param, request); err != nil {
+				return nil, "java.io.IOException"
+			}
+			rm.sources = append(rm.sources, request.GetReqInfo().GetReqSource())
+			if rm.failTransitions {
+				return nil, "org.apache.hadoop.ha.ServiceFailedException"
+			}
+			rm.state = hadoop_common.HAServiceStateProto_ACTIVE
+			if method == "transitionToStandby" {
+				rm.state = hadoop_common.HAServiceStateProto_STANDBY
+			}
+			return &hadoop_common.TransitionToActiveResponseProto{}, ""
+		}
+		return nil, "org.apache.hadoop.ipc.RpcNoSuchMethodException"
+	})
+	rm.addr = listener.Addr().String()
+	return rm
+}
+
+func (rm *fakeHARM) getState() hadoop_common.HAServiceStateProto {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	return rm.state
+}
+
+func newTestHAAdmin(t *testing.T, rm1, rm2 *fakeHARM, autoFailover string, opts ...YarnHAAdminOption) *YarnHAAdmin {
+	confDir := writeYarnSite(t, map[string]string{
+		"yarn.resourcemanager.ha.enabled":                    "true",
+		"yarn.resourcemanager.ha.rm-ids":                     "rm1,rm2",
+		"yarn.resourcemanager.ha.automatic-failover.enabled": autoFailover,
+		"yarn.resourcemanager.admin.address.rm1":             rm1.addr,
+		"yarn.resourcemanager.admin.address.rm2":             rm2.addr,
+	})
+	admin, err := NewYarnHAAdmin(confDir, "", nil, opts...)
+	assert.NoError(t, err)
+	return admin
+}
+
+func TestYarnHAAdminFailover(t *testing.T) {
+	ctx := context.Background()
+	active, standby := hadoop_common.HAServiceStateProto_ACTIVE, hadoop_common.HAServiceStateProto_STANDBY
+
+	t.Run("failover", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		admin := newTestHAAdmin(t, rm1, rm2, "false")
+		assert.NoError(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, standby, rm1.getState())
+		assert.Equal(t, active, rm2.getState())
+		assert.Equal(t, []hadoop_common.HARequestSource{hadoop_common.HARequestSource_REQUEST_BY_USER}, rm2.sources)
+
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"), "rm1 is not standby")
+		assert.Error(t, admin.Failover(ctx, "rm2", "rm2"))
+		assert.Error(t, admin.Failover(ctx, "rm2", "rm3"))
+	})
+
+	t.Run("automatic failover", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		admin := newTestHAAdmin(t, rm1, rm2, "true")
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, active, rm1.getState())
+
+		admin = newTestHAAdmin(t, rm1, rm2, "true", WithForceManual())
+		assert.NoError(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, []hadoop_common.HARequestSource{hadoop_common.HARequestSource_REQUEST_BY_USER_FORCED}, rm2.sources)
+	})
+
+	t.Run("unhealthy target", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		rm2.unhealthy = true
+		admin := newTestHAAdmin(t, rm1, rm2, "false")
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, active, rm1.getState())
+		assert.Empty(t, rm1.sources)
+	})
+
+	t.Run("fence", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		rm1.failTransitions = true
+		admin := newTestHAAdmin(t, rm1, rm2, "false")
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, standby, rm2.getState())
+
+		var fenced []string
+		admin = newTestHAAdmin(t, rm1, rm2, "false", WithFencer(func(ctx context.Context, rmID string) error {
+			fenced = append(fenced, rmID)
+			return nil
+		}))
+		assert.NoError(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, []string{"rm1"}, fenced)
+		assert.Equal(t, active, rm2.getState())
+	})
+
+	t.Run("fence failed", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		rm1.failTransitions = true
+		admin := newTestHAAdmin(t, rm1, rm2, "false", WithFencer(func(ctx context.Context, rmID string) error {
+			return errors.New("fence failed")
+		}))
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, standby, rm2.getState())
+	})
+
+	t.Run("roll back", func(t *testing.T) {
+		rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+		rm2.failTransitions = true
+		admin := newTestHAAdmin(t, rm1, rm2, "false")
+		assert.Error(t, admin.Failover(ctx, "rm1", "rm2"))
+		assert.Equal(t, active, rm1.getState())
+		assert.Len(t, rm1.sources, 2)
+	})
+}
+
+func TestYarnHAAdminTransitions(t *testing.T) {
+	ctx := context.Background()
+	active, standby := hadoop_common.HAServiceStateProto_ACTIVE, hadoop_common.HAServiceStateProto_STANDBY
+	rm1, rm2 := newFakeHARM(t, active), newFakeHARM(t, standby)
+	admin := newTestHAAdmin(t, rm1, rm2, "false")
+
+	status, err := admin.GetServiceStatus(ctx, "rm1")
+	assert.NoError(t, err)
+	assert.Equal(t, active, status.GetState())
+	assert.NoError(t, admin.MonitorHealth(ctx, "rm2"))
+
+	// only one rm can be active
+	assert.Error(t, admin.TransitionToActive(ctx, "rm2"))
+	assert.Equal(t, standby, rm2.getState())
+	assert.NoError(t, admin.TransitionToStandby(ctx, "rm1"))
+	assert.NoError(t, admin.TransitionToActive(ctx, "rm2"))
+	assert.Equal(t, active, rm2.getState())
+	assert.Error(t, admin.TransitionToObserver(ctx, "rm1"))
+}
@@ -17,22 +17,82 @@ limitations under the License.
 package client
 
 import (
+	"context"
+
 	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
 
 type YarnHAClient struct {
 	client yarnservice.HAServiceProtocolService
 }
 
-func CreateYarnHAClient(rmAddress string) (*YarnHAClient, error) {
-	c, err := yarnservice.DialHAServiceProtocolService(rmAddress)
+func CreateYarnHAClient(conf yarnconf.YarnConfiguration, rmAddress string, ugi *security.UserGroupInformation) (*YarnHAClient, error) {
+	c, err := yarnservice.DialHAServiceProtocolService(conf, rmAddress, ugi)
 	return &YarnHAClient{client: c}, err
 }
 
+func (c *YarnHAClient) MonitorHealth(request *hadoopcommon.MonitorHealthRequestProto) (*hadoopcommon.MonitorHealthResponseProto, error) {
+	return c.MonitorHealthWithContext(context.Background(), request)
+}
+
+func (c *YarnHAClient) MonitorHealthWithContext(ctx context.Context, request *hadoopcommon.MonitorHealthRequestProto) (*hadoopcommon.MonitorHealthResponseProto, error) {
+	response := &hadoopcommon.MonitorHealthResponseProto{}
+	err := c.client.MonitorHealthWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnHAClient) TransitionToActive(request *hadoopcommon.TransitionToActiveRequestProto) (*hadoopcommon.TransitionToActiveResponseProto, error) {
+	return c.TransitionToActiveWithContext(context.Background(), request)
+}
+
+func (c *YarnHAClient) TransitionToActiveWithContext(ctx context.Context, request *hadoopcommon.TransitionToActiveRequestProto) (*hadoopcommon.TransitionToActiveResponseProto, error) {
+	response := &hadoopcommon.TransitionToActiveResponseProto{}
+	err := c.client.TransitionToActiveWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnHAClient) TransitionToStandby(request *hadoopcommon.TransitionToStandbyRequestProto) (*hadoopcommon.TransitionToStandbyResponseProto, error) {
+	return c.TransitionToStandbyWithContext(context.Background(), request)
+}
+
+func (c *YarnHAClient) TransitionToStandbyWithContext(ctx context.Context, request *hadoopcommon.TransitionToStandbyRequestProto) (*hadoopcommon.TransitionToStandbyResponseProto, error) {
+	response := &hadoopcommon.TransitionToStandbyResponseProto{}
+	err := c.client.TransitionToStandbyWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnHAClient) TransitionToObserver(request *hadoopcommon.TransitionToObserverRequestProto) (*hadoopcommon.TransitionToObserverResponseProto, error) {
+	return c.TransitionToObserverWithContext(context.Background(), request)
+}
+
+func (c *YarnHAClient) TransitionToObserverWithContext(ctx context.Context, request *hadoopcommon.TransitionToObserverRequestProto) (*hadoopcommon.TransitionToObserverResponseProto, error) {
+	response := &hadoopcommon.TransitionToObserverResponseProto{}
+	err := c.client.TransitionToObserverWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (c *YarnHAClient) GetServiceStatus(request *hadoopcommon.GetServiceStatusRequestProto) (*hadoopcommon.GetServiceStatusResponseProto, error) {
+	return c.GetServiceStatusWithContext(context.Background(), request)
+}
+
+func (c *YarnHAClient) GetServiceStatusWithContext(ctx context.Context, request *hadoopcommon.GetServiceStatusRequestProto) (*hadoopcommon.GetServiceStatusResponseProto, error) {
 	response := &hadoopcommon.GetServiceStatusResponseProto{}
-	err := c.client.GetServiceStatus(request, response)
+	err := c.client.GetServiceStatusWithContext(ctx, request, response)
 	if err != nil {
 		return nil, err
 	}
@@ -20,11 +20,13 @@ package ipc
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	gouuid "github.com/nu7hatch/gouuid"
@@ -38,26 +40,89 @@ import (
 )
 
 const (
-	connDefaultTimeout = 10 * time.Second
-	rwDefaultTimeout   = 5 * time.Second
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultRPCTimeout     = 5 * time.Second
 )
 
+// connMaxIdleTime is the same as the default of ipc.client.connection.maxidletime in hadoop
+var connMaxIdleTime = 10 * time.Second
+
 type Client struct {
-	ClientId      *gouuid.UUID
-	Ugi           *hadoop_common.UserInformationProto
+	ClientId *gouuid.UUID
+	// Ugi is the identity of client, the current user of process is used if not set
+	Ugi           *security.UserGroupInformation
 	ServerAddress string
 	TCPNoDelay    bool
+	// ConnectTimeout bounds dialing the server, DefaultConnectTimeout is used if not set
+	ConnectTimeout time.Duration
+	// RPCTimeout bounds a call from sending the request to receiving the response, DefaultRPCTimeout is used if not set
+	RPCTimeout time.Duration
+	// AuthMethod is AUTH_KERBEROS if hadoop.security.authentication is kerberos, otherwise tokens or simple auth are used
+	AuthMethod yarnauth.AuthMethod
+	// ServerPrincipal is the kerberos principal of server like rm/_HOST@REALM, which is required by AUTH_KERBEROS
+	ServerPrincipal string
+	// TokenService is the logical name of ha servers for selecting tokens, e.g. yarn.resourcemanager.cluster-id,
+	// which is tried before the ip:port of ServerAddress
+	TokenService string
+	// Protection is the qops allowed by hadoop.rpc.protection, the strongest one supported by server is used,
+	// only auth is allowed if not set
+	Protection []security.QOP
 }
 
+// connection is a long-lived socket to a server shared by all clients with the same connection_id,
+// calls are multiplexed by callId and responses are dispatched by the receiveResponses goroutine
 type connection struct {
-	con *net.TCPConn
+	id     connection_id
+	con    *net.TCPConn
+	reader *bufio.Reader
+	// sasl wraps all packets after the SASL negotiation if integrity or privacy is negotiated
+	sasl security.SaslWrapper
+
+	// rpcTimeout bounds reading the rest of a response once its first byte is received
+	rpcTimeout time.Duration
+
+	setupMtx sync.Mutex
+	writeMtx sync.Mutex
+
+	mtx          sync.Mutex
+	calls        map[int32]*call
+	closed       bool
+	closeErr     error
+	lastActivity time.Time
 }
 
+// connection_id is the key of pooled connections like ConnectionId in hadoop, the settings of the client setting up
+// a connection are part of the key, so that clients with different timeouts or security do not share connections
 type connection_id struct {
-	user     string
+	// user is compared by identity like the ticket of ConnectionId in hadoop, since users with the same name may
+	// have different credentials
+	user     *security.UserGroupInformation
 	protocol string
 	address  string
-	ClientId gouuid.UUID
+
+	connectTimeout  time.Duration
+	rpcTimeout      time.Duration
+	tcpNoDelay      bool
+	authMethod      yarnauth.AuthMethod
+	serverPrincipal string
+	tokenService    string
+	// protection is the qops of client in order, which is a string to keep connection_id comparable
+	protection string
+}
+
+func newConnectionId(c *Client, protocol string) connection_id {
+	return connection_id{
+		user:            c.user(),
+		protocol:        protocol,
+		address:         c.ServerAddress,
+		connectTimeout:  c.connectTimeout(),
+		rpcTimeout:      c.rpcTimeout(),
+		tcpNoDelay:      c.TCPNoDelay,
+		authMethod:      c.AuthMethod,
+		serverPrincipal: c.ServerPrincipal,
+		tokenService:    c.TokenService,
+		protection:      fmt.Sprint(c.Protection),
+	}
 }
 
 type call struct {
@@ -65,8 +130,19 @@ type call struct {
 	procedure proto.Message
 	request   proto.Message
 	response  proto.Message
-	// err        *error
+	// client which issued the call, used for checking the clientId of response
+	client     *Client
 	retryCount int32
+
+	done chan struct{}
+	err  error
+}
+
+func (c *Client) user() *security.UserGroupInformation {
+	if c.Ugi != nil {
+		return c.Ugi
+	}
+	return security.GetCurrentUser()
 }
 
 func (c *Client) String() string {
@@ -83,143 +159,365 @@ var (
 	SASL_RPC_INVALID_RETRY_COUNT int32  = -1
 )
 
+var (
+	ErrConnectionClosed = errors.New("connection closed")
+	ErrCallTimeout      = errors.New("call timeout")
+)
+
+// callIdCounter is shared by all connections like the one in hadoop ipc client, so that a call id is unique in process
+var callIdCounter atomic.Int32
+
+// NextCallId allocates a call id, which is used by callers retrying a call with WithRetry
+func NextCallId() int32 {
+	return callIdCounter.Add(1) & 0x7FFFFFFF
+}
+
+type retryKey struct{}
+
+type retryInfo struct {
+	callId     int32
+	retryCount int32
+}
+
+// WithRetry returns a context for sending a call as the retryCount-th retry of call callId, so that the server
+// can recognize the retried call as RetryInvocationHandler does in hadoop. The first attempt has a retryCount 0.
+func WithRetry(ctx context.Context, callId, retryCount int32) context.Context {
+	return context.WithValue(ctx, retryKey{}, retryInfo{callId: callId, retryCount: retryCount})
+}
+
+func (c *Client) connectTimeout() time.Duration {
+	if c.ConnectTimeout > 0 {
+		return c.ConnectTimeout
+	}
+	return DefaultConnectTimeout
+}
+
+func (c *Client) rpcTimeout() time.Duration {
+	if c.RPCTimeout > 0 {
+		return c.RPCTimeout
+	}
+	return DefaultRPCTimeout
+}
+
 func (c *Client) Call(rpc *hadoop_common.RequestHeaderProto, rpcRequest proto.Message, rpcResponse proto.Message) error {
+	return c.CallWithContext(context.Background(), rpc, rpcRequest, rpcResponse)
+}
+
+// CallWithContext is the same as Call, but it stops waiting for the response once ctx is cancelled or
+// its deadline passes, whichever comes earlier than the RPCTimeout of client.
+func (c *Client) CallWithContext(ctx context.Context, rpc *hadoop_common.RequestHeaderProto, rpcRequest proto.Message, rpcResponse proto.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.rpcTimeout())
+	defer cancel()
+
 	// Create connection_id
-	connectionId := connection_id{
-		user:     *c.Ugi.RealUser,
-		protocol: *rpc.DeclaringClassProtocolName,
-		address:  c.ServerAddress,
-		ClientId: *c.ClientId,
+	connectionId := newConnectionId(c, *rpc.DeclaringClassProtocolName)
+
+	// Create call
+	rpcCall := &call{callId: NextCallId(), procedure: rpc, request: rpcRequest, response: rpcResponse, client: c,
+		retryCount: yarnauth.RPC_DEFAULT_RETRY_COUNT, done: make(chan struct{})}
+	if retry, ok := ctx.Value(retryKey{}).(retryInfo); ok {
+		rpcCall.callId, rpcCall.retryCount = retry.callId, retry.retryCount
 	}
 
 	// Get connection to server
 	klog.V(5).Infof("Connecting... %v", c)
-	conn, err := getConnection(c, &connectionId)
+	conn, err := getConnection(ctx, c, &connectionId)
 	if err != nil {
 		return err
 	}
-
-	// Create call and send request
-	rpcCall := call{callId: 0, procedure: rpc, request: rpcRequest, response: rpcResponse}
-	err = sendRequest(c, conn, &rpcCall)
+	if err = conn.addCall(rpcCall); errors.Is(err, ErrConnectionClosed) {
+		// the pooled connection is closed after getConnection, e.g. by being idle, nothing has been sent yet
+		klog.V(4).Infof("connection to %v is closed before call %v, reconnect, error %v", c.ServerAddress, rpcCall.callId, err)
+		if conn, err = getConnection(ctx, c, &connectionId); err != nil {
+			return err
+		}
+		err = conn.addCall(rpcCall)
+	}
 	if err != nil {
-		klog.Warningf("sendRequest", err)
 		return err
 	}
 
-	// Read & return response
-	err = c.readResponse(conn, &rpcCall)
-
-	// TODO keep connection alive for reuse
-	conn.con.Close()
+	// Send request
+	err = sendRequest(ctx, c, conn, rpcCall)
+	if err != nil {
+		klog.Warningf("sendRequest %v", err)
+		// the stream may be broken after a partial write, so the connection cannot be reused
+		conn.close(err)
+		return err
+	}
 
-	return err
+	// Wait for the response dispatched by receiveResponses
+	select {
+	case <-rpcCall.done:
+		return rpcCall.err
+	case <-ctx.Done():
+		if conn.removeCall(rpcCall.callId) == nil {
+			// the response is being read into rpcResponse, which must not be touched after returning
+			<-rpcCall.done
+			return rpcCall.err
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%w: call %v to %v, %v", ErrCallTimeout, rpcCall.callId, c.ServerAddress, ctx.Err())
+		}
+		return ctx.Err()
+	}
 }
 
-//var connectionPool = struct {
-//	sync.RWMutex
-//	connections map[connection_id]*connection
-//}{connections: make(map[connection_id]*connection)}
+var connectionPool = struct {
+	sync.Mutex
+	connections map[connection_id]*connection
+}{connections: make(map[connection_id]*connection)}
 
-func findUsableTokenForService(service string) (*hadoop_common.TokenProto, bool) {
-	userTokens := security.GetCurrentUser().GetUserTokens()
-
-	klog.V(5).Infof("looking for token for service: %s\n", service)
-
-	if len(userTokens) == 0 {
+// selectToken returns the token of user for the protocol of connection, which is selected by the token kind of
+// protocol and the service of server like SaslRpcClient in hadoop
+func selectToken(c *Client, connectionId *connection_id) (*hadoop_common.TokenProto, bool) {
+	kind := security.TokenKindForProtocol(connectionId.protocol)
+	if kind == "" {
 		return nil, false
 	}
+	for _, service := range c.tokenServices() {
+		klog.V(5).Infof("looking for %v token for service: %s", kind, service)
+		if token, found := connectionId.user.SelectToken(kind, service); found {
+			return token, true
+		}
+	}
+	return nil, false
+}
 
-	token := userTokens[service]
-	if token != nil {
-		return token, true
+// tokenServices returns the services of tokens for the server, which are TokenService if set, the ip:port of server
+// like SecurityUtil.buildTokenService in hadoop, and the host:port for tokens not using ip
+func (c *Client) tokenServices() []string {
+	var services []string
+	if c.TokenService != "" {
+		services = append(services, c.TokenService)
+	}
+	if service := BuildTokenService(c.ServerAddress); service != c.ServerAddress {
+		services = append(services, service)
 	}
+	return append(services, c.ServerAddress)
+}
 
-	return nil, false
+// BuildTokenService returns the ip:port of address like SecurityUtil.buildTokenService in hadoop, address is
+// returned as is if it cannot be resolved
+func BuildTokenService(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return address
+	}
+	if addrs, err := net.LookupHost(host); err == nil && len(addrs) > 0 {
+		return net.JoinHostPort(addrs[0], port)
+	}
+	return address
 }
 
-func getConnection(c *Client, connectionId *connection_id) (*connection, error) {
-	// Try to re-use an existing connection
-	//connectionPool.RLock()
-	//con := connectionPool.connections[*connectionId]
-	//connectionPool.RUnlock()
+func getConnection(ctx context.Context, c *Client, connectionId *connection_id) (*connection, error) {
+	// Try to re-use an existing connection, otherwise save a new one in the connection-pool
+	connectionPool.Lock()
+	con, exist := connectionPool.connections[*connectionId]
+	if !exist || con.isClosed() {
+		con = &connection{id: *connectionId, calls: map[int32]*call{}, rpcTimeout: c.rpcTimeout()}
+		connectionPool.connections[*connectionId] = con
+	}
+	connectionPool.Unlock()
 
-	// If necessary, create a new connection and save it in the connection-pool
-	//var err error
-	//if con == nil {
-	con, err := setupConnection(c)
-	if err != nil {
-		klog.Warningf("Couldn't setup connection: %v", err)
+	// Setup outside the pool lock, so that a dead server does not block connections to others
+	if err := con.setup(ctx, c); err != nil {
 		return nil, err
 	}
+	return con, nil
+}
 
-	//connectionPool.Lock()
-	//connectionPool.connections[*connectionId] = con
-	//connectionPool.Unlock()
+func (con *connection) setup(ctx context.Context, c *Client) error {
+	con.setupMtx.Lock()
+	defer con.setupMtx.Unlock()
+
+	con.mtx.Lock()
+	closed, closeErr := con.closed, con.closeErr
+	con.mtx.Unlock()
+	if closed {
+		return closeErr
+	} else if con.con != nil {
+		return nil
+	}
+
+	if err := setupConnection(ctx, c, con); err != nil {
+		klog.Warningf("Couldn't setup connection: %v", err)
+		con.close(err)
+		return err
+	}
 
 	var authProtocol yarnauth.AuthProtocol = yarnauth.AUTH_PROTOCOL_NONE
 
-	if _, found := findUsableTokenForService(c.ServerAddress); found {
+	if _, found := selectToken(c, &con.id); found {
 		klog.V(4).Infof("found token for service: %s", c.ServerAddress)
 		authProtocol = yarnauth.AUTH_PROTOCOL_SASL
+	} else if c.AuthMethod == yarnauth.AUTH_KERBEROS {
+		authProtocol = yarnauth.AUTH_PROTOCOL_SASL
 	}
 
-	err = writeConnectionHeader(con, authProtocol)
+	err := writeConnectionHeader(con, authProtocol)
 	if err != nil {
-		return nil, err
+		con.close(err)
+		return err
 	}
 
+	authMethod := yarnauth.AUTH_SIMPLE
 	if authProtocol == yarnauth.AUTH_PROTOCOL_SASL {
 		klog.V(4).Infof("attempting SASL negotiation.")
 
-		if err = negotiateSimpleTokenAuth(c, con); err != nil {
+		if authMethod, con.sasl, err = negotiateSaslAuth(c, con); err != nil {
 			klog.Warningf("failed to complete SASL negotiation!")
-			return nil, err
+			con.close(err)
+			return err
 		}
 
 	} else {
 		klog.V(5).Infof("no usable tokens. proceeding without auth.")
 	}
 
-	err = writeConnectionContext(c, con, connectionId, authProtocol)
+	err = writeConnectionContext(c, con, &con.id, authMethod)
 	if err != nil {
-		return nil, err
+		con.close(err)
+		return err
 	}
-	//}
 
-	return con, nil
+	if err = con.con.SetDeadline(time.Time{}); err != nil {
+		con.close(err)
+		return err
+	}
+	con.reader = bufio.NewReader(con.con)
+	if con.sasl != nil {
+		con.reader = bufio.NewReader(&saslReader{con: con, raw: con.reader})
+	}
+	con.touch()
+	go con.receiveResponses()
+	return nil
 }
 
-func setupConnection(c *Client) (*connection, error) {
-	d := net.Dialer{Timeout: connDefaultTimeout}
-	conn, err := d.Dial("tcp", c.ServerAddress)
+func setupConnection(ctx context.Context, c *Client, con *connection) error {
+	d := net.Dialer{Timeout: c.connectTimeout()}
+	conn, err := d.DialContext(ctx, "tcp", c.ServerAddress)
 	if err != nil {
 		klog.V(4).Infof("error: %v", err)
-		return nil, err
+		return err
 	} else {
 		klog.V(5).Infof("setup connection success %v", c)
 	}
 
 	tcpConn, ok := conn.(*net.TCPConn)
 	if !ok {
-		return nil, fmt.Errorf("net.TCPConn type assert failed")
+		conn.Close()
+		return fmt.Errorf("net.TCPConn type assert failed")
 	}
 
-	// TODO: Ping thread
-
 	// Set tcp no-delay
 	err = tcpConn.SetNoDelay(c.TCPNoDelay)
 	if err != nil {
-		return nil, err
+		tcpConn.Close()
+		return err
 	}
 
-	return &connection{tcpConn}, nil
+	con.con = tcpConn
+	// the handshake must be finished before the deadline of the call which sets up the connection
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(c.rpcTimeout())
+	}
+	return con.con.SetDeadline(deadline)
 }
 
-func writeConnectionHeader(conn *connection, authProtocol yarnauth.AuthProtocol) error {
-	if err := conn.con.SetDeadline(time.Now().Add(rwDefaultTimeout)); err != nil {
-		return err
+func (con *connection) addCall(rpcCall *call) error {
+	con.mtx.Lock()
+	defer con.mtx.Unlock()
+	if con.closed {
+		return connectionClosedError(con.closeErr)
+	}
+	con.calls[rpcCall.callId] = rpcCall
+	return nil
+}
+
+func (con *connection) removeCall(callId int32) *call {
+	con.mtx.Lock()
+	defer con.mtx.Unlock()
+	rpcCall, exist := con.calls[callId]
+	if !exist {
+		return nil
+	}
+	delete(con.calls, callId)
+	con.lastActivity = time.Now()
+	return rpcCall
+}
+
+func (con *connection) touch() {
+	con.mtx.Lock()
+	defer con.mtx.Unlock()
+	con.lastActivity = time.Now()
+}
+
+func (con *connection) isClosed() bool {
+	con.mtx.Lock()
+	defer con.mtx.Unlock()
+	return con.closed
+}
+
+// closeIfIdle closes the connection if there is no call in flight for connMaxIdleTime
+func (con *connection) closeIfIdle() bool {
+	return con.shutdown(ErrConnectionClosed, true)
+}
+
+// close shuts down the socket, removes the connection from pool and fails all calls in flight with err
+func (con *connection) close(err error) {
+	con.shutdown(err, false)
+}
+
+// shutdown closes the connection, the idleness is checked with the closing under the same lock if onlyIfIdle,
+// so that no call is added in between
+func (con *connection) shutdown(err error, onlyIfIdle bool) bool {
+	con.mtx.Lock()
+	if con.closed || (onlyIfIdle && (len(con.calls) > 0 || time.Since(con.lastActivity) < connMaxIdleTime)) {
+		con.mtx.Unlock()
+		return false
 	}
+	if onlyIfIdle {
+		klog.V(5).Infof("close idle connection to %v", con.id.address)
+	}
+	con.closed = true
+	con.closeErr = err
+	calls := con.calls
+	con.calls = map[int32]*call{}
+	con.mtx.Unlock()
+
+	connectionPool.Lock()
+	if connectionPool.connections[con.id] == con {
+		delete(connectionPool.connections, con.id)
+	}
+	connectionPool.Unlock()
+
+	if con.con != nil {
+		con.con.Close()
+	}
+	for _, rpcCall := range calls {
+		rpcCall.err = connectionClosedError(err)
+		close(rpcCall.done)
+	}
+	return true
+}
+
+// connectionClosedError wraps both ErrConnectionClosed and the cause of closing, so that a fatal RpcError closing
+// the connection is still recognized by the calls failed for it
+func connectionClosedError(cause error) error {
+	if cause == nil {
+		return ErrConnectionClosed
+	} else if errors.Is(cause, ErrConnectionClosed) {
+		return cause
+	}
+	return fmt.Errorf("%w: %w", ErrConnectionClosed, cause)
+}
+
+func writeConnectionHeader(conn *connection, authProtocol yarnauth.AuthProtocol) error {
 	// RPC_HEADER
 	if _, err := conn.con.Write(yarnauth.RPC_HEADER); err != nil {
 		klog.Warningf("conn.Write yarnauth.RPC_HEADER %v", err)
@@ -253,22 +551,19 @@ func writeConnectionHeader(conn *connection, authProtocol yarnauth.AuthProtocol)
 	return nil
 }
 
-func writeConnectionContext(c *Client, conn *connection, connectionId *connection_id, authProtocol yarnauth.AuthProtocol) error {
-	if err := conn.con.SetDeadline(time.Now().Add(rwDefaultTimeout)); err != nil {
-		return err
-	}
+func writeConnectionContext(c *Client, conn *connection, connectionId *connection_id, authMethod yarnauth.AuthMethod) error {
 	// Create hadoop_common.IpcConnectionContextProto
-	ugi, _ := yarnauth.CreateSimpleUGIProto()
-	ipcCtxProto := hadoop_common.IpcConnectionContextProto{UserInfo: ugi, Protocol: &connectionId.protocol}
+	var userInfo *hadoop_common.UserInformationProto
+	if authMethod != yarnauth.AUTH_TOKEN {
+		// the user of token is authenticated by the token identifier, so it is not sent like ProtoUtil in hadoop
+		userInfo = connectionId.user.GetUserInformation()
+	}
+	ipcCtxProto := hadoop_common.IpcConnectionContextProto{UserInfo: userInfo, Protocol: &connectionId.protocol}
 
 	// Create RpcRequestHeaderProto
 	var callId int32 = -3
 	var clientId [16]byte = [16]byte(*c.ClientId)
 
-	/*if (authProtocol == yarnauth.AUTH_PROTOCOL_SASL) {
-	  callId = SASL_RPC_CALL_ID
-	}*/
-
 	rpcReqHeaderProto := hadoop_common.RpcRequestHeaderProto{RpcKind: &yarnauth.RPC_PROTOCOL_BUFFFER, RpcOp: &yarnauth.RPC_FINAL_PACKET, CallId: &callId, ClientId: clientId[0:16], RetryCount: &yarnauth.RPC_DEFAULT_RETRY_COUNT}
 
 	rpcReqHeaderProtoBytes, err := proto.Marshal(&rpcReqHeaderProto)
@@ -283,27 +578,67 @@ func writeConnectionContext(c *Client, conn *connection, connectionId *connectio
 		return err
 	}
 
-	totalLength := len(rpcReqHeaderProtoBytes) + sizeVarint(len(rpcReqHeaderProtoBytes)) + len(ipcCtxProtoBytes) + sizeVarint(len(ipcCtxProtoBytes))
-	var tLen int32 = int32(totalLength)
-	totalLengthBytes, err := yarnauth.ConvertFixedToBytes(tLen)
-
+	packet, err := newPacket(rpcReqHeaderProtoBytes, ipcCtxProtoBytes)
 	if err != nil {
-		klog.Warningf("ConvertFixedToBytes(totalLength) %v", err)
 		return err
-	} else if _, err := conn.con.Write(totalLengthBytes); err != nil {
-		klog.Warningf("conn.con.Write(totalLengthBytes) %v", err)
+	}
+	// the connection context is wrapped as well if a security layer is negotiated
+	if err := conn.write(packet); err != nil {
+		klog.Warningf("conn.write(connectionContext) %v", err)
 		return err
 	}
 
-	if err := writeDelimitedBytes(conn, rpcReqHeaderProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, rpcReqHeaderProtoBytes) %v", err)
-		return err
+	return nil
+}
+
+// newPacket assembles the length-prefixed packet of delimited messages
+func newPacket(messages ...[]byte) ([]byte, error) {
+	totalLength := 0
+	for _, message := range messages {
+		totalLength += len(message) + sizeVarint(len(message))
+	}
+	totalLengthBytes, err := yarnauth.ConvertFixedToBytes(int32(totalLength))
+	if err != nil {
+		klog.Warningf("ConvertFixedToBytes(totalLength) %v", err)
+		return nil, err
 	}
-	if err := writeDelimitedBytes(conn, ipcCtxProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, ipcCtxProtoBytes) %v", err)
+
+	packet := bytes.NewBuffer(make([]byte, 0, 4+totalLength))
+	packet.Write(totalLengthBytes)
+	for _, message := range messages {
+		packet.Write(protowire.AppendVarint(nil, uint64(len(message))))
+		packet.Write(message)
+	}
+	return packet.Bytes(), nil
+}
+
+// write sends the packet to server, the packet is wrapped into SASL WRAP messages if a security layer is negotiated
+func (con *connection) write(packet []byte) error {
+	if con.sasl == nil {
+		_, err := con.con.Write(packet)
 		return err
 	}
 
+	saslWrapState := hadoop_common.RpcSaslProto_WRAP
+	for len(packet) > 0 {
+		chunk := packet
+		if maxSize := con.sasl.MaxWrapSize(); maxSize > 0 && len(chunk) > maxSize {
+			chunk = chunk[:maxSize]
+		}
+		packet = packet[len(chunk):]
+
+		token, err := con.sasl.Wrap(chunk)
+		if err != nil {
+			return err
+		}
+		saslPacket, err := newSaslPacket(&hadoop_common.RpcSaslProto{State: &saslWrapState, Token: token})
+		if err != nil {
+			return err
+		}
+		if _, err := con.con.Write(saslPacket); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -318,13 +653,9 @@ func sizeVarint(x int) (n int) {
 	return n
 }
 
-func sendRequest(c *Client, conn *connection, rpcCall *call) error {
+func sendRequest(ctx context.Context, c *Client, conn *connection, rpcCall *call) error {
 	klog.V(5).Infof("About to call RPC: %v", rpcCall.procedure)
 
-	if err := conn.con.SetDeadline(time.Now().Add(rwDefaultTimeout)); err != nil {
-		return err
-	}
-
 	// 0. RpcRequestHeaderProto
 	var clientId [16]byte = [16]byte(*c.ClientId)
 	rpcReqHeaderProto := hadoop_common.RpcRequestHeaderProto{RpcKind: &yarnauth.RPC_PROTOCOL_BUFFFER, RpcOp: &yarnauth.RPC_FINAL_PACKET, CallId: &rpcCall.callId, ClientId: clientId[0:16], RetryCount: &rpcCall.retryCount}
@@ -350,93 +681,172 @@ func sendRequest(c *Client, conn *connection, rpcCall *call) error {
 		return err
 	}
 
-	totalLength := len(rpcReqHeaderProtoBytes) + sizeVarint(len(rpcReqHeaderProtoBytes)) + len(requestHeaderProtoBytes) + sizeVarint(len(requestHeaderProtoBytes)) + len(paramProtoBytes) + sizeVarint(len(paramProtoBytes))
-	var tLen int32 = int32(totalLength)
-	if totalLengthBytes, err := yarnauth.ConvertFixedToBytes(tLen); err != nil {
-		klog.Warningf("ConvertFixedToBytes(totalLength) %v", err)
+	// Assemble the whole packet first, so that concurrent calls on the connection are never interleaved
+	packet, err := newPacket(rpcReqHeaderProtoBytes, requestHeaderProtoBytes, paramProtoBytes)
+	if err != nil {
 		return err
-	} else {
-		if _, err := conn.con.Write(totalLengthBytes); err != nil {
-			klog.Warningf("conn.con.Write(totalLengthBytes) %v", err)
-			return err
-		}
 	}
 
-	if err := writeDelimitedBytes(conn, rpcReqHeaderProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, rpcReqHeaderProtoBytes) %v", err)
+	conn.writeMtx.Lock()
+	defer conn.writeMtx.Unlock()
+	deadline, _ := ctx.Deadline()
+	if err := conn.con.SetWriteDeadline(deadline); err != nil {
 		return err
 	}
-	if err := writeDelimitedBytes(conn, requestHeaderProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, requestHeaderProtoBytes) %v", err)
-		return err
-	}
-	if err := writeDelimitedBytes(conn, paramProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, paramProtoBytes) %v", err)
+	if err := conn.write(packet); err != nil {
+		klog.Warningf("conn.write(packet) %v", err)
 		return err
 	}
 
-	klog.V(5).Infof("Succesfully sent request of length: ", totalLength)
+	klog.V(5).Infof("Succesfully sent request of length: %v", len(packet))
 
 	return nil
 }
 
-//func writeDelimitedTo(conn *connection, msg proto.Message) error {
-//	msgBytes, err := proto.Marshal(msg)
-//	if err != nil {
-//		klog.Warningf("proto.Marshal(msg)", err)
-//		return err
-//	}
-//	return writeDelimitedBytes(conn, msgBytes)
-//}
+// receiveResponses reads responses from the connection until it is closed, and dispatches them to calls by callId
+func (con *connection) receiveResponses() {
+	for {
+		responseBytes, err := con.readResponse()
+		if isTimeout(err) && con.closeIfIdle() {
+			return
+		} else if isTimeout(err) {
+			continue
+		} else if err != nil {
+			klog.V(4).Infof("read response from %v failed, close connection, error %v", con.id.address, err)
+			con.close(err)
+			return
+		}
 
-func writeDelimitedBytes(conn *connection, data []byte) error {
-	if _, err := conn.con.Write(protowire.AppendVarint(nil, uint64(len(data)))); err != nil {
-		klog.Warningf("conn.con.Write(proto.EncodeVarint(uint64(len(data)))) %v", err)
-		return err
-	}
-	if _, err := conn.con.Write(data); err != nil {
-		klog.Warningf("conn.con.Write(data) %v", err)
-		return err
-	}
+		// Parse RpcResponseHeaderProto
+		rpcResponseHeaderProto := hadoop_common.RpcResponseHeaderProto{}
+		off, err := readDelimited(responseBytes, &rpcResponseHeaderProto)
+		if err != nil {
+			klog.Warningf("readDelimited(responseBytes, rpcResponseHeaderProto) %v", err)
+			con.close(err)
+			return
+		}
+		klog.V(5).Infof("Received rpcResponseHeaderProto = %v", rpcResponseHeaderProto.String())
 
-	return nil
+		rpcCall := con.removeCall(int32(rpcResponseHeaderProto.GetCallId()))
+		if rpcCall == nil {
+			// the call has been abandoned by caller, e.g. timeout
+			klog.V(4).Infof("drop response of unknown call %v from %v", rpcResponseHeaderProto.GetCallId(), con.id.address)
+		} else {
+			rpcCall.err = rpcCall.client.readResponse(&rpcResponseHeaderProto, responseBytes[off:], rpcCall)
+			close(rpcCall.done)
+		}
+
+		if rpcResponseHeaderProto.GetStatus() == hadoop_common.RpcResponseHeaderProto_FATAL {
+			// server closes the connection after fatal errors
+			con.close(newRpcError(&rpcResponseHeaderProto))
+			return
+		}
+	}
 }
 
-func (c *Client) readResponse(conn *connection, rpcCall *call) error {
+// readResponse reads one length-prefixed packet from the connection
+func (con *connection) readResponse() ([]byte, error) {
+	// Wait for the next response, a timeout without any data means the connection is idle
+	if err := con.con.SetReadDeadline(time.Now().Add(connMaxIdleTime)); err != nil {
+		return nil, err
+	}
+	if _, err := con.reader.Peek(1); err != nil {
+		return nil, err
+	}
+	if err := con.con.SetReadDeadline(time.Now().Add(con.rpcTimeout)); err != nil {
+		return nil, err
+	}
+
 	// Read first 4 bytes to get total-length
 	var totalLength int32 = -1
 	var totalLengthBytes [4]byte
-	if _, err := conn.con.Read(totalLengthBytes[0:4]); err != nil {
+	if _, err := io.ReadFull(con.reader, totalLengthBytes[0:4]); err != nil {
 		klog.Warningf("conn.con.Read(totalLengthBytes) %v", err)
-		return err
+		return nil, noTimeout(err)
 	}
 
 	if err := yarnauth.ConvertBytesToFixed(totalLengthBytes[0:4], &totalLength); err != nil {
 		klog.Warningf("yarnauth.ConvertBytesToFixed(totalLengthBytes, &totalLength) %v", err)
-		return err
+		return nil, err
+	}
+	if totalLength < 0 {
+		return nil, fmt.Errorf("invalid response length %v", totalLength)
 	}
 
 	var responseBytes = make([]byte, totalLength)
-	reader := bufio.NewReader(conn.con)
-	read, err := io.ReadFull(reader, responseBytes)
-	if err != nil {
+	if _, err := io.ReadFull(con.reader, responseBytes); err != nil {
 		klog.Warningf("io.ReadFull(reader, responseBytes), %v", err)
-		return err
+		return nil, noTimeout(err)
 	}
-	if int32(read) != totalLength {
-		return fmt.Errorf("actural read length %v does not match the total length %v", read, totalLength)
+	return responseBytes, nil
+}
+
+// saslReader unwraps the SASL WRAP messages from server into the stream of rpc responses
+type saslReader struct {
+	con *connection
+	raw *bufio.Reader
+	buf []byte
+}
+
+func (r *saslReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		token, err := r.readWrapToken()
+		if err != nil {
+			return 0, err
+		}
+		if r.buf, err = r.con.sasl.Unwrap(token); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *saslReader) readWrapToken() ([]byte, error) {
+	// a timeout is only recoverable before the first byte of a message
+	if _, err := r.raw.Peek(1); err != nil {
+		return nil, err
+	}
+	var totalLength int32 = -1
+	var totalLengthBytes [4]byte
+	if _, err := io.ReadFull(r.raw, totalLengthBytes[0:4]); err != nil {
+		return nil, noTimeout(err)
+	}
+	if err := yarnauth.ConvertBytesToFixed(totalLengthBytes[0:4], &totalLength); err != nil {
+		return nil, err
+	}
+	if totalLength < 0 {
+		return nil, fmt.Errorf("invalid response length %v", totalLength)
+	}
+	responseBytes := make([]byte, totalLength)
+	if _, err := io.ReadFull(r.raw, responseBytes); err != nil {
+		return nil, noTimeout(err)
 	}
 
-	// Parse RpcResponseHeaderProto
 	rpcResponseHeaderProto := hadoop_common.RpcResponseHeaderProto{}
-	off, err := readDelimited(responseBytes[0:totalLength], &rpcResponseHeaderProto)
+	off, err := readDelimited(responseBytes, &rpcResponseHeaderProto)
 	if err != nil {
-		klog.Warningf("readDelimited(responseBytes, rpcResponseHeaderProto) %v", err)
-		return err
+		return nil, err
+	}
+	if int32(rpcResponseHeaderProto.GetCallId()) != SASL_RPC_CALL_ID {
+		return nil, fmt.Errorf("expected SASL WRAP message, got response of call %v", rpcResponseHeaderProto.GetCallId())
 	}
-	klog.V(5).Infof("Received rpcResponseHeaderProto = %v", rpcResponseHeaderProto.String())
+	if rpcResponseHeaderProto.GetStatus() != hadoop_common.RpcResponseHeaderProto_SUCCESS {
+		return nil, newRpcError(&rpcResponseHeaderProto)
+	}
+	var saslMessage hadoop_common.RpcSaslProto
+	if _, err := readDelimited(responseBytes[off:], &saslMessage); err != nil {
+		return nil, err
+	}
+	if saslMessage.GetState() != hadoop_common.RpcSaslProto_WRAP {
+		return nil, fmt.Errorf("expected SASL WRAP message, got %v", saslMessage.GetState())
+	}
+	return saslMessage.GetToken(), nil
+}
 
-	err = c.checkRpcHeader(&rpcResponseHeaderProto)
+func (c *Client) readResponse(rpcResponseHeaderProto *hadoop_common.RpcResponseHeaderProto, responseBytes []byte, rpcCall *call) error {
+	err := c.checkRpcHeader(rpcResponseHeaderProto)
 	if err != nil {
 		klog.Warningf("c.checkRpcHeader failed %v", err)
 		return err
@@ -444,29 +854,35 @@ func (c *Client) readResponse(conn *connection, rpcCall *call) error {
 
 	if *rpcResponseHeaderProto.Status == hadoop_common.RpcResponseHeaderProto_SUCCESS {
 		// Parse RpcResponseWrapper
-		_, err = readDelimited(responseBytes[off:], rpcCall.response)
+		_, err = readDelimited(responseBytes, rpcCall.response)
 	} else {
 		klog.V(4).Infof("RPC failed with status: %v", rpcResponseHeaderProto.Status.String())
-		errorDetails := [4]string{rpcResponseHeaderProto.Status.String(), "ServerDidNotSetExceptionClassName", "ServerDidNotSetErrorMsg", "ServerDidNotSetErrorDetail"}
-		if rpcResponseHeaderProto.ExceptionClassName != nil {
-			errorDetails[0] = *rpcResponseHeaderProto.ExceptionClassName
-		}
-		if rpcResponseHeaderProto.ErrorMsg != nil {
-			errorDetails[1] = *rpcResponseHeaderProto.ErrorMsg
-		}
-		if rpcResponseHeaderProto.ErrorDetail != nil {
-			errorDetails[2] = rpcResponseHeaderProto.ErrorDetail.String()
-		}
-		err = errors.New(strings.Join(errorDetails[:], ":"))
+		err = newRpcError(rpcResponseHeaderProto)
+	}
+	return err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// noTimeout converts a timeout in the middle of a packet to a plain error, since the stream cannot be recovered
+func noTimeout(err error) error {
+	if isTimeout(err) {
+		return fmt.Errorf("read partial packet: %v", err)
 	}
 	return err
 }
 
 func readDelimited(rawData []byte, msg proto.Message) (int, error) {
 	headerLength, off := protowire.ConsumeVarint(rawData)
-	if off == 0 {
+	if off <= 0 {
 		klog.Warningf("proto.DecodeVarint(rawData) returned zero")
-		return -1, nil
+		return -1, protowire.ParseError(off)
+	}
+	if uint64(len(rawData)-off) < headerLength {
+		return -1, io.ErrUnexpectedEOF
 	}
 	b := rawData[off : off+int(headerLength)]
 	err := proto.Unmarshal(b, msg)
@@ -491,6 +907,19 @@ func (c *Client) checkRpcHeader(rpcResponseHeaderProto *hadoop_common.RpcRespons
 }
 
 func sendSaslMessage(c *Client, conn *connection, message *hadoop_common.RpcSaslProto) error {
+	packet, err := newSaslPacket(message)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.con.Write(packet); err != nil {
+		klog.Warningf("conn.con.Write(saslPacket) %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func newSaslPacket(message *hadoop_common.RpcSaslProto) ([]byte, error) {
 	saslRpcHeaderProto := hadoop_common.RpcRequestHeaderProto{RpcKind: &yarnauth.RPC_PROTOCOL_BUFFFER,
 		RpcOp:      &yarnauth.RPC_FINAL_PACKET,
 		CallId:     &SASL_RPC_CALL_ID,
@@ -501,40 +930,17 @@ func sendSaslMessage(c *Client, conn *connection, message *hadoop_common.RpcSasl
 
 	if err != nil {
 		klog.Warningf("proto.Marshal(&saslRpcHeaderProto) %v", err)
-		return err
+		return nil, err
 	}
 
 	saslRpcMessageProtoBytes, err := proto.Marshal(message)
 
 	if err != nil {
 		klog.Warningf("proto.Marshal(saslMessage) %v", err)
-		return err
-	}
-
-	totalLength := len(saslRpcHeaderProtoBytes) + sizeVarint(len(saslRpcHeaderProtoBytes)) + len(saslRpcMessageProtoBytes) + sizeVarint(len(saslRpcMessageProtoBytes))
-	var tLen int32 = int32(totalLength)
-	if err := conn.con.SetDeadline(time.Now().Add(rwDefaultTimeout)); err != nil {
-		return err
-	}
-	if totalLengthBytes, err := yarnauth.ConvertFixedToBytes(tLen); err != nil {
-		klog.Warningf("ConvertFixedToBytes(totalLength) %v", err)
-		return err
-	} else {
-		if _, err := conn.con.Write(totalLengthBytes); err != nil {
-			klog.Warningf("conn.con.Write(totalLengthBytes) %v", err)
-			return err
-		}
-	}
-	if err := writeDelimitedBytes(conn, saslRpcHeaderProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, saslRpcHeaderProtoBytes) %v", err)
-		return err
-	}
-	if err := writeDelimitedBytes(conn, saslRpcMessageProtoBytes); err != nil {
-		klog.Warningf("writeDelimitedBytes(conn, saslRpcMessageProtoBytes) %v", err)
-		return err
+		return nil, err
 	}
 
-	return nil
+	return newPacket(saslRpcHeaderProtoBytes, saslRpcMessageProtoBytes)
 }
 
 func receiveSaslMessage(c *Client, conn *connection) (*hadoop_common.RpcSaslProto, error) {
@@ -542,11 +948,7 @@ func receiveSaslMessage(c *Client, conn *connection) (*hadoop_common.RpcSaslProt
 	var totalLength int32 = -1
 	var totalLengthBytes [4]byte
 
-	if err := conn.con.SetDeadline(time.Now().Add(rwDefaultTimeout)); err != nil {
-		return nil, err
-	}
-
-	if _, err := conn.con.Read(totalLengthBytes[0:4]); err != nil {
+	if _, err := io.ReadFull(conn.con, totalLengthBytes[0:4]); err != nil {
 		klog.Warningf("conn.con.Read(totalLengthBytes) %v", err)
 		return nil, err
 	}
@@ -557,7 +959,7 @@ func receiveSaslMessage(c *Client, conn *connection) (*hadoop_common.RpcSaslProt
 
 	var responseBytes []byte = make([]byte, totalLength)
 
-	if _, err := conn.con.Read(responseBytes); err != nil {
+	if _, err := io.ReadFull(conn.con, responseBytes); err != nil {
 		klog.Warningf("conn.con.Read(totalLengthBytes) %v", err)
 		return nil, err
 	}
@@ -588,18 +990,7 @@ func receiveSaslMessage(c *Client, conn *connection) (*hadoop_common.RpcSaslProt
 		}
 	} else {
 		klog.V(4).Infof("RPC failed with status: %v", rpcResponseHeaderProto.Status.String())
-		errorDetails := [4]string{rpcResponseHeaderProto.Status.String(), "ServerDidNotSetExceptionClassName", "ServerDidNotSetErrorMsg", "ServerDidNotSetErrorDetail"}
-		if rpcResponseHeaderProto.ExceptionClassName != nil {
-			errorDetails[0] = *rpcResponseHeaderProto.ExceptionClassName
-		}
-		if rpcResponseHeaderProto.ErrorMsg != nil {
-			errorDetails[1] = *rpcResponseHeaderProto.ErrorMsg
-		}
-		if rpcResponseHeaderProto.ErrorDetail != nil {
-			errorDetails[2] = rpcResponseHeaderProto.ErrorDetail.String()
-		}
-		err = errors.New(strings.Join(errorDetails[:], ":"))
-		return nil, err
+		return nil, newRpcError(&rpcResponseHeaderProto)
 	}
 }
 
@@ -613,82 +1004,3 @@ func checkSaslRpcHeader(rpcResponseHeaderProto *hadoop_common.RpcResponseHeaderP
 	}
 	return nil
 }
-
-func negotiateSimpleTokenAuth(client *Client, con *connection) error {
-	var saslNegotiateState hadoop_common.RpcSaslProto_SaslState = hadoop_common.RpcSaslProto_NEGOTIATE
-	var saslNegotiateMessage hadoop_common.RpcSaslProto = hadoop_common.RpcSaslProto{State: &saslNegotiateState}
-	var saslResponseMessage *hadoop_common.RpcSaslProto
-	var err error
-
-	//send a SASL negotiation request
-	if err = sendSaslMessage(client, con, &saslNegotiateMessage); err != nil {
-		klog.Warningf("failed to send SASL NEGOTIATE message!")
-		return err
-	}
-
-	//get a response with supported mehcanisms/challenge
-	if saslResponseMessage, err = receiveSaslMessage(client, con); err != nil {
-		klog.Warningf("failed to receive SASL NEGOTIATE response!")
-		return err
-	}
-
-	var auths []*hadoop_common.RpcSaslProto_SaslAuth = saslResponseMessage.GetAuths()
-
-	if numAuths := len(auths); numAuths <= 0 {
-		klog.Warningf("No supported auth mechanisms!")
-		return errors.New("No supported auth mechanisms!")
-	}
-
-	//for now we only support auth when TOKEN/DIGEST-MD5 is the first/only
-	//supported auth mechanism
-	var auth *hadoop_common.RpcSaslProto_SaslAuth = auths[0]
-
-	if !(auth.GetMethod() == "TOKEN" && auth.GetMechanism() == "DIGEST-MD5") {
-		klog.Warningf("yarnauth only supports TOKEN/DIGEST-MD5 auth!")
-		return errors.New("yarnauth only supports TOKEN/DIGEST-MD5 auth!")
-	}
-
-	method := auth.GetMethod()
-	mechanism := auth.GetMechanism()
-	protocol := auth.GetProtocol()
-	serverId := auth.GetServerId()
-	challenge := auth.GetChallenge()
-
-	//TODO: token/service mapping + token selection based on type/service
-	//we wouldn't have gotten this far if there wasn't at least one available token.
-	userToken, _ := findUsableTokenForService(client.ServerAddress)
-	response, err := security.GetDigestMD5ChallengeResponse(protocol, serverId, challenge, userToken)
-
-	if err != nil {
-		klog.Warningf("failed to get challenge response! %v", err)
-		return err
-	}
-
-	saslInitiateState := hadoop_common.RpcSaslProto_INITIATE
-	authSend := hadoop_common.RpcSaslProto_SaslAuth{Method: &method, Mechanism: &mechanism,
-		Protocol: &protocol, ServerId: &serverId}
-	authsSendArray := []*hadoop_common.RpcSaslProto_SaslAuth{&authSend}
-	saslInitiateMessage := hadoop_common.RpcSaslProto{State: &saslInitiateState,
-		Token: []byte(response), Auths: authsSendArray}
-
-	//send a SASL inititate request
-	if err = sendSaslMessage(client, con, &saslInitiateMessage); err != nil {
-		klog.Warningf("failed to send SASL INITIATE message!")
-		return err
-	}
-
-	//get a response with supported mehcanisms/challenge
-	if saslResponseMessage, err = receiveSaslMessage(client, con); err != nil {
-		klog.Warningf("failed to read response to SASL INITIATE response!")
-		return err
-	}
-
-	if saslResponseMessage.GetState() != hadoop_common.RpcSaslProto_SUCCESS {
-		klog.Warningf("expected SASL SUCCESS response!")
-		return errors.New("expected SASL SUCCESS response!")
-	}
-
-	klog.V(4).Infof("Successfully completed SASL negotiation!")
-
-	return nil //errors.New("abort here")
-}
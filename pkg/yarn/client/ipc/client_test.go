@@ -0,0 +1,363 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gouuid "github.com/nu7hatch/gouuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+)
+
+const testProtocol = "org.apache.hadoop.test.TestProtocolPB"
+
+func TestMain(m *testing.M) {
+	// closing idle connections is tested without waiting for the default
+	connMaxIdleTime = 500 * time.Millisecond
+	os.Exit(m.Run())
+}
+
+// fakeServer is a hadoop rpc server without auth, which answers each call with handler in its own goroutine,
+// no response is sent if handler returns nil
+type fakeServer struct {
+	listener net.Listener
+	handler  func(method string, request []byte) proto.Message
+
+	accepted     atomic.Int32
+	disconnected atomic.Int32
+
+	mtx     sync.Mutex
+	headers []*hadoop_common.RpcRequestHeaderProto
+}
+
+func newFakeServer(t *testing.T, handler func(method string, request []byte) proto.Message) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{listener: listener, handler: handler}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+// echoHandler answers GetDelegationTokenRequestProto with its renewer as the token kind
+func echoHandler(method string, request []byte) proto.Message {
+	in := &hadoop_common.GetDelegationTokenRequestProto{}
+	if _, err := readDelimited(request, in); err != nil {
+		return nil
+	}
+	return newEchoResponse(in.GetRenewer())
+}
+
+func newEchoResponse(renewer string) *hadoop_common.GetDelegationTokenResponseProto {
+	return &hadoop_common.GetDelegationTokenResponseProto{Token: &hadoop_common.TokenProto{
+		Identifier: []byte{}, Password: []byte{}, Kind: proto.String(renewer), Service: proto.String("")}}
+}
+
+func (s *fakeServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeServer) requestHeaders() []*hadoop_common.RpcRequestHeaderProto {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]*hadoop_common.RpcRequestHeaderProto{}, s.headers...)
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.accepted.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+func (s *fakeServer) serveConn(conn net.Conn) {
+	defer s.disconnected.Add(1)
+	defer conn.Close()
+
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(conn, header); err != nil || !bytes.Equal(header[:4], yarnauth.RPC_HEADER) {
+		return
+	}
+	var writeMtx sync.Mutex
+	for {
+		packet, err := readPacket(conn)
+		if err != nil {
+			return
+		}
+		rpcHeader := &hadoop_common.RpcRequestHeaderProto{}
+		off, err := readDelimited(packet, rpcHeader)
+		if err != nil {
+			return
+		}
+		if rpcHeader.GetCallId() < 0 {
+			// connection context
+			continue
+		}
+		requestHeader := &hadoop_common.RequestHeaderProto{}
+		n, err := readDelimited(packet[off:], requestHeader)
+		if err != nil {
+			return
+		}
+		s.mtx.Lock()
+		s.headers = append(s.headers, rpcHeader)
+		s.mtx.Unlock()
+
+		go func(request []byte) {
+			response := s.handler(requestHeader.GetMethodName(), request)
+			if response == nil {
+				return
+			}
+			status := hadoop_common.RpcResponseHeaderProto_SUCCESS
+			responseHeader := &hadoop_common.RpcResponseHeaderProto{CallId: proto.Uint32(uint32(rpcHeader.GetCallId())),
+				Status: &status, ClientId: rpcHeader.GetClientId()}
+			headerBytes, _ := proto.Marshal(responseHeader)
+			responseBytes, _ := proto.Marshal(response)
+			packet, _ := newPacket(headerBytes, responseBytes)
+			writeMtx.Lock()
+			defer writeMtx.Unlock()
+			conn.Write(packet)
+		}(packet[off+n:])
+	}
+}
+
+func readPacket(r io.Reader) ([]byte, error) {
+	var length int32
+	var lengthBytes [4]byte
+	if _, err := io.ReadFull(r, lengthBytes[:]); err != nil {
+		return nil, err
+	}
+	if err := yarnauth.ConvertBytesToFixed(lengthBytes[:], &length); err != nil {
+		return nil, err
+	}
+	packet := make([]byte, length)
+	_, err := io.ReadFull(r, packet)
+	return packet, err
+}
+
+func newTestClient(t *testing.T, address string, ugi *security.UserGroupInformation) *Client {
+	clientId, err := gouuid.NewV4()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{ClientId: clientId, Ugi: ugi, ServerAddress: address, RPCTimeout: 5 * time.Second}
+}
+
+func echo(c *Client, protocol string, renewer string) (string, error) {
+	response := &hadoop_common.GetDelegationTokenResponseProto{}
+	err := c.Call(yarnauth.NewRPCRequestHeaderProto("echo", &protocol),
+		&hadoop_common.GetDelegationTokenRequestProto{Renewer: &renewer}, response)
+	return response.GetToken().GetKind(), err
+}
+
+func TestConnectionReuse(t *testing.T) {
+	server := newFakeServer(t, echoHandler)
+	alice, anotherAlice := security.NewRemoteUser("alice"), security.NewRemoteUser("alice")
+
+	// clients of the same user, protocol and address share a connection
+	for i := 0; i < 3; i++ {
+		got, err := echo(newTestClient(t, server.address(), alice), testProtocol, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", got)
+	}
+	assert.Equal(t, int32(1), server.accepted.Load())
+
+	// users are compared by identity instead of name
+	_, err := echo(newTestClient(t, server.address(), anotherAlice), testProtocol, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), server.accepted.Load())
+
+	_, err = echo(newTestClient(t, server.address(), alice), testProtocol+"2", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), server.accepted.Load())
+
+	// clients with different settings do not share the connection set up by others
+	settings := []func(c *Client){
+		func(c *Client) { c.RPCTimeout = time.Second },
+		func(c *Client) { c.ConnectTimeout = time.Second },
+		func(c *Client) { c.TCPNoDelay = true },
+		func(c *Client) { c.TokenService = "yarn-cluster" },
+		func(c *Client) { c.Protection = []security.QOP{security.QOPAuth} },
+	}
+	for i, set := range settings {
+		c := newTestClient(t, server.address(), alice)
+		set(c)
+		_, err = echo(c, testProtocol, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(4+i), server.accepted.Load())
+	}
+}
+
+func TestConcurrentCalls(t *testing.T) {
+	// later calls are answered earlier, so responses are out of the order of requests
+	server := newFakeServer(t, func(method string, request []byte) proto.Message {
+		in := &hadoop_common.GetDelegationTokenRequestProto{}
+		readDelimited(request, in)
+		var i int
+		fmt.Sscanf(in.GetRenewer(), "user%d", &i)
+		time.Sleep(time.Duration(20-i) * 10 * time.Millisecond)
+		return newEchoResponse(in.GetRenewer())
+	})
+	c := newTestClient(t, server.address(), security.NewRemoteUser("yarn"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			want := fmt.Sprintf("user%d", i)
+			got, err := echo(c, testProtocol, want)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), server.accepted.Load())
+
+	callIds := map[int32]bool{}
+	for _, header := range server.requestHeaders() {
+		assert.Equal(t, c.ClientId[:], header.GetClientId())
+		assert.Equal(t, yarnauth.RPC_DEFAULT_RETRY_COUNT, header.GetRetryCount())
+		callIds[header.GetCallId()] = true
+	}
+	assert.Len(t, callIds, 20)
+}
+
+func TestCloseIdleConnection(t *testing.T) {
+	server := newFakeServer(t, echoHandler)
+	c := newTestClient(t, server.address(), security.NewRemoteUser("yarn"))
+
+	_, err := echo(c, testProtocol, "yarn")
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return server.disconnected.Load() == 1 }, 5*time.Second, 50*time.Millisecond)
+
+	// a new connection is set up after the idle one is closed
+	got, err := echo(c, testProtocol, "yarn")
+	assert.NoError(t, err)
+	assert.Equal(t, "yarn", got)
+	assert.Equal(t, int32(2), server.accepted.Load())
+}
+
+func TestCallWithRetry(t *testing.T) {
+	server := newFakeServer(t, echoHandler)
+	c := newTestClient(t, server.address(), security.NewRemoteUser("yarn"))
+
+	callId := NextCallId()
+	protocol := testProtocol
+	for retry := int32(0); retry < 2; retry++ {
+		err := c.CallWithContext(WithRetry(context.Background(), callId, retry), yarnauth.NewRPCRequestHeaderProto("echo", &protocol),
+			&hadoop_common.GetDelegationTokenRequestProto{Renewer: proto.String("yarn")}, &hadoop_common.GetDelegationTokenResponseProto{})
+		assert.NoError(t, err)
+	}
+	headers := server.requestHeaders()
+	assert.Len(t, headers, 2)
+	for i, header := range headers {
+		assert.Equal(t, callId, header.GetCallId())
+		assert.Equal(t, int32(i), header.GetRetryCount())
+	}
+}
+
+func TestCallTimeoutAndCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := newFakeServer(t, func(method string, request []byte) proto.Message {
+		if method == "block" {
+			<-release
+		}
+		return echoHandler(method, request)
+	})
+	c := newTestClient(t, server.address(), security.NewRemoteUser("yarn"))
+	protocol := testProtocol
+	call := func(ctx context.Context) error {
+		return c.CallWithContext(ctx, yarnauth.NewRPCRequestHeaderProto("block", &protocol),
+			&hadoop_common.GetDelegationTokenRequestProto{Renewer: proto.String("yarn")}, &hadoop_common.GetDelegationTokenResponseProto{})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := call(ctx)
+	assert.ErrorIs(t, err, ErrCallTimeout)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	err = call(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the rpc timeout of client applies without a deadline of ctx
+	c.RPCTimeout = 100 * time.Millisecond
+	err = call(context.Background())
+	assert.ErrorIs(t, err, ErrCallTimeout)
+
+	// the abandoned calls do not break the connections, which are one per rpc timeout
+	got, err := echo(c, testProtocol, "yarn")
+	assert.NoError(t, err)
+	assert.Equal(t, "yarn", got)
+	assert.Equal(t, int32(2), server.accepted.Load())
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, call(ctx), context.Canceled)
+}
+
+func TestSelectToken(t *testing.T) {
+	security.RegisterProtocolTokenKind(testProtocol, security.TokenKindRMDelegation)
+	ugi := security.NewRemoteUser("yarn")
+	ugi.AddUserToken(&hadoop_common.TokenProto{Identifier: []byte("logical"), Password: []byte{},
+		Kind: proto.String(security.TokenKindRMDelegation), Service: proto.String("yarn-cluster")})
+	ugi.AddUserToken(&hadoop_common.TokenProto{Identifier: []byte("ha"), Password: []byte{},
+		Kind: proto.String(security.TokenKindRMDelegation), Service: proto.String("10.0.0.1:8032,10.0.0.2:8032")})
+	tests := []struct {
+		name          string
+		serverAddress string
+		tokenService  string
+		protocol      string
+		want          string
+	}{
+		{name: "logical service", serverAddress: "10.0.0.3:8032", tokenService: "yarn-cluster", protocol: testProtocol, want: "logical"},
+		{name: "ip of ha rms", serverAddress: "10.0.0.2:8032", protocol: testProtocol, want: "ha"},
+		{name: "ip after logical service", serverAddress: "10.0.0.2:8032", tokenService: "other-cluster", protocol: testProtocol, want: "ha"},
+		{name: "no token for server", serverAddress: "10.0.0.3:8032", protocol: testProtocol},
+		{name: "protocol without tokens", serverAddress: "10.0.0.2:8032", protocol: testProtocol + "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{ServerAddress: tt.serverAddress, TokenService: tt.tokenService}
+			token, found := selectToken(c, &connection_id{user: ugi, protocol: tt.protocol, address: tt.serverAddress})
+			assert.Equal(t, tt.want != "", found)
+			assert.Equal(t, tt.want, string(token.GetIdentifier()))
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+// exception class names thrown by hadoop server
+const (
+	StandbyException             = "org.apache.hadoop.ipc.StandbyException"
+	RetriableException           = "org.apache.hadoop.ipc.RetriableException"
+	ServerTooBusyException       = "org.apache.hadoop.ipc.ServerTooBusyException"
+	AccessControlException       = "org.apache.hadoop.security.AccessControlException"
+	AuthorizationException       = "org.apache.hadoop.security.authorize.AuthorizationException"
+	InvalidTokenException        = "org.apache.hadoop.security.token.SecretManager$InvalidToken"
+	RpcNoSuchMethodException     = "org.apache.hadoop.ipc.RpcNoSuchMethodException"
+	RpcNoSuchProtocolException   = "org.apache.hadoop.ipc.RpcNoSuchProtocolException"
+	IllegalArgumentException     = "java.lang.IllegalArgumentException"
+	IOException                  = "java.io.IOException"
+	ApplicationNotFoundException = "org.apache.hadoop.yarn.exceptions.ApplicationNotFoundException"
+
+	ApplicationMasterNotRegisteredException = "org.apache.hadoop.yarn.exceptions.ApplicationMasterNotRegisteredException"
+)
+
+// RpcError is the error responded by server in RpcResponseHeaderProto
+type RpcError struct {
+	CallId             uint32
+	Status             hadoop_common.RpcResponseHeaderProto_RpcStatusProto
+	ExceptionClassName string
+	ErrorMsg           string
+	ErrorCode          hadoop_common.RpcResponseHeaderProto_RpcErrorCodeProto
+}
+
+var _ error = &RpcError{}
+
+func newRpcError(header *hadoop_common.RpcResponseHeaderProto) *RpcError {
+	return &RpcError{
+		CallId:             header.GetCallId(),
+		Status:             header.GetStatus(),
+		ExceptionClassName: header.GetExceptionClassName(),
+		ErrorMsg:           header.GetErrorMsg(),
+		ErrorCode:          header.GetErrorDetail(),
+	}
+}
+
+func (e *RpcError) Error() string {
+	return fmt.Sprintf("%v(%v) %v: %v", e.Status, e.ErrorCode, e.ExceptionClassName, e.ErrorMsg)
+}
+
+// IsFatal returns whether the server has closed the connection for the error
+func (e *RpcError) IsFatal() bool {
+	return e.Status == hadoop_common.RpcResponseHeaderProto_FATAL
+}
+
+func asRpcError(err error) (*RpcError, bool) {
+	var rpcErr *RpcError
+	if errors.As(err, &rpcErr) {
+		return rpcErr, true
+	}
+	return nil, false
+}
+
+// IsRpcError returns whether err is responded by server, instead of a connection or client side failure
+func IsRpcError(err error) bool {
+	_, ok := asRpcError(err)
+	return ok
+}
+
+// IsException returns whether err is responded by server with an exception of className
+func IsException(err error, className string) bool {
+	rpcErr, ok := asRpcError(err)
+	return ok && rpcErr.ExceptionClassName == className
+}
+
+// IsFatal returns whether err is a fatal rpc error
+func IsFatal(err error) bool {
+	rpcErr, ok := asRpcError(err)
+	return ok && rpcErr.IsFatal()
+}
+
+// IsStandby returns whether the server is in standby state, the call should go to another server
+func IsStandby(err error) bool {
+	rpcErr, ok := asRpcError(err)
+	return ok && rpcErr.ExceptionClassName == StandbyException
+}
+
+// IsRetriable returns whether the call can be retried on the same server later
+func IsRetriable(err error) bool {
+	rpcErr, ok := asRpcError(err)
+	return ok && (rpcErr.ExceptionClassName == RetriableException || rpcErr.ExceptionClassName == ServerTooBusyException)
+}
+
+// IsAccessDenied returns whether the call is rejected for authentication or authorization
+func IsAccessDenied(err error) bool {
+	rpcErr, ok := asRpcError(err)
+	if !ok {
+		return false
+	}
+	switch rpcErr.ExceptionClassName {
+	case AccessControlException, AuthorizationException, InvalidTokenException:
+		return true
+	}
+	return rpcErr.ErrorCode == hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED
+}
+
+// IsBadRequest returns whether the call is malformed or not supported by server, it fails wherever it is sent
+func IsBadRequest(err error) bool {
+	rpcErr, ok := asRpcError(err)
+	if !ok {
+		return false
+	}
+	switch rpcErr.ExceptionClassName {
+	case RpcNoSuchMethodException, RpcNoSuchProtocolException, IllegalArgumentException:
+		return true
+	}
+	switch rpcErr.ErrorCode {
+	case hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_METHOD,
+		hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_PROTOCOL,
+		hadoop_common.RpcResponseHeaderProto_ERROR_RPC_VERSION_MISMATCH,
+		hadoop_common.RpcResponseHeaderProto_FATAL_UNSUPPORTED_SERIALIZATION,
+		hadoop_common.RpcResponseHeaderProto_FATAL_INVALID_RPC_HEADER,
+		hadoop_common.RpcResponseHeaderProto_FATAL_DESERIALIZING_REQUEST,
+		hadoop_common.RpcResponseHeaderProto_FATAL_VERSION_MISMATCH:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+)
+
+func TestNewRpcError(t *testing.T) {
+	status := hadoop_common.RpcResponseHeaderProto_ERROR
+	errorCode := hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION
+	header := &hadoop_common.RpcResponseHeaderProto{
+		CallId:             proto.Uint32(7),
+		Status:             &status,
+		ExceptionClassName: proto.String(StandbyException),
+		ErrorMsg:           proto.String("Operation category READ is not supported in state standby"),
+		ErrorDetail:        &errorCode,
+	}
+	err := newRpcError(header)
+	assert.Equal(t, &RpcError{
+		CallId:             7,
+		Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+		ExceptionClassName: StandbyException,
+		ErrorMsg:           "Operation category READ is not supported in state standby",
+		ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+	}, err)
+	assert.False(t, err.IsFatal())
+	assert.Contains(t, err.Error(), StandbyException)
+
+	fatal := hadoop_common.RpcResponseHeaderProto_FATAL
+	assert.True(t, newRpcError(&hadoop_common.RpcResponseHeaderProto{CallId: proto.Uint32(1), Status: &fatal}).IsFatal())
+}
+
+func TestErrorClassification(t *testing.T) {
+	rpcError := func(className string, errorCode hadoop_common.RpcResponseHeaderProto_RpcErrorCodeProto) error {
+		return &RpcError{Status: hadoop_common.RpcResponseHeaderProto_ERROR, ExceptionClassName: className, ErrorCode: errorCode}
+	}
+	fatalError := &RpcError{Status: hadoop_common.RpcResponseHeaderProto_FATAL,
+		ErrorCode: hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED}
+	tests := []struct {
+		name           string
+		err            error
+		wantRpcError   bool
+		wantFatal      bool
+		wantStandby    bool
+		wantRetriable  bool
+		wantDenied     bool
+		wantBadRequest bool
+	}{
+		{name: "plain error", err: errors.New("connection refused")},
+		{name: "standby", err: rpcError(StandbyException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantStandby: true},
+		{name: "retriable", err: rpcError(RetriableException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantRetriable: true},
+		{name: "server too busy", err: rpcError(ServerTooBusyException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantRetriable: true},
+		{name: "access control", err: rpcError(AccessControlException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantDenied: true},
+		{name: "invalid token", err: rpcError(InvalidTokenException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantDenied: true},
+		{name: "fatal unauthorized", err: fatalError, wantRpcError: true, wantFatal: true, wantDenied: true},
+		{name: "no such method", err: rpcError("", hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_METHOD),
+			wantRpcError: true, wantBadRequest: true},
+		{name: "illegal argument", err: rpcError(IllegalArgumentException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true, wantBadRequest: true},
+		{name: "application error", err: rpcError(ApplicationNotFoundException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION),
+			wantRpcError: true},
+		{name: "wrapped standby", err: fmt.Errorf("get cluster nodes: %w", rpcError(StandbyException, hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION)),
+			wantRpcError: true, wantStandby: true},
+		{name: "connection closed by fatal error", err: connectionClosedError(fatalError),
+			wantRpcError: true, wantFatal: true, wantDenied: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantRpcError, IsRpcError(tt.err))
+			assert.Equal(t, tt.wantFatal, IsFatal(tt.err))
+			assert.Equal(t, tt.wantStandby, IsStandby(tt.err))
+			assert.Equal(t, tt.wantRetriable, IsRetriable(tt.err))
+			assert.Equal(t, tt.wantDenied, IsAccessDenied(tt.err))
+			assert.Equal(t, tt.wantBadRequest, IsBadRequest(tt.err))
+		})
+	}
+	assert.True(t, IsException(fmt.Errorf("allocate: %w", rpcError(ApplicationMasterNotRegisteredException,
+		hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION)), ApplicationMasterNotRegisteredException))
+	assert.False(t, IsException(errors.New(ApplicationMasterNotRegisteredException), ApplicationMasterNotRegisteredException))
+	assert.ErrorIs(t, connectionClosedError(fatalError), ErrConnectionClosed)
+	assert.Equal(t, ErrConnectionClosed, connectionClosedError(ErrConnectionClosed))
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+)
+
+const (
+	saslMethodSimple   = "SIMPLE"
+	saslMethodToken    = "TOKEN"
+	saslMethodKerberos = "KERBEROS"
+
+	saslMechanismDigestMD5 = "DIGEST-MD5"
+	saslMechanismGSSAPI    = "GSSAPI"
+)
+
+// saslMechanism is the client side of a SASL mechanism
+type saslMechanism interface {
+	// initialResponse returns the token sent with INITIATE for the auth selected from the NEGOTIATE of server
+	initialResponse(auth *hadoop_common.RpcSaslProto_SaslAuth) ([]byte, error)
+	// evaluateChallenge returns the token sent with RESPONSE for the CHALLENGE of server, it also evaluates the
+	// final token sent with SUCCESS
+	evaluateChallenge(challenge []byte) ([]byte, error)
+	isComplete() bool
+	// wrapper returns the security layer negotiated, which is nil for auth
+	wrapper() security.SaslWrapper
+}
+
+// simpleMechanism switches to simple auth if server allows it, the server responds SUCCESS to INITIATE
+type simpleMechanism struct{}
+
+func (m *simpleMechanism) initialResponse(auth *hadoop_common.RpcSaslProto_SaslAuth) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *simpleMechanism) evaluateChallenge(challenge []byte) ([]byte, error) {
+	return nil, errors.New("unexpected SASL challenge of simple auth")
+}
+
+func (m *simpleMechanism) isComplete() bool {
+	return true
+}
+
+func (m *simpleMechanism) wrapper() security.SaslWrapper {
+	return nil
+}
+
+type digestMD5Mechanism struct {
+	client *security.DigestMD5Client
+}
+
+func (m *digestMD5Mechanism) initialResponse(auth *hadoop_common.RpcSaslProto_SaslAuth) ([]byte, error) {
+	response, err := m.client.EvaluateChallenge(auth.GetProtocol(), auth.GetServerId(), auth.GetChallenge())
+	if err != nil {
+		klog.Warningf("failed to get challenge response! %v", err)
+		return nil, err
+	}
+	return response, nil
+}
+
+func (m *digestMD5Mechanism) evaluateChallenge(challenge []byte) ([]byte, error) {
+	// the only token after INITIATE is the response auth of server
+	return nil, m.client.VerifyResponseAuth(challenge)
+}
+
+func (m *digestMD5Mechanism) isComplete() bool {
+	return m.client.IsComplete()
+}
+
+func (m *digestMD5Mechanism) wrapper() security.SaslWrapper {
+	if m.client.QOP() == security.QOPAuth {
+		return nil
+	}
+	return m.client
+}
+
+type gssapiMechanism struct {
+	client *security.GSSAPIClient
+}
+
+func (m *gssapiMechanism) initialResponse(auth *hadoop_common.RpcSaslProto_SaslAuth) ([]byte, error) {
+	return m.client.InitialResponse()
+}
+
+func (m *gssapiMechanism) evaluateChallenge(challenge []byte) ([]byte, error) {
+	return m.client.EvaluateChallenge(challenge)
+}
+
+func (m *gssapiMechanism) isComplete() bool {
+	return m.client.IsComplete()
+}
+
+func (m *gssapiMechanism) wrapper() security.SaslWrapper {
+	if m.client.QOP() == security.QOPAuth {
+		return nil
+	}
+	return m.client
+}
+
+// negotiateSaslAuth authenticates the connection with the first auth offered by server which the client supports,
+// and returns the negotiated auth method and the security layer if integrity or privacy is negotiated
+func negotiateSaslAuth(client *Client, con *connection) (yarnauth.AuthMethod, security.SaslWrapper, error) {
+	saslNegotiateState := hadoop_common.RpcSaslProto_NEGOTIATE
+	saslNegotiateMessage := &hadoop_common.RpcSaslProto{State: &saslNegotiateState}
+
+	//send a SASL negotiation request
+	if err := sendSaslMessage(client, con, saslNegotiateMessage); err != nil {
+		klog.Warningf("failed to send SASL NEGOTIATE message!")
+		return 0, nil, err
+	}
+
+	//get a response with supported mechanisms
+	saslResponseMessage, err := receiveSaslMessage(client, con)
+	if err != nil {
+		klog.Warningf("failed to receive SASL NEGOTIATE response!")
+		return 0, nil, err
+	}
+	if saslResponseMessage.GetState() == hadoop_common.RpcSaslProto_SUCCESS {
+		// security is disabled on server
+		return yarnauth.AUTH_SIMPLE, nil, nil
+	} else if saslResponseMessage.GetState() != hadoop_common.RpcSaslProto_NEGOTIATE {
+		return 0, nil, fmt.Errorf("expected SASL NEGOTIATE response, got %v", saslResponseMessage.GetState())
+	}
+
+	auth, mechanism, authMethod, err := selectSaslMechanism(client, con, saslResponseMessage.GetAuths())
+	if err != nil {
+		klog.Warningf("no supported SASL auth: %v", err)
+		return 0, nil, err
+	}
+	token, err := mechanism.initialResponse(auth)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	method, mech, protocol, serverId := auth.GetMethod(), auth.GetMechanism(), auth.GetProtocol(), auth.GetServerId()
+	saslInitiateState := hadoop_common.RpcSaslProto_INITIATE
+	saslMessage := &hadoop_common.RpcSaslProto{
+		State: &saslInitiateState,
+		Token: token,
+		Auths: []*hadoop_common.RpcSaslProto_SaslAuth{{Method: &method, Mechanism: &mech, Protocol: &protocol, ServerId: &serverId}},
+	}
+	for {
+		if err = sendSaslMessage(client, con, saslMessage); err != nil {
+			klog.Warningf("failed to send SASL %v message!", saslMessage.GetState())
+			return 0, nil, err
+		}
+		if saslResponseMessage, err = receiveSaslMessage(client, con); err != nil {
+			klog.Warningf("failed to read response to SASL %v message!", saslMessage.GetState())
+			return 0, nil, err
+		}
+
+		switch saslResponseMessage.GetState() {
+		case hadoop_common.RpcSaslProto_SUCCESS:
+			if len(saslResponseMessage.GetToken()) > 0 {
+				if _, err = mechanism.evaluateChallenge(saslResponseMessage.GetToken()); err != nil {
+					return 0, nil, err
+				}
+			}
+			if !mechanism.isComplete() {
+				return 0, nil, fmt.Errorf("SASL %v negotiation is not completed on SUCCESS", mech)
+			}
+			wrapper := mechanism.wrapper()
+			if wrapper != nil {
+				klog.V(4).Infof("Successfully completed SASL %v negotiation with qop %v!", mech, wrapper.QOP())
+			} else {
+				klog.V(4).Infof("Successfully completed SASL %v negotiation!", mech)
+			}
+			return authMethod, wrapper, nil
+		case hadoop_common.RpcSaslProto_CHALLENGE:
+			if token, err = mechanism.evaluateChallenge(saslResponseMessage.GetToken()); err != nil {
+				return 0, nil, err
+			}
+			saslResponseState := hadoop_common.RpcSaslProto_RESPONSE
+			saslMessage = &hadoop_common.RpcSaslProto{State: &saslResponseState, Token: token}
+		default:
+			return 0, nil, fmt.Errorf("unexpected SASL state %v", saslResponseMessage.GetState())
+		}
+	}
+}
+
+// selectSaslMechanism prefers tokens to kerberos in the order of auths, which is the same as SaslRpcClient in hadoop,
+// and falls back to simple auth if server allows it
+func selectSaslMechanism(client *Client, con *connection, auths []*hadoop_common.RpcSaslProto_SaslAuth) (*hadoop_common.RpcSaslProto_SaslAuth, saslMechanism, yarnauth.AuthMethod, error) {
+	var serverAuths []string
+	for _, auth := range auths {
+		serverAuths = append(serverAuths, auth.GetMethod()+"/"+auth.GetMechanism())
+		switch {
+		case auth.GetMethod() == saslMethodSimple:
+			return auth, &simpleMechanism{}, yarnauth.AUTH_SIMPLE, nil
+		case auth.GetMethod() == saslMethodToken && auth.GetMechanism() == saslMechanismDigestMD5:
+			if token, found := selectToken(client, &con.id); found {
+				return auth, &digestMD5Mechanism{client: security.NewDigestMD5Client(token, client.Protection)}, yarnauth.AUTH_TOKEN, nil
+			}
+		case auth.GetMethod() == saslMethodKerberos && auth.GetMechanism() == saslMechanismGSSAPI:
+			if client.AuthMethod != yarnauth.AUTH_KERBEROS {
+				continue
+			}
+			credential, err := getKerberosCredential(con.id.user)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			spn, err := getServerPrincipal(client, auth)
+			if err != nil {
+				return nil, nil, 0, err
+			}
+			return auth, &gssapiMechanism{client: security.NewGSSAPIClient(credential, spn, client.Protection)}, yarnauth.AUTH_KERBEROS, nil
+		}
+	}
+	return nil, nil, 0, fmt.Errorf("client cannot authenticate via %v", serverAuths)
+}
+
+// getKerberosCredential returns the credential authenticating ugi, the current user logs in with the ticket cache
+// if it has not logged in, like the login user of hadoop
+func getKerberosCredential(ugi *security.UserGroupInformation) (security.KerberosCredential, error) {
+	if credential := ugi.GetKerberosCredential(); credential != nil {
+		return credential, nil
+	}
+	if ugi.AuthenticatingUser() != security.GetCurrentUser() {
+		return nil, fmt.Errorf("user %v has not logged in with kerberos", ugi.AuthenticatingUser().UserName())
+	}
+	if err := security.LoginUserFromTicketCache(""); err != nil {
+		return nil, err
+	}
+	return security.GetCurrentUser().GetKerberosCredential(), nil
+}
+
+// getServerPrincipal checks the principal advertised by server against ServerPrincipal of client,
+// so that the client does not authenticate to a server which is not expected
+func getServerPrincipal(client *Client, auth *hadoop_common.RpcSaslProto_SaslAuth) (string, error) {
+	if client.ServerPrincipal == "" {
+		return "", errors.New("failed to specify server's kerberos principal name")
+	}
+	host, _, err := net.SplitHostPort(client.ServerAddress)
+	if err != nil {
+		return "", err
+	}
+	principal, err := security.GetServerPrincipal(client.ServerPrincipal, canonicalHostName(host))
+	if err != nil {
+		return "", err
+	}
+	spn := auth.GetProtocol() + "/" + auth.GetServerId()
+	if i := strings.LastIndex(principal, "@"); i >= 0 {
+		principal = principal[:i]
+	}
+	if principal != spn {
+		return "", fmt.Errorf("server has invalid kerberos principal: %v, expecting: %v", spn, principal)
+	}
+	return spn, nil
+}
+
+// lookupHost and lookupAddr are replaced by tests
+var (
+	lookupHost = net.LookupHost
+	lookupAddr = net.LookupAddr
+)
+
+// canonicalHostName returns the canonical name of host which is used by _HOST in principal, like
+// InetAddress.getCanonicalHostName in hadoop, host is resolved to its ip and the ip is resolved back to a name.
+// host is returned as is if it cannot be resolved.
+func canonicalHostName(host string) string {
+	ip := host
+	if net.ParseIP(host) == nil {
+		addrs, err := lookupHost(host)
+		if err != nil || len(addrs) == 0 {
+			return host
+		}
+		ip = addrs[0]
+	}
+	names, err := lookupAddr(ip)
+	if err != nil || len(names) == 0 {
+		return host
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
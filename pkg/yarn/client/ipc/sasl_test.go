@@ -0,0 +1,181 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+)
+
+func TestGetServerPrincipal(t *testing.T) {
+	hosts := map[string]string{"rm1": "10.0.0.1", "rm1.example.com": "10.0.0.1"}
+	names := map[string]string{"10.0.0.1": "rm1.example.com."}
+	lookupHost = func(host string) ([]string, error) {
+		if addr, ok := hosts[host]; ok {
+			return []string{addr}, nil
+		}
+		return nil, fmt.Errorf("no such host %v", host)
+	}
+	lookupAddr = func(addr string) ([]string, error) {
+		if name, ok := names[addr]; ok {
+			return []string{name}, nil
+		}
+		return nil, fmt.Errorf("no name of %v", addr)
+	}
+	defer func() {
+		lookupHost, lookupAddr = net.LookupHost, net.LookupAddr
+	}()
+
+	tests := []struct {
+		name            string
+		serverAddress   string
+		serverPrincipal string
+		serverId        string
+		wantErr         bool
+	}{
+		{name: "short name", serverAddress: "rm1:8032", serverPrincipal: "rm/_HOST@EXAMPLE.COM", serverId: "rm1.example.com"},
+		{name: "ip", serverAddress: "10.0.0.1:8032", serverPrincipal: "rm/_HOST@EXAMPLE.COM", serverId: "rm1.example.com"},
+		{name: "canonical name", serverAddress: "rm1.example.com:8032", serverPrincipal: "rm/_HOST@EXAMPLE.COM", serverId: "rm1.example.com"},
+		{name: "unresolvable name", serverAddress: "rm2:8032", serverPrincipal: "rm/_HOST@EXAMPLE.COM", serverId: "rm2"},
+		{name: "fixed host", serverAddress: "rm1:8032", serverPrincipal: "rm/rm1.example.com@EXAMPLE.COM", serverId: "rm1.example.com"},
+		{name: "unexpected server", serverAddress: "rm1:8032", serverPrincipal: "rm/_HOST@EXAMPLE.COM", serverId: "evil.example.com", wantErr: true},
+		{name: "no principal", serverAddress: "rm1:8032", serverId: "rm1.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{ServerAddress: tt.serverAddress, ServerPrincipal: tt.serverPrincipal}
+			auth := &hadoop_common.RpcSaslProto_SaslAuth{Method: proto.String(saslMethodKerberos),
+				Mechanism: proto.String(saslMechanismGSSAPI), Protocol: proto.String("rm"), ServerId: proto.String(tt.serverId)}
+			spn, err := getServerPrincipal(c, auth)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "rm/"+tt.serverId, spn)
+		})
+	}
+}
+
+// xorWrapper is a security layer which flips the bits of messages, so that unwrapped data on the wire is noticed
+type xorWrapper struct {
+	maxWrapSize int
+}
+
+func (w *xorWrapper) QOP() security.QOP {
+	return security.QOPAuthConf
+}
+
+func (w *xorWrapper) Wrap(message []byte) ([]byte, error) {
+	return w.xor(message), nil
+}
+
+func (w *xorWrapper) Unwrap(token []byte) ([]byte, error) {
+	return w.xor(token), nil
+}
+
+func (w *xorWrapper) MaxWrapSize() int {
+	return w.maxWrapSize
+}
+
+func (w *xorWrapper) xor(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[i] = b[i] ^ 0x5a
+	}
+	return out
+}
+
+// newTCPPair returns both ends of a loopback tcp connection
+func newTCPPair(t *testing.T) (*net.TCPConn, net.Conn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client.(*net.TCPConn), server
+}
+
+func TestSaslWrapFraming(t *testing.T) {
+	wrapper := &xorWrapper{maxWrapSize: 8}
+	client, server := newTCPPair(t)
+	con := &connection{con: client, sasl: wrapper, rpcTimeout: time.Second}
+
+	// requests are split into WRAP messages of at most MaxWrapSize bytes
+	packet, err := newPacket([]byte("rpc request header"), []byte("request"))
+	assert.NoError(t, err)
+	go func() {
+		assert.NoError(t, con.write(packet))
+	}()
+	var unwrapped []byte
+	for len(unwrapped) < len(packet) {
+		saslPacket, err := readPacket(server)
+		assert.NoError(t, err)
+		header := &hadoop_common.RpcRequestHeaderProto{}
+		off, err := readDelimited(saslPacket, header)
+		assert.NoError(t, err)
+		assert.Equal(t, SASL_RPC_CALL_ID, header.GetCallId())
+		message := &hadoop_common.RpcSaslProto{}
+		_, err = readDelimited(saslPacket[off:], message)
+		assert.NoError(t, err)
+		assert.Equal(t, hadoop_common.RpcSaslProto_WRAP, message.GetState())
+		assert.LessOrEqual(t, len(message.GetToken()), wrapper.maxWrapSize)
+		token, _ := wrapper.Unwrap(message.GetToken())
+		unwrapped = append(unwrapped, token...)
+	}
+	assert.Equal(t, packet, unwrapped)
+
+	// a response wrapped into several WRAP messages is read as one packet
+	response, err := newPacket([]byte("rpc response header"), []byte("response"))
+	assert.NoError(t, err)
+	status := hadoop_common.RpcResponseHeaderProto_SUCCESS
+	saslHeader, _ := proto.Marshal(&hadoop_common.RpcResponseHeaderProto{CallId: proto.Uint32(uint32(SASL_RPC_CALL_ID)), Status: &status})
+	for _, chunk := range [][]byte{response[:5], response[5:]} {
+		token, _ := wrapper.Wrap(chunk)
+		wrapState := hadoop_common.RpcSaslProto_WRAP
+		message, _ := proto.Marshal(&hadoop_common.RpcSaslProto{State: &wrapState, Token: token})
+		saslPacket, err := newPacket(saslHeader, message)
+		assert.NoError(t, err)
+		_, err = server.Write(saslPacket)
+		assert.NoError(t, err)
+	}
+	con.reader = bufio.NewReader(&saslReader{con: con, raw: bufio.NewReader(client)})
+	got, err := con.readResponse()
+	assert.NoError(t, err)
+	assert.Equal(t, response[4:], got)
+}
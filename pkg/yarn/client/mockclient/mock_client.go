@@ -22,9 +22,11 @@ limitations under the License.
 package mock_client
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	hadoopcommon "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
 	hadoopyarn "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
 	server "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
 )
@@ -52,6 +54,51 @@ func (m *MockYarnClient) EXPECT() *MockYarnClientMockRecorder {
 	return m.recorder
 }
 
+// AddToClusterNodeLabelsWithContext mocks base method.
+func (m *MockYarnClient) AddToClusterNodeLabelsWithContext(ctx context.Context, request *server.AddToClusterNodeLabelsRequestProto) (*server.AddToClusterNodeLabelsResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToClusterNodeLabelsWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.AddToClusterNodeLabelsResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddToClusterNodeLabelsWithContext indicates an expected call of AddToClusterNodeLabelsWithContext.
+func (mr *MockYarnClientMockRecorder) AddToClusterNodeLabelsWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToClusterNodeLabelsWithContext", reflect.TypeOf((*MockYarnClient)(nil).AddToClusterNodeLabelsWithContext), ctx, request)
+}
+
+// CancelDelegationTokenWithContext mocks base method.
+func (m *MockYarnClient) CancelDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.CancelDelegationTokenRequestProto) (*hadoopcommon.CancelDelegationTokenResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDelegationTokenWithContext", ctx, request)
+	ret0, _ := ret[0].(*hadoopcommon.CancelDelegationTokenResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelDelegationTokenWithContext indicates an expected call of CancelDelegationTokenWithContext.
+func (mr *MockYarnClientMockRecorder) CancelDelegationTokenWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDelegationTokenWithContext", reflect.TypeOf((*MockYarnClient)(nil).CancelDelegationTokenWithContext), ctx, request)
+}
+
+// CheckForDecommissioningNodesWithContext mocks base method.
+func (m *MockYarnClient) CheckForDecommissioningNodesWithContext(ctx context.Context, request *server.CheckForDecommissioningNodesRequestProto) (*server.CheckForDecommissioningNodesResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckForDecommissioningNodesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.CheckForDecommissioningNodesResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckForDecommissioningNodesWithContext indicates an expected call of CheckForDecommissioningNodesWithContext.
+func (mr *MockYarnClientMockRecorder) CheckForDecommissioningNodesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckForDecommissioningNodesWithContext", reflect.TypeOf((*MockYarnClient)(nil).CheckForDecommissioningNodesWithContext), ctx, request)
+}
+
 // Close mocks base method.
 func (m *MockYarnClient) Close() {
 	m.ctrl.T.Helper()
@@ -79,6 +126,51 @@ func (mr *MockYarnClientMockRecorder) GetClusterNodes(request interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterNodes", reflect.TypeOf((*MockYarnClient)(nil).GetClusterNodes), request)
 }
 
+// GetClusterNodesWithContext mocks base method.
+func (m *MockYarnClient) GetClusterNodesWithContext(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterNodesWithContext", ctx, request)
+	ret0, _ := ret[0].(*hadoopyarn.GetClusterNodesResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterNodesWithContext indicates an expected call of GetClusterNodesWithContext.
+func (mr *MockYarnClientMockRecorder) GetClusterNodesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterNodesWithContext", reflect.TypeOf((*MockYarnClient)(nil).GetClusterNodesWithContext), ctx, request)
+}
+
+// GetDelegationTokenWithContext mocks base method.
+func (m *MockYarnClient) GetDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.GetDelegationTokenRequestProto) (*hadoopcommon.GetDelegationTokenResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDelegationTokenWithContext", ctx, request)
+	ret0, _ := ret[0].(*hadoopcommon.GetDelegationTokenResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDelegationTokenWithContext indicates an expected call of GetDelegationTokenWithContext.
+func (mr *MockYarnClientMockRecorder) GetDelegationTokenWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDelegationTokenWithContext", reflect.TypeOf((*MockYarnClient)(nil).GetDelegationTokenWithContext), ctx, request)
+}
+
+// GetGroupsForUserWithContext mocks base method.
+func (m *MockYarnClient) GetGroupsForUserWithContext(ctx context.Context, request *server.GetGroupsForUserRequestProto) (*server.GetGroupsForUserResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupsForUserWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.GetGroupsForUserResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupsForUserWithContext indicates an expected call of GetGroupsForUserWithContext.
+func (mr *MockYarnClientMockRecorder) GetGroupsForUserWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupsForUserWithContext", reflect.TypeOf((*MockYarnClient)(nil).GetGroupsForUserWithContext), ctx, request)
+}
+
 // Initialize mocks base method.
 func (m *MockYarnClient) Initialize() error {
 	m.ctrl.T.Helper()
@@ -93,6 +185,141 @@ func (mr *MockYarnClientMockRecorder) Initialize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Initialize", reflect.TypeOf((*MockYarnClient)(nil).Initialize))
 }
 
+// MapAttributesToNodesWithContext mocks base method.
+func (m *MockYarnClient) MapAttributesToNodesWithContext(ctx context.Context, request *server.NodesToAttributesMappingRequestProto) (*server.NodesToAttributesMappingResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MapAttributesToNodesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.NodesToAttributesMappingResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MapAttributesToNodesWithContext indicates an expected call of MapAttributesToNodesWithContext.
+func (mr *MockYarnClientMockRecorder) MapAttributesToNodesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MapAttributesToNodesWithContext", reflect.TypeOf((*MockYarnClient)(nil).MapAttributesToNodesWithContext), ctx, request)
+}
+
+// RefreshAdminAclsWithContext mocks base method.
+func (m *MockYarnClient) RefreshAdminAclsWithContext(ctx context.Context, request *server.RefreshAdminAclsRequestProto) (*server.RefreshAdminAclsResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshAdminAclsWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshAdminAclsResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshAdminAclsWithContext indicates an expected call of RefreshAdminAclsWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshAdminAclsWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshAdminAclsWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshAdminAclsWithContext), ctx, request)
+}
+
+// RefreshClusterMaxPriorityWithContext mocks base method.
+func (m *MockYarnClient) RefreshClusterMaxPriorityWithContext(ctx context.Context, request *server.RefreshClusterMaxPriorityRequestProto) (*server.RefreshClusterMaxPriorityResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshClusterMaxPriorityWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshClusterMaxPriorityResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshClusterMaxPriorityWithContext indicates an expected call of RefreshClusterMaxPriorityWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshClusterMaxPriorityWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshClusterMaxPriorityWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshClusterMaxPriorityWithContext), ctx, request)
+}
+
+// RefreshNodesResourcesWithContext mocks base method.
+func (m *MockYarnClient) RefreshNodesResourcesWithContext(ctx context.Context, request *server.RefreshNodesResourcesRequestProto) (*server.RefreshNodesResourcesResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshNodesResourcesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshNodesResourcesResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshNodesResourcesWithContext indicates an expected call of RefreshNodesResourcesWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshNodesResourcesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshNodesResourcesWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshNodesResourcesWithContext), ctx, request)
+}
+
+// RefreshNodesWithContext mocks base method.
+func (m *MockYarnClient) RefreshNodesWithContext(ctx context.Context, request *server.RefreshNodesRequestProto) (*server.RefreshNodesResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshNodesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshNodesResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshNodesWithContext indicates an expected call of RefreshNodesWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshNodesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshNodesWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshNodesWithContext), ctx, request)
+}
+
+// RefreshQueuesWithContext mocks base method.
+func (m *MockYarnClient) RefreshQueuesWithContext(ctx context.Context, request *server.RefreshQueuesRequestProto) (*server.RefreshQueuesResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshQueuesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshQueuesResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshQueuesWithContext indicates an expected call of RefreshQueuesWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshQueuesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshQueuesWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshQueuesWithContext), ctx, request)
+}
+
+// RefreshServiceAclsWithContext mocks base method.
+func (m *MockYarnClient) RefreshServiceAclsWithContext(ctx context.Context, request *server.RefreshServiceAclsRequestProto) (*server.RefreshServiceAclsResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshServiceAclsWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshServiceAclsResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshServiceAclsWithContext indicates an expected call of RefreshServiceAclsWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshServiceAclsWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshServiceAclsWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshServiceAclsWithContext), ctx, request)
+}
+
+// RefreshSuperUserGroupsConfigurationWithContext mocks base method.
+func (m *MockYarnClient) RefreshSuperUserGroupsConfigurationWithContext(ctx context.Context, request *server.RefreshSuperUserGroupsConfigurationRequestProto) (*server.RefreshSuperUserGroupsConfigurationResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshSuperUserGroupsConfigurationWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshSuperUserGroupsConfigurationResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshSuperUserGroupsConfigurationWithContext indicates an expected call of RefreshSuperUserGroupsConfigurationWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshSuperUserGroupsConfigurationWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshSuperUserGroupsConfigurationWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshSuperUserGroupsConfigurationWithContext), ctx, request)
+}
+
+// RefreshUserToGroupsMappingsWithContext mocks base method.
+func (m *MockYarnClient) RefreshUserToGroupsMappingsWithContext(ctx context.Context, request *server.RefreshUserToGroupsMappingsRequestProto) (*server.RefreshUserToGroupsMappingsResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshUserToGroupsMappingsWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RefreshUserToGroupsMappingsResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshUserToGroupsMappingsWithContext indicates an expected call of RefreshUserToGroupsMappingsWithContext.
+func (mr *MockYarnClientMockRecorder) RefreshUserToGroupsMappingsWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshUserToGroupsMappingsWithContext", reflect.TypeOf((*MockYarnClient)(nil).RefreshUserToGroupsMappingsWithContext), ctx, request)
+}
+
 // Reinitialize mocks base method.
 func (m *MockYarnClient) Reinitialize() error {
 	m.ctrl.T.Helper()
@@ -107,6 +334,51 @@ func (mr *MockYarnClientMockRecorder) Reinitialize() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reinitialize", reflect.TypeOf((*MockYarnClient)(nil).Reinitialize))
 }
 
+// RemoveFromClusterNodeLabelsWithContext mocks base method.
+func (m *MockYarnClient) RemoveFromClusterNodeLabelsWithContext(ctx context.Context, request *server.RemoveFromClusterNodeLabelsRequestProto) (*server.RemoveFromClusterNodeLabelsResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveFromClusterNodeLabelsWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.RemoveFromClusterNodeLabelsResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveFromClusterNodeLabelsWithContext indicates an expected call of RemoveFromClusterNodeLabelsWithContext.
+func (mr *MockYarnClientMockRecorder) RemoveFromClusterNodeLabelsWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveFromClusterNodeLabelsWithContext", reflect.TypeOf((*MockYarnClient)(nil).RemoveFromClusterNodeLabelsWithContext), ctx, request)
+}
+
+// RenewDelegationTokenWithContext mocks base method.
+func (m *MockYarnClient) RenewDelegationTokenWithContext(ctx context.Context, request *hadoopcommon.RenewDelegationTokenRequestProto) (*hadoopcommon.RenewDelegationTokenResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenewDelegationTokenWithContext", ctx, request)
+	ret0, _ := ret[0].(*hadoopcommon.RenewDelegationTokenResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RenewDelegationTokenWithContext indicates an expected call of RenewDelegationTokenWithContext.
+func (mr *MockYarnClientMockRecorder) RenewDelegationTokenWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewDelegationTokenWithContext", reflect.TypeOf((*MockYarnClient)(nil).RenewDelegationTokenWithContext), ctx, request)
+}
+
+// ReplaceLabelsOnNodesWithContext mocks base method.
+func (m *MockYarnClient) ReplaceLabelsOnNodesWithContext(ctx context.Context, request *server.ReplaceLabelsOnNodeRequestProto) (*server.ReplaceLabelsOnNodeResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceLabelsOnNodesWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.ReplaceLabelsOnNodeResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceLabelsOnNodesWithContext indicates an expected call of ReplaceLabelsOnNodesWithContext.
+func (mr *MockYarnClientMockRecorder) ReplaceLabelsOnNodesWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceLabelsOnNodesWithContext", reflect.TypeOf((*MockYarnClient)(nil).ReplaceLabelsOnNodesWithContext), ctx, request)
+}
+
 // UpdateNodeResource mocks base method.
 func (m *MockYarnClient) UpdateNodeResource(request *server.UpdateNodeResourceRequestProto) (*server.UpdateNodeResourceResponseProto, error) {
 	m.ctrl.T.Helper()
@@ -121,3 +393,18 @@ func (mr *MockYarnClientMockRecorder) UpdateNodeResource(request interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeResource", reflect.TypeOf((*MockYarnClient)(nil).UpdateNodeResource), request)
 }
+
+// UpdateNodeResourceWithContext mocks base method.
+func (m *MockYarnClient) UpdateNodeResourceWithContext(ctx context.Context, request *server.UpdateNodeResourceRequestProto) (*server.UpdateNodeResourceResponseProto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNodeResourceWithContext", ctx, request)
+	ret0, _ := ret[0].(*server.UpdateNodeResourceResponseProto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNodeResourceWithContext indicates an expected call of UpdateNodeResourceWithContext.
+func (mr *MockYarnClientMockRecorder) UpdateNodeResourceWithContext(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNodeResourceWithContext", reflect.TypeOf((*MockYarnClient)(nil).UpdateNodeResourceWithContext), ctx, request)
+}
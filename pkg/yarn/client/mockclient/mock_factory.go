@@ -25,6 +25,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	security "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client"
 )
 
@@ -95,3 +96,15 @@ func (mr *MockYarnClientFactoryMockRecorder) CreateYarnClientByClusterID(cluster
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateYarnClientByClusterID", reflect.TypeOf((*MockYarnClientFactory)(nil).CreateYarnClientByClusterID), clusterID)
 }
+
+// SetClusterUser mocks base method.
+func (m *MockYarnClientFactory) SetClusterUser(clusterID string, ugi *security.UserGroupInformation) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetClusterUser", clusterID, ugi)
+}
+
+// SetClusterUser indicates an expected call of SetClusterUser.
+func (mr *MockYarnClientFactoryMockRecorder) SetClusterUser(clusterID, ugi interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetClusterUser", reflect.TypeOf((*MockYarnClientFactory)(nil).SetClusterUser), clusterID, ugi)
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+// ContainerIDString formats container id the same as ContainerId.toString in hadoop, e.g.
+// container_e17_1410901177871_0001_01_000005
+func ContainerIDString(containerID *hadoopyarn.ContainerIdProto) string {
+	appID := containerID.GetAppAttemptId().GetApplicationId()
+	epoch := containerID.GetId() >> 40
+	id := containerID.GetId() & (1<<40 - 1)
+	if epoch > 0 {
+		return fmt.Sprintf("container_e%02d_%d_%04d_%02d_%06d", epoch, appID.GetClusterTimestamp(), appID.GetId(),
+			containerID.GetAppAttemptId().GetAttemptId(), id)
+	}
+	return fmt.Sprintf("container_%d_%04d_%02d_%06d", appID.GetClusterTimestamp(), appID.GetId(),
+		containerID.GetAppAttemptId().GetAttemptId(), id)
+}
+
+// applicationAttemptIDString formats attempt id the same as ApplicationAttemptId.toString in hadoop
+func applicationAttemptIDString(attemptID *hadoopyarn.ApplicationAttemptIdProto) string {
+	return fmt.Sprintf("appattempt_%d_%04d_%06d", attemptID.GetApplicationId().GetClusterTimestamp(),
+		attemptID.GetApplicationId().GetId(), attemptID.GetAttemptId())
+}
+
+// containerFailure returns the error of container in the failed requests of a node manager response
+func containerFailure(failedRequests []*hadoopyarn.ContainerExceptionMapProto, containerID *hadoopyarn.ContainerIdProto) error {
+	for _, failed := range failedRequests {
+		if !proto.Equal(failed.GetContainerId(), containerID) {
+			continue
+		}
+		exception := failed.GetException()
+		return fmt.Errorf("container %v failed with %v: %v", ContainerIDString(containerID),
+			exception.GetClassName(), exception.GetMessage())
+	}
+	return nil
+}
+
+// nmProxy is the client of a node manager authenticated with an nm token
+type nmProxy struct {
+	token  *hadoopcommon.TokenProto
+	client *YarnContainerManagementClient
+}
+
+// NMClient launches and manages the containers of an application on node managers like NMClient in hadoop. Node
+// managers are called with the NMToken of the node in the token cache, which is filled by the AMRMClient of the
+// application, as the user of the application attempt.
+type NMClient struct {
+	conf      yarnconf.YarnConfiguration
+	clusterID string
+	nmTokens  *NMTokenCache
+
+	mtx     sync.Mutex
+	proxies map[string]*nmProxy
+	// startedContainers are the containers started and not stopped by the client, keyed by the container id
+	startedContainers map[string]*hadoopyarn.ContainerProto
+}
+
+// NewNMClient creates a node manager client of cluster with the nm tokens of application in nmTokens
+func NewNMClient(confDir string, clusterID string, nmTokens *NMTokenCache) (*NMClient, error) {
+	conf, err := yarnconf.NewYarnConfiguration(confDir, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return &NMClient{
+		conf:              conf,
+		clusterID:         clusterID,
+		nmTokens:          nmTokens,
+		proxies:           map[string]*nmProxy{},
+		startedContainers: map[string]*hadoopyarn.ContainerProto{},
+	}, nil
+}
+
+// containerManager returns the client of the node manager of node, which is recreated once the nm token of node
+// is updated by rm
+func (c *NMClient) containerManager(nodeID *hadoopyarn.NodeIdProto, containerID *hadoopyarn.ContainerIdProto) (*YarnContainerManagementClient, error) {
+	address := NodeAddress(nodeID)
+	token, found := c.nmTokens.GetToken(address)
+	if !found {
+		return nil, fmt.Errorf("nm token of node %v not found for container %v", address, ContainerIDString(containerID))
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if proxy, ok := c.proxies[address]; ok && proto.Equal(proxy.token, token) {
+		return proxy.client, nil
+	}
+	ugi := security.NewRemoteUser(applicationAttemptIDString(containerID.GetAppAttemptId()))
+	nmToken := proto.Clone(token).(*hadoopcommon.TokenProto)
+	nmToken.Service = proto.String(ipc.BuildTokenService(address))
+	ugi.AddUserToken(nmToken)
+	client, err := CreateYarnContainerManagementClient(c.conf, address, ugi)
+	if err != nil {
+		return nil, err
+	}
+	c.proxies[address] = &nmProxy{token: token, client: client}
+	return client, nil
+}
+
+// StartContainer launches container allocated by rm with launchContext, and returns the meta data of auxiliary
+// services of the node manager
+func (c *NMClient) StartContainer(ctx context.Context, container *hadoopyarn.ContainerProto, launchContext *hadoopyarn.ContainerLaunchContextProto) ([]*hadoopyarn.StringBytesMapProto, error) {
+	client, err := c.containerManager(container.GetNodeId(), container.GetId())
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.StartContainersWithContext(ctx, &hadoopyarn.StartContainersRequestProto{
+		StartContainerRequest: []*hadoopyarn.StartContainerRequestProto{
+			{ContainerLaunchContext: launchContext, ContainerToken: container.GetContainerToken()},
+		}})
+	if err != nil {
+		return nil, err
+	}
+	if err := containerFailure(response.GetFailedRequests(), container.GetId()); err != nil {
+		return nil, err
+	}
+	c.mtx.Lock()
+	c.startedContainers[ContainerIDString(container.GetId())] = container
+	c.mtx.Unlock()
+	return response.GetServicesMetaData(), nil
+}
+
+// StopContainer stops container on node
+func (c *NMClient) StopContainer(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) error {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return err
+	}
+	response, err := client.StopContainersWithContext(ctx, &hadoopyarn.StopContainersRequestProto{
+		ContainerId: []*hadoopyarn.ContainerIdProto{containerID}})
+	if err != nil {
+		return err
+	}
+	if err := containerFailure(response.GetFailedRequests(), containerID); err != nil {
+		return err
+	}
+	c.mtx.Lock()
+	delete(c.startedContainers, ContainerIDString(containerID))
+	c.mtx.Unlock()
+	return nil
+}
+
+// GetContainerStatus returns the status of container on node
+func (c *NMClient) GetContainerStatus(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) (*hadoopyarn.ContainerStatusProto, error) {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.GetContainerStatusesWithContext(ctx, &hadoopyarn.GetContainerStatusesRequestProto{
+		ContainerId: []*hadoopyarn.ContainerIdProto{containerID}})
+	if err != nil {
+		return nil, err
+	}
+	if err := containerFailure(response.GetFailedRequests(), containerID); err != nil {
+		return nil, err
+	}
+	for _, status := range response.GetStatus() {
+		if proto.Equal(status.GetContainerId(), containerID) {
+			return status, nil
+		}
+	}
+	return nil, fmt.Errorf("status of container %v not found in node %v", ContainerIDString(containerID), NodeAddress(nodeID))
+}
+
+// UpdateContainerResource applies the update of container approved by rm to the node manager, container must carry
+// the container token of the update reported by OnContainersUpdated
+func (c *NMClient) UpdateContainerResource(ctx context.Context, container *hadoopyarn.ContainerProto) error {
+	client, err := c.containerManager(container.GetNodeId(), container.GetId())
+	if err != nil {
+		return err
+	}
+	response, err := client.UpdateContainerWithContext(ctx, &hadoopyarn.ContainerUpdateRequestProto{
+		UpdateContainerToken: []*hadoopcommon.TokenProto{container.GetContainerToken()}})
+	if err != nil {
+		return err
+	}
+	return containerFailure(response.GetFailedRequests(), container.GetId())
+}
+
+// ReInitializeContainer relaunches container with launchContext, the previous launch context is kept for rollback
+// until the re-initialization is committed, which happens immediately if autoCommit
+func (c *NMClient) ReInitializeContainer(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto,
+	launchContext *hadoopyarn.ContainerLaunchContextProto, autoCommit bool) error {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return err
+	}
+	_, err = client.ReInitializeContainerWithContext(ctx, &hadoopyarn.ReInitializeContainerRequestProto{
+		ContainerId: containerID, ContainerLaunchContext: launchContext, AutoCommit: proto.Bool(autoCommit)})
+	return err
+}
+
+// RestartContainer relaunches container with its current launch context
+func (c *NMClient) RestartContainer(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) error {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return err
+	}
+	_, err = client.RestartContainerWithContext(ctx, containerID)
+	return err
+}
+
+// RollbackLastReInitialization relaunches container with the launch context before the last re-initialization
+func (c *NMClient) RollbackLastReInitialization(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) error {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return err
+	}
+	_, err = client.RollbackLastReInitializationWithContext(ctx, containerID)
+	return err
+}
+
+// CommitLastReInitialization drops the launch context kept for rollback of the last re-initialization
+func (c *NMClient) CommitLastReInitialization(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) error {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return err
+	}
+	_, err = client.CommitLastReInitializationWithContext(ctx, containerID)
+	return err
+}
+
+// GetLocalizationStatuses returns the localization statuses of the resources of container
+func (c *NMClient) GetLocalizationStatuses(ctx context.Context, containerID *hadoopyarn.ContainerIdProto, nodeID *hadoopyarn.NodeIdProto) ([]*hadoopyarn.LocalizationStatusProto, error) {
+	client, err := c.containerManager(nodeID, containerID)
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.GetLocalizationStatusesWithContext(ctx, &hadoopyarn.GetLocalizationStatusesRequestProto{
+		ContainerId: []*hadoopyarn.ContainerIdProto{containerID}})
+	if err != nil {
+		return nil, err
+	}
+	if err := containerFailure(response.GetFailedRequests(), containerID); err != nil {
+		return nil, err
+	}
+	for _, statuses := range response.GetCntnLocalizationStatuses() {
+		if proto.Equal(statuses.GetContainerId(), containerID) {
+			return statuses.GetLocalizationStatuses(), nil
+		}
+	}
+	return nil, nil
+}
+
+// CleanupRunningContainers stops all containers started and not stopped by the client, which is done by am before
+// it exits like NMClient in hadoop does on stop
+func (c *NMClient) CleanupRunningContainers(ctx context.Context) error {
+	c.mtx.Lock()
+	containers := make([]*hadoopyarn.ContainerProto, 0, len(c.startedContainers))
+	for _, container := range c.startedContainers {
+		containers = append(containers, container)
+	}
+	c.mtx.Unlock()
+
+	var lastErr error
+	for _, container := range containers {
+		if err := c.StopContainer(ctx, container.GetId(), container.GetNodeId()); err != nil {
+			klog.Warningf("stop container %v of yarn cluster %v failed %v", ContainerIDString(container.GetId()), c.clusterID, err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+// fakeNM runs containers in memory, containers without launch context fail to start
+type fakeNM struct {
+	nodeID *hadoopyarn.NodeIdProto
+
+	mtx        sync.Mutex
+	containers map[int64]hadoopyarn.ContainerStateProto
+	updates    int
+	restarts   int
+}
+
+func newFakeNM(t *testing.T) *fakeNM {
+	nm := &fakeNM{containers: map[int64]hadoopyarn.ContainerStateProto{}}
+	listener := newFakeRPCServer(t, func(header *hadoop_common.RpcRequestHeaderProto, method string, param []byte) (proto.Message, string) {
+		nm.mtx.Lock()
+		defer nm.mtx.Unlock()
+		switch method {
+		case "startContainers":
+			request := &hadoopyarn.StartContainersRequestProto{}
+			if err := proto.Unmarshal(param, request); err != nil {
+				return nil, ipc.IllegalArgumentException
+			}
+			response := &hadoopyarn.StartContainersResponseProto{}
+			for _, start := range request.GetStartContainerRequest() {
+				containerID := &hadoopyarn.ContainerIdProto{}
+				if err := proto.Unmarshal(start.GetContainerToken().GetIdentifier(), containerID); err != nil {
+					return nil, ipc.IllegalArgumentException
+				}
+				if start.GetContainerLaunchContext() == nil {
+					response.FailedRequests = append(response.FailedRequests, &hadoopyarn.ContainerExceptionMapProto{
+						ContainerId: containerID, Exception: &hadoopyarn.SerializedExceptionProto{
+							ClassName: proto.String("org.apache.hadoop.yarn.exceptions.YarnException"),
+							Message:   proto.String("no launch context")}})
+					continue
+				}
+				nm.containers[containerID.GetId()] = hadoopyarn.ContainerStateProto_C_RUNNING
+				response.SucceededRequests = append(response.SucceededRequests, containerID)
+			}
+			return response, ""
+		case "stopContainers", "getContainerStatuses":
+			request := &hadoopyarn.StopContainersRequestProto{}
+			if err := proto.Unmarshal(param, request); err != nil {
+				return nil, ipc.IllegalArgumentException
+			}
+			response := &hadoopyarn.GetContainerStatusesResponseProto{}
+			for _, containerID := range request.GetContainerId() {
+				state, ok := nm.containers[containerID.GetId()]
+				if !ok {
+					response.FailedRequests = append(response.FailedRequests, &hadoopyarn.ContainerExceptionMapProto{
+						ContainerId: containerID, Exception: &hadoopyarn.SerializedExceptionProto{
+							ClassName: proto.String("org.apache.hadoop.yarn.exceptions.InvalidContainerException")}})
+					continue
+				}
+				if method == "stopContainers" {
+					state = hadoopyarn.ContainerStateProto_C_COMPLETE
+					nm.containers[containerID.GetId()] = state
+				}
+				response.Status = append(response.Status, &hadoopyarn.ContainerStatusProto{ContainerId: containerID, State: state.Enum()})
+			}
+			if method == "stopContainers" {
+				return &hadoopyarn.StopContainersResponseProto{FailedRequests: response.FailedRequests}, ""
+			}
+			return response, ""
+		case "updateContainer":
+			nm.updates++
+			return &hadoopyarn.ContainerUpdateResponseProto{}, ""
+		case "restartContainer":
+			nm.restarts++
+			return &hadoopyarn.RestartContainerResponseProto{}, ""
+		}
+		return nil, ipc.RpcNoSuchMethodException
+	})
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	nm.nodeID = &hadoopyarn.NodeIdProto{Host: proto.String(host), Port: proto.Int32(int32(portNum))}
+	return nm
+}
+
+func newTestContainer(nodeID *hadoopyarn.NodeIdProto, id int64) *hadoopyarn.ContainerProto {
+	containerID := &hadoopyarn.ContainerIdProto{Id: proto.Int64(id), AppAttemptId: &hadoopyarn.ApplicationAttemptIdProto{
+		ApplicationId: &hadoopyarn.ApplicationIdProto{Id: proto.Int32(1), ClusterTimestamp: proto.Int64(1410901177871)},
+		AttemptId:     proto.Int32(1)}}
+	identifier, _ := proto.Marshal(containerID)
+	token := newTestToken("ContainerToken", "")
+	token.Identifier = identifier
+	return &hadoopyarn.ContainerProto{Id: containerID, NodeId: nodeID, ContainerToken: token}
+}
+
+func TestContainerIDString(t *testing.T) {
+	container := newTestContainer(nil, 5)
+	assert.Equal(t, "container_1410901177871_0001_01_000005", ContainerIDString(container.GetId()))
+	container.Id.Id = proto.Int64(17<<40 | 5)
+	assert.Equal(t, "container_e17_1410901177871_0001_01_000005", ContainerIDString(container.GetId()))
+	assert.Equal(t, "appattempt_1410901177871_0001_000001", applicationAttemptIDString(container.GetId().GetAppAttemptId()))
+}
+
+func TestNMClient(t *testing.T) {
+	ctx := context.Background()
+	nm := newFakeNM(t)
+	nmTokens := NewNMTokenCache()
+	c, err := NewNMClient(writeYarnSite(t, map[string]string{}), "", nmTokens)
+	assert.NoError(t, err)
+
+	container := newTestContainer(nm.nodeID, 1)
+	launchContext := &hadoopyarn.ContainerLaunchContextProto{Command: []string{"sleep", "10"}}
+	_, err = c.StartContainer(ctx, container, launchContext)
+	assert.Error(t, err, "no nm token of the node")
+
+	nmTokens.SetToken(NodeAddress(nm.nodeID), newTestToken(security.TokenKindNM, "nm"))
+	_, err = c.StartContainer(ctx, container, launchContext)
+	assert.NoError(t, err)
+	status, err := c.GetContainerStatus(ctx, container.GetId(), nm.nodeID)
+	assert.NoError(t, err)
+	assert.Equal(t, hadoopyarn.ContainerStateProto_C_RUNNING, status.GetState())
+
+	failed := newTestContainer(nm.nodeID, 2)
+	_, err = c.StartContainer(ctx, failed, nil)
+	assert.ErrorContains(t, err, "no launch context")
+	_, err = c.GetContainerStatus(ctx, failed.GetId(), nm.nodeID)
+	assert.ErrorContains(t, err, "InvalidContainerException")
+
+	assert.NoError(t, c.UpdateContainerResource(ctx, container))
+	assert.NoError(t, c.RestartContainer(ctx, container.GetId(), nm.nodeID))
+	assert.Equal(t, 1, nm.updates)
+	assert.Equal(t, 1, nm.restarts)
+
+	// the client is recreated with the new token
+	nmTokens.SetToken(NodeAddress(nm.nodeID), newTestToken(security.TokenKindNM, "new"))
+	assert.NoError(t, c.CleanupRunningContainers(ctx))
+	status, err = c.GetContainerStatus(ctx, container.GetId(), nm.nodeID)
+	assert.NoError(t, err)
+	assert.Equal(t, hadoopyarn.ContainerStateProto_C_COMPLETE, status.GetState())
+	assert.Empty(t, c.startedContainers)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+)
+
+// NMTokenCache keeps the nm tokens of an application by the address of node managers, which are issued by rm in
+// allocate responses and used to talk to node managers, the same as NMTokenCache in hadoop
+type NMTokenCache struct {
+	mtx    sync.RWMutex
+	tokens map[string]*hadoopcommon.TokenProto
+}
+
+func NewNMTokenCache() *NMTokenCache {
+	return &NMTokenCache{tokens: map[string]*hadoopcommon.TokenProto{}}
+}
+
+// NodeAddress returns the host:port of the node manager of node
+func NodeAddress(node *hadoopyarn.NodeIdProto) string {
+	return fmt.Sprintf("%v:%v", node.GetHost(), node.GetPort())
+}
+
+func (c *NMTokenCache) SetToken(nodeAddress string, token *hadoopcommon.TokenProto) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.tokens[nodeAddress] = token
+}
+
+func (c *NMTokenCache) GetToken(nodeAddress string) (*hadoopcommon.TokenProto, bool) {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	token, ok := c.tokens[nodeAddress]
+	return token, ok
+}
+
+func (c *NMTokenCache) RemoveToken(nodeAddress string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.tokens, nodeAddress)
+}
+
+// addTokens adds the nm tokens of an allocate or register response
+func (c *NMTokenCache) addTokens(tokens []*hadoopyarn.NMTokenProto) {
+	for _, token := range tokens {
+		if token.GetNodeId() != nil && token.GetToken() != nil {
+			c.SetToken(NodeAddress(token.GetNodeId()), token.GetToken())
+		}
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"k8s.io/klog/v2"
+
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+const (
+	defaultReloadDelay         = time.Second
+	defaultReloadRetryInterval = 30 * time.Second
+)
+
+// YarnClientsSubscriber is called with the clients of all clusters by cluster id once they change, the clients
+// replaced are closed after subscribers return
+type YarnClientsSubscriber func(clients map[string]YarnClient)
+
+// ReloadingYarnClientFactory keeps a client for every cluster in the conf dir, which is the default cluster of
+// yarn-site.xml and the clusters of <cluster id>.yarn-site.xml. The conf dir is watched, e.g. a mounted ConfigMap, so
+// that clients are created for clusters added, rebuilt for clusters changed and closed for clusters removed. A reload
+// is applied only if the clients of all clusters are created, otherwise the clients in use are kept.
+type ReloadingYarnClientFactory struct {
+	configDir string
+	factory   YarnClientFactory
+	// reloadDelay batches the changes of files written together, e.g. the update of a ConfigMap
+	reloadDelay         time.Duration
+	reloadRetryInterval time.Duration
+
+	// reloadMtx serializes reloads, which create clients without holding mtx
+	reloadMtx sync.Mutex
+
+	mtx          sync.RWMutex
+	clients      map[string]YarnClient
+	fingerprints map[string]string
+	// configurations are the configurations of clusters loaded with the clients in use
+	configurations map[string]yarnconf.YarnConfiguration
+	subscribers    []YarnClientsSubscriber
+}
+
+// NewReloadingYarnClientFactory watches configDir, and creates clients by factory, which is configured by configDir
+func NewReloadingYarnClientFactory(configDir string, factory YarnClientFactory) *ReloadingYarnClientFactory {
+	return &ReloadingYarnClientFactory{
+		configDir:           configDir,
+		factory:             factory,
+		reloadDelay:         defaultReloadDelay,
+		reloadRetryInterval: defaultReloadRetryInterval,
+		clients:             map[string]YarnClient{},
+		fingerprints:        map[string]string{},
+		configurations:      map[string]yarnconf.YarnConfiguration{},
+	}
+}
+
+// NewDefaultReloadingYarnClientFactory watches $HADOOP_CONF_DIR, and creates clients by DefaultYarnClientFactory
+func NewDefaultReloadingYarnClientFactory() *ReloadingYarnClientFactory {
+	return NewReloadingYarnClientFactory(os.Getenv(envHadoopConfDir), DefaultYarnClientFactory)
+}
+
+// Clients returns the clients of all clusters by cluster id, the clients are owned by the factory
+func (f *ReloadingYarnClientFactory) Clients() map[string]YarnClient {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return copyClients(f.clients)
+}
+
+// Configuration returns the configuration of clusterID, which is loaded with the client in use
+func (f *ReloadingYarnClientFactory) Configuration(clusterID string) (yarnconf.YarnConfiguration, bool) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	conf, ok := f.configurations[clusterID]
+	return conf, ok
+}
+
+// Subscribe registers fn to be called once the clients change
+func (f *ReloadingYarnClientFactory) Subscribe(fn YarnClientsSubscriber) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.subscribers = append(f.subscribers, fn)
+}
+
+// Reload reads the conf dir and updates the clients of clusters changed, either all changes are applied or none
+func (f *ReloadingYarnClientFactory) Reload() error {
+	f.reloadMtx.Lock()
+	defer f.reloadMtx.Unlock()
+
+	clusterIDs, err := listClusterIDs(f.configDir)
+	if err != nil {
+		return err
+	}
+	fingerprints := map[string]string{DefaultClusterID: ""}
+	for _, id := range clusterIDs {
+		fingerprints[id] = ""
+	}
+	configurations := make(map[string]yarnconf.YarnConfiguration, len(fingerprints))
+	for id := range fingerprints {
+		if configurations[id], fingerprints[id], err = f.fingerprint(id); err != nil {
+			return fmt.Errorf("read config of yarn cluster %v failed, error %w", id, err)
+		}
+	}
+
+	f.mtx.RLock()
+	oldClients, oldFingerprints := f.clients, f.fingerprints
+	f.mtx.RUnlock()
+
+	clients, created := map[string]YarnClient{}, map[string]YarnClient{}
+	for id, fingerprint := range fingerprints {
+		if c, ok := oldClients[id]; ok && oldFingerprints[id] == fingerprint {
+			clients[id] = c
+			continue
+		}
+		c, err := f.create(id)
+		if err != nil {
+			for _, c := range created {
+				c.Close()
+			}
+			return fmt.Errorf("create yarn client %v failed, error %w", id, err)
+		}
+		clients[id], created[id] = c, c
+	}
+	var retired []string
+	for id, c := range oldClients {
+		if clients[id] != c {
+			retired = append(retired, id)
+		}
+	}
+	if len(created) == 0 && len(retired) == 0 {
+		return nil
+	}
+	sort.Strings(retired)
+
+	f.mtx.Lock()
+	f.clients, f.fingerprints, f.configurations = clients, fingerprints, configurations
+	subscribers := append([]YarnClientsSubscriber(nil), f.subscribers...)
+	f.mtx.Unlock()
+	klog.V(3).Infof("yarn clients reloaded from %v, %v created, %v retired", f.configDir, sortedKeys(created), retired)
+
+	for _, subscriber := range subscribers {
+		subscriber(copyClients(clients))
+	}
+	for _, id := range retired {
+		oldClients[id].Close()
+	}
+	return nil
+}
+
+func (f *ReloadingYarnClientFactory) create(clusterID string) (YarnClient, error) {
+	if clusterID == DefaultClusterID {
+		return f.factory.CreateDefaultYarnClient()
+	}
+	return f.factory.CreateYarnClientByClusterID(clusterID)
+}
+
+// fingerprint loads the configuration of clusterID and hashes the files read by its client, which are the resources
+// and includes of the cluster, the ones of its sub-clusters if it is federated, and the machine list
+func (f *ReloadingYarnClientFactory) fingerprint(clusterID string) (yarnconf.YarnConfiguration, string, error) {
+	confClusterID := clusterID
+	if clusterID == DefaultClusterID {
+		confClusterID = ""
+	}
+	conf, err := yarnconf.NewYarnConfiguration(f.configDir, confClusterID)
+	if err != nil {
+		return nil, "", err
+	}
+	h := sha256.New()
+	if err := hashFiles(h, conf.Files()); err != nil {
+		return nil, "", err
+	}
+	if enabled, err := conf.GetFederationEnabled(); err != nil {
+		return nil, "", err
+	} else if enabled {
+		subClusterIDs, err := conf.GetFederationSubClusterIDs()
+		if err != nil {
+			return nil, "", err
+		}
+		for _, id := range subClusterIDs {
+			// the clients of sub-clusters load their configurations once used, so a sub-cluster failing to load is
+			// hashed by its resources instead of failing the reload
+			subClusterConf, err := yarnconf.NewYarnConfiguration(f.configDir, id)
+			if err != nil {
+				err = f.hashResources(h, id)
+			} else {
+				err = hashFiles(h, subClusterConf.Files())
+			}
+			if err != nil {
+				return nil, "", err
+			}
+		}
+		machineList, err := conf.GetFederationMachineList()
+		if err != nil {
+			return nil, "", err
+		}
+		if machineList != "" {
+			if err := hashFile(h, machineList); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+	return conf, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (f *ReloadingYarnClientFactory) hashResources(h hash.Hash, clusterID string) error {
+	var paths []string
+	for _, resource := range []yarnconf.Resource{yarnconf.CORE_DEFAULT, yarnconf.CORE_SITE, yarnconf.YARN_DEFAULT, yarnconf.YARN_SITE} {
+		name := resource.Name
+		if clusterID != "" {
+			name = clusterID + "." + name
+		}
+		paths = append(paths, filepath.Join(f.configDir, name))
+	}
+	return hashFiles(h, paths)
+}
+
+func hashFiles(h hash.Hash, paths []string) error {
+	for _, path := range paths {
+		if err := hashFile(h, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashFile writes the path and content of file into h, files not existing are hashed as empty
+func hashFile(h hash.Hash, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	fmt.Fprintf(h, "%v:%v:", path, len(content))
+	h.Write(content)
+	return nil
+}
+
+// Start watches the conf dir and reloads on its changes until ctx is done, the clients are loaded by Reload before
+func (f *ReloadingYarnClientFactory) Start(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(f.configDir); err != nil {
+		return fmt.Errorf("watch yarn config dir %v failed, error %w", f.configDir, err)
+	}
+	klog.V(3).Infof("watching yarn config dir %v", f.configDir)
+
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			klog.V(5).Infof("yarn config dir changed, %v", event)
+			timer.Reset(f.reloadDelay)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			klog.Warningf("watch yarn config dir %v failed, error %v", f.configDir, err)
+		case <-timer.C:
+			if err := f.Reload(); err != nil {
+				klog.Errorf("reload yarn clients from %v failed, keep the clients in use and retry in %v, error %v",
+					f.configDir, f.reloadRetryInterval, err)
+				timer.Reset(f.reloadRetryInterval)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func copyClients(clients map[string]YarnClient) map[string]YarnClient {
+	res := make(map[string]YarnClient, len(clients))
+	for id, c := range clients {
+		res[id] = c
+	}
+	return res
+}
+
+func sortedKeys(clients map[string]YarnClient) []string {
+	keys := make([]string, 0, len(clients))
+	for id := range clients {
+		keys = append(keys, id)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakerm"
+)
+
+func newTestReloadingFactory(t *testing.T) (string, *ReloadingYarnClientFactory, map[string]*fakerm.ResourceManager) {
+	rms := map[string]*fakerm.ResourceManager{}
+	for _, id := range []string{"rm0", "rm1", "rm2"} {
+		rm, err := fakerm.NewResourceManager()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(rm.Close)
+		rms[id] = rm
+	}
+	confDir := t.TempDir()
+	assert.NoError(t, fakerm.WriteYarnSite(confDir, rms["rm0"].YarnSite()))
+	assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "a", rms["rm1"].YarnSite()))
+	f := NewReloadingYarnClientFactory(confDir, NewYarnClientFactory(confDir))
+	f.reloadDelay = 10 * time.Millisecond
+	return confDir, f, rms
+}
+
+func TestReloadingFactoryReload(t *testing.T) {
+	confDir, f, rms := newTestReloadingFactory(t)
+	var notified []map[string]YarnClient
+	f.Subscribe(func(clients map[string]YarnClient) { notified = append(notified, clients) })
+
+	assert.NoError(t, f.Reload())
+	clients := f.Clients()
+	assert.ElementsMatch(t, []string{DefaultClusterID, "a"}, sortedKeys(clients))
+	assert.Len(t, notified, 1)
+	_, err := clients["a"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm1"].CallCount("getClusterNodes"))
+
+	// nothing changed
+	assert.NoError(t, f.Reload())
+	assert.Len(t, notified, 1)
+
+	// a malformed config is not applied, the clients in use are kept
+	assert.NoError(t, os.WriteFile(filepath.Join(confDir, "b.yarn-site.xml"), []byte("<configuration>"), 0644))
+	assert.Error(t, f.Reload())
+	assert.Equal(t, clients, f.Clients())
+	assert.Len(t, notified, 1)
+
+	// b is added and a is rebuilt, the client of a replaced is closed
+	assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "b", rms["rm2"].YarnSite()))
+	assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "a", rms["rm2"].YarnSite()))
+	assert.NoError(t, f.Reload())
+	reloaded := f.Clients()
+	assert.ElementsMatch(t, []string{DefaultClusterID, "a", "b"}, sortedKeys(reloaded))
+	assert.Len(t, notified, 2)
+	assert.Equal(t, reloaded, notified[1])
+	assert.Same(t, clients[DefaultClusterID], reloaded[DefaultClusterID])
+	assert.NotSame(t, clients["a"], reloaded["a"])
+	assert.Nil(t, clients["a"].(*yarnClient).activeRMAdminAddress)
+	// the client replaced is not brought back by callers still holding it
+	_, err = clients["a"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.ErrorIs(t, err, ErrClientClosed)
+	assert.False(t, NeedReinitialize(err))
+	assert.ErrorIs(t, clients["a"].Reinitialize(), ErrClientClosed)
+	assert.Nil(t, clients["a"].(*yarnClient).activeRMAdminAddress)
+	assert.Equal(t, 1, rms["rm1"].CallCount("getClusterNodes"))
+	_, err = reloaded["a"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm2"].CallCount("getClusterNodes"))
+}
+
+func TestReloadingFactoryReloadIncludes(t *testing.T) {
+	confDir, f, rms := newTestReloadingFactory(t)
+	writeIncluded := func(name string, rm *fakerm.ResourceManager) {
+		conf, err := yarnconf.NewConfigurationFromMap(rm.YarnSite())
+		assert.NoError(t, err)
+		file, err := os.Create(filepath.Join(confDir, name))
+		assert.NoError(t, err)
+		defer file.Close()
+		assert.NoError(t, conf.WriteXML(file))
+	}
+	writeIncluded("rm.xml", rms["rm1"])
+	assert.NoError(t, os.WriteFile(filepath.Join(confDir, "b.yarn-site.xml"), []byte(`<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <xi:include href="rm.xml"/>
+  <xi:include href="overrides.xml"><xi:fallback/></xi:include>
+</configuration>`), 0644))
+	assert.NoError(t, f.Reload())
+	clients := f.Clients()
+	_, err := clients["b"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm1"].CallCount("getClusterNodes"))
+
+	// the client of b is rebuilt once the file it includes changes
+	writeIncluded("rm.xml", rms["rm2"])
+	assert.NoError(t, f.Reload())
+	reloaded := f.Clients()
+	assert.Same(t, clients["a"], reloaded["a"])
+	assert.NotSame(t, clients["b"], reloaded["b"])
+	_, err = reloaded["b"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm2"].CallCount("getClusterNodes"))
+
+	// and once the include missing is created
+	writeIncluded("overrides.xml", rms["rm0"])
+	assert.NoError(t, f.Reload())
+	assert.NotSame(t, reloaded["b"], f.Clients()["b"])
+	_, err = f.Clients()["b"].GetClusterNodes(&hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rms["rm0"].CallCount("getClusterNodes"))
+}
+
+func TestReloadingFactoryWatch(t *testing.T) {
+	confDir, f, rms := newTestReloadingFactory(t)
+	var mtx sync.Mutex
+	var latest map[string]YarnClient
+	f.Subscribe(func(clients map[string]YarnClient) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		latest = clients
+	})
+	latestIDs := func() []string {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return sortedKeys(latest)
+	}
+	assert.NoError(t, f.Reload())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		assert.NoError(t, f.Start(ctx))
+	}()
+	defer func() {
+		cancel()
+		<-stopped
+	}()
+	// the watch may start after the first write, which is retried until it is seen
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, fakerm.WriteClusterYarnSite(confDir, "b", rms["rm2"].YarnSite()))
+		return len(latestIDs()) == 3
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, []string{DefaultClusterID, "a", "b"}, latestIDs())
+
+	assert.NoError(t, os.Remove(filepath.Join(confDir, "a.yarn-site.xml")))
+	assert.Eventually(t, func() bool { return len(latestIDs()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{DefaultClusterID, "b"}, sortedKeys(f.Clients()))
+}
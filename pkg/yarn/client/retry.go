@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+type RetryDecision int
+
+const (
+	RetryDecisionFail RetryDecision = iota
+	RetryDecisionRetry
+	RetryDecisionFailoverAndRetry
+)
+
+func (d RetryDecision) String() string {
+	switch d {
+	case RetryDecisionFail:
+		return "FAIL"
+	case RetryDecisionRetry:
+		return "RETRY"
+	case RetryDecisionFailoverAndRetry:
+		return "FAILOVER_AND_RETRY"
+	}
+	return "UNKNOWN"
+}
+
+type RetryAction struct {
+	Decision RetryDecision
+	Delay    time.Duration
+}
+
+// RetryPolicy decides what to do after a call failed, which is the same as RetryPolicy in hadoop.
+// retries is the number of retries already made and failovers is the number of failovers among them.
+type RetryPolicy interface {
+	ShouldRetry(err error, retries, failovers int, idempotent bool) RetryAction
+}
+
+// TryOnceThenFail never retries
+var TryOnceThenFail RetryPolicy = &tryOnceThenFail{}
+
+type tryOnceThenFail struct{}
+
+func (p *tryOnceThenFail) ShouldRetry(err error, retries, failovers int, idempotent bool) RetryAction {
+	return RetryAction{Decision: RetryDecisionFail}
+}
+
+// NewFailoverOnNetworkExceptionPolicy fails over to the next rm on connection errors and standby rm, and retries
+// on the same rm for retriable errors, with an exponential backoff and jitter between attempts. Other errors
+// responded by rm are not retried.
+func NewFailoverOnNetworkExceptionPolicy(maxFailovers, maxRetries, maxRetriesOnSocketTimeouts int,
+	baseSleep, maxSleep time.Duration) RetryPolicy {
+	return &failoverOnNetworkException{
+		maxFailovers:               maxFailovers,
+		maxRetries:                 maxRetries,
+		maxRetriesOnSocketTimeouts: maxRetriesOnSocketTimeouts,
+		baseSleep:                  baseSleep,
+		maxSleep:                   maxSleep,
+	}
+}
+
+// NewRetryPolicyFromConf creates the failover policy with yarn.client.failover-* settings
+func NewRetryPolicyFromConf(conf yarnconf.YarnConfiguration) (RetryPolicy, error) {
+	maxFailovers, err := conf.GetIntInRange(yarnconf.CLIENT_FAILOVER_MAX_ATTEMPTS, yarnconf.DEFAULT_CLIENT_FAILOVER_MAX_ATTEMPTS,
+		0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	baseSleep, err := conf.GetTimeDuration(yarnconf.CLIENT_FAILOVER_SLEEPTIME_BASE_MS,
+		yarnconf.DEFAULT_CLIENT_FAILOVER_SLEEPTIME_BASE_MS*time.Millisecond, time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	maxSleep, err := conf.GetTimeDuration(yarnconf.CLIENT_FAILOVER_SLEEPTIME_MAX_MS,
+		yarnconf.DEFAULT_CLIENT_FAILOVER_SLEEPTIME_MAX_MS*time.Millisecond, time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+	maxRetries, err := conf.GetIntInRange(yarnconf.CLIENT_FAILOVER_RETRIES, yarnconf.DEFAULT_CLIENT_FAILOVER_RETRIES,
+		0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	maxRetriesOnSocketTimeouts, err := conf.GetIntInRange(yarnconf.CLIENT_FAILOVER_RETRIES_ON_SOCKET_TIMEOUTS,
+		yarnconf.DEFAULT_CLIENT_FAILOVER_RETRIES_ON_SOCKET_TIMEOUTS, 0, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+	return NewFailoverOnNetworkExceptionPolicy(maxFailovers, maxRetries, maxRetriesOnSocketTimeouts, baseSleep, maxSleep), nil
+}
+
+type failoverOnNetworkException struct {
+	maxFailovers               int
+	maxRetries                 int
+	maxRetriesOnSocketTimeouts int
+	baseSleep                  time.Duration
+	maxSleep                   time.Duration
+}
+
+func (p *failoverOnNetworkException) ShouldRetry(err error, retries, failovers int, idempotent bool) RetryAction {
+	if failovers >= p.maxFailovers {
+		return RetryAction{Decision: RetryDecisionFail}
+	}
+	// retries on the same rm, failovers are bounded by maxFailovers only
+	sameRMRetries := retries - failovers
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return RetryAction{Decision: RetryDecisionFail}
+	case ipc.IsStandby(err), isConnectError(err):
+		// the call has never been processed by rm, it is safe to fail over for all calls
+		return RetryAction{Decision: RetryDecisionFailoverAndRetry, Delay: p.sleepTime(failovers)}
+	case ipc.IsRetriable(err) && sameRMRetries < p.maxRetries:
+		// rm is busy, try again later on the same rm
+		return RetryAction{Decision: RetryDecisionRetry, Delay: p.sleepTime(retries)}
+	case ipc.IsRpcError(err):
+		// other errors responded by rm will be the same after retrying
+		return RetryAction{Decision: RetryDecisionFail}
+	case errors.Is(err, ipc.ErrCallTimeout) && sameRMRetries < p.maxRetriesOnSocketTimeouts:
+		return RetryAction{Decision: RetryDecisionRetry, Delay: p.sleepTime(retries)}
+	case idempotent:
+		// the connection is broken after sending, which is only safe to send again for idempotent calls
+		return RetryAction{Decision: RetryDecisionFailoverAndRetry, Delay: p.sleepTime(retries)}
+	}
+	return RetryAction{Decision: RetryDecisionFail}
+}
+
+// retryCanceledError is returned if ctx is done while waiting to retry, which matches both the error of ctx and the
+// last error of calls by errors.Is and errors.As, e.g. context.DeadlineExceeded and ipc.IsStandby
+type retryCanceledError struct {
+	ctxErr  error
+	lastErr error
+}
+
+func (e *retryCanceledError) Error() string {
+	return fmt.Sprintf("%v, last error %v", e.ctxErr, e.lastErr)
+}
+
+func (e *retryCanceledError) Is(target error) bool {
+	return errors.Is(e.ctxErr, target)
+}
+
+func (e *retryCanceledError) Unwrap() error {
+	return e.lastErr
+}
+
+// retryInvoke calls the server picked by pick until the call succeeds or policy gives up. All attempts share the
+// same call id with an increasing retry count, so that the retry cache of server recognizes them. pick returns the
+// index of server, which is passed to failover when the call should go to the next server, failover is nil if there
+// is no other server.
+func retryInvoke[T any](ctx context.Context, name string, policy RetryPolicy, idempotent bool,
+	pick func() (int, T, error), call func(ctx context.Context, target T) error, failover func(fromIndex int)) error {
+	callId := ipc.NextCallId()
+	for retries, failovers := 0, 0; ; retries++ {
+		index, target, err := pick()
+		if err != nil {
+			return err
+		}
+		err = call(ipc.WithRetry(ctx, callId, int32(retries)), target)
+		if err == nil {
+			return nil
+		}
+		action := policy.ShouldRetry(err, retries, failovers, idempotent)
+		if action.Decision == RetryDecisionFail {
+			return err
+		} else if action.Decision == RetryDecisionFailoverAndRetry && failover == nil {
+			// there is no other server to fail over to, fail fast and leave retrying the only server to callers
+			return err
+		}
+		klog.V(4).Infof("%v failed, %v after %v, retries %v, failovers %v, error %v",
+			name, action.Decision, action.Delay, retries, failovers, err)
+
+		if action.Delay > 0 {
+			timer := time.NewTimer(action.Delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return &retryCanceledError{ctxErr: ctx.Err(), lastErr: err}
+			case <-timer.C:
+			}
+		}
+		if action.Decision == RetryDecisionFailoverAndRetry {
+			failover(index)
+			failovers++
+		}
+	}
+}
+
+// sleepTime is zero for the first attempt, so that a normal rm failover is handled immediately
+func (p *failoverOnNetworkException) sleepTime(times int) time.Duration {
+	if times == 0 {
+		return 0
+	}
+	return exponentialBackoff(p.baseSleep, times, p.maxSleep)
+}
+
+// exponentialBackoff returns min(base * 2^retries, cap) with a random factor in [0.5, 1.5)
+func exponentialBackoff(base time.Duration, retries int, cap time.Duration) time.Duration {
+	sleep := cap
+	if retries < 62 && base < cap>>uint(retries) {
+		sleep = base << uint(retries)
+	}
+	return time.Duration(float64(sleep) * (rand.Float64() + 0.5))
+}
+
+// isConnectError returns whether the connection cannot be established, e.g. refused or no route to host
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
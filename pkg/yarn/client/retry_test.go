@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+func TestFailoverOnNetworkExceptionPolicy(t *testing.T) {
+	policy := NewFailoverOnNetworkExceptionPolicy(3, 1, 2, 100*time.Millisecond, time.Second)
+	standbyErr := &ipc.RpcError{
+		Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+		ExceptionClassName: ipc.StandbyException,
+		ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+	}
+	retriableErr := &ipc.RpcError{
+		Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+		ExceptionClassName: ipc.RetriableException,
+		ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+	}
+	appErr := &ipc.RpcError{
+		Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+		ExceptionClassName: ipc.IllegalArgumentException,
+		ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+	}
+	connectErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	tests := []struct {
+		name       string
+		err        error
+		retries    int
+		failovers  int
+		idempotent bool
+		want       RetryDecision
+	}{
+		{name: "standby rm fails over", err: standbyErr, want: RetryDecisionFailoverAndRetry},
+		{name: "connect error fails over", err: fmt.Errorf("wrapped: %w", connectErr), want: RetryDecisionFailoverAndRetry},
+		{name: "retriable error retries", err: retriableErr, want: RetryDecisionRetry},
+		{name: "application error fails", err: appErr, idempotent: true, want: RetryDecisionFail},
+		{name: "context canceled fails", err: context.Canceled, idempotent: true, want: RetryDecisionFail},
+		{name: "call timeout retries", err: ipc.ErrCallTimeout, retries: 1, want: RetryDecisionRetry},
+		{name: "broken connection fails over for idempotent call", err: ipc.ErrConnectionClosed, idempotent: true, want: RetryDecisionFailoverAndRetry},
+		{name: "broken connection fails for non-idempotent call", err: ipc.ErrConnectionClosed, want: RetryDecisionFail},
+		{name: "max failovers exceeded", err: standbyErr, retries: 3, failovers: 3, want: RetryDecisionFail},
+		{name: "max retries exceeded", err: retriableErr, retries: 3, want: RetryDecisionFail},
+		{name: "max retries reached", err: retriableErr, retries: 1, want: RetryDecisionFail},
+		{name: "retries after failovers", err: retriableErr, retries: 2, failovers: 2, want: RetryDecisionRetry},
+		{name: "max socket timeout retries reached", err: ipc.ErrCallTimeout, retries: 2, want: RetryDecisionFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.ShouldRetry(tt.err, tt.retries, tt.failovers, tt.idempotent)
+			assert.Equal(t, tt.want, got.Decision)
+			if tt.retries == 0 && tt.failovers == 0 {
+				assert.Equal(t, time.Duration(0), got.Delay)
+			}
+		})
+	}
+}
+
+func TestRetryInvokeCanceledDuringBackoff(t *testing.T) {
+	policy := NewFailoverOnNetworkExceptionPolicy(3, 1, 2, time.Minute, time.Minute)
+	standbyErr := &ipc.RpcError{
+		Status:             hadoop_common.RpcResponseHeaderProto_ERROR,
+		ExceptionClassName: ipc.StandbyException,
+		ErrorCode:          hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	err := retryInvoke(ctx, "test", policy, true,
+		func() (int, string, error) { return calls % 2, "rm", nil },
+		func(ctx context.Context, target string) error { calls++; return standbyErr },
+		func(fromIndex int) {})
+	assert.Equal(t, 2, calls)
+	// both the error of ctx and the last error of calls are kept
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, ipc.IsStandby(err))
+	assert.ErrorContains(t, err, "context deadline exceeded, last error")
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	for retries := 0; retries < 70; retries++ {
+		got := exponentialBackoff(100*time.Millisecond, retries, time.Second)
+		assert.True(t, got >= 50*time.Millisecond && got < 1500*time.Millisecond, "retries %v got %v", retries, got)
+	}
+}
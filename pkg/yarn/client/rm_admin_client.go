@@ -17,7 +17,10 @@ limitations under the License.
 package client
 
 import (
+	"context"
+
 	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
 	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
 	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
 )
@@ -26,14 +29,200 @@ type YarnAdminClient struct {
 	client yarnservice.ResourceManagerAdministrationProtocolService
 }
 
-func CreateYarnAdminClient(conf yarnconf.YarnConfiguration, rmAddress *string) (*YarnAdminClient, error) {
-	c, err := yarnservice.DialResourceManagerAdministrationProtocolService(conf, rmAddress)
+func CreateYarnAdminClient(conf yarnconf.YarnConfiguration, rmAddress *string, ugi *security.UserGroupInformation) (*YarnAdminClient, error) {
+	c, err := yarnservice.DialResourceManagerAdministrationProtocolService(conf, rmAddress, ugi)
 	return &YarnAdminClient{client: c}, err
 }
 
+func (c *YarnAdminClient) RefreshQueues(request *yarnserver.RefreshQueuesRequestProto) (*yarnserver.RefreshQueuesResponseProto, error) {
+	return c.RefreshQueuesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshQueuesWithContext(ctx context.Context, request *yarnserver.RefreshQueuesRequestProto) (*yarnserver.RefreshQueuesResponseProto, error) {
+	response := &yarnserver.RefreshQueuesResponseProto{}
+	err := c.client.RefreshQueuesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshNodes(request *yarnserver.RefreshNodesRequestProto) (*yarnserver.RefreshNodesResponseProto, error) {
+	return c.RefreshNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshNodesWithContext(ctx context.Context, request *yarnserver.RefreshNodesRequestProto) (*yarnserver.RefreshNodesResponseProto, error) {
+	response := &yarnserver.RefreshNodesResponseProto{}
+	err := c.client.RefreshNodesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshSuperUserGroupsConfiguration(request *yarnserver.RefreshSuperUserGroupsConfigurationRequestProto) (*yarnserver.RefreshSuperUserGroupsConfigurationResponseProto, error) {
+	return c.RefreshSuperUserGroupsConfigurationWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshSuperUserGroupsConfigurationWithContext(ctx context.Context, request *yarnserver.RefreshSuperUserGroupsConfigurationRequestProto) (*yarnserver.RefreshSuperUserGroupsConfigurationResponseProto, error) {
+	response := &yarnserver.RefreshSuperUserGroupsConfigurationResponseProto{}
+	err := c.client.RefreshSuperUserGroupsConfigurationWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshUserToGroupsMappings(request *yarnserver.RefreshUserToGroupsMappingsRequestProto) (*yarnserver.RefreshUserToGroupsMappingsResponseProto, error) {
+	return c.RefreshUserToGroupsMappingsWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshUserToGroupsMappingsWithContext(ctx context.Context, request *yarnserver.RefreshUserToGroupsMappingsRequestProto) (*yarnserver.RefreshUserToGroupsMappingsResponseProto, error) {
+	response := &yarnserver.RefreshUserToGroupsMappingsResponseProto{}
+	err := c.client.RefreshUserToGroupsMappingsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshAdminAcls(request *yarnserver.RefreshAdminAclsRequestProto) (*yarnserver.RefreshAdminAclsResponseProto, error) {
+	return c.RefreshAdminAclsWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshAdminAclsWithContext(ctx context.Context, request *yarnserver.RefreshAdminAclsRequestProto) (*yarnserver.RefreshAdminAclsResponseProto, error) {
+	response := &yarnserver.RefreshAdminAclsResponseProto{}
+	err := c.client.RefreshAdminAclsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshServiceAcls(request *yarnserver.RefreshServiceAclsRequestProto) (*yarnserver.RefreshServiceAclsResponseProto, error) {
+	return c.RefreshServiceAclsWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshServiceAclsWithContext(ctx context.Context, request *yarnserver.RefreshServiceAclsRequestProto) (*yarnserver.RefreshServiceAclsResponseProto, error) {
+	response := &yarnserver.RefreshServiceAclsResponseProto{}
+	err := c.client.RefreshServiceAclsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) GetGroupsForUser(request *yarnserver.GetGroupsForUserRequestProto) (*yarnserver.GetGroupsForUserResponseProto, error) {
+	return c.GetGroupsForUserWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) GetGroupsForUserWithContext(ctx context.Context, request *yarnserver.GetGroupsForUserRequestProto) (*yarnserver.GetGroupsForUserResponseProto, error) {
+	response := &yarnserver.GetGroupsForUserResponseProto{}
+	err := c.client.GetGroupsForUserWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
 func (c *YarnAdminClient) UpdateNodeResource(request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
+	return c.UpdateNodeResourceWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) UpdateNodeResourceWithContext(ctx context.Context, request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
 	response := &yarnserver.UpdateNodeResourceResponseProto{}
-	err := c.client.UpdateNodeResource(request, response)
+	err := c.client.UpdateNodeResourceWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshNodesResources(request *yarnserver.RefreshNodesResourcesRequestProto) (*yarnserver.RefreshNodesResourcesResponseProto, error) {
+	return c.RefreshNodesResourcesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshNodesResourcesWithContext(ctx context.Context, request *yarnserver.RefreshNodesResourcesRequestProto) (*yarnserver.RefreshNodesResourcesResponseProto, error) {
+	response := &yarnserver.RefreshNodesResourcesResponseProto{}
+	err := c.client.RefreshNodesResourcesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) AddToClusterNodeLabels(request *yarnserver.AddToClusterNodeLabelsRequestProto) (*yarnserver.AddToClusterNodeLabelsResponseProto, error) {
+	return c.AddToClusterNodeLabelsWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) AddToClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.AddToClusterNodeLabelsRequestProto) (*yarnserver.AddToClusterNodeLabelsResponseProto, error) {
+	response := &yarnserver.AddToClusterNodeLabelsResponseProto{}
+	err := c.client.AddToClusterNodeLabelsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RemoveFromClusterNodeLabels(request *yarnserver.RemoveFromClusterNodeLabelsRequestProto) (*yarnserver.RemoveFromClusterNodeLabelsResponseProto, error) {
+	return c.RemoveFromClusterNodeLabelsWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RemoveFromClusterNodeLabelsWithContext(ctx context.Context, request *yarnserver.RemoveFromClusterNodeLabelsRequestProto) (*yarnserver.RemoveFromClusterNodeLabelsResponseProto, error) {
+	response := &yarnserver.RemoveFromClusterNodeLabelsResponseProto{}
+	err := c.client.RemoveFromClusterNodeLabelsWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) ReplaceLabelsOnNodes(request *yarnserver.ReplaceLabelsOnNodeRequestProto) (*yarnserver.ReplaceLabelsOnNodeResponseProto, error) {
+	return c.ReplaceLabelsOnNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) ReplaceLabelsOnNodesWithContext(ctx context.Context, request *yarnserver.ReplaceLabelsOnNodeRequestProto) (*yarnserver.ReplaceLabelsOnNodeResponseProto, error) {
+	response := &yarnserver.ReplaceLabelsOnNodeResponseProto{}
+	err := c.client.ReplaceLabelsOnNodesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) CheckForDecommissioningNodes(request *yarnserver.CheckForDecommissioningNodesRequestProto) (*yarnserver.CheckForDecommissioningNodesResponseProto, error) {
+	return c.CheckForDecommissioningNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) CheckForDecommissioningNodesWithContext(ctx context.Context, request *yarnserver.CheckForDecommissioningNodesRequestProto) (*yarnserver.CheckForDecommissioningNodesResponseProto, error) {
+	response := &yarnserver.CheckForDecommissioningNodesResponseProto{}
+	err := c.client.CheckForDecommissioningNodesWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) RefreshClusterMaxPriority(request *yarnserver.RefreshClusterMaxPriorityRequestProto) (*yarnserver.RefreshClusterMaxPriorityResponseProto, error) {
+	return c.RefreshClusterMaxPriorityWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) RefreshClusterMaxPriorityWithContext(ctx context.Context, request *yarnserver.RefreshClusterMaxPriorityRequestProto) (*yarnserver.RefreshClusterMaxPriorityResponseProto, error) {
+	response := &yarnserver.RefreshClusterMaxPriorityResponseProto{}
+	err := c.client.RefreshClusterMaxPriorityWithContext(ctx, request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *YarnAdminClient) MapAttributesToNodes(request *yarnserver.NodesToAttributesMappingRequestProto) (*yarnserver.NodesToAttributesMappingResponseProto, error) {
+	return c.MapAttributesToNodesWithContext(context.Background(), request)
+}
+
+func (c *YarnAdminClient) MapAttributesToNodesWithContext(ctx context.Context, request *yarnserver.NodesToAttributesMappingRequestProto) (*yarnserver.NodesToAttributesMappingResponseProto, error) {
+	response := &yarnserver.NodesToAttributesMappingResponseProto{}
+	err := c.client.MapAttributesToNodesWithContext(ctx, request, response)
 	if err != nil {
 		return nil, err
 	}
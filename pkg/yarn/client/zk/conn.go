@@ -0,0 +1,460 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	ErrNoNode           = errors.New("zk: node does not exist")
+	ErrConnectionClosed = errors.New("zk: connection closed")
+)
+
+const defaultPort = "2181"
+
+type EventType int32
+
+const (
+	EventNodeCreated         EventType = 1
+	EventNodeDeleted         EventType = 2
+	EventNodeDataChanged     EventType = 3
+	EventNodeChildrenChanged EventType = 4
+	// EventNotWatching is sent to the watches of a session lost, since zookeeper drops them with the session
+	EventNotWatching EventType = -2
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventNodeCreated:
+		return "NodeCreated"
+	case EventNodeDeleted:
+		return "NodeDeleted"
+	case EventNodeDataChanged:
+		return "NodeDataChanged"
+	case EventNodeChildrenChanged:
+		return "NodeChildrenChanged"
+	case EventNotWatching:
+		return "NotWatching"
+	}
+	return fmt.Sprintf("EventType(%d)", int32(t))
+}
+
+// Event is the notification of a watch, which fires once
+type Event struct {
+	Type EventType
+	Path string
+	// Err is the reason of EventNotWatching
+	Err error
+}
+
+// Conn is a session of zookeeper on a single connection, which supports the reads and watches needed to follow
+// znodes like the leader election lock of rm. The session ends once the connection is lost, callers dial again
+// and set their watches again, which are notified by EventNotWatching.
+type Conn struct {
+	conn      net.Conn
+	chroot    string
+	timeout   time.Duration
+	sessionID int64
+
+	writeMtx sync.Mutex
+
+	mtx     sync.Mutex
+	xid     int32
+	pending map[int32]*request
+	watches map[string][]chan Event
+	err     error
+	closing bool
+	done    chan struct{}
+}
+
+// request is a call waiting for its reply, the watch is set by the receiver before the reply is delivered, so that
+// no event after the reply is missed
+type request struct {
+	replies chan reply
+	path    string
+	watch   chan Event
+	// watchNoNode sets the watch even if the node does not exist, which is the watch of creation set by exists
+	watchNoNode bool
+}
+
+type reply struct {
+	header ReplyHeader
+	body   *Decoder
+	err    error
+}
+
+// ParseConnectString parses "host1:port1,host2:port2[/chroot]" as zookeeper clients, the port is 2181 if omitted
+func ParseConnectString(connectString string) ([]string, string, error) {
+	hosts, chroot := connectString, ""
+	if i := strings.Index(connectString, "/"); i >= 0 {
+		hosts, chroot = connectString[:i], strings.TrimSuffix(connectString[i:], "/")
+	}
+	var servers []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, defaultPort)
+		}
+		servers = append(servers, host)
+	}
+	if len(servers) == 0 {
+		return nil, "", fmt.Errorf("no zookeeper server in %q", connectString)
+	}
+	return servers, chroot, nil
+}
+
+// Dial creates a session of timeout with a server of connectString, servers are tried in order until one accepts
+func Dial(ctx context.Context, connectString string, timeout time.Duration) (*Conn, error) {
+	servers, chroot, err := ParseConnectString(connectString)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	for _, server := range servers {
+		c, err := dial(ctx, server, chroot, timeout)
+		if err == nil {
+			return c, nil
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, fmt.Sprintf("%v: %v", server, err))
+	}
+	return nil, fmt.Errorf("connect zookeeper %v failed, errors %v", connectString, errs)
+}
+
+func dial(ctx context.Context, server string, chroot string, timeout time.Duration) (*Conn, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", server)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+	response := &ConnectResponse{}
+	if err := handshake(conn, timeout, response); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	c := &Conn{
+		conn:      conn,
+		chroot:    chroot,
+		timeout:   time.Duration(response.TimeOut) * time.Millisecond,
+		sessionID: response.SessionID,
+		pending:   map[int32]*request{},
+		watches:   map[string][]chan Event{},
+		done:      make(chan struct{}),
+	}
+	klog.V(4).Infof("zookeeper session 0x%x established with %v, timeout %v", c.sessionID, server, c.timeout)
+	go c.recvLoop()
+	go c.pingLoop()
+	return c, nil
+}
+
+func handshake(conn net.Conn, timeout time.Duration, response *ConnectResponse) error {
+	if err := WritePacket(conn, &ConnectRequest{TimeOut: int32(timeout / time.Millisecond), Passwd: make([]byte, 16)}); err != nil {
+		return err
+	}
+	packet, err := ReadPacket(conn)
+	if err != nil {
+		return err
+	}
+	d := NewDecoder(packet)
+	response.Decode(d)
+	if d.Err() != nil {
+		return fmt.Errorf("malformed connect response, error %v", d.Err())
+	}
+	if response.TimeOut <= 0 {
+		return errors.New("session is refused by zookeeper")
+	}
+	return nil
+}
+
+func (c *Conn) SessionID() int64 {
+	return c.sessionID
+}
+
+// Done is closed once the session ends, Err returns the reason
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Conn) Err() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.err
+}
+
+// Close closes the session, the watches are notified by EventNotWatching
+func (c *Conn) Close() {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.mtx.Lock()
+	c.closing = true
+	c.mtx.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.call(ctx, OpCloseSession, nil, nil); err != nil {
+		klog.V(4).Infof("close zookeeper session 0x%x failed, error %v", c.sessionID, err)
+	}
+	c.closeWithError(ErrConnectionClosed)
+}
+
+// Get returns the data of path
+func (c *Conn) Get(ctx context.Context, path string) ([]byte, *Stat, error) {
+	return c.get(ctx, path, nil)
+}
+
+// GetW returns the data of path and sets a watch of its change or deletion, which is not set if the node does not
+// exist, use ExistsW to watch the creation
+func (c *Conn) GetW(ctx context.Context, path string) ([]byte, *Stat, <-chan Event, error) {
+	watch := make(chan Event, 1)
+	data, stat, err := c.get(ctx, path, &request{watch: watch})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return data, stat, watch, nil
+}
+
+func (c *Conn) get(ctx context.Context, path string, req *request) ([]byte, *Stat, error) {
+	if req == nil {
+		req = &request{}
+	}
+	req.path = c.serverPath(path)
+	body, err := c.call(ctx, OpGetData, &PathWatchRequest{Path: req.path, Watch: req.watch != nil}, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	response := &GetDataResponse{}
+	response.Decode(body)
+	if body.Err() != nil {
+		return nil, nil, fmt.Errorf("malformed getData response, error %v", body.Err())
+	}
+	return response.Data, &response.Stat, nil
+}
+
+// ExistsW returns whether path exists and sets a watch of its creation, change or deletion
+func (c *Conn) ExistsW(ctx context.Context, path string) (bool, *Stat, <-chan Event, error) {
+	watch := make(chan Event, 1)
+	req := &request{path: c.serverPath(path), watch: watch, watchNoNode: true}
+	body, err := c.call(ctx, OpExists, &PathWatchRequest{Path: req.path, Watch: true}, req)
+	if errors.Is(err, ErrNoNode) {
+		return false, nil, watch, nil
+	} else if err != nil {
+		return false, nil, nil, err
+	}
+	response := &ExistsResponse{}
+	response.Decode(body)
+	if body.Err() != nil {
+		return false, nil, nil, fmt.Errorf("malformed exists response, error %v", body.Err())
+	}
+	return true, &response.Stat, watch, nil
+}
+
+func (c *Conn) serverPath(path string) string {
+	if c.chroot == "" {
+		return path
+	}
+	if path == "/" {
+		return c.chroot
+	}
+	return c.chroot + path
+}
+
+func (c *Conn) clientPath(path string) string {
+	if c.chroot == "" {
+		return path
+	}
+	if path == c.chroot {
+		return "/"
+	}
+	return strings.TrimPrefix(path, c.chroot)
+}
+
+// call sends op with body and waits for its reply, errors of zookeeper are returned as errors
+func (c *Conn) call(ctx context.Context, op int32, body Record, req *request) (*Decoder, error) {
+	if req == nil {
+		req = &request{}
+	}
+	req.replies = make(chan reply, 1)
+	c.mtx.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mtx.Unlock()
+		return nil, err
+	}
+	c.xid++
+	xid := c.xid
+	c.pending[xid] = req
+	c.mtx.Unlock()
+
+	records := []Record{&RequestHeader{Xid: xid, Type: op}}
+	if body != nil {
+		records = append(records, body)
+	}
+	if err := c.write(records...); err != nil {
+		c.closeWithError(err)
+	}
+
+	select {
+	case r := <-req.replies:
+		if r.err != nil {
+			return nil, r.err
+		}
+		switch r.header.Err {
+		case ErrCodeOK:
+			return r.body, nil
+		case ErrCodeNoNode:
+			return nil, ErrNoNode
+		}
+		return nil, fmt.Errorf("zk: error code %v of op %v", r.header.Err, op)
+	case <-ctx.Done():
+		c.mtx.Lock()
+		delete(c.pending, xid)
+		c.mtx.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Conn) write(records ...Record) error {
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	return WritePacket(c.conn, records...)
+}
+
+// recvLoop dispatches replies and events, the session is considered lost if nothing is received in 2/3 of timeout,
+// during which pings are sent twice, as the client of zookeeper
+func (c *Conn) recvLoop() {
+	for {
+		_ = c.conn.SetReadDeadline(time.Now().Add(c.timeout * 2 / 3))
+		packet, err := ReadPacket(c.conn)
+		if err != nil {
+			c.closeWithError(err)
+			return
+		}
+		d := NewDecoder(packet)
+		header := ReplyHeader{}
+		header.Decode(d)
+		if d.Err() != nil {
+			c.closeWithError(fmt.Errorf("malformed reply header, error %v", d.Err()))
+			return
+		}
+
+		switch header.Xid {
+		case XidPing:
+			continue
+		case XidWatcherEvent:
+			event := &WatcherEvent{}
+			event.Decode(d)
+			if d.Err() != nil {
+				c.closeWithError(fmt.Errorf("malformed watcher event, error %v", d.Err()))
+				return
+			}
+			c.fire(c.clientPath(event.Path), Event{Type: EventType(event.Type), Path: c.clientPath(event.Path)})
+			continue
+		}
+
+		c.mtx.Lock()
+		req, ok := c.pending[header.Xid]
+		delete(c.pending, header.Xid)
+		if ok && req.watch != nil && (header.Err == ErrCodeOK || header.Err == ErrCodeNoNode && req.watchNoNode) {
+			path := c.clientPath(req.path)
+			c.watches[path] = append(c.watches[path], req.watch)
+		}
+		c.mtx.Unlock()
+		if ok {
+			req.replies <- reply{header: header, body: d}
+		}
+	}
+}
+
+func (c *Conn) pingLoop() {
+	ticker := time.NewTicker(c.timeout / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.write(&RequestHeader{Xid: XidPing, Type: OpPing}); err != nil {
+				c.closeWithError(err)
+				return
+			}
+		}
+	}
+}
+
+// fire notifies the watches of path, which are removed since watches fire once
+func (c *Conn) fire(path string, event Event) {
+	c.mtx.Lock()
+	watches := c.watches[path]
+	delete(c.watches, path)
+	c.mtx.Unlock()
+	for _, watch := range watches {
+		watch <- event
+	}
+}
+
+func (c *Conn) closeWithError(err error) {
+	c.mtx.Lock()
+	if c.err != nil {
+		c.mtx.Unlock()
+		return
+	}
+	// the server closes the connection once the session is closed
+	if c.closing {
+		err = ErrConnectionClosed
+	}
+	c.err = err
+	close(c.done)
+	pending, watches := c.pending, c.watches
+	c.pending, c.watches = map[int32]*request{}, map[string][]chan Event{}
+	c.mtx.Unlock()
+
+	c.conn.Close()
+	for _, req := range pending {
+		req.replies <- reply{err: err}
+	}
+	for path, pathWatches := range watches {
+		for _, watch := range pathWatches {
+			watch <- Event{Type: EventNotWatching, Path: path, Err: err}
+		}
+	}
+	if err != ErrConnectionClosed {
+		klog.V(4).Infof("zookeeper session 0x%x is lost, error %v", c.sessionID, err)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zk_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/zk"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/fakezk"
+)
+
+func newTestConn(t *testing.T, chroot string) (*fakezk.Server, *zk.Conn) {
+	server, err := fakezk.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	conn, err := zk.Dial(context.Background(), "127.0.0.1:1,"+server.Address()+chroot, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+	return server, conn
+}
+
+func receive(t *testing.T, watch <-chan zk.Event) zk.Event {
+	select {
+	case event := <-watch:
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch is not fired")
+	}
+	return zk.Event{}
+}
+
+func TestParseConnectString(t *testing.T) {
+	servers, chroot, err := zk.ParseConnectString("zk1:2182, zk2/yarn/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"zk1:2182", "zk2:2181"}, servers)
+	assert.Equal(t, "/yarn", chroot)
+
+	_, _, err = zk.ParseConnectString("/yarn")
+	assert.Error(t, err)
+}
+
+func TestGetAndWatch(t *testing.T) {
+	server, conn := newTestConn(t, "/yarn")
+	ctx := context.Background()
+
+	_, _, err := conn.Get(ctx, "/a")
+	assert.ErrorIs(t, err, zk.ErrNoNode)
+	_, _, _, err = conn.GetW(ctx, "/a")
+	assert.ErrorIs(t, err, zk.ErrNoNode)
+
+	exists, _, watch, err := conn.ExistsW(ctx, "/a")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	server.Set("/yarn/a", []byte("v1"))
+	assert.Equal(t, zk.Event{Type: zk.EventNodeCreated, Path: "/a"}, receive(t, watch))
+
+	data, stat, watch, err := conn.GetW(ctx, "/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", string(data))
+	assert.Equal(t, int32(0), stat.Version)
+	server.Set("/yarn/a", []byte("v2"))
+	assert.Equal(t, zk.Event{Type: zk.EventNodeDataChanged, Path: "/a"}, receive(t, watch))
+
+	exists, stat, watch, err = conn.ExistsW(ctx, "/a")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, int32(1), stat.Version)
+	server.Delete("/yarn/a")
+	assert.Equal(t, zk.Event{Type: zk.EventNodeDeleted, Path: "/a"}, receive(t, watch))
+}
+
+func TestSessionLost(t *testing.T) {
+	server, conn := newTestConn(t, "")
+	_, _, watch, err := conn.ExistsW(context.Background(), "/a")
+	assert.NoError(t, err)
+
+	server.ExpireSessions()
+	event := receive(t, watch)
+	assert.Equal(t, zk.EventNotWatching, event.Type)
+	assert.Error(t, event.Err)
+	<-conn.Done()
+	_, _, err = conn.Get(context.Background(), "/a")
+	assert.Equal(t, conn.Err(), err)
+}
+
+func TestClose(t *testing.T) {
+	server, conn := newTestConn(t, "")
+	_, _, watch, err := conn.ExistsW(context.Background(), "/a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, server.SessionCount())
+
+	conn.Close()
+	assert.Equal(t, zk.Event{Type: zk.EventNotWatching, Path: "/a", Err: zk.ErrConnectionClosed}, receive(t, watch))
+	assert.Eventually(t, func() bool { return server.SessionCount() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
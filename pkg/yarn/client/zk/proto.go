@@ -0,0 +1,372 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zk
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// the records of zookeeper protocol in jute encoding, only the ones of reads and watches are defined
+
+const (
+	OpExists       int32 = 3
+	OpGetData      int32 = 4
+	OpPing         int32 = 11
+	OpCloseSession int32 = -11
+
+	XidWatcherEvent int32 = -1
+	XidPing         int32 = -2
+
+	ErrCodeOK            int32 = 0
+	ErrCodeUnimplemented int32 = -6
+	ErrCodeNoNode        int32 = -101
+
+	StateSyncConnected int32 = 3
+
+	// maxPacketSize is jute.maxbuffer of zookeeper
+	maxPacketSize = 0xfffff
+)
+
+// Encoder appends the jute encoding of records, which is big endian
+type Encoder struct {
+	buf []byte
+}
+
+func (e *Encoder) Int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *Encoder) Int64(v int64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v))
+}
+
+func (e *Encoder) Bool(v bool) {
+	if v {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+// Buffer writes b with its length, nil is written as length -1
+func (e *Encoder) Buffer(b []byte) {
+	if b == nil {
+		e.Int32(-1)
+		return
+	}
+	e.Int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *Encoder) String(s string) {
+	e.Int32(int32(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// Decoder reads records from the jute encoding, the first error is kept and returned by Err
+type Decoder struct {
+	buf []byte
+	err error
+}
+
+func NewDecoder(b []byte) *Decoder {
+	return &Decoder{buf: b}
+}
+
+func (d *Decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *Decoder) Int32() int32 {
+	if b := d.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *Decoder) Int64() int64 {
+	if b := d.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *Decoder) Bool() bool {
+	if b := d.next(1); b != nil {
+		return b[0] != 0
+	}
+	return false
+}
+
+func (d *Decoder) Buffer() []byte {
+	n := d.Int32()
+	if n < 0 {
+		return nil
+	}
+	return append([]byte(nil), d.next(int(n))...)
+}
+
+func (d *Decoder) String() string {
+	return string(d.Buffer())
+}
+
+// Remaining returns the number of bytes not read, e.g. optional fields of newer versions
+func (d *Decoder) Remaining() int {
+	return len(d.buf)
+}
+
+func (d *Decoder) Err() error {
+	return d.err
+}
+
+// Record is a record of zookeeper protocol
+type Record interface {
+	Encode(e *Encoder)
+	Decode(d *Decoder)
+}
+
+type ConnectRequest struct {
+	ProtocolVersion int32
+	LastZxidSeen    int64
+	TimeOut         int32
+	SessionID       int64
+	Passwd          []byte
+	ReadOnly        bool
+}
+
+func (r *ConnectRequest) Encode(e *Encoder) {
+	e.Int32(r.ProtocolVersion)
+	e.Int64(r.LastZxidSeen)
+	e.Int32(r.TimeOut)
+	e.Int64(r.SessionID)
+	e.Buffer(r.Passwd)
+	e.Bool(r.ReadOnly)
+}
+
+func (r *ConnectRequest) Decode(d *Decoder) {
+	r.ProtocolVersion = d.Int32()
+	r.LastZxidSeen = d.Int64()
+	r.TimeOut = d.Int32()
+	r.SessionID = d.Int64()
+	r.Passwd = d.Buffer()
+	// readOnly is only sent by clients of 3.4 and later
+	if d.Remaining() > 0 {
+		r.ReadOnly = d.Bool()
+	}
+}
+
+type ConnectResponse struct {
+	ProtocolVersion int32
+	TimeOut         int32
+	SessionID       int64
+	Passwd          []byte
+	ReadOnly        bool
+}
+
+func (r *ConnectResponse) Encode(e *Encoder) {
+	e.Int32(r.ProtocolVersion)
+	e.Int32(r.TimeOut)
+	e.Int64(r.SessionID)
+	e.Buffer(r.Passwd)
+	e.Bool(r.ReadOnly)
+}
+
+func (r *ConnectResponse) Decode(d *Decoder) {
+	r.ProtocolVersion = d.Int32()
+	r.TimeOut = d.Int32()
+	r.SessionID = d.Int64()
+	r.Passwd = d.Buffer()
+	if d.Remaining() > 0 {
+		r.ReadOnly = d.Bool()
+	}
+}
+
+type RequestHeader struct {
+	Xid  int32
+	Type int32
+}
+
+func (r *RequestHeader) Encode(e *Encoder) {
+	e.Int32(r.Xid)
+	e.Int32(r.Type)
+}
+
+func (r *RequestHeader) Decode(d *Decoder) {
+	r.Xid = d.Int32()
+	r.Type = d.Int32()
+}
+
+type ReplyHeader struct {
+	Xid  int32
+	Zxid int64
+	Err  int32
+}
+
+func (r *ReplyHeader) Encode(e *Encoder) {
+	e.Int32(r.Xid)
+	e.Int64(r.Zxid)
+	e.Int32(r.Err)
+}
+
+func (r *ReplyHeader) Decode(d *Decoder) {
+	r.Xid = d.Int32()
+	r.Zxid = d.Int64()
+	r.Err = d.Int32()
+}
+
+// PathWatchRequest is the request of exists and getData
+type PathWatchRequest struct {
+	Path  string
+	Watch bool
+}
+
+func (r *PathWatchRequest) Encode(e *Encoder) {
+	e.String(r.Path)
+	e.Bool(r.Watch)
+}
+
+func (r *PathWatchRequest) Decode(d *Decoder) {
+	r.Path = d.String()
+	r.Watch = d.Bool()
+}
+
+type Stat struct {
+	Czxid          int64
+	Mzxid          int64
+	Ctime          int64
+	Mtime          int64
+	Version        int32
+	Cversion       int32
+	Aversion       int32
+	EphemeralOwner int64
+	DataLength     int32
+	NumChildren    int32
+	Pzxid          int64
+}
+
+func (s *Stat) Encode(e *Encoder) {
+	e.Int64(s.Czxid)
+	e.Int64(s.Mzxid)
+	e.Int64(s.Ctime)
+	e.Int64(s.Mtime)
+	e.Int32(s.Version)
+	e.Int32(s.Cversion)
+	e.Int32(s.Aversion)
+	e.Int64(s.EphemeralOwner)
+	e.Int32(s.DataLength)
+	e.Int32(s.NumChildren)
+	e.Int64(s.Pzxid)
+}
+
+func (s *Stat) Decode(d *Decoder) {
+	s.Czxid = d.Int64()
+	s.Mzxid = d.Int64()
+	s.Ctime = d.Int64()
+	s.Mtime = d.Int64()
+	s.Version = d.Int32()
+	s.Cversion = d.Int32()
+	s.Aversion = d.Int32()
+	s.EphemeralOwner = d.Int64()
+	s.DataLength = d.Int32()
+	s.NumChildren = d.Int32()
+	s.Pzxid = d.Int64()
+}
+
+// ExistsResponse is the response of exists
+type ExistsResponse struct {
+	Stat Stat
+}
+
+func (r *ExistsResponse) Encode(e *Encoder) {
+	r.Stat.Encode(e)
+}
+
+func (r *ExistsResponse) Decode(d *Decoder) {
+	r.Stat.Decode(d)
+}
+
+type GetDataResponse struct {
+	Data []byte
+	Stat Stat
+}
+
+func (r *GetDataResponse) Encode(e *Encoder) {
+	e.Buffer(r.Data)
+	r.Stat.Encode(e)
+}
+
+func (r *GetDataResponse) Decode(d *Decoder) {
+	r.Data = d.Buffer()
+	r.Stat.Decode(d)
+}
+
+type WatcherEvent struct {
+	Type  int32
+	State int32
+	Path  string
+}
+
+func (r *WatcherEvent) Encode(e *Encoder) {
+	e.Int32(r.Type)
+	e.Int32(r.State)
+	e.String(r.Path)
+}
+
+func (r *WatcherEvent) Decode(d *Decoder) {
+	r.Type = d.Int32()
+	r.State = d.Int32()
+	r.Path = d.String()
+}
+
+// WritePacket writes records in a packet prefixed by its length
+func WritePacket(w io.Writer, records ...Record) error {
+	e := &Encoder{buf: make([]byte, 4, 64)}
+	for _, record := range records {
+		record.Encode(e)
+	}
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+	_, err := w.Write(e.buf)
+	return err
+}
+
+// ReadPacket reads a packet prefixed by its length
+func ReadPacket(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(length[:])
+	if n > maxPacketSize {
+		return nil, fmt.Errorf("zk packet of %v bytes exceeds %v", n, maxPacketSize)
+	}
+	packet := make([]byte, n)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
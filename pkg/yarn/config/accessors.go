@@ -0,0 +1,204 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueError is returned by getters of Configuration for a malformed value, which names the key and the resources
+// setting it
+type ValueError struct {
+	Key     string
+	Value   string
+	Sources []string
+	Err     error
+}
+
+func (e *ValueError) Error() string {
+	if len(e.Sources) == 0 {
+		return fmt.Sprintf("invalid value %q of %v, error %v", e.Value, e.Key, e.Err)
+	}
+	return fmt.Sprintf("invalid value %q of %v from %v, error %v", e.Value, e.Key, strings.Join(e.Sources, ","), e.Err)
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// timeUnits are the suffixes of Configuration.getTimeDuration of hadoop, suffixes ending with others go first
+var timeUnits = []struct {
+	suffix string
+	unit   time.Duration
+}{
+	{"ns", time.Nanosecond},
+	{"us", time.Microsecond},
+	{"ms", time.Millisecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", 24 * time.Hour},
+}
+
+// storageUnits are the suffixes of StorageUnit and TraditionalBinaryPrefix of hadoop, which are multiples of 1024
+var storageUnits = []struct {
+	suffix string
+	shift  uint
+}{
+	{"kb", 10}, {"mb", 20}, {"gb", 30}, {"tb", 40}, {"pb", 50}, {"eb", 60},
+	{"k", 10}, {"m", 20}, {"g", 30}, {"t", 40}, {"p", 50}, {"e", 60},
+	{"b", 0},
+}
+
+func (conf *configuration) newValueError(key string, value string, err error) error {
+	return &ValueError{Key: key, Value: value, Sources: conf.GetPropertySources(key), Err: err}
+}
+
+// getTrimmed returns the value of key trimmed as Configuration.getTrimmed of hadoop, a value of blanks is not set
+func (conf *configuration) getTrimmed(key string) (string, bool, error) {
+	if _, exists := conf.getRaw(key); !exists {
+		return "", false, nil
+	}
+	value, err := conf.Get(key, "")
+	if err != nil {
+		return "", false, err
+	}
+	value = strings.TrimSpace(value)
+	return value, value != "", nil
+}
+
+func (conf *configuration) GetTrimmedStrings(key string) ([]string, error) {
+	value, _, err := conf.getTrimmed(key)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+func (conf *configuration) GetTimeDuration(key string, defaultValue time.Duration, unit time.Duration) (time.Duration, error) {
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil || !exists {
+		return defaultValue, err
+	}
+	duration, err := parseTimeDuration(value, unit)
+	if err != nil {
+		return 0, conf.newValueError(key, value, err)
+	}
+	return duration, nil
+}
+
+func (conf *configuration) GetStorageSize(key string, defaultValue int64) (int64, error) {
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil || !exists {
+		return defaultValue, err
+	}
+	size, err := parseStorageSize(value)
+	if err != nil {
+		return 0, conf.newValueError(key, value, err)
+	}
+	return size, nil
+}
+
+func (conf *configuration) GetSocketAddress(key string, defaultAddress string, defaultPort int) (string, error) {
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		value = defaultAddress
+	}
+	host, port, err := splitHostPort(value, defaultPort)
+	if err != nil {
+		return "", conf.newValueError(key, value, err)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func (conf *configuration) GetIntInRange(key string, defaultValue int, minValue int, maxValue int) (int, error) {
+	value, err := conf.GetInt(key, defaultValue)
+	if err != nil {
+		return 0, err
+	}
+	if value < minValue || value > maxValue {
+		return 0, conf.newValueError(key, strconv.Itoa(value), fmt.Errorf("out of range [%v, %v]", minValue, maxValue))
+	}
+	return value, nil
+}
+
+func (conf *configuration) GetEnum(key string, defaultValue string, values ...string) (string, error) {
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil || !exists {
+		return defaultValue, err
+	}
+	for _, v := range values {
+		if strings.EqualFold(value, v) {
+			return v, nil
+		}
+	}
+	return "", conf.newValueError(key, value, fmt.Errorf("not one of %v", values))
+}
+
+// parseTimeDuration parses value with a suffix of timeUnits, value without suffix is in unit
+func parseTimeDuration(value string, unit time.Duration) (time.Duration, error) {
+	number, lower := value, strings.ToLower(value)
+	for _, u := range timeUnits {
+		if strings.HasSuffix(lower, u.suffix) {
+			number, unit = strings.TrimSpace(value[:len(value)-len(u.suffix)]), u.unit
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil {
+		return 0, errors.New("not a duration with unit ns, us, ms, s, m, h or d")
+	}
+	if n > int64(math.MaxInt64/unit) || n < int64(math.MinInt64/unit) {
+		return 0, errors.New("duration out of range")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseStorageSize parses value with a suffix of storageUnits to bytes, value without suffix is in bytes
+func parseStorageSize(value string) (int64, error) {
+	number, lower, shift := value, strings.ToLower(value), uint(0)
+	for _, u := range storageUnits {
+		if strings.HasSuffix(lower, u.suffix) {
+			number, shift = strings.TrimSpace(value[:len(value)-len(u.suffix)]), u.shift
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) || n < 0 {
+		return 0, errors.New("not a storage size with unit b, kb, mb, gb, tb, pb or eb")
+	}
+	bytes := n * float64(uint64(1)<<shift)
+	if bytes >= math.MaxInt64 {
+		return 0, errors.New("storage size out of range")
+	}
+	return int64(bytes), nil
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"10":   10 * time.Millisecond,
+		"-1":   -time.Millisecond,
+		"10ns": 10 * time.Nanosecond,
+		"10us": 10 * time.Microsecond,
+		"10MS": 10 * time.Millisecond,
+		"10s":  10 * time.Second,
+		"10 m": 10 * time.Minute,
+		"10h":  10 * time.Hour,
+		"2d":   48 * time.Hour,
+	}
+	for value, expected := range tests {
+		duration, err := parseTimeDuration(value, time.Millisecond)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, duration, value)
+	}
+	for _, value := range []string{"10x", "1.5s", "s", "1000d0", "9999999999999999h"} {
+		_, err := parseTimeDuration(value, time.Millisecond)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseStorageSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":   512,
+		"512b":  512,
+		"1KB":   1 << 10,
+		"128MB": 128 << 20,
+		"1.5g":  3 << 29,
+		"2 Tb":  2 << 40,
+		"1e":    1 << 60,
+	}
+	for value, expected := range tests {
+		size, err := parseStorageSize(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+	for _, value := range []string{"8e", "-1k", "1x", "mb"} {
+		_, err := parseStorageSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"yarn-site.xml": `<configuration>
+  <property><name>test.list</name><value> a, b ,,c, </value></property>
+  <property><name>test.duration</name><value>${test.seconds}s</value></property>
+  <property><name>test.seconds</name><value>30</value></property>
+  <property><name>test.size</name><value>64MB</value></property>
+  <property><name>test.address</name><value>rm.example.com</value></property>
+  <property><name>test.int</name><value> 7 </value></property>
+  <property><name>test.enum</name><value>Kerberos</value></property>
+  <property><name>test.blank</name><value>  </value></property>
+  <property><name>test.bad</name><value>ten</value></property>
+</configuration>`,
+	})
+	c, err := NewConfigurationResources(dir, []Resource{YARN_SITE}, "")
+	assert.NoError(t, err)
+
+	list, err := c.GetTrimmedStrings("test.list")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, list)
+	list, err = c.GetTrimmedStrings("test.unset")
+	assert.NoError(t, err)
+	assert.Empty(t, list)
+
+	duration, err := c.GetTimeDuration("test.duration", time.Second, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, duration)
+	duration, err = c.GetTimeDuration("test.blank", time.Second, time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, duration)
+
+	size, err := c.GetStorageSize("test.size", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(64<<20), size)
+
+	address, err := c.GetSocketAddress("test.address", "", 8032)
+	assert.NoError(t, err)
+	assert.Equal(t, "rm.example.com:8032", address)
+	address, err = c.GetSocketAddress("test.unset", "0.0.0.0:8050", 8050)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:8050", address)
+
+	i, err := c.GetIntInRange("test.int", 0, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, i)
+	_, err = c.GetIntInRange("test.int", 0, 8, math.MaxInt32)
+	assert.ErrorContains(t, err, `invalid value "7" of test.int from yarn-site.xml, error out of range [8, 2147483647]`)
+
+	enum, err := c.GetEnum("test.enum", AUTHENTICATION_SIMPLE, AUTHENTICATION_SIMPLE, AUTHENTICATION_KERBEROS)
+	assert.NoError(t, err)
+	assert.Equal(t, AUTHENTICATION_KERBEROS, enum)
+	_, err = c.GetEnum("test.enum", "a", "a", "b")
+	assert.ErrorContains(t, err, `invalid value "Kerberos" of test.enum from yarn-site.xml, error not one of [a b]`)
+
+	// malformed values are errors naming the key and the resource setting it
+	getters := map[string]func() error{
+		"int":      func() error { _, err := c.GetInt("test.bad", 0); return err },
+		"bool":     func() error { _, err := c.GetBool("test.bad", false); return err },
+		"duration": func() error { _, err := c.GetTimeDuration("test.bad", 0, time.Millisecond); return err },
+		"size":     func() error { _, err := c.GetStorageSize("test.bad", 0); return err },
+		"address":  func() error { _, err := c.GetSocketAddress("test.bad", "", -1); return err },
+	}
+	for name, get := range getters {
+		err := get()
+		var valueErr *ValueError
+		if assert.True(t, errors.As(err, &valueErr), name) {
+			assert.Equal(t, "test.bad", valueErr.Key, name)
+			assert.Equal(t, "ten", valueErr.Value, name)
+			assert.Equal(t, []string{"yarn-site.xml"}, valueErr.Sources, name)
+		}
+	}
+}
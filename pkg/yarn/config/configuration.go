@@ -18,12 +18,10 @@ limitations under the License.
 package conf
 
 import (
-	"encoding/xml"
+	"fmt"
 	"io"
-	"os"
 	"strconv"
-
-	"k8s.io/klog/v2"
+	"time"
 )
 
 var (
@@ -33,111 +31,187 @@ var (
 	HDFS_SITE    Resource = Resource{"hdfs-site.xml", false}
 )
 
+const (
+	IPC_CLIENT_CONNECT_TIMEOUT = "ipc.client.connect.timeout"
+	IPC_CLIENT_RPC_TIMEOUT_MS  = "ipc.client.rpc-timeout.ms"
+
+	HADOOP_SECURITY_AUTHENTICATION = "hadoop.security.authentication"
+	HADOOP_RPC_PROTECTION          = "hadoop.rpc.protection"
+
+	AUTHENTICATION_SIMPLE   = "simple"
+	AUTHENTICATION_KERBEROS = "kerberos"
+)
+
 type Resource struct {
 	Name     string
 	Required bool
 }
 
 type Configuration interface {
+	// Get returns the value of key with variables expanded as hadoop, ${name} is a system property or a property of
+	// the configuration, ${env.NAME} is an environment variable, which may have a default as ${env.NAME:-default}
 	Get(key string, defaultValue string) (string, error)
 	GetInt(key string, defaultValue int) (int, error)
 	GetBool(key string, defaultValue bool) (bool, error)
+	// GetTrimmedStrings returns the comma separated values of key trimmed, empty values are dropped
+	GetTrimmedStrings(key string) ([]string, error)
+	// GetTimeDuration returns the duration of key with a suffix of ns, us, ms, s, m, h or d as hadoop, a value without
+	// suffix is in unit
+	GetTimeDuration(key string, defaultValue time.Duration, unit time.Duration) (time.Duration, error)
+	// GetStorageSize returns the bytes of key with a suffix like KB, MB or g as hadoop, a value without suffix is in
+	// bytes
+	GetStorageSize(key string, defaultValue int64) (int64, error)
+	// GetSocketAddress returns host:port of key, whose port is defaultPort if not specified
+	GetSocketAddress(key string, defaultAddress string, defaultPort int) (string, error)
+	// GetIntInRange returns the int of key, which must be in [minValue, maxValue]
+	GetIntInRange(key string, defaultValue int, minValue int, maxValue int) (int, error)
+	// GetEnum returns the one of values matching the value of key case-insensitively
+	GetEnum(key string, defaultValue string, values ...string) (string, error)
+	// GetPropertySources returns the resources setting key, which are the <source> of the property if declared
+	GetPropertySources(key string) []string
+	// Describe returns the value of key and where it is set, which is an error wrapping ErrPropertyNotSet if key is
+	// not set. Sensitive values are redacted as hadoop.security.sensitive-config-keys.
+	Describe(key string) (*PropertyDescription, error)
+	// Dump writes the effective properties in format DUMP_FORMAT_XML or DUMP_FORMAT_JSON, sensitive values are
+	// redacted as hadoop.security.sensitive-config-keys
+	Dump(w io.Writer, format string) error
+	// WriteXML writes the raw properties as a <configuration> of hadoop without redaction, which loads as the same
+	// configuration by hadoop or NewConfigurationFromReader
+	WriteXML(w io.Writer) error
+	// Files returns the paths of the resources and includes read in order, the ones missing are returned too since
+	// creating them changes the configuration
+	Files() []string
 
 	Set(key string, value string) error
 	SetInt(key string, value int) error
 }
 
+// programmaticSource is the source of properties set in code rather than loaded from resources
+const programmaticSource = "programmatically"
+
+// configuration keeps properties as the Configuration of hadoop, deprecated keys are set and read with their new keys
 type configuration struct {
 	Properties map[string]string
+	// finals are the properties declared final, which are not overridden by resources loaded later
+	finals  map[string]bool
+	sources map[string][]string
+	// locations are the resources and lines setting properties
+	locations map[string]propertyLocation
+	// files are the paths of resources and includes read, whether they exist or not
+	files []string
 }
 
-type property struct {
-	Name  string `xml:"name"`
-	Value string `xml:"value"`
+// propertyLocation is where a property is set, the line is 0 if it is not set by a resource
+type propertyLocation struct {
+	resource string
+	line     int
 }
 
-type hadoopConfiguration struct {
-	XMLName    xml.Name   `xml:"configuration"`
-	Properties []property `xml:"property"`
+func newConfiguration() *configuration {
+	return &configuration{
+		Properties: map[string]string{},
+		finals:     map[string]bool{},
+		sources:    map[string][]string{},
+		locations:  map[string]propertyLocation{},
+	}
 }
 
 func (conf *configuration) Get(key string, defaultValue string) (string, error) {
-	value, exists := conf.Properties[key]
+	value, exists := conf.getRaw(key)
 	if !exists {
-		return defaultValue, nil
+		value = defaultValue
 	}
-	return value, nil
+	return conf.substituteVars(value)
 }
 
 func (conf *configuration) GetInt(key string, defaultValue int) (int, error) {
-	value, exists := conf.Properties[key]
-	if !exists {
-		return defaultValue, nil
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil || !exists {
+		return defaultValue, err
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, conf.newValueError(key, value, err)
 	}
-	return strconv.Atoi(value)
+	return i, nil
 }
 
 func (conf *configuration) GetBool(key string, defaultValue bool) (bool, error) {
-	value, exists := conf.Properties[key]
-	if !exists {
-		return defaultValue, nil
+	value, exists, err := conf.getTrimmed(key)
+	if err != nil || !exists {
+		return defaultValue, err
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, conf.newValueError(key, value, err)
 	}
-	return strconv.ParseBool(value)
+	return b, nil
 }
 
+func (conf *configuration) GetPropertySources(key string) []string {
+	return conf.sources[handleDeprecation(key)]
+}
+
+func (conf *configuration) Files() []string {
+	return append([]string(nil), conf.files...)
+}
+
+// getRaw returns the value of key without expanding variables
+func (conf *configuration) getRaw(key string) (string, bool) {
+	value, exists := conf.Properties[handleDeprecation(key)]
+	return value, exists
+}
+
+// Set sets key and its deprecated or new keys, which overrides final properties as hadoop
 func (conf *configuration) Set(key string, value string) error {
-	conf.Properties[key] = value
+	for _, k := range deprecationAliases(key) {
+		conf.Properties[k] = value
+		conf.sources[k] = []string{programmaticSource}
+		conf.locations[k] = propertyLocation{resource: programmaticSource}
+	}
 	return nil
 }
 
 func (conf *configuration) SetInt(key string, value int) error {
-	conf.Properties[key] = strconv.Itoa(value)
-	return nil
+	return conf.Set(key, strconv.Itoa(value))
+}
+
+// loadProperty sets a property of resource source at location, unless the property has been declared final
+func (conf *configuration) loadProperty(key string, value *string, final bool, sources []string, location propertyLocation) {
+	keys := []string{key}
+	if newKeys, deprecated := deprecatedKeys(key); deprecated {
+		keys = append(keys, newKeys...)
+	}
+	for _, k := range keys {
+		if value != nil {
+			if !conf.finals[k] {
+				conf.Properties[k] = *value
+				conf.sources[k] = sources
+				conf.locations[k] = location
+			} else if conf.Properties[k] != *value {
+				warnOnce(fmt.Sprintf("final:%v:%v", sources, k), "%v: an attempt to override final parameter %v; Ignoring.", sources, k)
+			}
+		}
+		if final {
+			conf.finals[k] = true
+		}
+	}
 }
 
 func NewConfiguration(hadoopConfDir string) (Configuration, error) {
 	return NewConfigurationResources(hadoopConfDir, []Resource{}, "")
 }
 
+// NewConfigurationResources loads core-default.xml, core-site.xml and resources in order from hadoopConfDir, the
+// names of resources are prefixed by prefix, e.g. the cluster id. Resources include other files by xi:include.
 func NewConfigurationResources(hadoopConfDir string, resources []Resource, prefix string) (Configuration, error) {
 	// Add $HADOOP_CONF_DIR/core-default.xml & $HADOOP_CONF_DIR/core-site.xml
 	resourcesWithDefault := []Resource{CORE_DEFAULT, CORE_SITE}
 	resourcesWithDefault = append(resourcesWithDefault, resources...)
 
-	c := configuration{Properties: make(map[string]string)}
-
+	sources := make([]Source, 0, len(resourcesWithDefault))
 	for _, resource := range resourcesWithDefault {
-		conf, err := os.Open(hadoopConfDir + string(os.PathSeparator) + prefix + resource.Name)
-		if err != nil {
-			if !resource.Required {
-				continue
-			}
-			klog.Warningf("Couldn't open resource: ", err)
-			return nil, err
-		}
-		confData, err := io.ReadAll(conf)
-		if err != nil {
-			klog.Warningf("Couldn't read resource: ", err)
-			return nil, err
-		}
-		defer conf.Close()
-
-		// Parse
-		var hConf hadoopConfiguration
-		err = xml.Unmarshal(confData, &hConf)
-		if err != nil {
-			klog.Warningf("Couldn't parse core-site.xml: ", err)
-			return nil, err
-		}
-
-		// Save into configuration
-		for _, kv := range hConf.Properties {
-			err = c.Set(kv.Name, kv.Value)
-			if err != nil {
-				return nil, err
-			}
-		}
+		sources = append(sources, ResourceSource(hadoopConfDir, resource, prefix))
 	}
-
-	return &c, nil
+	return NewConfigurationFromSources(sources...)
 }
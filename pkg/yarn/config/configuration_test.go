@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newTestConfiguration(properties map[string]string) *configuration {
+	c := newConfiguration()
+	for k, v := range properties {
+		c.Properties[k] = v
+	}
+	return c
+}
+
+func TestSubstituteVars(t *testing.T) {
+	t.Setenv("TEST_CONF_ENV", "env-value")
+	t.Setenv("TEST_CONF_EMPTY", "")
+	SetSystemProperty("test.conf.system", "system-value")
+	c := newTestConfiguration(map[string]string{
+		"yarn.resourcemanager.hostname.rm1": "rm1.example.com",
+		"yarn.resourcemanager.address.rm1":  "${yarn.resourcemanager.hostname.rm1}:8032",
+		"test.conf.system":                  "conf-value",
+		"a":                                 "${b}",
+		"b":                                 "${a}",
+		"self":                              "x${self}",
+		"inner":                             "b",
+	})
+	tests := map[string]string{
+		"${yarn.resourcemanager.address.rm1}": "rm1.example.com:8032",
+		"${env.TEST_CONF_ENV}/dir":            "env-value/dir",
+		"${env.TEST_CONF_UNSET:-default}":     "default",
+		"${env.TEST_CONF_EMPTY:-default}":     "default",
+		"${env.TEST_CONF_EMPTY-default}":      "",
+		"${env.TEST_CONF_UNSET-default}":      "default",
+		"${env.TEST_CONF_UNSET}":              "${env.TEST_CONF_UNSET}",
+		"${test.conf.system}":                 "system-value",
+		"${unbound}:${inner}":                 "${unbound}:${inner}",
+		"${inner}:${unbound}":                 "b:${unbound}",
+		"${self}":                             "${self}",
+		"${a${inner}}":                        "${ab}",
+		"$${inner}{}":                         "$b{}",
+		"no variable ${ x}":                   "no variable ${ x}",
+	}
+	for expr, expected := range tests {
+		actual, err := c.substituteVars(expr)
+		assert.NoError(t, err, expr)
+		assert.Equal(t, expected, actual, expr)
+	}
+
+	_, err := c.substituteVars("${a}")
+	assert.ErrorContains(t, err, "variable substitution depth too large")
+}
+
+func TestFindSubVariable(t *testing.T) {
+	tests := map[string][2]int{
+		"${a}":       {2, 3},
+		"x${ab}y":    {3, 5},
+		"${a${bc}}":  {5, 7},
+		"${}${a}":    {5, 6},
+		"{a}":        {-1, -1},
+		"${a":        {-1, -1},
+		"$ {a}${ b}": {-1, -1},
+	}
+	for eval, expected := range tests {
+		start, end := findSubVariable(eval)
+		assert.Equal(t, expected, [2]int{start, end}, eval)
+	}
+}
+
+func TestNewConfigurationResources(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"core-site.xml": `<configuration>
+  <property><name>final.key</name><value>core</value><final>true</final></property>
+  <property><name>override.key</name><value>core</value></property>
+  <property name="attr.key" value="attr"/>
+</configuration>`,
+		"yarn-site.xml": `<?xml version="1.0"?>
+<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <property><name>final.key</name><value>yarn</value></property>
+  <property><name>override.key</name><value>yarn</value><source>generated.xml</source></property>
+  <xi:include href="include/rm.xml"/>
+  <xi:include href="missing.xml"><xi:fallback/></xi:include>
+  <property><name>yarn.resourcemanager.zk-address</name><value>zk:2181</value></property>
+</configuration>`,
+		"include/rm.xml": `<configuration>
+  <property><name>yarn.resourcemanager.hostname</name><value>rm.example.com</value></property>
+  <property><name>yarn.resourcemanager.address</name><value>${yarn.resourcemanager.hostname}:8032</value></property>
+</configuration>`,
+	})
+	c, err := NewConfigurationResources(dir, []Resource{YARN_DEFAULT, YARN_SITE}, "")
+	assert.NoError(t, err)
+
+	get := func(key string) string {
+		value, err := c.Get(key, "")
+		assert.NoError(t, err)
+		return value
+	}
+	assert.Equal(t, "core", get("final.key"))
+	assert.Equal(t, "yarn", get("override.key"))
+	assert.Equal(t, "attr", get("attr.key"))
+	assert.Equal(t, "rm.example.com:8032", get(RM_ADDRESS))
+	assert.Equal(t, "zk:2181", get(ZK_ADDRESS))
+	assert.Equal(t, "zk:2181", get(RM_ZK_ADDRESS))
+
+	assert.Equal(t, []string{"core-site.xml"}, c.GetPropertySources("final.key"))
+	assert.Equal(t, []string{"generated.xml"}, c.GetPropertySources("override.key"))
+	assert.Equal(t, []string{"rm.xml"}, c.GetPropertySources(RM_ADDRESS))
+	// resources and includes missing are files of the configuration too
+	assert.Equal(t, []string{
+		filepath.Join(dir, "core-default.xml"),
+		filepath.Join(dir, "core-site.xml"),
+		filepath.Join(dir, "yarn-default.xml"),
+		filepath.Join(dir, "yarn-site.xml"),
+		filepath.Join(dir, "include/rm.xml"),
+		filepath.Join(dir, "missing.xml"),
+	}, c.Files())
+
+	// properties set are not final, and deprecated keys follow their new keys
+	assert.NoError(t, c.Set("final.key", "set"))
+	assert.Equal(t, "set", get("final.key"))
+	assert.NoError(t, c.Set(ZK_ADDRESS, "zk2:2181"))
+	assert.Equal(t, "zk2:2181", get(RM_ZK_ADDRESS))
+	assert.Equal(t, []string{"programmatically"}, c.GetPropertySources(RM_ZK_ADDRESS))
+}
+
+func TestNewConfigurationResourcesErrors(t *testing.T) {
+	tests := map[string]struct {
+		files map[string]string
+		err   string
+	}{
+		"missing required resource": {
+			files: map[string]string{},
+			err:   "load resource yarn-site.xml failed",
+		},
+		"include without fallback": {
+			files: map[string]string{"yarn-site.xml": `<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <xi:include href="missing.xml"/>
+</configuration>`},
+			err: "fetch fail on include for missing.xml with no fallback while loading yarn-site.xml",
+		},
+		"include itself": {
+			files: map[string]string{"yarn-site.xml": `<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <xi:include href="yarn-site.xml"/>
+</configuration>`},
+			err: "exceeds the depth",
+		},
+		"bad top-level element": {
+			files: map[string]string{"yarn-site.xml": `<properties/>`},
+			err:   "top-level element not <configuration>",
+		},
+		"malformed": {
+			files: map[string]string{"yarn-site.xml": `<configuration><property>`},
+			err:   "parse yarn-site.xml failed",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewConfigurationResources(writeConfFiles(t, tt.files), []Resource{YARN_SITE}, "")
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
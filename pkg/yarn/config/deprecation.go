@@ -0,0 +1,90 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+)
+
+var (
+	deprecationsMtx sync.RWMutex
+	// deprecations are the new keys of deprecated keys, and reverseDeprecations are the deprecated keys of new keys
+	deprecations        = map[string][]string{}
+	reverseDeprecations = map[string][]string{}
+
+	warned sync.Map
+)
+
+func init() {
+	// the deprecations of hadoop read by clients
+	AddDeprecation("fs.default.name", "fs.defaultFS")
+	AddDeprecation(RM_ZK_ADDRESS, ZK_ADDRESS)
+	AddDeprecation(RM_ZK_TIMEOUT_MS, ZK_TIMEOUT_MS)
+	AddDeprecation(RM_PREFIX+"zk-acl", "hadoop.zk.acl")
+	AddDeprecation(RM_PREFIX+"zk-auth", "hadoop.zk.auth")
+	AddDeprecation(RM_PREFIX+"zk-num-retries", "hadoop.zk.num-retries")
+	AddDeprecation(RM_PREFIX+"zk-retry-interval-ms", "hadoop.zk.retry-interval-ms")
+	AddDeprecation(YARN_PREFIX+"client.max-nodemanagers-proxies", YARN_PREFIX+"client.max-cached-nodemanagers-proxies")
+}
+
+// AddDeprecation makes key an alias of newKeys as Configuration.addDeprecation of hadoop, the value of key is set to
+// newKeys, and reading key returns the value of newKeys
+func AddDeprecation(key string, newKeys ...string) {
+	deprecationsMtx.Lock()
+	defer deprecationsMtx.Unlock()
+	deprecations[key] = newKeys
+	for _, newKey := range newKeys {
+		reverseDeprecations[newKey] = append(reverseDeprecations[newKey], key)
+	}
+}
+
+func deprecatedKeys(key string) ([]string, bool) {
+	deprecationsMtx.RLock()
+	defer deprecationsMtx.RUnlock()
+	newKeys, ok := deprecations[key]
+	if ok {
+		warnOnce("deprecation:"+key, "%v is deprecated. Instead, use %v", key, newKeys)
+	}
+	return newKeys, ok
+}
+
+// handleDeprecation returns the key holding the value of key, which is the last new key if key is deprecated
+func handleDeprecation(key string) string {
+	if newKeys, ok := deprecatedKeys(key); ok && len(newKeys) > 0 {
+		return newKeys[len(newKeys)-1]
+	}
+	return key
+}
+
+// deprecationAliases returns key with its new keys if it is deprecated, or with its deprecated keys if it is new
+func deprecationAliases(key string) []string {
+	keys := []string{key}
+	if newKeys, ok := deprecatedKeys(key); ok {
+		keys = append(keys, newKeys...)
+	}
+	deprecationsMtx.RLock()
+	defer deprecationsMtx.RUnlock()
+	return append(keys, reverseDeprecations[key]...)
+}
+
+func warnOnce(id string, format string, args ...interface{}) {
+	if _, loaded := warned.LoadOrStore(id, true); !loaded {
+		klog.Warningf(format, args...)
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS = "hadoop.security.sensitive-config-keys"
+
+	DEFAULT_HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS = "secret$,password$,ssl.keystore.pass$,fs.s3.*[Ss]ecret.?[Kk]ey," +
+		"fs.s3a.*.server-side-encryption.key,fs.azure.account.key.*,credential$,oauth.*secret,oauth.*password," +
+		"oauth.*token," + HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS
+
+	// REDACTED_TEXT replaces sensitive values in dumps as ConfigRedactor of hadoop
+	REDACTED_TEXT = "<redacted>"
+
+	DUMP_FORMAT_XML  = "xml"
+	DUMP_FORMAT_JSON = "json"
+)
+
+// ErrPropertyNotSet is wrapped by the error of Describe for keys not set
+var ErrPropertyNotSet = errors.New("property not set")
+
+// PropertyDescription is the value of a property and where it is set, Resource is the path of the resource setting
+// the property or "programmatically", and Line is 0 if the property is not set by a resource
+type PropertyDescription struct {
+	Key      string   `json:"key"`
+	Value    string   `json:"value"`
+	RawValue string   `json:"rawValue"`
+	Final    bool     `json:"isFinal"`
+	Sources  []string `json:"sources,omitempty"`
+	Resource string   `json:"resource"`
+	Line     int      `json:"line,omitempty"`
+}
+
+// xmlProperty is the <property> written as Configuration.writeXml of hadoop
+type xmlProperty struct {
+	XMLName xml.Name `xml:"property"`
+	Name    string   `xml:"name"`
+	Value   string   `xml:"value"`
+	Final   bool     `xml:"final"`
+	Sources []string `xml:"source"`
+}
+
+func (conf *configuration) Describe(key string) (*PropertyDescription, error) {
+	sensitive, err := conf.sensitiveConfigKeys()
+	if err != nil {
+		return nil, err
+	}
+	return conf.describe(key, sensitive)
+}
+
+// describe describes key, whose value is redacted if key matches a pattern of sensitive
+func (conf *configuration) describe(key string, sensitive []*regexp.Regexp) (*PropertyDescription, error) {
+	k := handleDeprecation(key)
+	raw, exists := conf.Properties[k]
+	if !exists {
+		return nil, fmt.Errorf("%w: %v", ErrPropertyNotSet, key)
+	}
+	value, err := conf.substituteVars(raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range sensitive {
+		if pattern.MatchString(key) || pattern.MatchString(k) {
+			value, raw = REDACTED_TEXT, REDACTED_TEXT
+			break
+		}
+	}
+	location := conf.locations[k]
+	return &PropertyDescription{
+		Key:      key,
+		Value:    value,
+		RawValue: raw,
+		Final:    conf.finals[k],
+		Sources:  conf.sources[k],
+		Resource: location.resource,
+		Line:     location.line,
+	}, nil
+}
+
+// describeAll describes all properties sorted by key with sensitive values redacted
+func (conf *configuration) describeAll() ([]*PropertyDescription, error) {
+	sensitive, err := conf.sensitiveConfigKeys()
+	if err != nil {
+		return nil, err
+	}
+	keys := conf.sortedKeys()
+	descriptions := make([]*PropertyDescription, 0, len(keys))
+	for _, key := range keys {
+		description, err := conf.describe(key, sensitive)
+		if err != nil {
+			return nil, err
+		}
+		descriptions = append(descriptions, description)
+	}
+	return descriptions, nil
+}
+
+func (conf *configuration) sortedKeys() []string {
+	keys := make([]string, 0, len(conf.Properties))
+	for key := range conf.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sensitiveConfigKeys returns the patterns of keys whose values are redacted, as ConfigRedactor of hadoop
+func (conf *configuration) sensitiveConfigKeys() ([]*regexp.Regexp, error) {
+	value, err := conf.Get(HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS, DEFAULT_HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS)
+	if err != nil {
+		return nil, err
+	}
+	var patterns []*regexp.Regexp
+	for _, expr := range strings.Split(value, ",") {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, &ValueError{Key: HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS, Value: expr,
+				Sources: conf.GetPropertySources(HADOOP_SECURITY_SENSITIVE_CONFIG_KEYS), Err: err}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func (conf *configuration) Dump(w io.Writer, format string) error {
+	descriptions, err := conf.describeAll()
+	if err != nil {
+		return err
+	}
+	switch format {
+	case DUMP_FORMAT_XML:
+		// the xml dump is loaded with the expanded values
+		properties := make([]*xmlProperty, 0, len(descriptions))
+		for _, description := range descriptions {
+			properties = append(properties, &xmlProperty{
+				Name:    description.Key,
+				Value:   description.Value,
+				Final:   description.Final,
+				Sources: description.Sources,
+			})
+		}
+		return writeXML(w, properties)
+	case DUMP_FORMAT_JSON:
+		e := json.NewEncoder(w)
+		e.SetEscapeHTML(false)
+		e.SetIndent("", "  ")
+		return e.Encode(struct {
+			Properties []*PropertyDescription `json:"properties"`
+		}{Properties: descriptions})
+	}
+	return fmt.Errorf("unknown dump format %v, expecting %v or %v", format, DUMP_FORMAT_XML, DUMP_FORMAT_JSON)
+}
+
+// WriteXML keeps variables unexpanded as Configuration.writeXml of hadoop, so that they are expanded by the reader
+func (conf *configuration) WriteXML(w io.Writer) error {
+	keys := conf.sortedKeys()
+	properties := make([]*xmlProperty, 0, len(keys))
+	for _, key := range keys {
+		properties = append(properties, &xmlProperty{
+			Name:    key,
+			Value:   conf.Properties[key],
+			Final:   conf.finals[key],
+			Sources: conf.sources[key],
+		})
+	}
+	return writeXML(w, properties)
+}
+
+// writeXML writes properties as a <configuration> of hadoop
+func writeXML(w io.Writer, properties []*xmlProperty) error {
+	if _, err := io.WriteString(w, xml.Header+"<configuration>\n"); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("  ", "  ")
+	for _, property := range properties {
+		if err := e.Encode(property); err != nil {
+			return err
+		}
+	}
+	if err := e.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n</configuration>\n")
+	return err
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestDescribeConfiguration(t *testing.T) (string, Configuration) {
+	dir := writeConfFiles(t, map[string]string{
+		"a.core-site.xml": `<configuration>
+  <property>
+    <name>hadoop.security.authentication</name>
+    <value>kerberos</value>
+    <final>true</final>
+  </property>
+</configuration>`,
+		"a.yarn-site.xml": `<?xml version="1.0"?>
+<configuration xmlns:xi="http://www.w3.org/2001/XInclude">
+  <property><name>hadoop.security.authentication</name><value>simple</value></property>
+  <property><name>yarn.resourcemanager.hostname</name><value>rm.example.com</value></property>
+  <xi:include href="rm.xml"/>
+  <property name="ssl.server.keystore.password" value="secret &amp; more"/>
+</configuration>`,
+		"rm.xml": `<configuration>
+
+  <property><name>yarn.resourcemanager.address</name><value>${yarn.resourcemanager.hostname}:8032</value></property>
+</configuration>`,
+	})
+	c, err := NewConfigurationResources(dir, []Resource{YARN_DEFAULT, YARN_SITE}, "a.")
+	assert.NoError(t, err)
+	return dir, c
+}
+
+func TestDescribe(t *testing.T) {
+	dir, c := newTestDescribeConfiguration(t)
+
+	description, err := c.Describe(HADOOP_SECURITY_AUTHENTICATION)
+	assert.NoError(t, err)
+	assert.Equal(t, &PropertyDescription{
+		Key:      HADOOP_SECURITY_AUTHENTICATION,
+		Value:    "kerberos",
+		RawValue: "kerberos",
+		Final:    true,
+		Sources:  []string{"a.core-site.xml"},
+		Resource: filepath.Join(dir, "a.core-site.xml"),
+		Line:     2,
+	}, description)
+
+	description, err = c.Describe(RM_ADDRESS)
+	assert.NoError(t, err)
+	assert.Equal(t, &PropertyDescription{
+		Key:      RM_ADDRESS,
+		Value:    "rm.example.com:8032",
+		RawValue: "${yarn.resourcemanager.hostname}:8032",
+		Sources:  []string{"rm.xml"},
+		Resource: filepath.Join(dir, "rm.xml"),
+		Line:     3,
+	}, description)
+
+	description, err = c.Describe("ssl.server.keystore.password")
+	assert.NoError(t, err)
+	assert.Equal(t, REDACTED_TEXT, description.Value)
+	assert.Equal(t, filepath.Join(dir, "a.yarn-site.xml"), description.Resource)
+	assert.Equal(t, 6, description.Line)
+
+	assert.NoError(t, c.Set(RM_ZK_ADDRESS, "zk:2181"))
+	description, err = c.Describe(ZK_ADDRESS)
+	assert.NoError(t, err)
+	assert.Equal(t, "programmatically", description.Resource)
+	assert.Equal(t, 0, description.Line)
+
+	_, err = c.Describe("not.set")
+	assert.ErrorIs(t, err, ErrPropertyNotSet)
+}
+
+func TestDump(t *testing.T) {
+	_, c := newTestDescribeConfiguration(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, c.Dump(buf, DUMP_FORMAT_JSON))
+	dump := struct {
+		Properties []PropertyDescription `json:"properties"`
+	}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &dump))
+	values := map[string]string{}
+	for _, p := range dump.Properties {
+		values[p.Key] = p.Value
+	}
+	assert.Equal(t, map[string]string{
+		HADOOP_SECURITY_AUTHENTICATION: "kerberos",
+		RM_HOSTNAME:                    "rm.example.com",
+		RM_ADDRESS:                     "rm.example.com:8032",
+		"ssl.server.keystore.password": REDACTED_TEXT,
+	}, values)
+
+	// the xml dump is loaded as the effective configuration
+	buf.Reset()
+	assert.NoError(t, c.Dump(buf, DUMP_FORMAT_XML))
+	assert.Contains(t, buf.String(), "<value>&lt;redacted&gt;</value>")
+	dumped := newConfiguration()
+	assert.NoError(t, dumped.parse(buf, "dump.xml", "dump.xml", 0))
+	assert.Equal(t, "rm.example.com:8032", dumped.Properties[RM_ADDRESS])
+	assert.True(t, dumped.finals[HADOOP_SECURITY_AUTHENTICATION])
+	assert.Equal(t, []string{"rm.xml"}, dumped.sources[RM_ADDRESS])
+
+	assert.ErrorContains(t, c.Dump(buf, "yaml"), "unknown dump format yaml")
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	xincludeNamespace = "http://www.w3.org/2001/XInclude"
+	// maxIncludeDepth stops resources including each other
+	maxIncludeDepth = 16
+)
+
+// property is a <property> of hadoop resources, whose fields are elements or attributes
+type property struct {
+	Name      string   `xml:"name"`
+	Value     *string  `xml:"value"`
+	Final     string   `xml:"final"`
+	Source    []string `xml:"source"`
+	NameAttr  string   `xml:"name,attr"`
+	ValueAttr *string  `xml:"value,attr"`
+	FinalAttr string   `xml:"final,attr"`
+}
+
+type include struct {
+	Href     string    `xml:"href,attr"`
+	Fallback *struct{} `xml:"http://www.w3.org/2001/XInclude fallback"`
+}
+
+// lineReader counts the lines of a resource read by the decoder, which locates the properties of the resource
+type lineReader struct {
+	r      io.Reader
+	offset int64
+	// lineOffsets are the offsets of the lines read after the first one
+	lineOffsets []int64
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			lr.lineOffsets = append(lr.lineOffsets, lr.offset+int64(i)+1)
+		}
+	}
+	lr.offset += int64(n)
+	return n, err
+}
+
+// line returns the line of offset, which has been read
+func (lr *lineReader) line(offset int64) int {
+	return sort.Search(len(lr.lineOffsets), func(i int) bool { return lr.lineOffsets[i] > offset }) + 1
+}
+
+// resourceParser loads a resource into conf, path locates the resource and name is the source of its properties
+type resourceParser struct {
+	conf  *configuration
+	d     *xml.Decoder
+	lines *lineReader
+	path  string
+	name  string
+	depth int
+}
+
+// loadFile loads the resource of path, name is the source of its properties
+func (conf *configuration) loadFile(path string, name string, depth int) error {
+	conf.files = append(conf.files, path)
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return conf.parse(file, path, name, depth)
+}
+
+// parse loads the properties of a <configuration> document in order, includes are loaded in place, and nested
+// <configuration> are loaded as hadoop does
+func (conf *configuration) parse(r io.Reader, path string, name string, depth int) error {
+	lines := &lineReader{r: r}
+	p := &resourceParser{conf: conf, d: xml.NewDecoder(lines), lines: lines, path: path, name: name, depth: depth}
+	for {
+		token, err := p.d.Token()
+		if err == io.EOF {
+			return fmt.Errorf("no <configuration> in %v", name)
+		} else if err != nil {
+			return fmt.Errorf("parse %v failed, error %w", name, err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local != "configuration" {
+				return fmt.Errorf("bad conf file %v: top-level element not <configuration>", name)
+			}
+			return p.parseElements()
+		}
+	}
+}
+
+// parseElements loads the children of the element started, until it ends
+func (p *resourceParser) parseElements() error {
+	for {
+		offset := p.d.InputOffset()
+		token, err := p.d.Token()
+		if err != nil {
+			return fmt.Errorf("parse %v failed, error %w", p.name, err)
+		}
+		switch t := token.(type) {
+		case xml.EndElement:
+			return nil
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == xincludeNamespace && t.Name.Local == "include":
+				inc := &include{}
+				if err := p.d.DecodeElement(inc, &t); err != nil {
+					return fmt.Errorf("parse %v failed, error %w", p.name, err)
+				}
+				if err := p.include(inc); err != nil {
+					return err
+				}
+			case t.Name.Local == "property":
+				prop := &property{}
+				if err := p.d.DecodeElement(prop, &t); err != nil {
+					return fmt.Errorf("parse %v failed, error %w", p.name, err)
+				}
+				p.loadProperty(prop, p.lines.line(offset))
+			case t.Name.Local == "configuration":
+				if err := p.parseElements(); err != nil {
+					return err
+				}
+			default:
+				if err := p.d.Skip(); err != nil {
+					return fmt.Errorf("parse %v failed, error %w", p.name, err)
+				}
+			}
+		}
+	}
+}
+
+// include loads the resource of href relative to the resource including it, a resource missing is an error unless
+// the include has a fallback
+func (p *resourceParser) include(inc *include) error {
+	if p.depth >= maxIncludeDepth {
+		return fmt.Errorf("include %v in %v exceeds the depth %v", inc.Href, p.name, maxIncludeDepth)
+	}
+	href := inc.Href
+	if !filepath.IsAbs(href) {
+		href = filepath.Join(filepath.Dir(p.path), href)
+	}
+	err := p.conf.loadFile(href, filepath.Base(href), p.depth+1)
+	if errors.Is(err, os.ErrNotExist) {
+		if inc.Fallback != nil {
+			return nil
+		}
+		return fmt.Errorf("fetch fail on include for %v with no fallback while loading %v", inc.Href, p.name)
+	}
+	return err
+}
+
+func (p *resourceParser) loadProperty(prop *property, line int) {
+	key := strings.TrimSpace(prop.Name)
+	if key == "" {
+		key = strings.TrimSpace(prop.NameAttr)
+	}
+	if key == "" {
+		return
+	}
+	value := prop.Value
+	if value == nil {
+		value = prop.ValueAttr
+	}
+	final := strings.TrimSpace(prop.Final) == "true" || strings.TrimSpace(prop.FinalAttr) == "true"
+	sources := []string{p.name}
+	if len(prop.Source) > 0 {
+		sources = prop.Source
+	}
+	p.conf.loadProperty(key, value, final, sources, propertyLocation{resource: p.path, line: line})
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// rmDefaultPorts are the ports of rm addresses derived from yarn.resourcemanager.hostname, which are the same as
+// YarnConfiguration.getRMDefaultPortNumber of hadoop
+var rmDefaultPorts = map[string]int{
+	RM_ADDRESS:                  DEFAULT_RM_PORT,
+	RM_SCHEDULER_ADDRESS:        DEFAULT_RM_SCHEDULER_PORT,
+	RM_RESOURCE_TRACKER_ADDRESS: DEFAULT_RM_RESOURCE_TRACKER_PORT,
+	RM_ADMIN_ADDRESS:            DEFAULT_RM_ADMIN_PORT,
+	RM_WEBAPP_ADDRESS:           DEFAULT_RM_WEBAPP_PORT,
+	RM_WEBAPP_HTTPS_ADDRESS:     DEFAULT_RM_WEBAPP_HTTPS_PORT,
+}
+
+// rmKey returns key of the rm of rmID as HAUtil.addSuffix of hadoop, which is key itself if rmID is empty
+func rmKey(key string, rmID string) string {
+	if rmID == "" {
+		return key
+	}
+	return key + "." + rmID
+}
+
+// getRMAddress returns the host and port of the rm address key of rmID. The address not set is derived from the
+// hostname of rm and the default port of key, and a hostname is required by ha as HAUtil.checkAndSetRMRPCAddress.
+func (yarnConf *yarn_configuration) getRMAddress(key string, rmID string) (string, string, error) {
+	defaultPort, ok := rmDefaultPorts[key]
+	if !ok {
+		return "", "", fmt.Errorf("invalid rm address key %v", key)
+	}
+	addressKey, hostnameKey := rmKey(key, rmID), rmKey(RM_HOSTNAME, rmID)
+	address, err := yarnConf.conf.Get(addressKey, "")
+	if err != nil {
+		return "", "", err
+	}
+	// the key of a malformed address, which is the hostname if the address is derived from it
+	valueKey := addressKey
+	if address = strings.TrimSpace(address); address == "" {
+		valueKey = hostnameKey
+		hostname, err := yarnConf.conf.Get(hostnameKey, "")
+		if err != nil {
+			return "", "", err
+		}
+		if hostname = strings.TrimSpace(hostname); hostname == "" {
+			if rmID != "" {
+				return "", "", fmt.Errorf("invalid configuration! %v or %v needs to be set in a ha configuration",
+					hostnameKey, addressKey)
+			}
+			hostname = DEFAULT_RM_HOSTNAME
+		}
+		address = hostname
+	}
+	host, port, err := splitHostPort(address, defaultPort)
+	if err != nil {
+		return "", "", &ValueError{Key: valueKey, Value: address, Sources: yarnConf.conf.GetPropertySources(valueKey), Err: err}
+	}
+	return host, port, nil
+}
+
+// getRMConnectAddress returns the address of key dialed by clients, the wildcard address of rm is an error since
+// clients would dial themselves
+func (yarnConf *yarn_configuration) getRMConnectAddress(key string, rmID string) (string, error) {
+	host, port, err := yarnConf.getRMAddress(key, rmID)
+	if err != nil {
+		return "", err
+	}
+	if isWildcard(host) {
+		return "", fmt.Errorf("rm address %v of %v is a wildcard address, set %v or %v to the address of rm",
+			net.JoinHostPort(host, port), rmKey(key, rmID), rmKey(key, rmID), rmKey(RM_HOSTNAME, rmID))
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func (yarnConf *yarn_configuration) GetRMConnectAddress(key string, rmID string) (string, error) {
+	return yarnConf.getRMConnectAddress(key, rmID)
+}
+
+func (yarnConf *yarn_configuration) GetRMBindAddress(key string, rmID string) (string, error) {
+	host, port, err := yarnConf.getRMAddress(key, rmID)
+	if err != nil {
+		return "", err
+	}
+	bindHost, err := yarnConf.conf.Get(RM_BIND_HOST, "")
+	if err != nil {
+		return "", err
+	}
+	if bindHost = strings.TrimSpace(bindHost); bindHost != "" {
+		host = bindHost
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// getRouterConnectAddress returns the address of router set by key, whose port is defaultPort if not set. Unlike rm,
+// the address must be set, since clients of a federation cannot dial the wildcard address router listens on.
+func (yarnConf *yarn_configuration) getRouterConnectAddress(key string, defaultPort int) (string, error) {
+	value, err := yarnConf.conf.Get(key, "")
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("invalid configuration! %v needs to be set in a federation configuration", key)
+	}
+	address, err := yarnConf.conf.GetSocketAddress(key, "", defaultPort)
+	if err != nil {
+		return "", err
+	}
+	if host, _, _ := net.SplitHostPort(address); isWildcard(host) {
+		return "", fmt.Errorf("router address %v of %v is a wildcard address, set %v to the address of router",
+			address, key, key)
+	}
+	return address, nil
+}
+
+func isWildcard(host string) bool {
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// splitHostPort splits address as NetUtils.createSocketAddr of hadoop, whose port is defaultPort if not specified
+func splitHostPort(address string, defaultPort int) (string, string, error) {
+	host, port := address, strconv.Itoa(defaultPort)
+	if strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]") {
+		host = address[1 : len(address)-1]
+	} else if strings.Contains(address, ":") {
+		var err error
+		if host, port, err = net.SplitHostPort(address); err != nil {
+			return "", "", errors.New("does not contain a valid host:port authority")
+		}
+	}
+	if host == "" {
+		return "", "", errors.New("does not contain a valid host:port authority")
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 0 || p > 65535 {
+		return "", "", errors.New("port out of range")
+	}
+	return host, port, nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Source is a layer of properties, sources loaded later override the properties set by former ones except finals
+type Source interface {
+	load(conf *configuration) error
+}
+
+type resourceSource struct {
+	hadoopConfDir string
+	resource      Resource
+	prefix        string
+}
+
+// ResourceSource is resource in hadoopConfDir whose name is prefixed by prefix, it is skipped if it does not exist
+// and is not required
+func ResourceSource(hadoopConfDir string, resource Resource, prefix string) Source {
+	return &resourceSource{hadoopConfDir: hadoopConfDir, resource: resource, prefix: prefix}
+}
+
+func (s *resourceSource) load(conf *configuration) error {
+	name := s.prefix + s.resource.Name
+	err := conf.loadFile(filepath.Join(s.hadoopConfDir, name), name, 0)
+	if errors.Is(err, os.ErrNotExist) && !s.resource.Required {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("load resource %v failed, error %w", name, err)
+	}
+	return nil
+}
+
+type readerSource struct {
+	name string
+	r    io.Reader
+}
+
+// ReaderSource is a <configuration> document read from r, name is the source of its properties and relative
+// xi:include hrefs are resolved against the directory of name
+func ReaderSource(name string, r io.Reader) Source {
+	return &readerSource{name: name, r: r}
+}
+
+func (s *readerSource) load(conf *configuration) error {
+	if err := conf.parse(s.r, s.name, s.name, 0); err != nil {
+		return fmt.Errorf("load resource %v failed, error %w", s.name, err)
+	}
+	return nil
+}
+
+type mapSource struct {
+	name       string
+	properties map[string]string
+}
+
+// MapSource is properties whose source is name, values may refer to other properties as ${key}
+func MapSource(name string, properties map[string]string) Source {
+	return &mapSource{name: name, properties: properties}
+}
+
+func (s *mapSource) load(conf *configuration) error {
+	keys := make([]string, 0, len(s.properties))
+	for key := range s.properties {
+		keys = append(keys, key)
+	}
+	// deprecated keys and their new keys are set in the same order on every load
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := s.properties[key]
+		conf.loadProperty(key, &value, false, []string{s.name}, propertyLocation{resource: s.name})
+	}
+	return nil
+}
+
+// NewConfigurationFromSources loads sources in order, core-default.xml and core-site.xml are not loaded unless they
+// are one of sources
+func NewConfigurationFromSources(sources ...Source) (Configuration, error) {
+	c := newConfiguration()
+	for _, source := range sources {
+		if err := source.load(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// NewConfigurationFromReader loads the <configuration> document read from r, whose source is name
+func NewConfigurationFromReader(name string, r io.Reader) (Configuration, error) {
+	return NewConfigurationFromSources(ReaderSource(name, r))
+}
+
+// NewConfigurationFromMap returns a configuration of properties, whose source is "programmatically" as Set
+func NewConfigurationFromMap(properties map[string]string) (Configuration, error) {
+	return NewConfigurationFromSources(MapSource(programmaticSource, properties))
+}
+
+// NewYarnConfigurationFromSources loads sources in order as NewConfigurationFromSources
+func NewYarnConfigurationFromSources(sources ...Source) (YarnConfiguration, error) {
+	c, err := NewConfigurationFromSources(sources...)
+	if err != nil {
+		return nil, err
+	}
+	return &yarn_configuration{conf: c}, nil
+}
+
+// NewYarnConfigurationFromReader loads the <configuration> document read from r, whose source is name
+func NewYarnConfigurationFromReader(name string, r io.Reader) (YarnConfiguration, error) {
+	return NewYarnConfigurationFromSources(ReaderSource(name, r))
+}
+
+// NewYarnConfigurationFromMap returns a yarn configuration of properties, whose source is "programmatically" as Set
+func NewYarnConfigurationFromMap(properties map[string]string) (YarnConfiguration, error) {
+	return NewYarnConfigurationFromSources(MapSource(programmaticSource, properties))
+}
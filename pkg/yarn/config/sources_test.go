@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewConfigurationFromSources(t *testing.T) {
+	dir := writeConfFiles(t, map[string]string{
+		"core-site.xml": `<configuration>
+  <property><name>hadoop.security.authentication</name><value>kerberos</value><final>true</final></property>
+</configuration>`,
+	})
+	c, err := NewYarnConfigurationFromSources(
+		MapSource("defaults", map[string]string{
+			RM_HOSTNAME: "rm0.example.com",
+			RM_ADDRESS:  "${yarn.resourcemanager.hostname}:8032",
+		}),
+		ResourceSource(dir, CORE_SITE, ""),
+		// resources not required are skipped if missing
+		ResourceSource(dir, YARN_DEFAULT, ""),
+		ReaderSource("overrides.xml", strings.NewReader(`<configuration>
+  <property><name>yarn.resourcemanager.hostname</name><value>rm1.example.com</value></property>
+  <property><name>hadoop.security.authentication</name><value>simple</value></property>
+</configuration>`)),
+	)
+	assert.NoError(t, err)
+
+	address, err := c.GetRMAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "rm1.example.com:8032", address)
+	description, err := c.Describe(RM_HOSTNAME)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"overrides.xml"}, description.Sources)
+	description, err = c.Describe(RM_ADDRESS)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"defaults"}, description.Sources)
+	assert.Equal(t, "defaults", description.Resource)
+	authentication, err := c.Get(HADOOP_SECURITY_AUTHENTICATION, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "kerberos", authentication)
+
+	_, err = NewConfigurationFromSources(ResourceSource(dir, Resource{"required.xml", true}, ""))
+	assert.ErrorContains(t, err, "load resource required.xml failed")
+	_, err = NewConfigurationFromReader("bad.xml", strings.NewReader("<property/>"))
+	assert.ErrorContains(t, err, "load resource bad.xml failed")
+}
+
+func TestNewConfigurationFromMap(t *testing.T) {
+	c, err := NewYarnConfigurationFromMap(map[string]string{
+		RM_HA_ENABLED:        "true",
+		RM_HA_RM_IDS:         "rm1, rm2",
+		RM_ZK_ADDRESS:        "zk:2181",
+		RM_HOSTNAME + ".rm1": "rm1.example.com",
+		RM_HOSTNAME + ".rm2": "rm2.example.com",
+	})
+	assert.NoError(t, err)
+
+	rms, err := c.GetRMs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rm1", "rm2"}, rms)
+	address, err := c.GetRMAddressByID("rm2")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm2.example.com:8032", address)
+	// deprecated keys are loaded with their new keys
+	zkAddress, err := c.Get(ZK_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "zk:2181", zkAddress)
+	description, err := c.Describe(RM_HA_RM_IDS)
+	assert.NoError(t, err)
+	assert.Equal(t, programmaticSource, description.Resource)
+	assert.Equal(t, []string{programmaticSource}, description.Sources)
+}
+
+func TestWriteXML(t *testing.T) {
+	_, c := newTestDescribeConfiguration(t)
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, c.WriteXML(buf))
+	assert.Contains(t, buf.String(), "<value>${yarn.resourcemanager.hostname}:8032</value>")
+	assert.Contains(t, buf.String(), "<value>secret &amp; more</value>")
+
+	// the written configuration loads as the same configuration
+	written, err := NewConfigurationFromReader("written.xml", bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, c.(*configuration).Properties, written.(*configuration).Properties)
+	assert.Equal(t, c.(*configuration).finals, written.(*configuration).finals)
+	assert.Equal(t, c.(*configuration).sources, written.(*configuration).sources)
+	address, err := written.Get(RM_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm.example.com:8032", address)
+
+	// the final property is not overridden by the layers after the written configuration
+	layered, err := NewConfigurationFromSources(
+		ReaderSource("written.xml", bytes.NewReader(buf.Bytes())),
+		MapSource("overrides", map[string]string{HADOOP_SECURITY_AUTHENTICATION: "simple", RM_HOSTNAME: "rm1"}),
+	)
+	assert.NoError(t, err)
+	authentication, err := layered.Get(HADOOP_SECURITY_AUTHENTICATION, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "kerberos", authentication)
+	address, err = layered.Get(RM_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm1:8032", address)
+
+	buf.Reset()
+	empty, err := NewConfigurationFromMap(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, empty.WriteXML(buf))
+	written, err = NewConfigurationFromReader("empty.xml", buf)
+	assert.NoError(t, err)
+	assert.Empty(t, written.(*configuration).Properties)
+}
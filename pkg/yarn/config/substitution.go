@@ -0,0 +1,165 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+)
+
+// maxSubst is the depth of variable substitution, which is MAX_SUBST of hadoop
+const maxSubst = 20
+
+var (
+	systemPropertiesMtx sync.RWMutex
+	// systemProperties are resolved before the properties of configurations, as java system properties in hadoop
+	systemProperties = map[string]string{}
+)
+
+// SetSystemProperty sets the value of ${name} in all configurations, which takes precedence over their properties
+func SetSystemProperty(name string, value string) {
+	systemPropertiesMtx.Lock()
+	defer systemPropertiesMtx.Unlock()
+	systemProperties[name] = value
+}
+
+// systemProperty returns the property set by SetSystemProperty, or the java system property of the process
+func systemProperty(name string) (string, bool) {
+	systemPropertiesMtx.RLock()
+	value, ok := systemProperties[name]
+	systemPropertiesMtx.RUnlock()
+	if ok {
+		return value, true
+	}
+	switch name {
+	case "user.name":
+		if u, err := user.Current(); err == nil {
+			return u.Username, true
+		}
+	case "user.home":
+		if home, err := os.UserHomeDir(); err == nil {
+			return home, true
+		}
+	case "user.dir":
+		if dir, err := os.Getwd(); err == nil {
+			return dir, true
+		}
+	case "java.io.tmpdir":
+		return os.TempDir(), true
+	case "file.separator":
+		return string(os.PathSeparator), true
+	case "path.separator":
+		return string(os.PathListSeparator), true
+	case "line.separator":
+		return "\n", true
+	}
+	return "", false
+}
+
+// substituteVars expands the variables of expr as Configuration.substituteVars of hadoop, variables unbound are kept
+// as they are, and expr is returned as it is if a variable refers to itself
+func (conf *configuration) substituteVars(expr string) (string, error) {
+	eval := expr
+	for s := 0; s < maxSubst; s++ {
+		start, end := findSubVariable(eval)
+		if start < 0 {
+			return eval, nil
+		}
+		name := eval[start:end]
+		value, ok := "", false
+		if strings.HasPrefix(name, "env.") && len(name) > len("env.") {
+			value, ok = getenv(name[len("env."):])
+		} else {
+			value, ok = systemProperty(name)
+		}
+		if !ok {
+			value, ok = conf.getRaw(name)
+		}
+		if !ok {
+			return eval, nil
+		}
+		ref := eval[start-len("${") : end+len("}")]
+		if strings.Contains(value, ref) {
+			return expr, nil
+		}
+		eval = eval[:start-len("${")] + value + eval[end+len("}"):]
+	}
+	return "", fmt.Errorf("variable substitution depth too large: %v %v", maxSubst, expr)
+}
+
+// getenv resolves ${env.NAME}, ${env.NAME:-default} is default if NAME is unset or empty, and ${env.NAME-default} is
+// default if NAME is unset
+func getenv(expr string) (string, bool) {
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == ':' && i < len(expr)-1 && expr[i+1] == '-' {
+			if value, ok := os.LookupEnv(expr[:i]); ok && value != "" {
+				return value, true
+			}
+			return expr[i+2:], true
+		} else if expr[i] == '-' {
+			if value, ok := os.LookupEnv(expr[:i]); ok {
+				return value, true
+			}
+			return expr[i+1:], true
+		}
+	}
+	return os.LookupEnv(expr)
+}
+
+// findSubVariable returns the bounds of the name of the first innermost ${name} in eval, names have no '$' or ' '.
+// It returns -1 if there is no variable.
+func findSubVariable(eval string) (int, int) {
+	matchStart := 1
+	for {
+		if matchStart >= len(eval) {
+			return -1, -1
+		}
+		leftBrace := strings.IndexByte(eval[matchStart:], '{')
+		if leftBrace < 0 {
+			return -1, -1
+		}
+		leftBrace += matchStart
+		// the right brace of the smallest variable "${c}"
+		if leftBrace+len("{c") >= len(eval) {
+			return -1, -1
+		}
+		if eval[leftBrace-1] != '$' {
+			matchStart = leftBrace + 1
+			continue
+		}
+		subStart, matched, reset := leftBrace+1, 0, false
+		for i := subStart; i < len(eval) && !reset; i++ {
+			switch eval[i] {
+			case '}':
+				if matched > 0 {
+					return subStart, subStart + matched
+				}
+				matchStart, reset = i+1, true
+			case ' ', '$':
+				matchStart, reset = i+1, true
+			default:
+				matched++
+			}
+		}
+		if !reset {
+			return -1, -1
+		}
+	}
+}
@@ -18,8 +18,9 @@ limitations under the License.
 package conf
 
 import (
-	"fmt"
-	"strings"
+	"io"
+	"math"
+	"time"
 )
 
 var (
@@ -35,13 +36,84 @@ const (
 	RM_ADMIN_ADDRESS         = RM_PREFIX + "admin.address"
 	RM_HA_ENABLED            = RM_PREFIX + "ha.enabled"
 	RM_HA_RM_IDS             = RM_PREFIX + "ha.rm-ids"
+	AUTO_FAILOVER_ENABLED    = RM_PREFIX + "ha.automatic-failover.enabled"
+	RM_CLUSTER_ID            = RM_PREFIX + "cluster-id"
+	RM_PRINCIPAL             = RM_PREFIX + "principal"
 	RM_AM_EXPIRY_INTERVAL_MS = YARN_PREFIX + "am.liveness-monitor.expiry-interval-ms"
 
+	// RM_HOSTNAME is the host of rm addresses not set, RM_BIND_HOST overrides the host rm listens on
+	RM_HOSTNAME                 = RM_PREFIX + "hostname"
+	RM_BIND_HOST                = RM_PREFIX + "bind-host"
+	RM_RESOURCE_TRACKER_ADDRESS = RM_PREFIX + "resource-tracker.address"
+	RM_WEBAPP_ADDRESS           = RM_PREFIX + "webapp.address"
+	RM_WEBAPP_HTTPS_ADDRESS     = RM_PREFIX + "webapp.https.address"
+
+	// ZK_ADDRESS and ZK_TIMEOUT_MS are keys of hadoop common, RM_ZK_ADDRESS and RM_ZK_TIMEOUT_MS are their
+	// deprecated keys
+	ZK_ADDRESS                 = "hadoop.zk.address"
+	ZK_TIMEOUT_MS              = "hadoop.zk.timeout-ms"
+	RM_ZK_ADDRESS              = RM_PREFIX + "zk-address"
+	RM_ZK_TIMEOUT_MS           = RM_PREFIX + "zk-timeout-ms"
+	AUTO_FAILOVER_ZK_BASE_PATH = RM_PREFIX + "ha.automatic-failover.zk-base-path"
+
+	FEDERATION_PREFIX       = YARN_PREFIX + "federation."
+	FEDERATION_ENABLED      = FEDERATION_PREFIX + "enabled"
+	FEDERATION_MACHINE_LIST = FEDERATION_PREFIX + "machine-list"
+
+	// COPILOT_PREFIX is the prefix of keys which are not keys of hadoop, so that they never clash with keys hadoop
+	// defines later
+	COPILOT_PREFIX = "yarn-copilot."
+	// FEDERATION_SUBCLUSTER_IDS lists the sub-clusters whose rm are configured by <sub-cluster id>.yarn-site.xml in
+	// the conf dir, since sub-clusters of hadoop register to the federation state store instead
+	FEDERATION_SUBCLUSTER_IDS = COPILOT_PREFIX + "federation.subcluster-ids"
+	// CLIENT_ACTIVE_RM_RESOLVER selects how clients find the active rm of ha clusters, which is
+	// ACTIVE_RM_RESOLVER_HA_STATUS or ACTIVE_RM_RESOLVER_ZOOKEEPER
+	CLIENT_ACTIVE_RM_RESOLVER = COPILOT_PREFIX + "client.active-rm-resolver"
+
+	ROUTER_PREFIX           = YARN_PREFIX + "router."
+	ROUTER_CLIENTRM_ADDRESS = ROUTER_PREFIX + "clientrm.address"
+	ROUTER_RMADMIN_ADDRESS  = ROUTER_PREFIX + "rmadmin.address"
+
+	CLIENT_FAILOVER_PREFIX                     = YARN_PREFIX + "client.failover-"
+	CLIENT_FAILOVER_MAX_ATTEMPTS               = CLIENT_FAILOVER_PREFIX + "max-attempts"
+	CLIENT_FAILOVER_SLEEPTIME_BASE_MS          = CLIENT_FAILOVER_PREFIX + "sleep-base-ms"
+	CLIENT_FAILOVER_SLEEPTIME_MAX_MS           = CLIENT_FAILOVER_PREFIX + "sleep-max-ms"
+	CLIENT_FAILOVER_RETRIES                    = CLIENT_FAILOVER_PREFIX + "retries"
+	CLIENT_FAILOVER_RETRIES_ON_SOCKET_TIMEOUTS = CLIENT_FAILOVER_PREFIX + "retries-on-socket-timeouts"
+
+	DEFAULT_RM_HOSTNAME              = "0.0.0.0"
+	DEFAULT_RM_PORT                  = 8032
+	DEFAULT_RM_SCHEDULER_PORT        = 8030
+	DEFAULT_RM_RESOURCE_TRACKER_PORT = 8031
+	DEFAULT_RM_ADMIN_PORT            = 8033
+	DEFAULT_RM_WEBAPP_PORT           = 8088
+	DEFAULT_RM_WEBAPP_HTTPS_PORT     = 8090
 	DEFAULT_RM_ADDRESS               = "0.0.0.0:8032"
 	DEFAULT_RM_SCHEDULER_ADDRESS     = "0.0.0.0:8030"
 	DEFAULT_RM_ADMIN_ADDRESS         = "0.0.0.0:8033"
 	DEFAULT_RM_AM_EXPIRY_INTERVAL_MS = 600000
 	DEFAULT_RM_HA_ENABLED            = false
+	DEFAULT_AUTO_FAILOVER_ENABLED    = true
+	DEFAULT_FEDERATION_ENABLED       = false
+	DEFAULT_ROUTER_CLIENTRM_PORT     = 8050
+	DEFAULT_ROUTER_RMADMIN_PORT      = 8052
+	DEFAULT_ZK_TIMEOUT_MS            = 10000
+	// the lock znode of the elector is <base path>/<cluster id>/ActiveStandbyElectorLock
+	DEFAULT_AUTO_FAILOVER_ZK_BASE_PATH = "/yarn-leader-election"
+
+	// ACTIVE_RM_RESOLVER_HA_STATUS asks the ha service status of rms in turn, ACTIVE_RM_RESOLVER_ZOOKEEPER reads and
+	// watches the lock znode of the elector of rms
+	ACTIVE_RM_RESOLVER_HA_STATUS      = "ha-status"
+	ACTIVE_RM_RESOLVER_ZOOKEEPER      = "zookeeper"
+	DEFAULT_CLIENT_ACTIVE_RM_RESOLVER = ACTIVE_RM_RESOLVER_HA_STATUS
+
+	// hadoop falls back to yarn.resourcemanager.connect.* which retries for 15 minutes, use the common
+	// failover defaults of hadoop instead, since callers are bounded by their own loops
+	DEFAULT_CLIENT_FAILOVER_MAX_ATTEMPTS               = 15
+	DEFAULT_CLIENT_FAILOVER_SLEEPTIME_BASE_MS          = 500
+	DEFAULT_CLIENT_FAILOVER_SLEEPTIME_MAX_MS           = 15000
+	DEFAULT_CLIENT_FAILOVER_RETRIES                    = 0
+	DEFAULT_CLIENT_FAILOVER_RETRIES_ON_SOCKET_TIMEOUTS = 0
 )
 
 type yarn_configuration struct {
@@ -49,19 +121,59 @@ type yarn_configuration struct {
 }
 
 type YarnConfiguration interface {
+	// rm addresses not set are derived from yarn.resourcemanager.hostname and the default ports as hadoop, the
+	// getters of addresses return the connect address of rm, which is an error if rm is not resolvable
 	GetRMAddress() (string, error)
 	GetRMSchedulerAddress() (string, error)
 	GetRMAdminAddress() (string, error)
+	GetRMResourceTrackerAddress() (string, error)
+	GetRMWebAppAddress() (string, error)
 	GetRMEnabledHA() (bool, error)
+	GetRMAutoFailoverEnabled() (bool, error)
 	GetRMs() ([]string, error)
 	GetRMAdminAddressByID(rmID string) (string, error)
 	GetRMAddressByID(rmID string) (string, error)
+	GetRMSchedulerAddressByID(rmID string) (string, error)
+	GetRMResourceTrackerAddressByID(rmID string) (string, error)
+	GetRMWebAppAddressByID(rmID string) (string, error)
+	// GetRMConnectAddress returns the address of key dialed by clients, rmID is empty if ha is not enabled
+	GetRMConnectAddress(key string, rmID string) (string, error)
+	// GetRMBindAddress returns the address of key which rm listens on, whose host is yarn.resourcemanager.bind-host
+	// if set
+	GetRMBindAddress(key string, rmID string) (string, error)
+	GetRMClusterID() (string, error)
+	// GetZKAddress returns the connect string of zookeeper used by rm, which is empty if not configured
+	GetZKAddress() (string, error)
+	GetZKTimeoutMS() (int, error)
+	GetRMAutoFailoverZKBasePath() (string, error)
+	GetClientActiveRMResolver() (string, error)
+
+	// GetFederationEnabled returns whether the cluster is a federation of sub-clusters behind routers, whose
+	// clients talk to the router at GetRouterAddress and GetRouterAdminAddress instead of rm
+	GetFederationEnabled() (bool, error)
+	GetRouterAddress() (string, error)
+	GetRouterAdminAddress() (string, error)
+	GetFederationSubClusterIDs() ([]string, error)
+	// GetFederationMachineList returns the path of machine list, whose lines are "<node>,<sub-cluster id>,<rack>"
+	// as DefaultSubClusterResolverImpl of hadoop reads, it is empty if not configured
+	GetFederationMachineList() (string, error)
 
 	SetRMAddress(address string) error
 	SetRMSchedulerAddress(address string) error
 
 	Get(key string, defaultValue string) (string, error)
 	GetInt(key string, defaultValue int) (int, error)
+	GetBool(key string, defaultValue bool) (bool, error)
+	GetTrimmedStrings(key string) ([]string, error)
+	GetTimeDuration(key string, defaultValue time.Duration, unit time.Duration) (time.Duration, error)
+	GetStorageSize(key string, defaultValue int64) (int64, error)
+	GetSocketAddress(key string, defaultAddress string, defaultPort int) (string, error)
+	GetIntInRange(key string, defaultValue int, minValue int, maxValue int) (int, error)
+	GetEnum(key string, defaultValue string, values ...string) (string, error)
+	Describe(key string) (*PropertyDescription, error)
+	Dump(w io.Writer, format string) error
+	WriteXML(w io.Writer) error
+	Files() []string
 
 	Set(key string, value string) error
 	SetInt(key string, value int) error
@@ -75,42 +187,144 @@ func (yarnConf *yarn_configuration) GetInt(key string, defaultValue int) (int, e
 	return yarnConf.conf.GetInt(key, defaultValue)
 }
 
+func (yarnConf *yarn_configuration) GetBool(key string, defaultValue bool) (bool, error) {
+	return yarnConf.conf.GetBool(key, defaultValue)
+}
+
+func (yarnConf *yarn_configuration) GetTrimmedStrings(key string) ([]string, error) {
+	return yarnConf.conf.GetTrimmedStrings(key)
+}
+
+func (yarnConf *yarn_configuration) GetTimeDuration(key string, defaultValue time.Duration, unit time.Duration) (time.Duration, error) {
+	return yarnConf.conf.GetTimeDuration(key, defaultValue, unit)
+}
+
+func (yarnConf *yarn_configuration) GetStorageSize(key string, defaultValue int64) (int64, error) {
+	return yarnConf.conf.GetStorageSize(key, defaultValue)
+}
+
+func (yarnConf *yarn_configuration) GetSocketAddress(key string, defaultAddress string, defaultPort int) (string, error) {
+	return yarnConf.conf.GetSocketAddress(key, defaultAddress, defaultPort)
+}
+
+func (yarnConf *yarn_configuration) GetIntInRange(key string, defaultValue int, minValue int, maxValue int) (int, error) {
+	return yarnConf.conf.GetIntInRange(key, defaultValue, minValue, maxValue)
+}
+
+func (yarnConf *yarn_configuration) GetEnum(key string, defaultValue string, values ...string) (string, error) {
+	return yarnConf.conf.GetEnum(key, defaultValue, values...)
+}
+
+func (yarnConf *yarn_configuration) Describe(key string) (*PropertyDescription, error) {
+	return yarnConf.conf.Describe(key)
+}
+
+func (yarnConf *yarn_configuration) Dump(w io.Writer, format string) error {
+	return yarnConf.conf.Dump(w, format)
+}
+
+func (yarnConf *yarn_configuration) WriteXML(w io.Writer) error {
+	return yarnConf.conf.WriteXML(w)
+}
+
+func (yarnConf *yarn_configuration) Files() []string {
+	return yarnConf.conf.Files()
+}
+
 func (yarnConf *yarn_configuration) GetRMAddress() (string, error) {
-	return yarnConf.conf.Get(RM_ADDRESS, DEFAULT_RM_ADDRESS)
+	return yarnConf.getRMConnectAddress(RM_ADDRESS, "")
 }
 
 func (yarnConf *yarn_configuration) GetRMSchedulerAddress() (string, error) {
-	return yarnConf.conf.Get(RM_SCHEDULER_ADDRESS, DEFAULT_RM_SCHEDULER_ADDRESS)
+	return yarnConf.getRMConnectAddress(RM_SCHEDULER_ADDRESS, "")
 }
 
 func (yarnConf *yarn_configuration) GetRMAdminAddress() (string, error) {
-	return yarnConf.conf.Get(RM_ADMIN_ADDRESS, DEFAULT_RM_ADMIN_ADDRESS)
+	return yarnConf.getRMConnectAddress(RM_ADMIN_ADDRESS, "")
+}
+
+func (yarnConf *yarn_configuration) GetRMResourceTrackerAddress() (string, error) {
+	return yarnConf.getRMConnectAddress(RM_RESOURCE_TRACKER_ADDRESS, "")
+}
+
+func (yarnConf *yarn_configuration) GetRMWebAppAddress() (string, error) {
+	return yarnConf.getRMConnectAddress(RM_WEBAPP_ADDRESS, "")
 }
 
 func (yarnConf *yarn_configuration) GetRMEnabledHA() (bool, error) {
 	return yarnConf.conf.GetBool(RM_HA_ENABLED, DEFAULT_RM_HA_ENABLED)
 }
 
+func (yarnConf *yarn_configuration) GetRMAutoFailoverEnabled() (bool, error) {
+	return yarnConf.conf.GetBool(AUTO_FAILOVER_ENABLED, DEFAULT_AUTO_FAILOVER_ENABLED)
+}
+
 func (yarnConf *yarn_configuration) GetRMs() ([]string, error) {
-	rmIDs := make([]string, 0)
-	allRMs, err := yarnConf.conf.Get(RM_HA_RM_IDS, "")
-	if err != nil {
-		return rmIDs, nil
-	}
-	rmIDs = strings.Split(allRMs, ",")
-	return rmIDs, nil
+	return yarnConf.conf.GetTrimmedStrings(RM_HA_RM_IDS)
 }
 
 func (yarnConf *yarn_configuration) GetRMAdminAddressByID(rmID string) (string, error) {
-	// yarn.resourcemanager.admin.address.rm1
-	rmAddrKey := fmt.Sprintf("%v.%v", RM_ADMIN_ADDRESS, rmID)
-	return yarnConf.conf.Get(rmAddrKey, DEFAULT_RM_ADMIN_ADDRESS)
+	// yarn.resourcemanager.admin.address.rm1, or yarn.resourcemanager.hostname.rm1:8033
+	return yarnConf.getRMConnectAddress(RM_ADMIN_ADDRESS, rmID)
 }
 
 func (yarnConf *yarn_configuration) GetRMAddressByID(rmID string) (string, error) {
-	// yarn.resourcemanager.address.rm1
-	rmAddrKey := fmt.Sprintf("%v.%v", RM_ADDRESS, rmID)
-	return yarnConf.conf.Get(rmAddrKey, DEFAULT_RM_ADDRESS)
+	// yarn.resourcemanager.address.rm1, or yarn.resourcemanager.hostname.rm1:8032
+	return yarnConf.getRMConnectAddress(RM_ADDRESS, rmID)
+}
+
+func (yarnConf *yarn_configuration) GetRMSchedulerAddressByID(rmID string) (string, error) {
+	// yarn.resourcemanager.scheduler.address.rm1, or yarn.resourcemanager.hostname.rm1:8030
+	return yarnConf.getRMConnectAddress(RM_SCHEDULER_ADDRESS, rmID)
+}
+
+func (yarnConf *yarn_configuration) GetRMResourceTrackerAddressByID(rmID string) (string, error) {
+	return yarnConf.getRMConnectAddress(RM_RESOURCE_TRACKER_ADDRESS, rmID)
+}
+
+func (yarnConf *yarn_configuration) GetRMWebAppAddressByID(rmID string) (string, error) {
+	return yarnConf.getRMConnectAddress(RM_WEBAPP_ADDRESS, rmID)
+}
+
+func (yarnConf *yarn_configuration) GetRMClusterID() (string, error) {
+	return yarnConf.conf.Get(RM_CLUSTER_ID, "")
+}
+
+func (yarnConf *yarn_configuration) GetZKAddress() (string, error) {
+	return yarnConf.conf.Get(ZK_ADDRESS, "")
+}
+
+func (yarnConf *yarn_configuration) GetZKTimeoutMS() (int, error) {
+	return yarnConf.conf.GetIntInRange(ZK_TIMEOUT_MS, DEFAULT_ZK_TIMEOUT_MS, 1, math.MaxInt32)
+}
+
+func (yarnConf *yarn_configuration) GetRMAutoFailoverZKBasePath() (string, error) {
+	return yarnConf.conf.Get(AUTO_FAILOVER_ZK_BASE_PATH, DEFAULT_AUTO_FAILOVER_ZK_BASE_PATH)
+}
+
+func (yarnConf *yarn_configuration) GetClientActiveRMResolver() (string, error) {
+	return yarnConf.conf.GetEnum(CLIENT_ACTIVE_RM_RESOLVER, DEFAULT_CLIENT_ACTIVE_RM_RESOLVER,
+		ACTIVE_RM_RESOLVER_HA_STATUS, ACTIVE_RM_RESOLVER_ZOOKEEPER)
+}
+
+func (yarnConf *yarn_configuration) GetFederationEnabled() (bool, error) {
+	return yarnConf.conf.GetBool(FEDERATION_ENABLED, DEFAULT_FEDERATION_ENABLED)
+}
+
+func (yarnConf *yarn_configuration) GetRouterAddress() (string, error) {
+	return yarnConf.getRouterConnectAddress(ROUTER_CLIENTRM_ADDRESS, DEFAULT_ROUTER_CLIENTRM_PORT)
+}
+
+func (yarnConf *yarn_configuration) GetRouterAdminAddress() (string, error) {
+	return yarnConf.getRouterConnectAddress(ROUTER_RMADMIN_ADDRESS, DEFAULT_ROUTER_RMADMIN_PORT)
+}
+
+func (yarnConf *yarn_configuration) GetFederationSubClusterIDs() ([]string, error) {
+	return yarnConf.conf.GetTrimmedStrings(FEDERATION_SUBCLUSTER_IDS)
+}
+
+func (yarnConf *yarn_configuration) GetFederationMachineList() (string, error) {
+	return yarnConf.conf.Get(FEDERATION_MACHINE_LIST, "")
 }
 
 func (yarnConf *yarn_configuration) Set(key string, value string) error {
@@ -0,0 +1,140 @@
+/*
+Copyright 2023 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestYarnConfiguration(properties map[string]string) *yarn_configuration {
+	return &yarn_configuration{conf: newTestConfiguration(properties)}
+}
+
+func TestGetRMAddressByID(t *testing.T) {
+	yarnConf := newTestYarnConfiguration(map[string]string{
+		RM_HA_ENABLED:              "true",
+		RM_HA_RM_IDS:               "rm1,rm2,rm3",
+		RM_HOSTNAME + ".rm1":       "rm1.example.com",
+		RM_ADMIN_ADDRESS + ".rm1":  "rm1-admin.example.com:18033",
+		RM_HOSTNAME + ".rm2":       " rm2.example.com ",
+		RM_ADDRESS + ".rm2":        "${yarn.resourcemanager.hostname.rm2}",
+		RM_WEBAPP_ADDRESS + ".rm2": "[::1]:18088",
+		RM_ADDRESS + ".rm3":        "0.0.0.0:8032",
+		RM_HOSTNAME:                "rm.example.com",
+	})
+	tests := []struct {
+		get      func(rmID string) (string, error)
+		rmID     string
+		expected string
+		err      string
+	}{
+		{get: yarnConf.GetRMAddressByID, rmID: "rm1", expected: "rm1.example.com:8032"},
+		{get: yarnConf.GetRMSchedulerAddressByID, rmID: "rm1", expected: "rm1.example.com:8030"},
+		{get: yarnConf.GetRMResourceTrackerAddressByID, rmID: "rm1", expected: "rm1.example.com:8031"},
+		{get: yarnConf.GetRMAdminAddressByID, rmID: "rm1", expected: "rm1-admin.example.com:18033"},
+		{get: yarnConf.GetRMWebAppAddressByID, rmID: "rm1", expected: "rm1.example.com:8088"},
+		{get: yarnConf.GetRMAddressByID, rmID: "rm2", expected: "rm2.example.com:8032"},
+		{get: yarnConf.GetRMWebAppAddressByID, rmID: "rm2", expected: "[::1]:18088"},
+		{get: yarnConf.GetRMAddressByID, rmID: "rm3", err: "rm address 0.0.0.0:8032 of yarn.resourcemanager.address.rm3 is a wildcard address"},
+		{get: yarnConf.GetRMAdminAddressByID, rmID: "rm3", err: "yarn.resourcemanager.hostname.rm3 or yarn.resourcemanager.admin.address.rm3 needs to be set"},
+		{get: yarnConf.GetRMAddressByID, rmID: "rm4", err: "yarn.resourcemanager.hostname.rm4 or yarn.resourcemanager.address.rm4 needs to be set"},
+	}
+	for _, tt := range tests {
+		address, err := tt.get(tt.rmID)
+		if tt.err != "" {
+			assert.ErrorContains(t, err, tt.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, address)
+	}
+}
+
+func TestGetRMAddress(t *testing.T) {
+	yarnConf := newTestYarnConfiguration(map[string]string{})
+	_, err := yarnConf.GetRMAddress()
+	assert.ErrorContains(t, err, "set yarn.resourcemanager.address or yarn.resourcemanager.hostname")
+	bindAddress, err := yarnConf.GetRMBindAddress(RM_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:8032", bindAddress)
+
+	assert.NoError(t, yarnConf.Set(RM_HOSTNAME, "rm.example.com"))
+	assert.NoError(t, yarnConf.Set(RM_SCHEDULER_ADDRESS, "scheduler.example.com"))
+	assert.NoError(t, yarnConf.Set(RM_ADMIN_ADDRESS, "rm.example.com:port"))
+	assert.NoError(t, yarnConf.Set(RM_BIND_HOST, "0.0.0.0"))
+	address, err := yarnConf.GetRMAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "rm.example.com:8032", address)
+	address, err = yarnConf.GetRMSchedulerAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduler.example.com:8030", address)
+	address, err = yarnConf.GetRMWebAppAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "rm.example.com:8088", address)
+	address, err = yarnConf.GetRMConnectAddress(RM_WEBAPP_HTTPS_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "rm.example.com:8090", address)
+	bindAddress, err = yarnConf.GetRMBindAddress(RM_SCHEDULER_ADDRESS, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:8030", bindAddress)
+
+	_, err = yarnConf.GetRMAdminAddress()
+	assert.ErrorContains(t, err, `invalid value "rm.example.com:port" of yarn.resourcemanager.admin.address from programmatically, error port out of range`)
+	_, err = yarnConf.GetRMConnectAddress(ROUTER_CLIENTRM_ADDRESS, "")
+	assert.ErrorContains(t, err, "invalid rm address key")
+}
+
+func TestGetRouterAddress(t *testing.T) {
+	yarnConf := newTestYarnConfiguration(map[string]string{
+		FEDERATION_ENABLED:      "true",
+		ROUTER_CLIENTRM_ADDRESS: "router.example.com",
+		ROUTER_RMADMIN_ADDRESS:  "router.example.com:18052",
+	})
+	address, err := yarnConf.GetRouterAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "router.example.com:8050", address)
+	address, err = yarnConf.GetRouterAdminAddress()
+	assert.NoError(t, err)
+	assert.Equal(t, "router.example.com:18052", address)
+
+	assert.NoError(t, yarnConf.Set(ROUTER_CLIENTRM_ADDRESS, "0.0.0.0:8050"))
+	_, err = yarnConf.GetRouterAddress()
+	assert.ErrorContains(t, err, "router address 0.0.0.0:8050 of yarn.router.clientrm.address is a wildcard address")
+	assert.NoError(t, yarnConf.Set(ROUTER_CLIENTRM_ADDRESS, "router.example.com:port"))
+	_, err = yarnConf.GetRouterAddress()
+	assert.ErrorContains(t, err, `invalid value "router.example.com:port" of yarn.router.clientrm.address`)
+	assert.NoError(t, yarnConf.Set(ROUTER_CLIENTRM_ADDRESS, " "))
+	_, err = yarnConf.GetRouterAddress()
+	assert.ErrorContains(t, err, "yarn.router.clientrm.address needs to be set in a federation configuration")
+}
+
+func TestGetRMs(t *testing.T) {
+	yarnConf := newTestYarnConfiguration(map[string]string{RM_HA_RM_IDS: " rm1 , ,rm2,"})
+	rmIDs, err := yarnConf.GetRMs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"rm1", "rm2"}, rmIDs)
+
+	assert.NoError(t, yarnConf.Set(CLIENT_ACTIVE_RM_RESOLVER, "ZooKeeper"))
+	resolver, err := yarnConf.GetClientActiveRMResolver()
+	assert.NoError(t, err)
+	assert.Equal(t, ACTIVE_RM_RESOLVER_ZOOKEEPER, resolver)
+	assert.NoError(t, yarnConf.Set(ZK_TIMEOUT_MS, "0"))
+	_, err = yarnConf.GetZKTimeoutMS()
+	assert.ErrorContains(t, err, "hadoop.zk.timeout-ms")
+}
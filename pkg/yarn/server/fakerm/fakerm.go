@@ -0,0 +1,462 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakerm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+	hadoop_ipc_server "github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/ipc"
+)
+
+// ResourceManager is an in-process fake of yarn resource manager for tests. It serves ApplicationClientProtocol on
+// Address, and ResourceManagerAdministrationProtocol and HAServiceProtocol on AdminAddress, with an in-memory model
+// of nodes and applications. Calls other than the ha ones are rejected with StandbyException unless it is active.
+type ResourceManager struct {
+	server       *hadoop_ipc_server.Server
+	address      string
+	adminAddress string
+
+	mtx              sync.Mutex
+	state            hadoopcommon.HAServiceStateProto
+	clusterTimestamp int64
+	lastAppID        int32
+	nodes            map[string]*hadoopyarn.NodeReportProto
+	apps             map[int32]*hadoopyarn.ApplicationReportProto
+	calls            map[string]int
+}
+
+// NewResourceManager starts an active rm listening on random ports of localhost
+func NewResourceManager() (*ResourceManager, error) {
+	rm := &ResourceManager{
+		server:           hadoop_ipc_server.NewServer(),
+		state:            hadoopcommon.HAServiceStateProto_ACTIVE,
+		clusterTimestamp: time.Now().UnixMilli(),
+		nodes:            map[string]*hadoopyarn.NodeReportProto{},
+		apps:             map[int32]*hadoopyarn.ApplicationReportProto{},
+		calls:            map[string]int{},
+	}
+	rm.registerApplicationClientProtocol()
+	rm.registerAdminProtocol()
+	rm.registerHAProtocol()
+
+	// both listeners serve all protocols, clients only call the protocols of their address
+	listener, err := rm.server.Listen("127.0.0.1:0")
+	if err != nil {
+		rm.server.Close()
+		return nil, err
+	}
+	adminListener, err := rm.server.Listen("127.0.0.1:0")
+	if err != nil {
+		rm.server.Close()
+		return nil, err
+	}
+	rm.address, rm.adminAddress = listener.Addr().String(), adminListener.Addr().String()
+	return rm, nil
+}
+
+// Close stops the rm
+func (rm *ResourceManager) Close() {
+	rm.server.Close()
+}
+
+// Address is the address of ApplicationClientProtocol, which is yarn.resourcemanager.address
+func (rm *ResourceManager) Address() string {
+	return rm.address
+}
+
+// AdminAddress is the address of admin and ha protocols, which is yarn.resourcemanager.admin.address
+func (rm *ResourceManager) AdminAddress() string {
+	return rm.adminAddress
+}
+
+// YarnSite returns the yarn-site properties of a cluster of the rm only
+func (rm *ResourceManager) YarnSite() map[string]string {
+	return map[string]string{
+		yarnconf.RM_ADDRESS:       rm.address,
+		yarnconf.RM_ADMIN_ADDRESS: rm.adminAddress,
+	}
+}
+
+// HAYarnSite returns the yarn-site properties of an ha cluster of rms by rm id
+func HAYarnSite(rms map[string]*ResourceManager) map[string]string {
+	rmIDs := make([]string, 0, len(rms))
+	for rmID := range rms {
+		rmIDs = append(rmIDs, rmID)
+	}
+	sort.Strings(rmIDs)
+	properties := map[string]string{
+		yarnconf.RM_HA_ENABLED: "true",
+		yarnconf.RM_HA_RM_IDS:  strings.Join(rmIDs, ","),
+	}
+	for rmID, rm := range rms {
+		properties[fmt.Sprintf("%v.%v", yarnconf.RM_ADDRESS, rmID)] = rm.address
+		properties[fmt.Sprintf("%v.%v", yarnconf.RM_ADMIN_ADDRESS, rmID)] = rm.adminAddress
+	}
+	return properties
+}
+
+// WriteYarnSite writes properties into yarn-site.xml of dir, which is the conf dir of clients
+func WriteYarnSite(dir string, properties map[string]string) error {
+	return WriteClusterYarnSite(dir, "", properties)
+}
+
+// WriteClusterYarnSite writes properties into <clusterID>.yarn-site.xml of dir, which is the yarn-site of cluster
+// clusterID for clients
+func WriteClusterYarnSite(dir string, clusterID string, properties map[string]string) error {
+	name := yarnconf.YARN_SITE.Name
+	if clusterID != "" {
+		name = clusterID + "." + name
+	}
+	conf, err := yarnconf.NewConfigurationFromSources(yarnconf.MapSource(name, properties))
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	if err := conf.WriteXML(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// SetHAState changes the ha state of rm, e.g. to make it standby without transitions
+func (rm *ResourceManager) SetHAState(state hadoopcommon.HAServiceStateProto) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	rm.state = state
+}
+
+func (rm *ResourceManager) HAState() hadoopcommon.HAServiceStateProto {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	return rm.state
+}
+
+// AddNode adds the node of report or replaces it, the node is RUNNING if the state of report is not set
+func (rm *ResourceManager) AddNode(report *hadoopyarn.NodeReportProto) {
+	report = proto.Clone(report).(*hadoopyarn.NodeReportProto)
+	if report.NodeState == nil {
+		report.NodeState = hadoopyarn.NodeStateProto_NS_RUNNING.Enum()
+	}
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	rm.nodes[nodeKey(report.GetNodeId())] = report
+}
+
+// Node returns the report of node
+func (rm *ResourceManager) Node(nodeID *hadoopyarn.NodeIdProto) (*hadoopyarn.NodeReportProto, bool) {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	report, ok := rm.nodes[nodeKey(nodeID)]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(report).(*hadoopyarn.NodeReportProto), true
+}
+
+// Applications returns the reports of all applications submitted
+func (rm *ResourceManager) Applications() []*hadoopyarn.ApplicationReportProto {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	return rm.applications(&hadoopyarn.GetApplicationsRequestProto{})
+}
+
+// SetApplicationState changes the state of application, e.g. to make it running or finished
+func (rm *ResourceManager) SetApplicationState(appID *hadoopyarn.ApplicationIdProto, state hadoopyarn.YarnApplicationStateProto) error {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	app, ok := rm.apps[appID.GetId()]
+	if !ok || appID.GetClusterTimestamp() != rm.clusterTimestamp {
+		return fmt.Errorf("application %v not found", appID)
+	}
+	app.YarnApplicationState = state.Enum()
+	return nil
+}
+
+// CallCount returns the number of calls of method received by rm, including the rejected ones
+func (rm *ResourceManager) CallCount(method string) int {
+	rm.mtx.Lock()
+	defer rm.mtx.Unlock()
+	return rm.calls[method]
+}
+
+func nodeKey(nodeID *hadoopyarn.NodeIdProto) string {
+	return fmt.Sprintf("%v:%v", nodeID.GetHost(), nodeID.GetPort())
+}
+
+// handle wraps handle of method, which is called with mtx held and only if rm is active unless ha
+func handle[Request, Response proto.Message](rm *ResourceManager, protocol string, method string, ha bool, handle func(ctx context.Context, request Request) (Response, error)) {
+	hadoop_ipc_server.Register(rm.server, protocol, method, func(ctx context.Context, request Request) (Response, error) {
+		rm.mtx.Lock()
+		defer rm.mtx.Unlock()
+		rm.calls[method]++
+		if !ha && rm.state != hadoopcommon.HAServiceStateProto_ACTIVE {
+			var response Response
+			return response, hadoop_ipc_server.NewException(hadoop_ipc_client.StandbyException,
+				"Operation category %v is not supported in state %v", method, rm.state)
+		}
+		return handle(ctx, request)
+	})
+}
+
+func (rm *ResourceManager) registerApplicationClientProtocol() {
+	protocol := yarnservice.APPLICATION_CLIENT_PROTOCOL
+	handle(rm, protocol, "getClusterMetrics", false, func(ctx context.Context, request *hadoopyarn.GetClusterMetricsRequestProto) (*hadoopyarn.GetClusterMetricsResponseProto, error) {
+		metrics := &hadoopyarn.YarnClusterMetricsProto{}
+		var managers, active, decommissioned, lost, unhealthy, rebooted int32
+		for _, node := range rm.nodes {
+			switch node.GetNodeState() {
+			case hadoopyarn.NodeStateProto_NS_RUNNING, hadoopyarn.NodeStateProto_NS_NEW:
+				active++
+			case hadoopyarn.NodeStateProto_NS_DECOMMISSIONED:
+				decommissioned++
+			case hadoopyarn.NodeStateProto_NS_LOST:
+				lost++
+			case hadoopyarn.NodeStateProto_NS_UNHEALTHY:
+				unhealthy++
+			case hadoopyarn.NodeStateProto_NS_REBOOTED:
+				rebooted++
+			}
+		}
+		managers = active
+		metrics.NumNodeManagers, metrics.NumActiveNms = &managers, &active
+		metrics.NumDecommissionedNms, metrics.NumLostNms = &decommissioned, &lost
+		metrics.NumUnhealthyNms, metrics.NumRebootedNms = &unhealthy, &rebooted
+		return &hadoopyarn.GetClusterMetricsResponseProto{ClusterMetrics: metrics}, nil
+	})
+	handle(rm, protocol, "getClusterNodes", false, func(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+		response := &hadoopyarn.GetClusterNodesResponseProto{}
+		for _, key := range sortedKeys(rm.nodes) {
+			node := rm.nodes[key]
+			if len(request.GetNodeStates()) == 0 || containsNodeState(request.GetNodeStates(), node.GetNodeState()) {
+				response.NodeReports = append(response.NodeReports, proto.Clone(node).(*hadoopyarn.NodeReportProto))
+			}
+		}
+		return response, nil
+	})
+	handle(rm, protocol, "getNewApplication", false, func(ctx context.Context, request *hadoopyarn.GetNewApplicationRequestProto) (*hadoopyarn.GetNewApplicationResponseProto, error) {
+		rm.lastAppID++
+		return &hadoopyarn.GetNewApplicationResponseProto{
+			ApplicationId: &hadoopyarn.ApplicationIdProto{Id: proto.Int32(rm.lastAppID), ClusterTimestamp: proto.Int64(rm.clusterTimestamp)},
+		}, nil
+	})
+	handle(rm, protocol, "submitApplication", false, func(ctx context.Context, request *hadoopyarn.SubmitApplicationRequestProto) (*hadoopyarn.SubmitApplicationResponseProto, error) {
+		submission := request.GetApplicationSubmissionContext()
+		appID := submission.GetApplicationId()
+		if appID.GetClusterTimestamp() != rm.clusterTimestamp || appID.GetId() <= 0 || appID.GetId() > rm.lastAppID {
+			return nil, hadoop_ipc_server.NewException(hadoop_ipc_client.IllegalArgumentException, "invalid application id %v", appID)
+		}
+		if _, ok := rm.apps[appID.GetId()]; ok {
+			// submissions are idempotent
+			return &hadoopyarn.SubmitApplicationResponseProto{}, nil
+		}
+		user := ""
+		if call, ok := hadoop_ipc_server.CallFromContext(ctx); ok {
+			user = call.User
+		}
+		rm.apps[appID.GetId()] = &hadoopyarn.ApplicationReportProto{
+			ApplicationId:          appID,
+			User:                   proto.String(user),
+			Queue:                  proto.String(submission.GetQueue()),
+			Name:                   proto.String(submission.GetApplicationName()),
+			ApplicationType:        proto.String(submission.GetApplicationType()),
+			ApplicationTags:        submission.GetApplicationTags(),
+			YarnApplicationState:   hadoopyarn.YarnApplicationStateProto_ACCEPTED.Enum(),
+			StartTime:              proto.Int64(time.Now().UnixMilli()),
+			FinalApplicationStatus: hadoopyarn.FinalApplicationStatusProto_APP_UNDEFINED.Enum(),
+		}
+		return &hadoopyarn.SubmitApplicationResponseProto{}, nil
+	})
+	handle(rm, protocol, "getApplicationReport", false, func(ctx context.Context, request *hadoopyarn.GetApplicationReportRequestProto) (*hadoopyarn.GetApplicationReportResponseProto, error) {
+		app, err := rm.application(request.GetApplicationId())
+		if err != nil {
+			return nil, err
+		}
+		return &hadoopyarn.GetApplicationReportResponseProto{ApplicationReport: proto.Clone(app).(*hadoopyarn.ApplicationReportProto)}, nil
+	})
+	handle(rm, protocol, "getApplications", false, func(ctx context.Context, request *hadoopyarn.GetApplicationsRequestProto) (*hadoopyarn.GetApplicationsResponseProto, error) {
+		return &hadoopyarn.GetApplicationsResponseProto{Applications: rm.applications(request)}, nil
+	})
+	handle(rm, protocol, "forceKillApplication", false, func(ctx context.Context, request *hadoopyarn.KillApplicationRequestProto) (*hadoopyarn.KillApplicationResponseProto, error) {
+		app, err := rm.application(request.GetApplicationId())
+		if err != nil {
+			return nil, err
+		}
+		switch app.GetYarnApplicationState() {
+		case hadoopyarn.YarnApplicationStateProto_FINISHED, hadoopyarn.YarnApplicationStateProto_FAILED, hadoopyarn.YarnApplicationStateProto_KILLED:
+		default:
+			app.YarnApplicationState = hadoopyarn.YarnApplicationStateProto_KILLED.Enum()
+			app.FinalApplicationStatus = hadoopyarn.FinalApplicationStatusProto_APP_KILLED.Enum()
+			app.FinishTime = proto.Int64(time.Now().UnixMilli())
+			app.Diagnostics = proto.String(request.GetDiagnostics())
+		}
+		return &hadoopyarn.KillApplicationResponseProto{IsKillCompleted: proto.Bool(true)}, nil
+	})
+}
+
+// application returns the application of appID, which must be called with mtx held
+func (rm *ResourceManager) application(appID *hadoopyarn.ApplicationIdProto) (*hadoopyarn.ApplicationReportProto, error) {
+	app, ok := rm.apps[appID.GetId()]
+	if !ok || appID.GetClusterTimestamp() != rm.clusterTimestamp {
+		return nil, hadoop_ipc_server.NewException(hadoop_ipc_client.ApplicationNotFoundException,
+			"Application with id '%v' doesn't exist in RM.", appID)
+	}
+	return app, nil
+}
+
+// applications returns the applications matching request, which must be called with mtx held
+func (rm *ResourceManager) applications(request *hadoopyarn.GetApplicationsRequestProto) []*hadoopyarn.ApplicationReportProto {
+	ids := make([]int, 0, len(rm.apps))
+	for id := range rm.apps {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	var reports []*hadoopyarn.ApplicationReportProto
+	for _, id := range ids {
+		app := rm.apps[int32(id)]
+		if len(request.GetApplicationStates()) > 0 && !containsApplicationState(request.GetApplicationStates(), app.GetYarnApplicationState()) {
+			continue
+		}
+		if len(request.GetApplicationTypes()) > 0 && !containsFold(request.GetApplicationTypes(), app.GetApplicationType()) {
+			continue
+		}
+		if len(request.GetQueues()) > 0 && !containsFold(request.GetQueues(), app.GetQueue()) {
+			continue
+		}
+		if len(request.GetUsers()) > 0 && !containsFold(request.GetUsers(), app.GetUser()) {
+			continue
+		}
+		if request.Limit != nil && int64(len(reports)) >= request.GetLimit() {
+			break
+		}
+		reports = append(reports, proto.Clone(app).(*hadoopyarn.ApplicationReportProto))
+	}
+	return reports
+}
+
+func (rm *ResourceManager) registerAdminProtocol() {
+	protocol := yarnservice.RESOURCE_MANAGER_ADMIN_PROTOCOL
+	handle(rm, protocol, "updateNodeResource", false, func(ctx context.Context, request *yarnserver.UpdateNodeResourceRequestProto) (*yarnserver.UpdateNodeResourceResponseProto, error) {
+		for _, nodeResource := range request.GetNodeResourceMap() {
+			node, ok := rm.nodes[nodeKey(nodeResource.GetNodeId())]
+			if !ok {
+				return nil, hadoop_ipc_server.NewException("org.apache.hadoop.yarn.exceptions.YarnException",
+					"Resource update get failed on all nodes due to change resource on an unrecognized node: %v",
+					nodeKey(nodeResource.GetNodeId()))
+			}
+			node.Capability = proto.Clone(nodeResource.GetResourceOption().GetResource()).(*hadoopyarn.ResourceProto)
+		}
+		return &yarnserver.UpdateNodeResourceResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshQueues", false, func(ctx context.Context, request *yarnserver.RefreshQueuesRequestProto) (*yarnserver.RefreshQueuesResponseProto, error) {
+		return &yarnserver.RefreshQueuesResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshNodes", false, func(ctx context.Context, request *yarnserver.RefreshNodesRequestProto) (*yarnserver.RefreshNodesResponseProto, error) {
+		return &yarnserver.RefreshNodesResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshNodesResources", false, func(ctx context.Context, request *yarnserver.RefreshNodesResourcesRequestProto) (*yarnserver.RefreshNodesResourcesResponseProto, error) {
+		return &yarnserver.RefreshNodesResourcesResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshSuperUserGroupsConfiguration", false, func(ctx context.Context, request *yarnserver.RefreshSuperUserGroupsConfigurationRequestProto) (*yarnserver.RefreshSuperUserGroupsConfigurationResponseProto, error) {
+		return &yarnserver.RefreshSuperUserGroupsConfigurationResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshUserToGroupsMappings", false, func(ctx context.Context, request *yarnserver.RefreshUserToGroupsMappingsRequestProto) (*yarnserver.RefreshUserToGroupsMappingsResponseProto, error) {
+		return &yarnserver.RefreshUserToGroupsMappingsResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshAdminAcls", false, func(ctx context.Context, request *yarnserver.RefreshAdminAclsRequestProto) (*yarnserver.RefreshAdminAclsResponseProto, error) {
+		return &yarnserver.RefreshAdminAclsResponseProto{}, nil
+	})
+	handle(rm, protocol, "refreshServiceAcls", false, func(ctx context.Context, request *yarnserver.RefreshServiceAclsRequestProto) (*yarnserver.RefreshServiceAclsResponseProto, error) {
+		return &yarnserver.RefreshServiceAclsResponseProto{}, nil
+	})
+	handle(rm, protocol, "getGroupsForUser", false, func(ctx context.Context, request *yarnserver.GetGroupsForUserRequestProto) (*yarnserver.GetGroupsForUserResponseProto, error) {
+		// every user is in the group of its name
+		return &yarnserver.GetGroupsForUserResponseProto{Groups: []string{request.GetUser()}}, nil
+	})
+}
+
+func (rm *ResourceManager) registerHAProtocol() {
+	protocol := yarnservice.HA_SERVICE_PROTOCOL
+	handle(rm, protocol, "getServiceStatus", true, func(ctx context.Context, request *hadoopcommon.GetServiceStatusRequestProto) (*hadoopcommon.GetServiceStatusResponseProto, error) {
+		return &hadoopcommon.GetServiceStatusResponseProto{State: rm.state.Enum(), ReadyToBecomeActive: proto.Bool(true)}, nil
+	})
+	handle(rm, protocol, "monitorHealth", true, func(ctx context.Context, request *hadoopcommon.MonitorHealthRequestProto) (*hadoopcommon.MonitorHealthResponseProto, error) {
+		return &hadoopcommon.MonitorHealthResponseProto{}, nil
+	})
+	handle(rm, protocol, "transitionToActive", true, func(ctx context.Context, request *hadoopcommon.TransitionToActiveRequestProto) (*hadoopcommon.TransitionToActiveResponseProto, error) {
+		rm.state = hadoopcommon.HAServiceStateProto_ACTIVE
+		return &hadoopcommon.TransitionToActiveResponseProto{}, nil
+	})
+	handle(rm, protocol, "transitionToStandby", true, func(ctx context.Context, request *hadoopcommon.TransitionToStandbyRequestProto) (*hadoopcommon.TransitionToStandbyResponseProto, error) {
+		rm.state = hadoopcommon.HAServiceStateProto_STANDBY
+		return &hadoopcommon.TransitionToStandbyResponseProto{}, nil
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsNodeState(states []hadoopyarn.NodeStateProto, state hadoopyarn.NodeStateProto) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func containsApplicationState(states []hadoopyarn.YarnApplicationStateProto, state hadoopyarn.YarnApplicationStateProto) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakerm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	yarnserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn/server"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnclient "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	yarnconf "github.com/koordinator-sh/yarn-copilot/pkg/yarn/config"
+)
+
+func newTestResourceManager(t *testing.T) *ResourceManager {
+	rm, err := NewResourceManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rm.Close)
+	return rm
+}
+
+func newTestNode(host string, vcores int32) *hadoopyarn.NodeReportProto {
+	return &hadoopyarn.NodeReportProto{
+		NodeId:     &hadoopyarn.NodeIdProto{Host: proto.String(host), Port: proto.Int32(8041)},
+		Capability: &hadoopyarn.ResourceProto{Memory: proto.Int64(8192), VirtualCores: proto.Int32(vcores)},
+	}
+}
+
+func TestResourceManagerApplications(t *testing.T) {
+	rm := newTestResourceManager(t)
+	confDir := t.TempDir()
+	assert.NoError(t, WriteYarnSite(confDir, rm.YarnSite()))
+	conf, err := yarnconf.NewYarnConfiguration(confDir, "")
+	assert.NoError(t, err)
+	c, err := yarnclient.CreateYarnApplicationClient(conf, nil, security.NewRemoteUser("alice"))
+	assert.NoError(t, err)
+
+	newApp, err := c.GetNewApplication(&hadoopyarn.GetNewApplicationRequestProto{})
+	assert.NoError(t, err)
+	appID := newApp.GetApplicationId()
+	assert.Equal(t, int32(1), appID.GetId())
+	_, err = c.SubmitApplication(&hadoopyarn.SubmitApplicationRequestProto{ApplicationSubmissionContext: &hadoopyarn.ApplicationSubmissionContextProto{
+		ApplicationId: appID, ApplicationName: proto.String("sleep"), Queue: proto.String("default"), ApplicationType: proto.String("YARN"),
+	}})
+	assert.NoError(t, err)
+
+	report, err := c.GetApplicationReport(&hadoopyarn.GetApplicationReportRequestProto{ApplicationId: appID})
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", report.GetApplicationReport().GetUser())
+	assert.Equal(t, hadoopyarn.YarnApplicationStateProto_ACCEPTED, report.GetApplicationReport().GetYarnApplicationState())
+
+	assert.NoError(t, rm.SetApplicationState(appID, hadoopyarn.YarnApplicationStateProto_RUNNING))
+	apps, err := c.GetApplications(&hadoopyarn.GetApplicationsRequestProto{
+		ApplicationStates: []hadoopyarn.YarnApplicationStateProto{hadoopyarn.YarnApplicationStateProto_RUNNING}})
+	assert.NoError(t, err)
+	assert.Len(t, apps.GetApplications(), 1)
+
+	_, err = c.ForceKillApplication(&hadoopyarn.KillApplicationRequestProto{ApplicationId: appID})
+	assert.NoError(t, err)
+	assert.Equal(t, hadoopyarn.YarnApplicationStateProto_KILLED, rm.Applications()[0].GetYarnApplicationState())
+
+	_, err = c.GetApplicationReport(&hadoopyarn.GetApplicationReportRequestProto{ApplicationId: &hadoopyarn.ApplicationIdProto{
+		Id: proto.Int32(2), ClusterTimestamp: proto.Int64(appID.GetClusterTimestamp())}})
+	assert.True(t, hadoop_ipc_client.IsException(err, hadoop_ipc_client.ApplicationNotFoundException), "unexpected error %v", err)
+}
+
+func TestResourceManagerFailover(t *testing.T) {
+	rm1, rm2 := newTestResourceManager(t), newTestResourceManager(t)
+	rm1.SetHAState(hadoopcommon.HAServiceStateProto_STANDBY)
+	for _, rm := range []*ResourceManager{rm1, rm2} {
+		rm.AddNode(newTestNode("node-1", 8))
+		rm.AddNode(newTestNode("node-2", 8))
+	}
+	confDir := t.TempDir()
+	assert.NoError(t, WriteYarnSite(confDir, HAYarnSite(map[string]*ResourceManager{"rm1": rm1, "rm2": rm2})))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c := yarnclient.NewYarnClient(confDir, "", yarnclient.WithRetryPolicy(
+		yarnclient.NewFailoverOnNetworkExceptionPolicy(3, 2, 0, time.Millisecond, 10*time.Millisecond)))
+	defer c.Close()
+	nodes, err := c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{})
+	assert.NoError(t, err)
+	assert.Len(t, nodes.GetNodeReports(), 2)
+	assert.Equal(t, 0, rm1.CallCount("getClusterNodes"), "the client discovers the active rm by ha status")
+	assert.Equal(t, 1, rm2.CallCount("getClusterNodes"))
+
+	// rm2 fails over to rm1, the client follows after a StandbyException
+	rm2.SetHAState(hadoopcommon.HAServiceStateProto_STANDBY)
+	rm1.SetHAState(hadoopcommon.HAServiceStateProto_ACTIVE)
+	_, err = c.UpdateNodeResourceWithContext(ctx, &yarnserver.UpdateNodeResourceRequestProto{NodeResourceMap: []*hadoopyarn.NodeResourceMapProto{{
+		NodeId:         newTestNode("node-1", 0).GetNodeId(),
+		ResourceOption: &hadoopyarn.ResourceOptionProto{Resource: &hadoopyarn.ResourceProto{Memory: proto.Int64(4096), VirtualCores: proto.Int32(4)}},
+	}}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rm2.CallCount("updateNodeResource"))
+	node, ok := rm1.Node(newTestNode("node-1", 0).GetNodeId())
+	assert.True(t, ok)
+	assert.Equal(t, int32(4), node.GetCapability().GetVirtualCores())
+	node, _ = rm2.Node(newTestNode("node-1", 0).GetNodeId())
+	assert.Equal(t, int32(8), node.GetCapability().GetVirtualCores())
+
+	nodes, err = c.GetClusterNodesWithContext(ctx, &hadoopyarn.GetClusterNodesRequestProto{
+		NodeStates: []hadoopyarn.NodeStateProto{hadoopyarn.NodeStateProto_NS_DECOMMISSIONED}})
+	assert.NoError(t, err)
+	assert.Empty(t, nodes.GetNodeReports())
+}
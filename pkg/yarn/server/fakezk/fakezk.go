@@ -0,0 +1,239 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakezk
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/zk"
+)
+
+// Server is an in-process stand-in of zookeeper for tests. It keeps znodes in memory by path without the tree, and
+// serves sessions with getData, exists and their watches, other operations are rejected as unimplemented.
+type Server struct {
+	listener net.Listener
+
+	mtx           sync.Mutex
+	zxid          int64
+	lastSessionID int64
+	nodes         map[string]*znode
+	sessions      map[int64]*session
+	closed        bool
+	wg            sync.WaitGroup
+}
+
+type znode struct {
+	data []byte
+	stat zk.Stat
+}
+
+type session struct {
+	id   int64
+	conn net.Conn
+	// watches are the paths watched by the session, which fire once
+	watches map[string]bool
+}
+
+// NewServer starts a server listening on a random port of localhost
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		nodes:    map[string]*znode{},
+		sessions: map[int64]*session{},
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Address is the connect string of the server
+func (s *Server) Address() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server and closes all sessions
+func (s *Server) Close() {
+	s.mtx.Lock()
+	s.closed = true
+	s.mtx.Unlock()
+	s.listener.Close()
+	s.ExpireSessions()
+	s.wg.Wait()
+}
+
+// Set creates the node of path or updates its data, and fires the watches of path
+func (s *Server) Set(path string, data []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.zxid++
+	now := time.Now().UnixMilli()
+	eventType := zk.EventNodeDataChanged
+	node, ok := s.nodes[path]
+	if !ok {
+		eventType = zk.EventNodeCreated
+		node = &znode{stat: zk.Stat{Czxid: s.zxid, Ctime: now, Pzxid: s.zxid, Version: -1}}
+		s.nodes[path] = node
+	}
+	node.data = append([]byte(nil), data...)
+	node.stat.Mzxid, node.stat.Mtime = s.zxid, now
+	node.stat.Version++
+	node.stat.DataLength = int32(len(data))
+	s.fire(path, eventType)
+}
+
+// Delete deletes the node of path, and fires the watches of path
+func (s *Server) Delete(path string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.nodes[path]; !ok {
+		return
+	}
+	s.zxid++
+	delete(s.nodes, path)
+	s.fire(path, zk.EventNodeDeleted)
+}
+
+// ExpireSessions closes the connections of all sessions, clients see their sessions lost
+func (s *Server) ExpireSessions() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for id, sess := range s.sessions {
+		sess.conn.Close()
+		delete(s.sessions, id)
+	}
+}
+
+// SessionCount returns the number of open sessions
+func (s *Server) SessionCount() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	packet, err := zk.ReadPacket(conn)
+	if err != nil {
+		return
+	}
+	request := &zk.ConnectRequest{}
+	request.Decode(zk.NewDecoder(packet))
+
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return
+	}
+	s.lastSessionID++
+	sess := &session{id: s.lastSessionID, conn: conn, watches: map[string]bool{}}
+	s.sessions[sess.id] = sess
+	err = zk.WritePacket(conn, &zk.ConnectResponse{TimeOut: request.TimeOut, SessionID: sess.id, Passwd: make([]byte, 16)})
+	s.mtx.Unlock()
+	if err != nil {
+		return
+	}
+	defer func() {
+		s.mtx.Lock()
+		delete(s.sessions, sess.id)
+		s.mtx.Unlock()
+	}()
+
+	for {
+		packet, err := zk.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if !s.handle(sess, zk.NewDecoder(packet)) {
+			return
+		}
+	}
+}
+
+// handle replies a request of sess, and returns false once the session is closed. The reply is written with the lock
+// held, so that replies and events are ordered as the changes.
+func (s *Server) handle(sess *session, d *zk.Decoder) bool {
+	header := &zk.RequestHeader{}
+	header.Decode(d)
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	reply := &zk.ReplyHeader{Xid: header.Xid, Zxid: s.zxid}
+	var response zk.Record
+	switch header.Type {
+	case zk.OpPing:
+		reply.Xid = zk.XidPing
+	case zk.OpCloseSession:
+		_ = zk.WritePacket(sess.conn, reply)
+		return false
+	case zk.OpGetData, zk.OpExists:
+		request := &zk.PathWatchRequest{}
+		request.Decode(d)
+		node, ok := s.nodes[request.Path]
+		if request.Watch && (ok || header.Type == zk.OpExists) {
+			sess.watches[request.Path] = true
+		}
+		if !ok {
+			reply.Err = zk.ErrCodeNoNode
+		} else if header.Type == zk.OpGetData {
+			response = &zk.GetDataResponse{Data: node.data, Stat: node.stat}
+		} else {
+			response = &zk.ExistsResponse{Stat: node.stat}
+		}
+	default:
+		klog.V(4).Infof("fake zookeeper rejects unimplemented op %v", header.Type)
+		reply.Err = zk.ErrCodeUnimplemented
+	}
+	records := []zk.Record{reply}
+	if response != nil {
+		records = append(records, response)
+	}
+	return zk.WritePacket(sess.conn, records...) == nil
+}
+
+// fire sends the event of path to the sessions watching it, the lock is held by callers
+func (s *Server) fire(path string, eventType zk.EventType) {
+	for _, sess := range s.sessions {
+		if !sess.watches[path] {
+			continue
+		}
+		delete(sess.watches, path)
+		event := &zk.WatcherEvent{Type: int32(eventType), State: zk.StateSyncConnected, Path: path}
+		_ = zk.WritePacket(sess.conn, &zk.ReplyHeader{Xid: zk.XidWatcherEvent, Zxid: s.zxid, Err: zk.ErrCodeOK}, event)
+	}
+}
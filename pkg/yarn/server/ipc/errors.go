@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"errors"
+	"fmt"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+// Exception is an error responded to clients as a java exception of ClassName, which is recognized by the exception
+// checks of pkg/yarn/client/ipc, e.g. IsStandby. Other errors of handlers are responded as java.io.IOException.
+type Exception struct {
+	ClassName string
+	Message   string
+	// ErrorCode is ERROR_APPLICATION if not set
+	ErrorCode hadoop_common.RpcResponseHeaderProto_RpcErrorCodeProto
+}
+
+var _ error = &Exception{}
+
+// NewException creates an exception of className with the formatted message
+func NewException(className string, format string, args ...interface{}) *Exception {
+	return &Exception{ClassName: className, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Exception) Error() string {
+	return fmt.Sprintf("%v: %v", e.ClassName, e.Message)
+}
+
+func (e *Exception) errorCode() hadoop_common.RpcResponseHeaderProto_RpcErrorCodeProto {
+	if e.ErrorCode == 0 {
+		return hadoop_common.RpcResponseHeaderProto_ERROR_APPLICATION
+	}
+	return e.ErrorCode
+}
+
+func toException(err error) *Exception {
+	var exception *Exception
+	if errors.As(err, &exception) {
+		return exception
+	}
+	return &Exception{ClassName: hadoop_ipc_client.IOException, Message: err.Error()}
+}
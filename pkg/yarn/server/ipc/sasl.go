@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+const (
+	saslMethodSimple       = "SIMPLE"
+	saslMethodToken        = "TOKEN"
+	saslMechanismDigestMD5 = "DIGEST-MD5"
+
+	// saslDefaultRealm is the server id and realm of DIGEST-MD5, whose protocol is empty, as SaslRpcServer in hadoop
+	saslDefaultRealm = "default"
+)
+
+// SecretManager verifies the tokens of clients authenticating with TOKEN auth, like SecretManager in hadoop
+type SecretManager interface {
+	// RetrievePassword returns the password of token identifier and the user the token is issued to, errors are
+	// responded to clients, e.g. an Exception of InvalidTokenException if the token is unknown or expired
+	RetrievePassword(identifier []byte) (password []byte, user string, err error)
+}
+
+// SecretManagerFunc is a SecretManager of a function
+type SecretManagerFunc func(identifier []byte) ([]byte, string, error)
+
+func (f SecretManagerFunc) RetrievePassword(identifier []byte) ([]byte, string, error) {
+	return f(identifier)
+}
+
+// enabledAuthMethods returns the auth methods offered to clients in the preferred order
+func (s *Server) enabledAuthMethods() []string {
+	var methods []string
+	if s.secretManager != nil {
+		methods = append(methods, saslMethodToken)
+	}
+	if s.simpleAuth {
+		methods = append(methods, saslMethodSimple)
+	}
+	return methods
+}
+
+// handleSasl processes a SASL message of the negotiation, which ends with SUCCESS once the client is authenticated.
+// The negotiation is skipped by SUCCESS to NEGOTIATE if only simple auth is enabled, like Server in hadoop does when
+// security is disabled.
+func (c *serverConn) handleSasl(packet []byte) error {
+	message := &hadoop_common.RpcSaslProto{}
+	if _, err := readDelimited(packet, message); err != nil {
+		return err
+	}
+	if c.saslComplete {
+		return fmt.Errorf("unexpected SASL %v message after negotiation", message.GetState())
+	}
+
+	switch message.GetState() {
+	case hadoop_common.RpcSaslProto_NEGOTIATE:
+		if c.server.secretManager == nil {
+			c.authMethod = yarnauth.AUTH_SIMPLE
+			c.saslComplete = true
+			return c.writeSaslResponse(&hadoop_common.RpcSaslProto{State: hadoop_common.RpcSaslProto_SUCCESS.Enum()})
+		}
+		return c.writeSaslResponse(c.negotiateResponse())
+	case hadoop_common.RpcSaslProto_INITIATE:
+		if len(message.GetAuths()) != 1 {
+			return errors.New("SASL INITIATE must have exactly one auth")
+		}
+		auth := message.GetAuths()[0]
+		switch {
+		case auth.GetMethod() == saslMethodSimple && c.server.simpleAuth:
+			c.authMethod = yarnauth.AUTH_SIMPLE
+			c.saslComplete = true
+			return c.writeSaslResponse(&hadoop_common.RpcSaslProto{State: hadoop_common.RpcSaslProto_SUCCESS.Enum()})
+		case auth.GetMethod() == saslMethodToken && auth.GetMechanism() == saslMechanismDigestMD5 && c.digest != nil:
+			return c.evaluateDigestResponse(message.GetToken())
+		}
+		return &Exception{ClassName: hadoop_ipc_client.AccessControlException,
+			Message: fmt.Sprintf("Client cannot authenticate via:[%v/%v]; Available:%v", auth.GetMethod(), auth.GetMechanism(), c.server.enabledAuthMethods())}
+	case hadoop_common.RpcSaslProto_RESPONSE:
+		if c.digest == nil {
+			return errors.New("SASL RESPONSE before INITIATE")
+		}
+		return c.evaluateDigestResponse(message.GetToken())
+	}
+	return fmt.Errorf("unexpected SASL %v message", message.GetState())
+}
+
+// negotiateResponse offers the enabled auths, the DIGEST-MD5 challenge is sent with the auth to save a round trip
+func (c *serverConn) negotiateResponse() *hadoop_common.RpcSaslProto {
+	response := &hadoop_common.RpcSaslProto{State: hadoop_common.RpcSaslProto_NEGOTIATE.Enum()}
+	for _, method := range c.server.enabledAuthMethods() {
+		switch method {
+		case saslMethodToken:
+			c.digest = security.NewDigestMD5Server("", saslDefaultRealm, c.server.protection, c.retrievePassword)
+			challenge, err := c.digest.Challenge()
+			if err != nil {
+				klog.Warningf("failed to generate DIGEST-MD5 challenge for %v, error %v", c.conn.RemoteAddr(), err)
+				c.digest = nil
+				continue
+			}
+			response.Auths = append(response.Auths, &hadoop_common.RpcSaslProto_SaslAuth{
+				Method:    proto.String(saslMethodToken),
+				Mechanism: proto.String(saslMechanismDigestMD5),
+				Protocol:  proto.String(""),
+				ServerId:  proto.String(saslDefaultRealm),
+				Challenge: challenge,
+			})
+		case saslMethodSimple:
+			response.Auths = append(response.Auths, &hadoop_common.RpcSaslProto_SaslAuth{
+				Method:    proto.String(saslMethodSimple),
+				Mechanism: proto.String(""),
+			})
+		}
+	}
+	return response
+}
+
+func (c *serverConn) retrievePassword(identifier []byte) ([]byte, error) {
+	password, user, err := c.server.secretManager.RetrievePassword(identifier)
+	if err != nil {
+		return nil, err
+	}
+	c.tokenUser = user
+	return password, nil
+}
+
+// evaluateDigestResponse verifies the token of client, and switches to the security layer negotiated after SUCCESS
+func (c *serverConn) evaluateDigestResponse(response []byte) error {
+	responseAuth, err := c.digest.EvaluateResponse(response)
+	if err != nil {
+		var exception *Exception
+		if errors.As(err, &exception) {
+			return err
+		}
+		return &Exception{ClassName: hadoop_ipc_client.AccessControlException, Message: err.Error()}
+	}
+	if err := c.writeSaslResponse(&hadoop_common.RpcSaslProto{State: hadoop_common.RpcSaslProto_SUCCESS.Enum(), Token: responseAuth}); err != nil {
+		return err
+	}
+	c.authMethod = yarnauth.AUTH_TOKEN
+	c.saslComplete = true
+	if c.digest.QOP() != security.QOPAuth {
+		// packets after SUCCESS are wrapped, and the client waits for SUCCESS before sending them
+		c.writeMtx.Lock()
+		c.sasl = c.digest
+		c.writeMtx.Unlock()
+		c.reader = bufio.NewReader(&saslReader{con: c, raw: c.reader})
+	}
+	klog.V(4).Infof("authenticated %v from %v with token, qop %v", c.tokenUser, c.conn.RemoteAddr(), c.digest.QOP())
+	return nil
+}
+
+func (c *serverConn) writeSaslResponse(message *hadoop_common.RpcSaslProto) error {
+	header := &hadoop_common.RpcResponseHeaderProto{
+		CallId: proto.Uint32(uint32(saslCallID)),
+		Status: hadoop_common.RpcResponseHeaderProto_SUCCESS.Enum(),
+	}
+	return c.write(header, message)
+}
+
+// checkUser makes sure the user claimed by the connection context is the one authenticated, the user of tokens
+// is the user of connection if the context has no user
+func (c *serverConn) checkUser() error {
+	if c.authMethod != yarnauth.AUTH_TOKEN {
+		return nil
+	}
+	if c.user != "" && c.user != c.tokenUser {
+		return &Exception{ClassName: hadoop_ipc_client.AccessControlException,
+			Message: fmt.Sprintf("Authenticated user (%v) doesn't match what the client claims to be (%v)", c.tokenUser, c.user)}
+	}
+	c.user, c.realUser = c.tokenUser, ""
+	return nil
+}
+
+// saslReader unwraps the SASL WRAP messages from client into the stream of rpc requests
+type saslReader struct {
+	con *serverConn
+	raw *bufio.Reader
+	buf []byte
+}
+
+func (r *saslReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		token, err := r.readWrapToken()
+		if err != nil {
+			return 0, err
+		}
+		if r.buf, err = r.con.sasl.Unwrap(token); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *saslReader) readWrapToken() ([]byte, error) {
+	packet, err := readPacket(r.raw)
+	if err != nil {
+		return nil, err
+	}
+	header := &hadoop_common.RpcRequestHeaderProto{}
+	off, err := readDelimited(packet, header)
+	if err != nil {
+		return nil, err
+	}
+	if header.GetCallId() != saslCallID {
+		return nil, fmt.Errorf("expected SASL WRAP message, got call %v", header.GetCallId())
+	}
+	message := &hadoop_common.RpcSaslProto{}
+	if _, err := readDelimited(packet[off:], message); err != nil {
+		return nil, err
+	}
+	if message.GetState() != hadoop_common.RpcSaslProto_WRAP {
+		return nil, fmt.Errorf("expected SASL WRAP message, got %v", message.GetState())
+	}
+	return message.GetToken(), nil
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	gouuid "github.com/nu7hatch/gouuid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	hadoop_ipc_server "github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/ipc"
+)
+
+// testSecretManager knows the tokens of alice, the password of a token is its identifier reversed
+var testSecretManager = hadoop_ipc_server.SecretManagerFunc(func(identifier []byte) ([]byte, string, error) {
+	if !strings.HasPrefix(string(identifier), "alice") {
+		return nil, "", hadoop_ipc_server.NewException(hadoop_ipc_client.InvalidTokenException, "token %v can't be found in cache", string(identifier))
+	}
+	return reverse(identifier), "alice", nil
+})
+
+func reverse(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
+
+func newTestTokenUser(t *testing.T, user string, address string, identifier string, password []byte) *security.UserGroupInformation {
+	ugi := security.NewRemoteUser(user)
+	ugi.AddUserToken(&hadoop_common.TokenProto{
+		Kind:       proto.String(security.TokenKindRMDelegation),
+		Service:    proto.String(address),
+		Identifier: []byte(identifier),
+		Password:   password,
+	})
+	return ugi
+}
+
+// newSaslTestServer serves getClusterNodes with a response of nodes, which is wrapped in several messages if large
+func newSaslTestServer(t *testing.T, nodes int, opts ...hadoop_ipc_server.ServerOption) (string, chan *hadoop_ipc_server.Call) {
+	s := hadoop_ipc_server.NewServer(opts...)
+	calls := make(chan *hadoop_ipc_server.Call, 10)
+	hadoop_ipc_server.Register(s, yarnservice.APPLICATION_CLIENT_PROTOCOL, "getClusterNodes", func(ctx context.Context, request *hadoopyarn.GetClusterNodesRequestProto) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+		call, _ := hadoop_ipc_server.CallFromContext(ctx)
+		calls <- call
+		response := &hadoopyarn.GetClusterNodesResponseProto{}
+		for i := 0; i < nodes; i++ {
+			response.NodeReports = append(response.NodeReports, &hadoopyarn.NodeReportProto{
+				NodeId: &hadoopyarn.NodeIdProto{Host: proto.String(fmt.Sprintf("node-%v.%v", i, strings.Repeat("x", 100))), Port: proto.Int32(8041)}})
+		}
+		return response, nil
+	})
+	listener, err := s.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return listener.Addr().String(), calls
+}
+
+func getClusterNodes(t *testing.T, address string, ugi *security.UserGroupInformation, protection []security.QOP) (*hadoopyarn.GetClusterNodesResponseProto, error) {
+	clientID, err := gouuid.NewV4()
+	assert.NoError(t, err)
+	c := &hadoop_ipc_client.Client{ClientId: clientID, Ugi: ugi, ServerAddress: address, Protection: protection}
+	response := &hadoopyarn.GetClusterNodesResponseProto{}
+	err = c.CallWithContext(context.Background(), yarnauth.NewRPCRequestHeaderProto("getClusterNodes", &yarnservice.APPLICATION_CLIENT_PROTOCOL),
+		&hadoopyarn.GetClusterNodesRequestProto{}, response)
+	return response, err
+}
+
+func TestServerTokenAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverQOPs []security.QOP
+		clientQOPs []security.QOP
+		nodes      int
+	}{
+		{
+			name:  "authentication",
+			nodes: 1,
+		},
+		{
+			name:       "integrity",
+			serverQOPs: []security.QOP{security.QOPAuthInt, security.QOPAuth},
+			clientQOPs: []security.QOP{security.QOPAuthInt},
+			nodes:      2000,
+		},
+		{
+			name:       "privacy",
+			serverQOPs: []security.QOP{security.QOPAuthConf},
+			clientQOPs: []security.QOP{security.QOPAuthInt, security.QOPAuthConf},
+			nodes:      2000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, calls := newSaslTestServer(t, tt.nodes, hadoop_ipc_server.WithSecretManager(testSecretManager), hadoop_ipc_server.WithProtection(tt.serverQOPs...))
+			// the user of connection is the one of token
+			ugi := newTestTokenUser(t, "client", address, "alice-1", reverse([]byte("alice-1")))
+			for i := 0; i < 2; i++ {
+				response, err := getClusterNodes(t, address, ugi, tt.clientQOPs)
+				assert.NoError(t, err)
+				assert.Len(t, response.GetNodeReports(), tt.nodes)
+				call := <-calls
+				assert.Equal(t, "alice", call.User)
+				assert.Equal(t, yarnauth.AUTH_TOKEN, call.AuthMethod)
+			}
+		})
+	}
+}
+
+func TestServerAuthFailures(t *testing.T) {
+	address, _ := newSaslTestServer(t, 1, hadoop_ipc_server.WithSecretManager(testSecretManager), hadoop_ipc_server.WithoutSimpleAuth())
+
+	_, err := getClusterNodes(t, address, newTestTokenUser(t, "alice", address, "alice-1", []byte("guess")), nil)
+	assert.True(t, hadoop_ipc_client.IsException(err, hadoop_ipc_client.AccessControlException), "unexpected error %v", err)
+	assert.ErrorContains(t, err, "digest response does not match")
+
+	_, err = getClusterNodes(t, address, newTestTokenUser(t, "bob", address, "bob-1", reverse([]byte("bob-1"))), nil)
+	assert.True(t, hadoop_ipc_client.IsException(err, hadoop_ipc_client.InvalidTokenException), "unexpected error %v", err)
+
+	_, err = getClusterNodes(t, address, security.NewRemoteUser("alice"), nil)
+	assert.ErrorContains(t, err, "SIMPLE authentication is not enabled")
+}
+
+func TestServerSimpleAuth(t *testing.T) {
+	address, calls := newSaslTestServer(t, 1, hadoop_ipc_server.WithSecretManager(testSecretManager))
+	_, err := getClusterNodes(t, address, security.NewRemoteUser("bob"), nil)
+	assert.NoError(t, err)
+	call := <-calls
+	assert.Equal(t, "bob", call.User)
+	assert.Equal(t, yarnauth.AUTH_SIMPLE, call.AuthMethod)
+
+	// tokens are ignored by servers without a secret manager
+	address, calls = newSaslTestServer(t, 1)
+	_, err = getClusterNodes(t, address, newTestTokenUser(t, "bob", address, "bob-1", []byte("password")), nil)
+	assert.NoError(t, err)
+	call = <-calls
+	assert.Equal(t, "bob", call.User)
+	assert.Equal(t, yarnauth.AUTH_SIMPLE, call.AuthMethod)
+}
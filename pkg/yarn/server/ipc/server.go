@@ -0,0 +1,559 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+
+	yarnauth "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/auth"
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+)
+
+// maxDataLength is the same as the default of ipc.maximum.data.length in hadoop
+const maxDataLength = 64 << 20
+
+// the call ids of requests other than calls
+var (
+	connectionContextCallID int32 = -3
+	pingCallID              int32 = -4
+	saslCallID              int32 = -33
+)
+
+// MethodHandler handles a call of a method, request is created by the newRequest of the method and filled with the
+// request of the call. Errors are responded as exceptions, see Exception.
+type MethodHandler func(ctx context.Context, request proto.Message) (proto.Message, error)
+
+type methodKey struct {
+	protocol string
+	method   string
+}
+
+type method struct {
+	newRequest func() proto.Message
+	handler    MethodHandler
+}
+
+// Call is the call being handled, which is in the context of MethodHandler
+type Call struct {
+	Protocol   string
+	Method     string
+	CallID     int32
+	ClientID   []byte
+	RetryCount int32
+	// User is the effective user of the connection, and RealUser is the user authenticated if User is proxied
+	User       string
+	RealUser   string
+	AuthMethod yarnauth.AuthMethod
+	RemoteAddr net.Addr
+}
+
+type callContextKey struct{}
+
+// CallFromContext returns the call handled with ctx
+func CallFromContext(ctx context.Context) (*Call, bool) {
+	call, ok := ctx.Value(callContextKey{}).(*Call)
+	return call, ok
+}
+
+// Server serves hadoop rpc calls of protobuf engine, it is the server side of the client in pkg/yarn/client/ipc.
+// Calls are dispatched by the protocol and method names in RequestHeaderProto to the registered handlers, and calls
+// of a connection are handled concurrently like the handlers of Server in hadoop. Clients are authenticated by
+// simple auth, and by tokens with SASL DIGEST-MD5 if a SecretManager is set.
+type Server struct {
+	secretManager SecretManager
+	simpleAuth    bool
+	protection    []security.QOP
+
+	mtx       sync.RWMutex
+	methods   map[methodKey]*method
+	listeners map[net.Listener]struct{}
+	conns     map[*serverConn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+type ServerOption func(s *Server)
+
+// WithSecretManager enables TOKEN auth, the tokens of clients are verified by secretManager
+func WithSecretManager(secretManager SecretManager) ServerOption {
+	return func(s *Server) {
+		s.secretManager = secretManager
+	}
+}
+
+// WithoutSimpleAuth rejects the clients not authenticated by tokens, like a secure cluster does
+func WithoutSimpleAuth() ServerOption {
+	return func(s *Server) {
+		s.simpleAuth = false
+	}
+}
+
+// WithProtection sets the qops accepted for TOKEN auth like hadoop.rpc.protection, only auth is accepted by default
+func WithProtection(qops ...security.QOP) ServerOption {
+	return func(s *Server) {
+		s.protection = qops
+	}
+}
+
+func NewServer(opts ...ServerOption) *Server {
+	s := &Server{
+		simpleAuth: true,
+		methods:    map[methodKey]*method{},
+		listeners:  map[net.Listener]struct{}{},
+		conns:      map[*serverConn]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// RegisterMethod makes the server handle calls of method of protocol with handler, protocol is the
+// DeclaringClassProtocolName of requests, e.g. org.apache.hadoop.yarn.api.ApplicationClientProtocolPB
+func (s *Server) RegisterMethod(protocol string, methodName string, newRequest func() proto.Message, handler MethodHandler) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.methods[methodKey{protocol: protocol, method: methodName}] = &method{newRequest: newRequest, handler: handler}
+}
+
+// Register registers the typed handle for method of protocol
+func Register[Request, Response proto.Message](s *Server, protocol string, methodName string, handle func(ctx context.Context, request Request) (Response, error)) {
+	s.RegisterMethod(protocol, methodName, func() proto.Message {
+		var request Request
+		return request.ProtoReflect().New().Interface()
+	}, func(ctx context.Context, request proto.Message) (proto.Message, error) {
+		return handle(ctx, request.(Request))
+	})
+}
+
+func (s *Server) lookup(protocol string, methodName string) (*method, *Exception) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if m, ok := s.methods[methodKey{protocol: protocol, method: methodName}]; ok {
+		return m, nil
+	}
+	for key := range s.methods {
+		if key.protocol == protocol {
+			return nil, &Exception{ClassName: hadoop_ipc_client.RpcNoSuchMethodException, ErrorCode: hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_METHOD,
+				Message: fmt.Sprintf("Unknown method %v called on %v protocol.", methodName, protocol)}
+		}
+	}
+	return nil, &Exception{ClassName: hadoop_ipc_client.RpcNoSuchProtocolException, ErrorCode: hadoop_common.RpcResponseHeaderProto_ERROR_NO_SUCH_PROTOCOL,
+		Message: fmt.Sprintf("Unknown protocol: %v", protocol)}
+}
+
+// Listen listens on address and serves connections in the background until the server is closed, address may
+// use port 0 and the address listened is returned by Addr of the listener
+func (s *Server) Listen(address string) (net.Listener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if err := s.Serve(listener); err != nil {
+			klog.V(4).Infof("ipc server on %v stopped, error %v", listener.Addr(), err)
+		}
+	}()
+	return listener, nil
+}
+
+// Serve accepts connections on listener until the server is closed
+func (s *Server) Serve(listener net.Listener) error {
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		listener.Close()
+		return errors.New("ipc server is closed")
+	}
+	s.listeners[listener] = struct{}{}
+	s.mtx.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mtx.Lock()
+			closed := s.closed
+			delete(s.listeners, listener)
+			s.mtx.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		c := newServerConn(s, conn)
+		s.mtx.Lock()
+		if s.closed {
+			s.mtx.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[c] = struct{}{}
+		s.wg.Add(1)
+		s.mtx.Unlock()
+		go func() {
+			defer s.wg.Done()
+			c.serve()
+			s.mtx.Lock()
+			delete(s.conns, c)
+			s.mtx.Unlock()
+		}()
+	}
+}
+
+// Close stops listening, closes all connections and waits for the calls being handled
+func (s *Server) Close() {
+	s.mtx.Lock()
+	s.closed = true
+	for listener := range s.listeners {
+		listener.Close()
+	}
+	for c := range s.conns {
+		c.conn.Close()
+	}
+	s.mtx.Unlock()
+	s.wg.Wait()
+}
+
+// serverConn is a connection from a client, calls are read by serve and handled in their own goroutines
+type serverConn struct {
+	server *Server
+	conn   net.Conn
+	reader *bufio.Reader
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	writeMtx sync.Mutex
+	calls    sync.WaitGroup
+
+	// set by the connection header and the SASL negotiation
+	authProtocol yarnauth.AuthProtocol
+	authMethod   yarnauth.AuthMethod
+	saslComplete bool
+	digest       *security.DigestMD5Server
+	tokenUser    string
+	// sasl wraps all packets after the SASL negotiation if integrity or privacy is negotiated, protected by writeMtx
+	sasl security.SaslWrapper
+
+	// set by the connection context
+	protocol string
+	user     string
+	realUser string
+}
+
+func newServerConn(s *Server, conn net.Conn) *serverConn {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &serverConn{server: s, conn: conn, reader: bufio.NewReader(conn), ctx: ctx, cancel: cancel}
+}
+
+func (c *serverConn) serve() {
+	defer func() {
+		c.cancel()
+		c.calls.Wait()
+		c.conn.Close()
+	}()
+	if err := c.readConnectionHeader(); err != nil {
+		klog.V(4).Infof("read connection header from %v failed %v", c.conn.RemoteAddr(), err)
+		return
+	}
+
+	contextRead := false
+	for {
+		packet, err := c.readPacket()
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				klog.V(4).Infof("read request from %v failed %v", c.conn.RemoteAddr(), err)
+			}
+			return
+		}
+		header := &hadoop_common.RpcRequestHeaderProto{}
+		off, err := readDelimited(packet, header)
+		if err != nil {
+			c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_INVALID_RPC_HEADER, err)
+			return
+		}
+		packet = packet[off:]
+
+		switch callID := header.GetCallId(); {
+		case callID == saslCallID && !contextRead && c.authProtocol == yarnauth.AUTH_PROTOCOL_SASL:
+			if err := c.handleSasl(packet); err != nil {
+				c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED, err)
+				return
+			}
+		case callID == connectionContextCallID && !contextRead:
+			if !c.saslComplete {
+				c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED,
+					errors.New("connection context before the SASL negotiation is completed"))
+				return
+			}
+			if err := c.readConnectionContext(packet); err != nil {
+				c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_DESERIALIZING_REQUEST, err)
+				return
+			}
+			if err := c.checkUser(); err != nil {
+				c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED, err)
+				return
+			}
+			contextRead = true
+		case callID == pingCallID:
+		case callID >= 0 && contextRead:
+			if err := c.readCall(header, packet); err != nil {
+				c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_DESERIALIZING_REQUEST, err)
+				return
+			}
+		default:
+			c.writeFatal(header, hadoop_common.RpcResponseHeaderProto_FATAL_INVALID_RPC_HEADER,
+				fmt.Errorf("unexpected call id %v", callID))
+			return
+		}
+	}
+}
+
+// readConnectionHeader reads "hrpc", the version, the service class and the auth protocol
+func (c *serverConn) readConnectionHeader() error {
+	header := make([]byte, 7)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return err
+	}
+	if !bytes.Equal(header[:4], yarnauth.RPC_HEADER) {
+		return fmt.Errorf("invalid connection header %q", header[:4])
+	}
+	if header[4] != yarnauth.VERSION[0] {
+		err := fmt.Errorf("server ipc version %v is incompatible with client version %v", yarnauth.VERSION[0], header[4])
+		c.writeFatal(&hadoop_common.RpcRequestHeaderProto{}, hadoop_common.RpcResponseHeaderProto_FATAL_VERSION_MISMATCH, err)
+		return err
+	}
+	switch c.authProtocol = yarnauth.AuthProtocol(header[6]); c.authProtocol {
+	case yarnauth.AUTH_PROTOCOL_NONE:
+		if !c.server.simpleAuth {
+			err := &Exception{ClassName: hadoop_ipc_client.AccessControlException,
+				Message: fmt.Sprintf("SIMPLE authentication is not enabled.  Available:%v", c.server.enabledAuthMethods())}
+			c.writeFatal(&hadoop_common.RpcRequestHeaderProto{}, hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED, err)
+			return err
+		}
+		c.authMethod = yarnauth.AUTH_SIMPLE
+		c.saslComplete = true
+		return nil
+	case yarnauth.AUTH_PROTOCOL_SASL:
+		return nil
+	}
+	return fmt.Errorf("unknown auth protocol %v", header[6])
+}
+
+func (c *serverConn) readConnectionContext(packet []byte) error {
+	connectionContext := &hadoop_common.IpcConnectionContextProto{}
+	if _, err := readDelimited(packet, connectionContext); err != nil {
+		return err
+	}
+	c.protocol = connectionContext.GetProtocol()
+	c.user = connectionContext.GetUserInfo().GetEffectiveUser()
+	c.realUser = connectionContext.GetUserInfo().GetRealUser()
+	return nil
+}
+
+func (c *serverConn) readCall(header *hadoop_common.RpcRequestHeaderProto, packet []byte) error {
+	requestHeader := &hadoop_common.RequestHeaderProto{}
+	off, err := readDelimited(packet, requestHeader)
+	if err != nil {
+		return err
+	}
+	packet = packet[off:]
+	call := &Call{
+		Protocol:   requestHeader.GetDeclaringClassProtocolName(),
+		Method:     requestHeader.GetMethodName(),
+		CallID:     header.GetCallId(),
+		ClientID:   header.GetClientId(),
+		RetryCount: header.GetRetryCount(),
+		User:       c.user,
+		RealUser:   c.realUser,
+		AuthMethod: c.authMethod,
+		RemoteAddr: c.conn.RemoteAddr(),
+	}
+
+	m, exception := c.server.lookup(call.Protocol, call.Method)
+	if exception != nil {
+		return c.writeResponse(header, nil, exception)
+	}
+	request := m.newRequest()
+	if _, err := readDelimited(packet, request); err != nil {
+		return err
+	}
+
+	c.calls.Add(1)
+	go func() {
+		defer c.calls.Done()
+		ctx := context.WithValue(c.ctx, callContextKey{}, call)
+		response, err := m.handler(ctx, request)
+		if err := c.writeResponse(header, response, err); err != nil {
+			klog.V(4).Infof("write response of %v.%v to %v failed %v", call.Protocol, call.Method, call.RemoteAddr, err)
+			c.conn.Close()
+		}
+	}()
+	return nil
+}
+
+// writeResponse responds response of the call, or the exception of err if it is not nil
+func (c *serverConn) writeResponse(header *hadoop_common.RpcRequestHeaderProto, response proto.Message, err error) error {
+	responseHeader := &hadoop_common.RpcResponseHeaderProto{
+		CallId:              proto.Uint32(uint32(header.GetCallId())),
+		Status:              hadoop_common.RpcResponseHeaderProto_SUCCESS.Enum(),
+		ServerIpcVersionNum: proto.Uint32(uint32(yarnauth.VERSION[0])),
+		ClientId:            header.GetClientId(),
+		RetryCount:          header.RetryCount,
+	}
+	if err != nil {
+		exception := toException(err)
+		responseHeader.Status = hadoop_common.RpcResponseHeaderProto_ERROR.Enum()
+		responseHeader.ExceptionClassName = proto.String(exception.ClassName)
+		responseHeader.ErrorMsg = proto.String(exception.Message)
+		responseHeader.ErrorDetail = exception.errorCode().Enum()
+		response = nil
+	}
+	return c.write(responseHeader, response)
+}
+
+// writeFatal responds a fatal error, the connection is closed by the caller afterwards
+func (c *serverConn) writeFatal(header *hadoop_common.RpcRequestHeaderProto, errorCode hadoop_common.RpcResponseHeaderProto_RpcErrorCodeProto, err error) {
+	className := hadoop_ipc_client.IOException
+	if errorCode == hadoop_common.RpcResponseHeaderProto_FATAL_UNAUTHORIZED {
+		className = hadoop_ipc_client.AccessControlException
+	}
+	var exception *Exception
+	if errors.As(err, &exception) {
+		className, err = exception.ClassName, errors.New(exception.Message)
+	}
+	responseHeader := &hadoop_common.RpcResponseHeaderProto{
+		CallId:              proto.Uint32(uint32(header.GetCallId())),
+		Status:              hadoop_common.RpcResponseHeaderProto_FATAL.Enum(),
+		ServerIpcVersionNum: proto.Uint32(uint32(yarnauth.VERSION[0])),
+		ClientId:            header.GetClientId(),
+		ExceptionClassName:  proto.String(className),
+		ErrorMsg:            proto.String(err.Error()),
+		ErrorDetail:         errorCode.Enum(),
+	}
+	if err := c.write(responseHeader, nil); err != nil {
+		klog.V(4).Infof("write fatal error to %v failed %v", c.conn.RemoteAddr(), err)
+	}
+}
+
+// write sends the length-prefixed packet of the delimited header and message, the packet is wrapped into SASL WRAP
+// messages if a security layer is negotiated
+func (c *serverConn) write(header *hadoop_common.RpcResponseHeaderProto, message proto.Message) error {
+	var body []byte
+	for _, m := range []proto.Message{header, message} {
+		if m == nil {
+			continue
+		}
+		b, err := proto.Marshal(m)
+		if err != nil {
+			return err
+		}
+		body = protowire.AppendBytes(body, b)
+	}
+	packet, err := yarnauth.ConvertFixedToBytes(int32(len(body)))
+	if err != nil {
+		return err
+	}
+	packet = append(packet, body...)
+
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+	if c.sasl == nil {
+		_, err = c.conn.Write(packet)
+		return err
+	}
+	for len(packet) > 0 {
+		chunk := packet
+		if maxSize := c.sasl.MaxWrapSize(); maxSize > 0 && len(chunk) > maxSize {
+			chunk = chunk[:maxSize]
+		}
+		packet = packet[len(chunk):]
+		token, err := c.sasl.Wrap(chunk)
+		if err != nil {
+			return err
+		}
+		wrapHeader, err := proto.Marshal(&hadoop_common.RpcResponseHeaderProto{
+			CallId: proto.Uint32(uint32(saslCallID)),
+			Status: hadoop_common.RpcResponseHeaderProto_SUCCESS.Enum(),
+		})
+		if err != nil {
+			return err
+		}
+		wrapMessage, err := proto.Marshal(&hadoop_common.RpcSaslProto{State: hadoop_common.RpcSaslProto_WRAP.Enum(), Token: token})
+		if err != nil {
+			return err
+		}
+		body := protowire.AppendBytes(protowire.AppendBytes(nil, wrapHeader), wrapMessage)
+		wrapped, err := yarnauth.ConvertFixedToBytes(int32(len(body)))
+		if err != nil {
+			return err
+		}
+		if _, err := c.conn.Write(append(wrapped, body...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *serverConn) readPacket() ([]byte, error) {
+	return readPacket(c.reader)
+}
+
+// readPacket reads a length-prefixed packet of requests
+func readPacket(reader *bufio.Reader) ([]byte, error) {
+	var length int32
+	lengthBytes := make([]byte, 4)
+	if _, err := io.ReadFull(reader, lengthBytes); err != nil {
+		return nil, err
+	}
+	if err := yarnauth.ConvertBytesToFixed(lengthBytes, &length); err != nil {
+		return nil, err
+	}
+	if length < 0 || length > maxDataLength {
+		return nil, fmt.Errorf("invalid request length %v", length)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(reader, packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+func readDelimited(b []byte, message proto.Message) (int, error) {
+	data, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	if err := proto.Unmarshal(data, message); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The Koordinator Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ipc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	hadoop_common "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopcommon"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/proto/hadoopyarn"
+	"github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/security"
+	yarnservice "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service"
+	yarnserviceserver "github.com/koordinator-sh/yarn-copilot/pkg/yarn/apis/service/server"
+	hadoop_ipc_client "github.com/koordinator-sh/yarn-copilot/pkg/yarn/client/ipc"
+	hadoop_ipc_server "github.com/koordinator-sh/yarn-copilot/pkg/yarn/server/ipc"
+)
+
+func newTestServer(t *testing.T) (*hadoop_ipc_server.Server, string) {
+	s := hadoop_ipc_server.NewServer()
+	listener, err := s.Listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s, listener.Addr().String()
+}
+
+func TestServerDispatch(t *testing.T) {
+	s, address := newTestServer(t)
+	var calls []*hadoop_ipc_server.Call
+	hadoop_ipc_server.Register(s, yarnservice.HA_SERVICE_PROTOCOL, "getServiceStatus", func(ctx context.Context, request *hadoop_common.GetServiceStatusRequestProto) (*hadoop_common.GetServiceStatusResponseProto, error) {
+		call, ok := hadoop_ipc_server.CallFromContext(ctx)
+		assert.True(t, ok)
+		calls = append(calls, call)
+		return &hadoop_common.GetServiceStatusResponseProto{State: hadoop_common.HAServiceStateProto_STANDBY.Enum()}, nil
+	})
+	hadoop_ipc_server.Register(s, yarnservice.HA_SERVICE_PROTOCOL, "transitionToActive", func(ctx context.Context, request *hadoop_common.TransitionToActiveRequestProto) (*hadoop_common.TransitionToActiveResponseProto, error) {
+		assert.Equal(t, hadoop_common.HARequestSource_REQUEST_BY_USER_FORCED, request.GetReqInfo().GetReqSource())
+		return nil, hadoop_ipc_server.NewException(hadoop_ipc_client.StandbyException, "rm is fenced")
+	})
+	hadoop_ipc_server.Register(s, yarnservice.HA_SERVICE_PROTOCOL, "transitionToStandby", func(ctx context.Context, request *hadoop_common.TransitionToStandbyRequestProto) (*hadoop_common.TransitionToStandbyResponseProto, error) {
+		return nil, errors.New("disk is full")
+	})
+
+	client, err := yarnservice.DialHAServiceProtocolService(nil, address, security.NewRemoteUser("alice"))
+	assert.NoError(t, err)
+	status := &hadoop_common.GetServiceStatusResponseProto{}
+	assert.NoError(t, client.GetServiceStatus(&hadoop_common.GetServiceStatusRequestProto{}, status))
+	assert.Equal(t, hadoop_common.HAServiceStateProto_STANDBY, status.GetState())
+	if assert.Len(t, calls, 1) {
+		assert.Equal(t, yarnservice.HA_SERVICE_PROTOCOL, calls[0].Protocol)
+		assert.Equal(t, "getServiceStatus", calls[0].Method)
+		assert.Equal(t, "alice", calls[0].User)
+	}
+
+	err = client.TransitionToActive(&hadoop_common.TransitionToActiveRequestProto{ReqInfo: &hadoop_common.HAStateChangeRequestInfoProto{
+		ReqSource: hadoop_common.HARequestSource_REQUEST_BY_USER_FORCED.Enum()}}, &hadoop_common.TransitionToActiveResponseProto{})
+	assert.True(t, hadoop_ipc_client.IsStandby(err), "exceptions are responded with their class names")
+	assert.ErrorContains(t, err, "rm is fenced")
+
+	err = client.TransitionToStandby(&hadoop_common.TransitionToStandbyRequestProto{ReqInfo: &hadoop_common.HAStateChangeRequestInfoProto{
+		ReqSource: hadoop_common.HARequestSource_REQUEST_BY_USER.Enum()}}, &hadoop_common.TransitionToStandbyResponseProto{})
+	assert.ErrorContains(t, err, hadoop_ipc_client.IOException)
+	assert.ErrorContains(t, err, "disk is full")
+
+	err = client.MonitorHealth(&hadoop_common.MonitorHealthRequestProto{}, &hadoop_common.MonitorHealthResponseProto{})
+	assert.ErrorContains(t, err, hadoop_ipc_client.RpcNoSuchMethodException)
+
+	// the connection is still usable after errors
+	assert.NoError(t, client.GetServiceStatus(&hadoop_common.GetServiceStatusRequestProto{}, status))
+}
+
+func TestServerNoSuchProtocol(t *testing.T) {
+	_, address := newTestServer(t)
+	client, err := yarnservice.DialApplicationClientProtocolService(nil, proto.String(address), security.NewRemoteUser("alice"))
+	assert.NoError(t, err)
+	err = client.GetClusterMetrics(&hadoopyarn.GetClusterMetricsRequestProto{}, &hadoopyarn.GetClusterMetricsResponseProto{})
+	assert.ErrorContains(t, err, hadoop_ipc_client.RpcNoSuchProtocolException)
+}
+
+// testHAService is the HAServiceProtocolServiceServer of a rm transitioning between active and standby
+type testHAService struct {
+	state hadoop_common.HAServiceStateProto
+}
+
+func (s *testHAService) MonitorHealth(ctx context.Context, in *hadoop_common.MonitorHealthRequestProto) (*hadoop_common.MonitorHealthResponseProto, error) {
+	return &hadoop_common.MonitorHealthResponseProto{}, nil
+}
+
+func (s *testHAService) TransitionToActive(ctx context.Context, in *hadoop_common.TransitionToActiveRequestProto) (*hadoop_common.TransitionToActiveResponseProto, error) {
+	s.state = hadoop_common.HAServiceStateProto_ACTIVE
+	return &hadoop_common.TransitionToActiveResponseProto{}, nil
+}
+
+func (s *testHAService) TransitionToStandby(ctx context.Context, in *hadoop_common.TransitionToStandbyRequestProto) (*hadoop_common.TransitionToStandbyResponseProto, error) {
+	s.state = hadoop_common.HAServiceStateProto_STANDBY
+	return &hadoop_common.TransitionToStandbyResponseProto{}, nil
+}
+
+func (s *testHAService) TransitionToObserver(ctx context.Context, in *hadoop_common.TransitionToObserverRequestProto) (*hadoop_common.TransitionToObserverResponseProto, error) {
+	return nil, hadoop_ipc_server.NewException(hadoop_ipc_client.IllegalArgumentException, "observer is not supported")
+}
+
+func (s *testHAService) GetServiceStatus(ctx context.Context, in *hadoop_common.GetServiceStatusRequestProto) (*hadoop_common.GetServiceStatusResponseProto, error) {
+	return &hadoop_common.GetServiceStatusResponseProto{State: s.state.Enum(), ReadyToBecomeActive: proto.Bool(true)}, nil
+}
+
+func TestServerRegisterGeneratedService(t *testing.T) {
+	s, address := newTestServer(t)
+	yarnserviceserver.RegisterHAServiceProtocolServiceServer(s, &testHAService{state: hadoop_common.HAServiceStateProto_STANDBY})
+
+	client, err := yarnservice.DialHAServiceProtocolService(nil, address, security.NewRemoteUser("alice"))
+	assert.NoError(t, err)
+	status := &hadoop_common.GetServiceStatusResponseProto{}
+	assert.NoError(t, client.GetServiceStatus(&hadoop_common.GetServiceStatusRequestProto{}, status))
+	assert.Equal(t, hadoop_common.HAServiceStateProto_STANDBY, status.GetState())
+
+	assert.NoError(t, client.TransitionToActive(&hadoop_common.TransitionToActiveRequestProto{ReqInfo: &hadoop_common.HAStateChangeRequestInfoProto{
+		ReqSource: hadoop_common.HARequestSource_REQUEST_BY_USER.Enum()}}, &hadoop_common.TransitionToActiveResponseProto{}))
+	assert.NoError(t, client.GetServiceStatus(&hadoop_common.GetServiceStatusRequestProto{}, status))
+	assert.Equal(t, hadoop_common.HAServiceStateProto_ACTIVE, status.GetState())
+
+	err = client.TransitionToObserver(&hadoop_common.TransitionToObserverRequestProto{ReqInfo: &hadoop_common.HAStateChangeRequestInfoProto{
+		ReqSource: hadoop_common.HARequestSource_REQUEST_BY_USER.Enum()}}, &hadoop_common.TransitionToObserverResponseProto{})
+	assert.True(t, hadoop_ipc_client.IsException(err, hadoop_ipc_client.IllegalArgumentException), "unexpected error %v", err)
+}